@@ -5,12 +5,15 @@ import (
 	_ "CRUD_Hexagonal/domain/product" // Mengimpor package domain (penggunaan implicit)
 	"CRUD_Hexagonal/infrastructure"   // Mengimpor package infrastructure untuk tracing dan logging
 	"CRUD_Hexagonal/utils"            // Mengimpor package utils untuk fungsi-fungsi utilitas seperti respon HTTP dan validasi
+	"encoding/json"                   // Mengimpor encoding/json untuk projection field pada response
 	"errors"                          // Mengimpor package errors untuk menangani error
+	"fmt"                             // Mengimpor fmt untuk menambahkan petunjuk pada pesan error
 	"net/http"                        // Mengimpor net/http untuk status code HTTP
+	"strconv"                         // Mengimpor strconv untuk membentuk dan membaca ETag
+	"strings"                         // Mengimpor strings untuk memecah header If-Match
 
-	"github.com/gofiber/fiber/v2"                // Mengimpor Fiber untuk membuat handler HTTP
-	"go.mongodb.org/mongo-driver/bson/primitive" // Mengimpor MongoDB primitive untuk menangani ObjectID
-	"golang.org/x/exp/slog"                      // Mengimpor Slog untuk logging
+	"github.com/gofiber/fiber/v2" // Mengimpor Fiber untuk membuat handler HTTP
+	"golang.org/x/exp/slog"       // Mengimpor Slog untuk logging
 )
 
 // Struktur adapter yang menyimpan referensi ke service yang berhubungan dengan Product
@@ -32,17 +35,43 @@ func (h *adapter) Get(ctx *fiber.Ctx) error {
 	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:Get")
 	defer span.End()
 
-	// Mengambil id dari URL parameter
-	id := ctx.Params("id")
+	// Mengambil id dari URL parameter dan memparsingnya menjadi ID domain
+	id, errID := product.ParseID(ctx.Params("id"))
+	if errID != nil {
+		// Jika id tidak valid, kembalikan response dengan status Unprocessable Entity
+		errorResponse(ctx, nil, errID)
+		return nil
+	}
 	// Memanggil service untuk mencari product berdasarkan id
 	resp, err := h.storeService.Find(c, id)
 	if err != nil {
-		// Jika terjadi error, kembalikan response dengan status Bad Request
-		utils.ResponseWithJSON(ctx, http.StatusBadRequest, *resp, err)
+		// Jika product tidak ditemukan atau terjadi error, status response ditentukan oleh kategori error
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+	// Jika berhasil, kembalikan response dengan status OK, data product, dan versinya sebagai ETag
+	ctx.Set(fiber.HeaderETag, etag(resp.Version))
+	utils.Respond(ctx, http.StatusOK, resp, nil)
+	return nil
+}
+
+// Fungsi GetByCode adalah handler untuk endpoint GET /product/code/:code
+// Fungsi ini mengambil satu product berdasarkan kode product (SKU) yang diterima dari parameter URL
+func (h *adapter) GetByCode(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi GetByCode
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:GetByCode")
+	defer span.End()
+
+	// Memanggil service untuk mencari product berdasarkan kode
+	resp, err := h.storeService.FindByCode(c, ctx.Params("code"))
+	if err != nil {
+		// Jika product tidak ditemukan atau terjadi error, status response ditentukan oleh kategori error
+		errorResponse(ctx, nil, err)
 		return nil
 	}
-	// Jika berhasil, kembalikan response dengan status OK dan data product
-	utils.ResponseWithJSON(ctx, http.StatusOK, resp, nil)
+	// Jika berhasil, kembalikan response dengan status OK, data product, dan versinya sebagai ETag
+	ctx.Set(fiber.HeaderETag, etag(resp.Version))
+	utils.Respond(ctx, http.StatusOK, resp, nil)
 	return nil
 }
 
@@ -53,26 +82,190 @@ func (h *adapter) GetAll(ctx *fiber.Ctx) error {
 	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:GetAll")
 	defer span.End()
 
-	// Membuat filter berdasarkan query parameters
-	filter := product.Filter{
-		Page:      ctx.QueryInt("page"),
-		Limit:     ctx.QueryInt("limit"),
-		Latitude:  ctx.Query("latitude"),
-		Longitude: ctx.Query("longitude"),
-		Keyword:   ctx.Query("keyword"),
+	// Memparsing filter dari query parameters, parameter yang tidak valid menghasilkan 422
+	filter, errFilter := parseFilter(ctx)
+	if errFilter != nil {
+		errorResponse(ctx, []*product.Product{}, errFilter)
+		return nil
 	}
 	// Memanggil service untuk mencari semua product berdasarkan filter
 	p, pagination, err := h.storeService.FindAll(c, filter)
 	if err != nil {
-		// Jika terjadi error, kembalikan response dengan status Bad Request
-		utils.ResponseWithJSON(ctx, http.StatusBadRequest, []*product.Product{}, err)
+		// Jika cursor atau urutan tidak valid (422) atau terjadi error lain, status response ditentukan oleh kategori error
+		errorResponse(ctx, []*product.Product{}, err)
 		return nil
 	}
+	// Jika projection diminta, response hanya berisi field yang dipilih
+	if len(filter.Fields) > 0 {
+		projected, err := projectProducts(p, filter.Fields)
+		if err != nil {
+			errorResponse(ctx, []*product.Product{}, err)
+			return nil
+		}
+		utils.Respond(ctx, http.StatusOK, projected, nil, pagination)
+		return nil
+	}
+
 	// Jika berhasil, kembalikan response dengan status OK, data product, dan informasi pagination
-	utils.ResponseWithJSON(ctx, http.StatusOK, p, nil, pagination)
+	utils.Respond(ctx, http.StatusOK, p, nil, pagination)
+	return nil
+}
+
+// Fungsi Search adalah handler untuk endpoint GET /product/search
+// Fungsi ini mencari product berdasarkan kata pada nama dan deskripsi dan mengembalikan skor relevansi serta highlight
+func (h *adapter) Search(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi Search
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:Search")
+	defer span.End()
+
+	query := product.SearchQuery{
+		Query: ctx.Query("q"),
+		Page:  ctx.QueryInt("page"),
+		Limit: ctx.QueryInt("limit"),
+	}
+	hits, pagination, err := h.storeService.Search(c, query)
+	if err != nil {
+		// Jika kata kunci kosong atau terlalu panjang (422) atau terjadi error lain, status response ditentukan oleh kategori error
+		errorResponse(ctx, []*product.SearchHit{}, err)
+		return nil
+	}
+	utils.Respond(ctx, http.StatusOK, hits, nil, pagination)
 	return nil
 }
 
+// parseFilter memparsing query parameters GET /product menjadi Filter. Parameter pencarian berdasarkan jarak, urutan,
+// projection, dan ekspresi filter yang tidak valid dikembalikan sebagai error validasi.
+func parseFilter(ctx *fiber.Ctx) (product.Filter, error) {
+	// Memparsing parameter pencarian berdasarkan jarak
+	near, err := product.ParseGeoQuery(ctx.Query("latitude"), ctx.Query("longitude"), ctx.Query("radius_km"))
+	if err != nil {
+		return product.Filter{}, err
+	}
+
+	// Memparsing urutan dan projection, field di luar whitelist ditolak
+	sorts, err := product.ParseSort(ctx.Query("sort"))
+	if err != nil {
+		return product.Filter{}, err
+	}
+	fields, err := product.ParseFields(ctx.Query("fields"))
+	if err != nil {
+		return product.Filter{}, err
+	}
+
+	// Memparsing ekspresi filter berbentuk field[operator]=nilai, parameter lain diabaikan
+	var conditions []product.Condition
+	var errCondition error
+	ctx.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		if errCondition != nil {
+			return
+		}
+		condition, err := product.ParseCondition(string(key), string(value))
+		if err != nil {
+			errCondition = err
+			return
+		}
+		if condition != nil {
+			conditions = append(conditions, *condition)
+		}
+	})
+	if errCondition != nil {
+		return product.Filter{}, errCondition
+	}
+
+	return product.Filter{
+		Page:    ctx.QueryInt("page"),
+		Limit:   ctx.QueryInt("limit"),
+		Keyword: ctx.Query("keyword"),
+
+		IncludeDeleted: ctx.QueryBool("include_deleted"),
+		Near:           near,
+
+		// Parameter cursor (walaupun kosong) mengaktifkan cursor pagination, cursor kosong berarti halaman pertama
+		UseCursor: ctx.Context().QueryArgs().Has("cursor"),
+		Cursor:    ctx.Query("cursor"),
+
+		Sort:   sorts,
+		Fields: fields,
+
+		Conditions: conditions,
+	}, nil
+}
+
+// projectProducts mengubah setiap product menjadi map yang hanya berisi field JSON yang dipilih,
+// sehingga field yang tidak diminta tidak muncul sebagai nilai kosong pada response.
+func projectProducts(products []*product.Product, fields []string) ([]map[string]json.RawMessage, error) {
+	projected := make([]map[string]json.RawMessage, 0, len(products))
+	for _, p := range products {
+		raw, err := json.Marshal(p)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &all); err != nil {
+			return nil, err
+		}
+
+		item := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				item[field] = value
+			}
+		}
+		projected = append(projected, item)
+	}
+	return projected, nil
+}
+
+// etag membentuk nilai header ETag (strong) dari versi product
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// expectedVersion membaca header If-Match menjadi versi yang diharapkan oleh repository.
+// Nilai "*" menghasilkan 0 (tanpa pemeriksaan versi). Jika header berisi beberapa ETag,
+// versi product saat ini dibaca melalui current dan digunakan bila termasuk dalam daftar.
+// ETag lemah (W/) atau yang bukan versi product tidak pernah cocok.
+func expectedVersion(ctx *fiber.Ctx, current func() (*product.Product, error)) (int64, error) {
+	header := strings.TrimSpace(ctx.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		return 0, errPreconditionRequired
+	}
+	if header == "*" {
+		return 0, nil
+	}
+
+	var versions []int64
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err != nil || version <= 0 {
+			continue
+		}
+		versions = append(versions, version)
+	}
+
+	switch len(versions) {
+	case 0:
+		return 0, product.ErrVersionConflict
+	case 1:
+		return versions[0], nil
+	}
+
+	stored, err := current()
+	if err != nil {
+		return 0, err
+	}
+	for _, version := range versions {
+		if version == stored.Version {
+			return version, nil
+		}
+	}
+	return 0, product.ErrVersionConflict
+}
+
 // Fungsi Create adalah handler untuk endpoint POST /product
 // Fungsi ini membuat product baru berdasarkan data yang dikirim melalui request body
 func (h *adapter) Create(ctx *fiber.Ctx) error {
@@ -85,28 +278,28 @@ func (h *adapter) Create(ctx *fiber.Ctx) error {
 	dataStore := &product.Product{}
 	if err := ctx.BodyParser(&dataStore); err != nil {
 		// Jika terjadi error saat parsing, kembalikan response dengan status Bad Request
-		utils.ResponseWithJSON(ctx, http.StatusBadRequest, nil, err)
+		statusResponse(ctx, http.StatusBadRequest, nil, err)
 		return nil
 	}
 
 	// Melakukan validasi terhadap data product yang diterima
-	errValidation := utils.Validate(dataStore)
-	if errValidation != "" {
+	if fieldErrors := utils.Validate(dataStore); len(fieldErrors) > 0 {
 		// Jika validasi gagal, log error dan kembalikan response dengan status Unprocessable Entity
-		slog.ErrorContext(c, "Failed to Validate api:product:Create", slog.Any("err ", errValidation))
-		utils.ResponseWithJSON(ctx, http.StatusUnprocessableEntity, nil, errors.New(errValidation))
+		slog.ErrorContext(c, "Failed to Validate api:product:Create", slog.Any("err ", fieldErrors))
+		validationResponse(ctx, fieldErrors)
 		return nil
 	}
 
 	// Memanggil service untuk menyimpan product baru
 	resp, err := h.storeService.Store(c, dataStore)
 	if err != nil {
-		// Jika terjadi error saat penyimpanan, kembalikan response dengan status Bad Request
-		utils.ResponseWithJSON(ctx, http.StatusBadRequest, nil, err)
+		// Stok awal negatif atau koordinat tidak valid (422), kode product yang sudah digunakan (409),
+		// atau error lain, status response ditentukan oleh kategori error
+		errorResponse(ctx, nil, err)
 		return nil
 	}
 	// Jika berhasil, kembalikan response dengan status OK dan data product yang disimpan
-	utils.ResponseWithJSON(ctx, http.StatusOK, resp, nil)
+	utils.Respond(ctx, http.StatusOK, resp, nil)
 	return nil
 
 }
@@ -125,61 +318,563 @@ func (h adapter) Update(ctx *fiber.Ctx) error {
 	dataStore := &product.Product{}
 	if err := ctx.BodyParser(&dataStore); err != nil {
 		// Jika terjadi error saat parsing, kembalikan response dengan status Bad Request
-		utils.ResponseWithJSON(ctx, http.StatusBadRequest, nil, err)
+		statusResponse(ctx, http.StatusBadRequest, nil, err)
 		return nil
 	}
 
-	// Melakukan validasi terhadap data product yang diterima
-	errValidation := utils.Validate(dataStore)
-	if errValidation != "" {
+	// Melakukan validasi terhadap field yang diisi, field yang kosong tidak diubah
+	if fieldErrors := utils.ValidatePartial(dataStore); len(fieldErrors) > 0 {
 		// Jika validasi gagal, log error dan kembalikan response dengan status Unprocessable Entity
-		slog.ErrorContext(c, "Failed to Validate api:product:Create", slog.Any("err ", errValidation))
-		utils.ResponseWithJSON(ctx, http.StatusUnprocessableEntity, nil, errors.New(errValidation))
+		slog.ErrorContext(c, "Failed to Validate api:product:Update", slog.Any("err ", fieldErrors))
+		validationResponse(ctx, fieldErrors)
 		return nil
 	}
 
-	// Memparsing id dari string ke ObjectID (untuk digunakan di MongoDB)
-	id, errObjectID := primitive.ObjectIDFromHex(paramsID)
-	if errObjectID != nil {
-		// Jika terjadi error saat parsing ObjectID, log error dan kembalikan response dengan status Unprocessable Entity
-		slog.ErrorContext(c, "Failed to Validate api:product:Create", slog.Any("err ", errObjectID))
-		utils.ResponseWithJSON(ctx, http.StatusUnprocessableEntity, nil, errObjectID)
+	// Memparsing id dari string menjadi ID domain, format khusus database diterjemahkan oleh repository
+	id, errID := product.ParseID(paramsID)
+	if errID != nil {
+		// Jika terjadi error saat parsing ID, log error dan kembalikan response dengan status Unprocessable Entity
+		slog.ErrorContext(c, "Failed to Validate api:product:Create", slog.Any("err ", errID))
+		errorResponse(ctx, nil, errID)
 		return nil
 	}
 	// Mengatur id pada data product
 	dataStore.ID = id
 
+	// Versi yang diharapkan diambil dari header If-Match, bukan dari request body
+	version, errVersion := expectedVersion(ctx, func() (*product.Product, error) {
+		return h.storeService.Find(c, id)
+	})
+	if errVersion != nil {
+		// Jika header If-Match tidak ada (428) atau tidak cocok (412), kembalikan response sesuai kategori error
+		errorResponse(ctx, nil, errVersion)
+		return nil
+	}
+	dataStore.Version = version
+
 	// Memanggil service untuk memperbarui product
 	err := h.storeService.Update(c, dataStore)
+	if errors.Is(err, product.ErrStockNotUpdatable) {
+		// Klien diarahkan ke endpoint yang mencatat perubahan stok di buku besar stok
+		err = fmt.Errorf("%w, use POST /product/:id/stock/adjust or PATCH /product/:id", err)
+	}
+	if err != nil {
+		// Product yang sudah diubah pihak lain (412), koordinat tidak valid (422), kode product yang sudah
+		// digunakan product lain (409), atau error lain, status response ditentukan oleh kategori error
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+
+	// Request body hanya berisi field yang diubah, sehingga product dibaca ulang agar response berisi seluruh data tersimpan
+	resp, err := h.storeService.Find(c, id)
+	if err != nil {
+		// Product dihapus pihak lain setelah diperbarui (404) atau error lain, status response ditentukan oleh kategori error
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+
+	// Jika berhasil, kembalikan response dengan status OK, data product yang tersimpan, dan ETag versinya
+	ctx.Set(fiber.HeaderETag, etag(resp.Version))
+	utils.Respond(ctx, http.StatusOK, resp, nil)
+	return nil
+}
+
+// patchTypes memetakan Content-Type request PATCH ke format dokumen patch, application/json dianggap JSON Merge Patch
+var patchTypes = map[string]product.PatchType{
+	fiber.MIMEApplicationJSON:  product.MergePatch,
+	string(product.MergePatch): product.MergePatch,
+	string(product.JSONPatch):  product.JSONPatch,
+}
+
+// Fungsi Patch adalah handler untuk endpoint PATCH /product/:id
+// Fungsi ini menerapkan dokumen JSON Merge Patch (RFC 7396) atau JSON Patch (RFC 6902) pada product.
+// Perubahan stock dicatat di buku besar stok sebagai pergerakan correction sebesar selisih stok baru dan stok saat ini
+func (h *adapter) Patch(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi Patch
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:Patch")
+	defer span.End()
+
+	// Format dokumen ditentukan dari Content-Type tanpa parameter seperti charset
+	mediaType, _, _ := strings.Cut(ctx.Get(fiber.HeaderContentType), ";")
+	patchType, ok := patchTypes[strings.ToLower(strings.TrimSpace(mediaType))]
+	if !ok {
+		// Jika Content-Type tidak didukung, kembalikan response dengan status Unsupported Media Type
+		statusResponse(ctx, http.StatusUnsupportedMediaType, nil, errors.New("unsupported patch content type"))
+		return nil
+	}
+
+	// Mengambil id dari URL parameter dan memparsingnya menjadi ID domain
+	id, errID := product.ParseID(ctx.Params("id"))
+	if errID != nil {
+		// Jika id tidak valid, kembalikan response dengan status Unprocessable Entity
+		errorResponse(ctx, nil, errID)
+		return nil
+	}
+
+	// Versi yang diharapkan diambil dari header If-Match, sama seperti PUT
+	version, err := expectedVersion(ctx, func() (*product.Product, error) {
+		return h.storeService.Find(c, id)
+	})
+	var resp *product.Product
+	if err == nil {
+		// Memanggil service untuk menerapkan dokumen patch
+		resp, err = h.storeService.Patch(c, id, product.PatchDocument{Type: patchType, Body: ctx.Body()}, version)
+	}
 	if err != nil {
-		// Jika terjadi error saat update, kembalikan response dengan status Bad Request
-		utils.ResponseWithJSON(ctx, http.StatusBadRequest, nil, err)
+		// Header If-Match yang tidak ada atau tidak cocok (428/412), dokumen patch atau product hasil patch yang tidak valid (422),
+		// operasi test yang tidak cocok atau kode product yang sudah digunakan (409), status response ditentukan oleh kategori error
+		errorResponse(ctx, nil, err)
 		return nil
 	}
 
-	// Jika berhasil, kembalikan response dengan status OK dan data product yang diperbarui
-	utils.ResponseWithJSON(ctx, http.StatusOK, dataStore, nil)
+	// Jika berhasil, kembalikan product hasil patch beserta ETag versi barunya
+	ctx.Set(fiber.HeaderETag, etag(resp.Version))
+	utils.Respond(ctx, http.StatusOK, resp, nil)
 	return nil
 }
 
 // Fungsi Delete adalah handler untuk endpoint DELETE /product/:id
-// Fungsi ini menghapus product berdasarkan id yang diterima dari parameter URL
+// Fungsi ini menandai product sebagai dihapus (soft delete) berdasarkan id yang diterima dari parameter URL
 func (h *adapter) Delete(ctx *fiber.Ctx) error {
 	// Tracing dimulai untuk memantau eksekusi fungsi Delete
 	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:DeleteByID")
 	defer span.End()
 
-	// Mengambil id dari URL parameter
-	id := ctx.Params("id")
-	// Memanggil service untuk menghapus product berdasarkan id
-	err := h.storeService.DeleteById(c, id)
+	// Mengambil id dari URL parameter dan memparsingnya menjadi ID domain
+	id, errID := product.ParseID(ctx.Params("id"))
+	if errID != nil {
+		// Jika id tidak valid, kembalikan response dengan status Unprocessable Entity
+		errorResponse(ctx, nil, errID)
+		return nil
+	}
+	// Memanggil service untuk menghapus product berdasarkan id dengan versi dari header If-Match
+	version, err := expectedVersion(ctx, func() (*product.Product, error) {
+		return h.storeService.Find(c, id)
+	})
+	if err == nil {
+		err = h.storeService.DeleteById(c, id, version)
+	}
+	if err != nil {
+		// Header If-Match yang tidak ada atau tidak cocok (428/412), product yang tidak ditemukan (404),
+		// atau error lain, status response ditentukan oleh kategori error
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+
+	// Jika berhasil, kembalikan response dengan status OK tanpa data, dalam format sesuai header Accept
+	utils.Respond(ctx, http.StatusOK, nil, nil)
+	return nil
+}
+
+// Fungsi DeleteByCode adalah handler untuk endpoint DELETE /product/code/:code
+// Fungsi ini menandai product sebagai dihapus (soft delete) berdasarkan kode product (SKU) dari parameter URL
+func (h *adapter) DeleteByCode(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi DeleteByCode
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:DeleteByCode")
+	defer span.End()
+
+	// Memanggil service untuk menghapus product berdasarkan kode dengan versi dari header If-Match
+	code := ctx.Params("code")
+	version, err := expectedVersion(ctx, func() (*product.Product, error) {
+		return h.storeService.FindByCode(c, code)
+	})
+	if err == nil {
+		err = h.storeService.Delete(c, code, version)
+	}
+	if err != nil {
+		// Header If-Match yang tidak ada atau tidak cocok (428/412), product yang tidak ditemukan (404),
+		// atau error lain, status response ditentukan oleh kategori error
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+
+	// Jika berhasil, kembalikan response dengan status OK tanpa data, dalam format sesuai header Accept
+	utils.Respond(ctx, http.StatusOK, nil, nil)
+	return nil
+}
+
+// Fungsi Restore adalah handler untuk endpoint POST /product/:id/restore
+// Fungsi ini memulihkan product yang sudah di-soft delete berdasarkan id yang diterima dari parameter URL
+func (h *adapter) Restore(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi Restore
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:Restore")
+	defer span.End()
+
+	// Mengambil id dari URL parameter dan memparsingnya menjadi ID domain
+	id, errID := product.ParseID(ctx.Params("id"))
+	if errID != nil {
+		// Jika id tidak valid, kembalikan response dengan status Unprocessable Entity
+		errorResponse(ctx, nil, errID)
+		return nil
+	}
+
+	// Memanggil service untuk memulihkan product berdasarkan id
+	resp, err := h.storeService.Restore(c, id)
+	if err != nil {
+		// Jika product tidak ditemukan di antara product yang dihapus atau terjadi error, status response ditentukan oleh kategori error
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+
+	// Jika berhasil, kembalikan response dengan status OK, data product yang dipulihkan, dan ETag versi barunya
+	ctx.Set(fiber.HeaderETag, etag(resp.Version))
+	utils.Respond(ctx, http.StatusOK, resp, nil)
+	return nil
+}
+
+// Fungsi Purge adalah handler untuk endpoint admin POST /admin/product/purge
+// Fungsi ini menghapus permanen product yang sudah di-soft delete lebih lama dari masa retensi
+func (h *adapter) Purge(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi Purge
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:Purge")
+	defer span.End()
+
+	// Memanggil service untuk menghapus permanen product yang sudah melewati masa retensi
+	purged, err := h.storeService.Purge(c)
+	if err != nil {
+		// Jika terjadi error saat purge, status response ditentukan oleh kategori error
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+
+	// Jika berhasil, kembalikan jumlah product yang dihapus permanen
+	utils.Respond(ctx, http.StatusOK, fiber.Map{"purged": purged}, nil)
+	return nil
+}
+
+// Fungsi AdjustStock adalah handler untuk endpoint POST /product/:id/stock/adjust
+// Fungsi ini mengubah stok product sebesar jumlah bertanda (signed) dengan kode alasan yang dikirim melalui request body
+func (h *adapter) AdjustStock(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi AdjustStock
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:AdjustStock")
+	defer span.End()
+
+	// Mengambil id dari URL parameter dan memparsingnya menjadi ID domain
+	id, errID := product.ParseID(ctx.Params("id"))
+	if errID != nil {
+		// Jika id tidak valid, kembalikan response dengan status Unprocessable Entity
+		errorResponse(ctx, nil, errID)
+		return nil
+	}
+
+	// Mem-parsing jumlah, kode alasan, dan catatan dari request body
+	adjustment := product.StockAdjustment{}
+	if err := ctx.BodyParser(&adjustment); err != nil {
+		// Jika terjadi error saat parsing, kembalikan response dengan status Bad Request
+		statusResponse(ctx, http.StatusBadRequest, nil, err)
+		return nil
+	}
+
+	// Memanggil service untuk mengubah stok dan mencatat pergerakannya
+	movement, err := h.storeService.AdjustStock(c, id, adjustment)
+	if err != nil {
+		// Jumlah 0 atau kode alasan tidak dikenal (422), product atau lokasi tidak ditemukan (404),
+		// atau stok tidak mencukupi (409), status response ditentukan oleh kategori error
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+
+	// Jika berhasil, kembalikan pergerakan stok yang dicatat beserta stok setelah perubahan
+	utils.Respond(ctx, http.StatusOK, movement, nil)
+	return nil
+}
+
+// Fungsi GetMovements adalah handler untuk endpoint GET /product/:id/stock/movements
+// Fungsi ini mengambil riwayat pergerakan stok product, dari yang terbaru, dengan pagination
+func (h *adapter) GetMovements(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi GetMovements
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:GetMovements")
+	defer span.End()
+
+	// Mengambil id dari URL parameter dan memparsingnya menjadi ID domain
+	id, errID := product.ParseID(ctx.Params("id"))
+	if errID != nil {
+		// Jika id tidak valid, kembalikan response dengan status Unprocessable Entity
+		errorResponse(ctx, nil, errID)
+		return nil
+	}
+
+	// Membuat filter pagination berdasarkan query parameters
+	filter := product.MovementFilter{
+		Page:  ctx.QueryInt("page"),
+		Limit: ctx.QueryInt("limit"),
+	}
+
+	// Memanggil service untuk mengambil riwayat pergerakan stok
+	movements, pagination, err := h.storeService.FindMovements(c, id, filter)
+	if err != nil {
+		// Jika terjadi error, status response ditentukan oleh kategori error
+		errorResponse(ctx, []*product.StockMovement{}, err)
+		return nil
+	}
+
+	// Jika berhasil, kembalikan response dengan status OK, riwayat pergerakan stok, dan informasi pagination
+	utils.Respond(ctx, http.StatusOK, movements, nil, pagination)
+	return nil
+}
+
+// Fungsi Reserve adalah handler untuk endpoint POST /product/:id/reservations
+// Fungsi ini menahan sejumlah stok product selama TTL tertentu untuk proses checkout
+func (h *adapter) Reserve(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi Reserve
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:Reserve")
+	defer span.End()
+
+	// Mengambil id dari URL parameter dan memparsingnya menjadi ID domain
+	id, errID := product.ParseID(ctx.Params("id"))
+	if errID != nil {
+		// Jika id tidak valid, kembalikan response dengan status Unprocessable Entity
+		errorResponse(ctx, nil, errID)
+		return nil
+	}
+
+	// Mem-parsing jumlah dan TTL reservasi dari request body
+	request := product.ReservationRequest{}
+	if err := ctx.BodyParser(&request); err != nil {
+		// Jika terjadi error saat parsing, kembalikan response dengan status Bad Request
+		statusResponse(ctx, http.StatusBadRequest, nil, err)
+		return nil
+	}
+
+	// Memanggil service untuk menahan stok
+	reservation, err := h.storeService.Reserve(c, id, request)
+	if err != nil {
+		// Jumlah atau TTL tidak valid (422), product tidak ditemukan (404), atau stok yang tersedia
+		// tidak mencukupi (409), status response ditentukan oleh kategori error
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+
+	// Jika berhasil, kembalikan reservasi yang dibuat
+	utils.Respond(ctx, http.StatusOK, reservation, nil)
+	return nil
+}
+
+// Fungsi GetReservation adalah handler untuk endpoint GET /reservations/:id
+// Fungsi ini mengambil satu reservasi berdasarkan id yang diterima dari parameter URL
+func (h *adapter) GetReservation(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi GetReservation
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:GetReservation")
+	defer span.End()
+
+	// Mengambil id reservasi dari URL parameter dan memparsingnya menjadi ID domain
+	id, errID := product.ParseID(ctx.Params("id"))
+	if errID != nil {
+		// Jika id tidak valid, kembalikan response dengan status Unprocessable Entity
+		errorResponse(ctx, nil, errID)
+		return nil
+	}
+
+	// Memanggil service untuk mencari reservasi
+	reservation, err := h.storeService.FindReservation(c, id)
+	h.reservationResponse(ctx, reservation, err)
+	return nil
+}
+
+// Fungsi ConfirmReservation adalah handler untuk endpoint POST /reservations/:id/confirm
+// Fungsi ini mengonfirmasi reservasi aktif sehingga stok product berkurang
+func (h *adapter) ConfirmReservation(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi ConfirmReservation
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:ConfirmReservation")
+	defer span.End()
+
+	// Mengambil id reservasi dari URL parameter dan memparsingnya menjadi ID domain
+	id, errID := product.ParseID(ctx.Params("id"))
+	if errID != nil {
+		// Jika id tidak valid, kembalikan response dengan status Unprocessable Entity
+		errorResponse(ctx, nil, errID)
+		return nil
+	}
+
+	// Memanggil service untuk mengonfirmasi reservasi
+	reservation, err := h.storeService.ConfirmReservation(c, id)
+	h.reservationResponse(ctx, reservation, err)
+	return nil
+}
+
+// Fungsi ReleaseReservation adalah handler untuk endpoint POST /reservations/:id/release
+// Fungsi ini membatalkan reservasi aktif sehingga stok yang ditahan dapat dijual kembali
+func (h *adapter) ReleaseReservation(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi ReleaseReservation
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:ReleaseReservation")
+	defer span.End()
+
+	// Mengambil id reservasi dari URL parameter dan memparsingnya menjadi ID domain
+	id, errID := product.ParseID(ctx.Params("id"))
+	if errID != nil {
+		// Jika id tidak valid, kembalikan response dengan status Unprocessable Entity
+		errorResponse(ctx, nil, errID)
+		return nil
+	}
+
+	// Memanggil service untuk melepas reservasi
+	reservation, err := h.storeService.ReleaseReservation(c, id)
+	h.reservationResponse(ctx, reservation, err)
+	return nil
+}
+
+// reservationResponse menulis response untuk endpoint reservasi beserta status code sesuai error dari service
+func (h *adapter) reservationResponse(ctx *fiber.Ctx, reservation *product.Reservation, err error) {
+	if err != nil {
+		// Reservasi tidak ditemukan (404) atau sudah dikonfirmasi, dilepas, atau kedaluwarsa (409),
+		// status response ditentukan oleh kategori error
+		errorResponse(ctx, nil, err)
+		return
+	}
+	// Jika berhasil, kembalikan data reservasi
+	utils.Respond(ctx, http.StatusOK, reservation, nil)
+}
+
+// Fungsi CreateLocation adalah handler untuk endpoint POST /locations
+// Fungsi ini membuat lokasi baru (gudang atau toko) dari data yang dikirim melalui request body
+func (h *adapter) CreateLocation(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi CreateLocation
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:CreateLocation")
+	defer span.End()
+
+	// Mem-parsing kode, nama, dan tipe lokasi dari request body
+	location := product.Location{}
+	if err := ctx.BodyParser(&location); err != nil {
+		// Jika terjadi error saat parsing, kembalikan response dengan status Bad Request
+		statusResponse(ctx, http.StatusBadRequest, nil, err)
+		return nil
+	}
+
+	// Memanggil service untuk menyimpan lokasi
+	res, err := h.storeService.CreateLocation(c, &location)
+	h.locationResponse(ctx, res, err)
+	return nil
+}
+
+// Fungsi GetLocations adalah handler untuk endpoint GET /locations
+// Fungsi ini mengambil semua lokasi yang terdaftar
+func (h *adapter) GetLocations(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi GetLocations
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:GetLocations")
+	defer span.End()
+
+	// Memanggil service untuk mengambil semua lokasi
+	locations, err := h.storeService.FindLocations(c)
 	if err != nil {
-		// Jika terjadi error saat penghapusan, kembalikan response dengan status Internal Server Error
-		return ctx.Status(500).JSON(fiber.Map{"message": err.Error()})
+		// Jika terjadi error, status response ditentukan oleh kategori error
+		errorResponse(ctx, []*product.Location{}, err)
+		return nil
+	}
+
+	// Jika berhasil, kembalikan daftar lokasi
+	utils.Respond(ctx, http.StatusOK, locations, nil)
+	return nil
+}
+
+// Fungsi GetLocation adalah handler untuk endpoint GET /locations/:id
+// Fungsi ini mengambil satu lokasi berdasarkan id yang diterima dari parameter URL
+func (h *adapter) GetLocation(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi GetLocation
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:GetLocation")
+	defer span.End()
+
+	// Mengambil id lokasi dari URL parameter dan memparsingnya menjadi ID domain
+	id, errID := product.ParseID(ctx.Params("id"))
+	if errID != nil {
+		// Jika id tidak valid, kembalikan response dengan status Unprocessable Entity
+		errorResponse(ctx, nil, errID)
+		return nil
+	}
+
+	// Memanggil service untuk mencari lokasi
+	location, err := h.storeService.FindLocation(c, id)
+	h.locationResponse(ctx, location, err)
+	return nil
+}
+
+// Fungsi GetLocationStock adalah handler untuk endpoint GET /locations/:id/stock
+// Fungsi ini mengambil stok setiap product pada sebuah lokasi
+func (h *adapter) GetLocationStock(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi GetLocationStock
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:GetLocationStock")
+	defer span.End()
+
+	// Mengambil id lokasi dari URL parameter dan memparsingnya menjadi ID domain
+	id, errID := product.ParseID(ctx.Params("id"))
+	if errID != nil {
+		// Jika id tidak valid, kembalikan response dengan status Unprocessable Entity
+		errorResponse(ctx, nil, errID)
+		return nil
+	}
+
+	// Memanggil service untuk mengambil stok pada lokasi
+	stocks, err := h.storeService.FindStockByLocation(c, id)
+	if err == nil && stocks == nil {
+		stocks = []*product.LocationStock{}
+	}
+	h.locationResponse(ctx, stocks, err)
+	return nil
+}
+
+// Fungsi GetProductStock adalah handler untuk endpoint GET /product/:id/stock
+// Fungsi ini mengambil stok product pada setiap lokasi
+func (h *adapter) GetProductStock(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi GetProductStock
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:GetProductStock")
+	defer span.End()
+
+	// Mengambil id dari URL parameter dan memparsingnya menjadi ID domain
+	id, errID := product.ParseID(ctx.Params("id"))
+	if errID != nil {
+		// Jika id tidak valid, kembalikan response dengan status Unprocessable Entity
+		errorResponse(ctx, nil, errID)
+		return nil
+	}
+
+	// Memanggil service untuk mengambil stok product per lokasi
+	stocks, err := h.storeService.FindStockByProduct(c, id)
+	if err == nil && stocks == nil {
+		stocks = []*product.LocationStock{}
 	}
+	h.locationResponse(ctx, stocks, err)
+	return nil
+}
+
+// Fungsi TransferStock adalah handler untuk endpoint POST /product/:id/stock/transfer
+// Fungsi ini memindahkan stok product dari satu lokasi ke lokasi lain.
+// Lokasi asal atau tujuan yang kosong berarti stok yang belum dialokasikan ke lokasi mana pun.
+func (h *adapter) TransferStock(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi TransferStock
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:TransferStock")
+	defer span.End()
+
+	// Mengambil id dari URL parameter dan memparsingnya menjadi ID domain
+	id, errID := product.ParseID(ctx.Params("id"))
+	if errID != nil {
+		// Jika id tidak valid, kembalikan response dengan status Unprocessable Entity
+		errorResponse(ctx, nil, errID)
+		return nil
+	}
+
+	// Mem-parsing lokasi asal, lokasi tujuan, jumlah, dan catatan dari request body
+	transfer := product.StockTransfer{}
+	if err := ctx.BodyParser(&transfer); err != nil {
+		// Jika terjadi error saat parsing, kembalikan response dengan status Bad Request
+		statusResponse(ctx, http.StatusBadRequest, nil, err)
+		return nil
+	}
+
+	// Memanggil service untuk memindahkan stok
+	res, err := h.storeService.TransferStock(c, id, transfer)
+	h.locationResponse(ctx, res, err)
+	return nil
+}
 
-	// Jika berhasil, kembalikan response dengan status OK dan pesan sukses
-	return ctx.Status(200).JSON(fiber.Map{"message": "Deleted successfully"})
+// locationResponse menulis response untuk endpoint lokasi beserta status code sesuai error dari service
+func (h *adapter) locationResponse(ctx *fiber.Ctx, data interface{}, err error) {
+	if err != nil {
+		// Data lokasi atau transfer tidak valid (422), lokasi tidak ditemukan (404), atau kode lokasi sudah digunakan
+		// dan stok lokasi asal tidak mencukupi (409), status response ditentukan oleh kategori error
+		errorResponse(ctx, nil, err)
+		return
+	}
+	// Jika berhasil, kembalikan data
+	utils.Respond(ctx, http.StatusOK, data, nil)
 }
 
 /*
@@ -194,7 +889,7 @@ utils: Ini adalah paket yang berisi fungsi-fungsi utilitas yang digunakan untuk
 errors: Digunakan untuk membuat dan menangani error dalam kode.
 net/http: Paket ini menyediakan konstanta status kode HTTP yang digunakan dalam respons HTTP.
 fiber: Framework HTTP yang digunakan untuk membangun API yang efisien dan ringan.
-primitive: Bagian dari MongoDB driver yang digunakan untuk menangani ObjectID, tipe data unik yang digunakan sebagai kunci utama dalam MongoDB.
+Lapisan ini tidak lagi mengimpor driver MongoDB. ID dari parameter URL diparsing dengan product.ParseID menjadi ID milik domain, lalu setiap repository menerjemahkannya ke format ID penyimpanannya sendiri (ObjectID, UUID).
 slog: Paket untuk logging, yang memungkinkan pencatatan aktivitas dalam aplikasi, terutama saat terjadi error atau eksekusi penting.
 Struktur adapter:
 
@@ -204,19 +899,30 @@ Fungsi NewStoreHandler:
 Fungsi ini adalah constructor yang membuat instance baru dari adapter dengan menerima service domain sebagai parameter. Fungsi ini menginisialisasi adapter dengan service domain yang akan digunakan untuk menangani operasi CRUD.
 Fungsi CRUD:
 
-Get: Mengambil satu entitas Product berdasarkan ID dari URL parameter.
-GetAll: Mengambil semua entitas Product berdasarkan filter yang diterima dari query parameters.
+Get: Mengambil satu entitas Product berdasarkan ID dari URL parameter. Versi product dikirim sebagai header ETag (misalnya "3").
+GetByCode: Mengambil satu entitas Product berdasarkan kode product (SKU) dari URL parameter, juga dengan header ETag.
+GetAll: Mengambil semua entitas Product berdasarkan filter yang diterima dari query parameters. Parameter latitude, longitude, dan radius_km mengaktifkan pencarian berdasarkan jarak, hasilnya diurutkan dari yang terdekat dan setiap item berisi distance_km. Parameter cursor mengaktifkan cursor pagination: response berisi next_cursor untuk halaman berikutnya sebagai pengganti objek pagination. Parameter sort (misalnya -stock,product_name) mengurutkan hasil dan parameter fields (misalnya product_id,product_name,stock) membatasi field pada setiap item; field di luar whitelist menghasilkan Unprocessable Entity (422). Ekspresi filter berbentuk field[operator]=nilai (misalnya stock[lte]=5, created_at[gte]=2024-01-01, product_id[in]=a,b,c) dapat digabungkan dan seluruhnya harus terpenuhi; field, operator, atau nilai yang tidak dikenal menghasilkan Unprocessable Entity (422) dengan pesan yang menjelaskan kesalahannya.
+Search: Mencari entitas Product berdasarkan kata pada nama dan deskripsi menggunakan index teks. Setiap hasil berisi product, score (relevansi), dan highlights (teks dengan kata yang cocok dibungkus <em>). Parameter q yang tidak berisi kata apa pun atau terlalu panjang menghasilkan Unprocessable Entity (422).
 Create: Membuat entitas Product baru berdasarkan data yang dikirim melalui request body.
-Update: Memperbarui entitas Product yang ada berdasarkan ID dari URL parameter dan data baru yang dikirim melalui request body.
-Delete: Menghapus entitas Product berdasarkan ID yang diterima dari URL parameter.
+Update: Memperbarui entitas Product yang ada berdasarkan ID dari URL parameter dan data baru yang dikirim melalui request body. Stok tidak ikut diperbarui: stock yang berbeda dari stok saat ini menghasilkan Unprocessable Entity (422) dengan petunjuk untuk menggunakan POST /product/:id/stock/adjust atau PATCH, sedangkan stock 0 atau stock yang sama dengan stok saat ini diabaikan. Response berisi seluruh data product yang tersimpan setelah diperbarui (bukan hanya field dari request body) beserta header ETag dengan versi baru.
+Delete: Menandai entitas Product sebagai dihapus (soft delete) berdasarkan ID yang diterima dari URL parameter.
+DeleteByCode: Menandai entitas Product sebagai dihapus (soft delete) berdasarkan kode product (SKU).
+Patch: Menerapkan dokumen JSON Merge Patch (Content-Type application/merge-patch+json atau application/json) atau JSON Patch (application/json-patch+json) pada entitas Product. Berbeda dengan Update, nilai kosong dan null ikut diterapkan sehingga field seperti description atau geo dapat dikosongkan. Field yang dapat diubah adalah code, product_name, description, geo, dan stock; perubahan field lain (misalnya reserved) atau dokumen yang tidak valid menghasilkan Unprocessable Entity (422). Perubahan stock (termasuk menjadi 0) dicatat di buku besar stok sebagai pergerakan correction sebesar selisih stok baru dan stok saat ini dengan pemeriksaan versi yang sama, dan stok baru yang lebih kecil dari stok yang direservasi atau dialokasikan menghasilkan Conflict (409). Product hasil patch divalidasi dengan aturan yang sama seperti Create (misalnya product_name wajib diisi dan code harus berformat sku) sebelum disimpan, dan kegagalannya dikirim seperti validasi Create. Operasi test yang tidak cocok menghasilkan Conflict (409), dan Content-Type lain menghasilkan Unsupported Media Type (415).
+Update, Patch, Delete, dan DeleteByCode wajib menyertakan header If-Match berisi ETag dari GET terakhir (optimistic concurrency control). Tanpa header tersebut response berstatus Precondition Required (428); jika product sudah diubah pihak lain sejak dibaca, response berstatus Precondition Failed (412) dan klien harus membaca ulang product. Nilai If-Match: * melewati pemeriksaan versi.
+Restore: Memulihkan entitas Product yang sudah di-soft delete. Response berisi header ETag dengan versi baru.
+Purge: Menghapus permanen entitas Product yang sudah di-soft delete lebih lama dari masa retensi. Endpoint ini ditujukan untuk admin.
+AdjustStock: Mengubah stok Product dengan jumlah bertanda dan kode alasan. Stok yang tidak mencukupi menghasilkan Conflict (409), sedangkan jumlah 0 atau kode alasan yang tidak dikenal menghasilkan Unprocessable Entity (422).
+GetMovements: Mengambil riwayat pergerakan stok Product dengan pagination.
+Reserve, GetReservation, ConfirmReservation, ReleaseReservation: Mengelola reservasi stok untuk proses checkout. Reservasi yang tidak ditemukan menghasilkan Not Found (404), sedangkan reservasi yang sudah tidak aktif atau stok yang tidak mencukupi menghasilkan Conflict (409).
+CreateLocation, GetLocations, GetLocation, GetLocationStock, GetProductStock, TransferStock: Mengelola lokasi (gudang atau toko) dan stok per lokasi. Lokasi yang tidak ditemukan menghasilkan Not Found (404), kode lokasi yang sudah digunakan atau stok lokasi asal yang tidak mencukupi menghasilkan Conflict (409), dan data lokasi atau transfer yang tidak valid menghasilkan Unprocessable Entity (422).
 Tracing dan Logging:
 
 Setiap fungsi menggunakan tracing yang dimulai dengan infrastructure.Tracer().Start() untuk memantau eksekusi fungsi tersebut. Tracing ini berguna untuk melacak alur eksekusi dalam aplikasi dan membantu dalam debugging.
 Logging dilakukan dengan slog.ErrorContext() untuk mencatat error yang terjadi selama proses eksekusi, terutama saat validasi atau operasi lainnya gagal.
 Validasi dan Error Handling:
 
-Data yang diterima dari request body divalidasi menggunakan fungsi utils.Validate(). Jika validasi gagal, aplikasi akan mengembalikan status HTTP Unprocessable Entity (422).
-Setiap error yang terjadi selama eksekusi fungsi (misalnya, parsing ID, validasi, atau operasi database) akan ditangani dengan mengembalikan respons yang sesuai, misalnya Bad Request (400) atau Internal Server Error (500).
+Data yang diterima dari request body divalidasi dengan tag validate pada product.Product menggunakan utils.Validate() pada Create dan utils.ValidatePartial() pada Update (lihat validation.go). Jika validasi gagal, aplikasi akan mengembalikan status HTTP Unprocessable Entity (422) melalui validationResponse, beserta daftar field dan aturan yang gagal jika klien meminta format problem+json.
+Request body yang tidak dapat diparsing menghasilkan Bad Request (400). Error lain dari service (misalnya ID yang tidak valid, product yang tidak ditemukan, kode product yang sudah digunakan, atau database yang tidak dapat dihubungi) diteruskan ke errorResponse (errors.go), yang menentukan status HTTP dari kategori error domain: 404, 409, 422, 503, atau 500 untuk error yang tidak dikenal. Status yang sama dikirim sebagai status HTTP dan sebagai field status pada envelope response. Klien yang mengirim header Accept: application/problem+json menerima error dalam format RFC 7807 (lihat errors.go).
 Pentingnya Lapisan Adapter:
 Lapisan adapter ini berfungsi sebagai jembatan antara dunia luar (seperti HTTP API) dan logika bisnis inti yang ada di domain. Ini memastikan bahwa segala interaksi dari klien (misalnya, browser, aplikasi mobile, atau layanan lain) diproses secara konsisten dan sesuai dengan aturan bisnis yang telah ditentukan. Dalam arsitektur heksagonal (Hexagonal Architecture), lapisan adapter adalah bagian penting yang memisahkan logika bisnis dari detail implementasi teknis seperti HTTP, sehingga memudahkan pemeliharaan, pengujian, dan pengembangan berkelanjutan.
 */
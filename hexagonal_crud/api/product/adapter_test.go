@@ -0,0 +1,573 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	repository "CRUD_Hexagonal/repository/product"
+	service "CRUD_Hexagonal/service/product"
+	"CRUD_Hexagonal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newTestApp membuat aplikasi Fiber dengan route dan middleware seperti cmd/main.go di atas repo.
+// Repository in-memory dipakai jika repo nil.
+func newTestApp(t *testing.T, repo product.Repository) *fiber.App {
+	t.Helper()
+
+	if repo == nil {
+		repo = repository.NewMemoryRepository()
+	}
+	handler := NewStoreHandler(service.NewStoreService(repo, time.Hour, time.Minute, time.Hour))
+
+	app := fiber.New()
+	app.Use(handler.Negotiate)
+	app.Use(handler.Idempotency)
+
+	app.Get("/product/search", handler.Search)
+	app.Post("/product/bulk", handler.BulkCreate)
+	app.Delete("/product/bulk", handler.BulkDelete)
+	app.Post("/product/import", handler.ImportProducts)
+	app.Get("/product/export", handler.Export)
+	app.Get("/product/:id", handler.Get)
+	app.Get("/product", handler.GetAll)
+	app.Post("/product", handler.Create)
+	app.Put("/product/:id", handler.Update)
+	app.Patch("/product/:id", handler.Patch)
+	app.Delete("/product/:id", handler.Delete)
+	app.Get("/product/code/:code", handler.GetByCode)
+	app.Delete("/product/code/:code", handler.DeleteByCode)
+	app.Get("/jobs/:id", handler.GetJob)
+	app.Get("/jobs/:id/errors", handler.GetJobErrors)
+	return app
+}
+
+// doRequest mengirim request ke app dan mengembalikan response beserta body-nya.
+// headers berisi pasangan nama dan nilai header.
+func doRequest(t *testing.T, app *fiber.App, method, target, body string, headers ...string) (*http.Response, []byte) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s returned error: %v", method, target, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading %s %s body: %v", method, target, err)
+	}
+	return resp, data
+}
+
+// productEnvelope adalah envelope JSON dengan satu product pada data.
+type productEnvelope struct {
+	Status  int              `json:"status"`
+	Message string           `json:"message"`
+	Data    *product.Product `json:"data"`
+}
+
+// decodeProduct membaca envelope JSON berisi satu product.
+func decodeProduct(t *testing.T, body []byte) *product.Product {
+	t.Helper()
+
+	var envelope productEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("decoding %s: %v", body, err)
+	}
+	if envelope.Data == nil {
+		t.Fatalf("response %s has no product", body)
+	}
+	return envelope.Data
+}
+
+// createProduct membuat product melalui POST /product dan menghentikan pengujian jika gagal.
+func createProduct(t *testing.T, app *fiber.App, body string) *product.Product {
+	t.Helper()
+
+	resp, data := doRequest(t, app, fiber.MethodPost, "/product", body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST /product returned %d: %s", resp.StatusCode, data)
+	}
+	return decodeProduct(t, data)
+}
+
+func TestCreateIgnoresServerFields(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	// Field yang dikelola server dikirim klien bersama data product
+	created := createProduct(t, app, `{"product_name":"Kopi","stock":5,"product_id":"myid","reserved":100,`+
+		`"allocated":7,"available":99,"deleted_at":5,"version":9}`)
+	if created.ID == "myid" || created.ID.IsZero() {
+		t.Fatalf("got product_id %q, want an ID generated by the repository", created.ID)
+	}
+	if created.Reserved != 0 || created.Allocated != 0 || created.DeletedAt != 0 || created.Available != 5 {
+		t.Fatalf("got reserved=%d allocated=%d deleted_at=%d available=%d, want 0, 0, 0, 5",
+			created.Reserved, created.Allocated, created.DeletedAt, created.Available)
+	}
+	if created.Version != 2 {
+		t.Fatalf("got version %d, want 2 (created, then opening stock)", created.Version)
+	}
+
+	// Product tersimpan tanpa soft delete dan dengan stok yang tersedia sesuai stok awal
+	resp, data := doRequest(t, app, fiber.MethodGet, "/product/"+created.ID.String(), "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /product/:id returned %d: %s", resp.StatusCode, data)
+	}
+	stored := decodeProduct(t, data)
+	if stored.Stock != 5 || stored.Available != 5 || stored.Reserved != 0 || stored.Version != 2 {
+		t.Fatalf("got stored %+v, want stock 5, available 5, reserved 0, version 2", stored)
+	}
+}
+
+// failingStockRepository adalah repository yang selalu gagal mencatat pergerakan stok.
+type failingStockRepository struct {
+	product.Repository
+}
+
+func (failingStockRepository) AdjustStock(context.Context, *product.StockMovement) (*product.StockMovement, error) {
+	return nil, product.Unavailable(errors.New("ledger unavailable"))
+}
+
+func TestCreateRemovesProductWhenOpeningStockFails(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	app := newTestApp(t, failingStockRepository{Repository: repo})
+
+	resp, data := doRequest(t, app, fiber.MethodPost, "/product", `{"code":"SKU-1","product_name":"Kopi","stock":5}`)
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("POST /product returned %d, want 503: %s", resp.StatusCode, data)
+	}
+
+	// Produk yang sudah disimpan dihapus kembali sehingga tidak ada produk tanpa catatan stok awal
+	got, _, err := repo.FindAll(context.Background(), product.Filter{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("FindAll returned error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("repository has %d products after failed create, want 0", len(got))
+	}
+
+	// Produk tanpa stok awal tidak memerlukan pergerakan stok
+	createProduct(t, app, `{"code":"SKU-1","product_name":"Kopi"}`)
+}
+
+func TestPatchAppliesMergePatchAndJSONPatch(t *testing.T) {
+	app := newTestApp(t, nil)
+	created := createProduct(t, app, `{"code":"SKU-1","product_name":"Kopi","description":"Arabika",`+
+		`"geo":{"type":"Point","coordinates":[106.8,-6.2]}}`)
+	target := "/product/" + created.ID.String()
+
+	// JSON Merge Patch: null mengosongkan field, field lain diganti
+	resp, data := doRequest(t, app, fiber.MethodPatch, target, `{"description":null,"geo":null,"product_name":"Kopi Susu"}`,
+		fiber.HeaderContentType, string(product.MergePatch), fiber.HeaderIfMatch, etag(created.Version))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("merge patch returned %d: %s", resp.StatusCode, data)
+	}
+	patched := decodeProduct(t, data)
+	if patched.Name != "Kopi Susu" || patched.Description != "" || patched.Geo != nil || patched.Code != "SKU-1" {
+		t.Fatalf("got %+v after merge patch", patched)
+	}
+	if got := resp.Header.Get(fiber.HeaderETag); got != etag(patched.Version) || patched.Version != created.Version+1 {
+		t.Fatalf("got ETag %s and version %d, want version %d", got, patched.Version, created.Version+1)
+	}
+
+	// JSON Patch: operasi test yang cocok diikuti replace
+	resp, data = doRequest(t, app, fiber.MethodPatch, target,
+		`[{"op":"test","path":"/product_name","value":"Kopi Susu"},{"op":"replace","path":"/code","value":" SKU-2 "}]`,
+		fiber.HeaderContentType, string(product.JSONPatch), fiber.HeaderIfMatch, etag(patched.Version))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("JSON patch returned %d: %s", resp.StatusCode, data)
+	}
+	if patched = decodeProduct(t, data); patched.Code != "SKU-2" {
+		t.Fatalf("got code %q after JSON patch, want SKU-2", patched.Code)
+	}
+
+	// Operasi test yang tidak cocok menghasilkan 409 tanpa mengubah product
+	resp, data = doRequest(t, app, fiber.MethodPatch, target,
+		`[{"op":"test","path":"/product_name","value":"Teh"},{"op":"replace","path":"/code","value":"SKU-3"}]`,
+		fiber.HeaderContentType, string(product.JSONPatch), fiber.HeaderIfMatch, "*")
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("failing test operation returned %d, want 409: %s", resp.StatusCode, data)
+	}
+
+	// Content-Type lain menghasilkan 415
+	resp, data = doRequest(t, app, fiber.MethodPatch, target, `code=SKU-3`,
+		fiber.HeaderContentType, fiber.MIMEApplicationForm, fiber.HeaderIfMatch, "*")
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("form body returned %d, want 415: %s", resp.StatusCode, data)
+	}
+}
+
+func TestPatchValidatesPatchedProduct(t *testing.T) {
+	app := newTestApp(t, nil)
+	created := createProduct(t, app, `{"code":"SKU-1","product_name":"Kopi"}`)
+	target := "/product/" + created.ID.String()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		fields      []string
+	}{
+		{"merge patch", string(product.MergePatch), `{"product_name":null,"code":"bad code!!"}`, []string{"code", "product_name"}},
+		{"JSON patch", string(product.JSONPatch), `[{"op":"remove","path":"/product_name"}]`, []string{"product_name"}},
+		{"too long", fiber.MIMEApplicationJSON, `{"code":"` + strings.Repeat("A", 65) + `"}`, []string{"code"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, data := doRequest(t, app, fiber.MethodPatch, target, tt.body,
+				fiber.HeaderContentType, tt.contentType, fiber.HeaderIfMatch, "*",
+				fiber.HeaderAccept, utils.MIMEApplicationProblemJSON)
+			if resp.StatusCode != http.StatusUnprocessableEntity {
+				t.Fatalf("PATCH returned %d, want 422: %s", resp.StatusCode, data)
+			}
+			var problem utils.Problem
+			if err := json.Unmarshal(data, &problem); err != nil {
+				t.Fatalf("decoding %s: %v", data, err)
+			}
+			var fields []string
+			for _, fieldError := range problem.Errors {
+				fields = append(fields, fieldError.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Fatalf("got invalid fields %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+
+	// Product tidak berubah setelah patch yang ditolak
+	resp, data := doRequest(t, app, fiber.MethodGet, target, "")
+	if stored := decodeProduct(t, data); resp.StatusCode != http.StatusOK || stored.Name != "Kopi" || stored.Code != "SKU-1" ||
+		stored.Version != created.Version {
+		t.Fatalf("got %d %+v after rejected patches", resp.StatusCode, stored)
+	}
+}
+
+func TestPatchRecordsStockCorrection(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	app := newTestApp(t, repo)
+	created := createProduct(t, app, `{"product_name":"Kopi","stock":5}`)
+	target := "/product/" + created.ID.String()
+
+	// Stok 0 tetap merupakan perubahan dan dicatat sebagai pergerakan correction sebesar selisihnya
+	resp, data := doRequest(t, app, fiber.MethodPatch, target, `{"stock":0,"description":"Arabika"}`,
+		fiber.HeaderIfMatch, etag(created.Version))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("PATCH stock 0 returned %d: %s", resp.StatusCode, data)
+	}
+	patched := decodeProduct(t, data)
+	if patched.Stock != 0 || patched.Available != 0 || patched.Description != "Arabika" {
+		t.Fatalf("got %+v, want stock 0 and the new description", patched)
+	}
+	movements, _, err := repo.FindMovements(context.Background(), created.ID, product.MovementFilter{Page: 1, Limit: 10})
+	if err != nil || len(movements) == 0 {
+		t.Fatalf("got movements %v and error %v, want the correction", movements, err)
+	}
+	if latest := movements[0]; latest.Reason != product.ReasonCorrection || latest.Quantity != -5 || latest.StockAfter != 0 {
+		t.Fatalf("got movement %+v, want a correction of -5", latest)
+	}
+
+	// JSON Patch juga dapat mengubah stock, dan versi lama ditolak tanpa mengubah stok
+	resp, data = doRequest(t, app, fiber.MethodPatch, target, `[{"op":"replace","path":"/stock","value":8}]`,
+		fiber.HeaderContentType, "application/json-patch+json", fiber.HeaderIfMatch, etag(patched.Version))
+	if resp.StatusCode != http.StatusOK || decodeProduct(t, data).Stock != 8 {
+		t.Fatalf("JSON Patch of /stock returned %d, want 200 with stock 8: %s", resp.StatusCode, data)
+	}
+	resp, data = doRequest(t, app, fiber.MethodPatch, target, `{"stock":1}`, fiber.HeaderIfMatch, etag(patched.Version))
+	if resp.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("PATCH with a stale If-Match returned %d, want 412: %s", resp.StatusCode, data)
+	}
+
+	// Field lain di luar PatchableFields dan nilai stock yang bukan bilangan bulat tetap ditolak
+	for _, body := range []string{`{"reserved":1}`, `{"stock":null}`, `{"stock":"abc"}`, `{"stock":-1}`} {
+		resp, data := doRequest(t, app, fiber.MethodPatch, target, body, fiber.HeaderIfMatch, "*")
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("PATCH %s returned %d, want 422: %s", body, resp.StatusCode, data)
+		}
+	}
+	resp, data = doRequest(t, app, fiber.MethodGet, target, "")
+	if stored := decodeProduct(t, data); stored.Stock != 8 {
+		t.Fatalf("got stock %d after the rejected patches, want 8", stored.Stock)
+	}
+}
+
+func TestUpdateRespondsWithStoredProduct(t *testing.T) {
+	app := newTestApp(t, nil)
+	created := createProduct(t, app, `{"code":"SKU-1","product_name":"Kopi","description":"Arabika","stock":5}`)
+
+	// PUT hanya mengirim field yang diubah, response tetap berisi seluruh data product yang tersimpan
+	resp, data := doRequest(t, app, fiber.MethodPut, "/product/"+created.ID.String(), `{"product_name":"Kopi Susu"}`,
+		fiber.HeaderIfMatch, etag(created.Version))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("PUT /product/:id returned %d: %s", resp.StatusCode, data)
+	}
+	updated := decodeProduct(t, data)
+	if updated.Name != "Kopi Susu" || updated.Code != "SKU-1" || updated.Description != "Arabika" || updated.Stock != 5 ||
+		updated.Available != 5 || updated.CreatedAt != created.CreatedAt {
+		t.Fatalf("got %+v, want the stored product with the new name", updated)
+	}
+	if got := resp.Header.Get(fiber.HeaderETag); updated.Version != created.Version+1 || got != etag(updated.Version) {
+		t.Fatalf("got version %d and ETag %s, want version %d with a matching ETag", updated.Version, got, created.Version+1)
+	}
+}
+
+func TestUpdateRejectsStockChange(t *testing.T) {
+	app := newTestApp(t, nil)
+	created := createProduct(t, app, `{"product_name":"Kopi","stock":5}`)
+	target := "/product/" + created.ID.String()
+
+	// Stok yang berbeda ditolak dengan petunjuk endpoint stok, bukan diabaikan diam-diam
+	resp, data := doRequest(t, app, fiber.MethodPut, target, `{"product_name":"Kopi Susu","stock":100}`,
+		fiber.HeaderIfMatch, etag(created.Version))
+	var envelope productEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil || resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("PUT with a new stock returned %d, want 422: %s", resp.StatusCode, data)
+	}
+	if !strings.HasPrefix(envelope.Message, product.ErrStockNotUpdatable.Error()) ||
+		!strings.Contains(envelope.Message, "/stock/adjust") {
+		t.Errorf("got message %q, want %q with a hint to the stock endpoint", envelope.Message, product.ErrStockNotUpdatable.Error())
+	}
+	resp, data = doRequest(t, app, fiber.MethodGet, target, "")
+	if stored := decodeProduct(t, data); stored.Stock != 5 || stored.Name != "Kopi" || stored.Version != created.Version {
+		t.Fatalf("got %+v after the rejected PUT, want the product unchanged", stored)
+	}
+
+	// Stok yang sama dengan stok saat ini bukan perubahan
+	resp, data = doRequest(t, app, fiber.MethodPut, target, `{"product_name":"Kopi Susu","stock":5}`,
+		fiber.HeaderIfMatch, etag(created.Version))
+	if updated := decodeProduct(t, data); resp.StatusCode != http.StatusOK || updated.Stock != 5 || updated.Name != "Kopi Susu" {
+		t.Fatalf("PUT with the current stock returned %d: %s", resp.StatusCode, data)
+	}
+}
+func TestDeleteNegotiatesResponseFormat(t *testing.T) {
+	app := newTestApp(t, nil)
+	first := createProduct(t, app, `{"code":"SKU-1","product_name":"Kopi"}`)
+	createProduct(t, app, `{"code":"SKU-2","product_name":"Teh"}`)
+
+	// Response DELETE memakai envelope yang sama seperti endpoint lain, dalam format sesuai header Accept
+	resp, data := doRequest(t, app, fiber.MethodDelete, "/product/"+first.ID.String(), "",
+		fiber.HeaderIfMatch, "*", fiber.HeaderAccept, fiber.MIMEApplicationXML)
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), fiber.MIMEApplicationXML) {
+		t.Fatalf("DELETE /product/:id returned %d %s: %s", resp.StatusCode, resp.Header.Get(fiber.HeaderContentType), data)
+	}
+	if !strings.Contains(string(data), "<status>200</status>") {
+		t.Fatalf("got XML body %s, want the response envelope", data)
+	}
+
+	resp, data = doRequest(t, app, fiber.MethodDelete, "/product/code/SKU-2", "", fiber.HeaderIfMatch, "*")
+	var envelope utils.Response
+	if err := json.Unmarshal(data, &envelope); err != nil || resp.StatusCode != http.StatusOK || envelope.Status != http.StatusOK {
+		t.Fatalf("DELETE /product/code/:code returned %d: %s", resp.StatusCode, data)
+	}
+
+	// Format yang tidak dapat dihasilkan ditolak oleh middleware Negotiate sebelum handler dijalankan
+	resp, data = doRequest(t, app, fiber.MethodDelete, "/product/code/SKU-2", "",
+		fiber.HeaderIfMatch, "*", fiber.HeaderAccept, "image/png")
+	if resp.StatusCode != http.StatusNotAcceptable {
+		t.Fatalf("DELETE with Accept image/png returned %d, want 406: %s", resp.StatusCode, data)
+	}
+}
+
+func TestInvalidIDHidesParserError(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	// ID yang lolos ParseID tetapi bukan UUID ditolak oleh repository dengan pesan error domain
+	resp, data := doRequest(t, app, fiber.MethodGet, "/product/abcd", "")
+	var envelope utils.Response
+	if err := json.Unmarshal(data, &envelope); err != nil || resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("GET /product/abcd returned %d: %s", resp.StatusCode, data)
+	}
+	if want := product.ErrInvalidID.Error() + `: "abcd"`; envelope.Message != want {
+		t.Fatalf("got message %q, want %q", envelope.Message, want)
+	}
+
+	resp, data = doRequest(t, app, fiber.MethodGet, "/product/abcd", "", fiber.HeaderAccept, utils.MIMEApplicationProblemJSON)
+	var problem utils.Problem
+	if err := json.Unmarshal(data, &problem); err != nil || resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("GET /product/abcd as problem+json returned %d: %s", resp.StatusCode, data)
+	}
+	if strings.Contains(problem.Detail, "UUID") || problem.Type != "/problems/validation" {
+		t.Fatalf("got problem %+v, want a validation problem without the parser error", problem)
+	}
+}
+
+// listEnvelope adalah envelope JSON GET /product dengan item data yang belum di-decode.
+type listEnvelope struct {
+	Status     int                          `json:"status"`
+	Message    string                       `json:"message"`
+	Data       []map[string]json.RawMessage `json:"data"`
+	Pagination *utils.Pagination            `json:"pagination"`
+	NextCursor string                       `json:"next_cursor"`
+}
+
+// getList memanggil GET target dan menghentikan pengujian jika status response bukan 200.
+func getList(t *testing.T, app *fiber.App, target string) listEnvelope {
+	t.Helper()
+
+	resp, data := doRequest(t, app, fiber.MethodGet, target, "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s returned %d: %s", target, resp.StatusCode, data)
+	}
+	var envelope listEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	return envelope
+}
+
+// listNames mengembalikan product_name setiap item pada envelope.
+func listNames(t *testing.T, envelope listEnvelope) []string {
+	t.Helper()
+
+	names := make([]string, len(envelope.Data))
+	for i, item := range envelope.Data {
+		if err := json.Unmarshal(item["product_name"], &names[i]); err != nil {
+			t.Fatalf("item %d has no product_name: %v", i, err)
+		}
+	}
+	return names
+}
+
+func TestGetAllCursorPagination(t *testing.T) {
+	app := newTestApp(t, nil)
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		createProduct(t, app, `{"product_name":"`+name+`"}`)
+	}
+
+	// Parameter cursor kosong memulai dari halaman pertama, next_cursor kosong menandai halaman terakhir
+	var names []string
+	target := "/product?limit=2&cursor="
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("cursor pagination did not end after %d pages", pages)
+		}
+		envelope := getList(t, app, target)
+		if envelope.Pagination != nil {
+			t.Fatalf("cursor page has pagination %+v, want only next_cursor", envelope.Pagination)
+		}
+		names = append(names, listNames(t, envelope)...)
+		if envelope.NextCursor == "" {
+			break
+		}
+		target = "/product?limit=2&cursor=" + envelope.NextCursor
+	}
+	if strings.Join(names, ",") != "A,B,C,D,E" {
+		t.Fatalf("got %v over all pages, want every product once in insertion order", names)
+	}
+
+	// Cursor yang tidak dapat dibaca, atau digabung dengan sort, ditolak
+	for _, target := range []string{"/product?cursor=bukan-cursor", "/product?cursor=&sort=stock"} {
+		resp, data := doRequest(t, app, fiber.MethodGet, target, "")
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("GET %s returned %d, want 422: %s", target, resp.StatusCode, data)
+		}
+	}
+}
+
+func TestGetAllSortAndProjection(t *testing.T) {
+	app := newTestApp(t, nil)
+	for _, body := range []string{
+		`{"product_name":"Kopi","stock":5}`,
+		`{"product_name":"Teh","stock":9}`,
+		`{"product_name":"Gula","stock":5}`,
+	} {
+		createProduct(t, app, body)
+	}
+
+	// Urutan menurun berdasarkan stok, lalu menaik berdasarkan nama untuk stok yang sama
+	envelope := getList(t, app, "/product?sort=-stock,product_name&fields=product_name,stock")
+	if got := strings.Join(listNames(t, envelope), ","); got != "Teh,Gula,Kopi" {
+		t.Fatalf("got %s, want Teh,Gula,Kopi", got)
+	}
+	for i, item := range envelope.Data {
+		if len(item) != 2 || item["product_name"] == nil || item["stock"] == nil {
+			t.Fatalf("item %d has fields %v, want only product_name and stock", i, item)
+		}
+	}
+	if envelope.Pagination == nil || envelope.Pagination.Total != 3 {
+		t.Fatalf("got pagination %+v, want total 3", envelope.Pagination)
+	}
+
+	// Field di luar whitelist ditolak
+	for _, target := range []string{"/product?sort=description", "/product?sort=stock,stock", "/product?fields=password"} {
+		resp, data := doRequest(t, app, fiber.MethodGet, target, "")
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("GET %s returned %d, want 422: %s", target, resp.StatusCode, data)
+		}
+	}
+}
+
+func TestGetAllFilterExpressions(t *testing.T) {
+	app := newTestApp(t, nil)
+	for _, body := range []string{
+		`{"code":"SKU-1","product_name":"Kopi","stock":2}`,
+		`{"code":"SKU-2","product_name":"Teh","stock":5}`,
+		`{"code":"SKU-3","product_name":"Gula","stock":9}`,
+	} {
+		createProduct(t, app, body)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"stock[lte]=5", "Kopi,Teh"},
+		{"stock[gt]=2&stock[lt]=9", "Teh"},
+		{"code[in]=SKU-1,SKU-3", "Kopi,Gula"},
+		{"product_name[ne]=Teh&stock[gte]=2", "Kopi,Gula"},
+		{"product_name[eq]=Teh", "Teh"},
+		{"created_at[gte]=2024-01-01&created_at[lt]=2100-01-01T00:00:00Z", "Kopi,Teh,Gula"},
+		{"created_at[lt]=1700000000", ""},
+	}
+	for _, tt := range tests {
+		envelope := getList(t, app, "/product?"+tt.query)
+		if got := strings.Join(listNames(t, envelope), ","); got != tt.want {
+			t.Errorf("GET /product?%s returned %q, want %q", tt.query, got, tt.want)
+		}
+		if envelope.Pagination == nil || envelope.Pagination.Total != len(envelope.Data) {
+			t.Errorf("GET /product?%s returned pagination %+v, want a total of the filtered products", tt.query, envelope.Pagination)
+		}
+	}
+
+	// Field, operator, atau nilai yang tidak dikenal menghasilkan 422 dengan pesan yang menjelaskan kesalahannya
+	invalid := []struct {
+		query   string
+		message string
+	}{
+		{"description[eq]=x", `unknown field "description"`},
+		{"stock[like]=5", `unknown operator "like"`},
+		{"code[gt]=A", `operator "gt" is not supported for field "code"`},
+		{"stock[gte]=banyak", `invalid value "banyak" for field "stock"`},
+		{"created_at[gte]=kemarin", `invalid value "kemarin" for field "created_at"`},
+		{"stock[lte=5", `malformed parameter "stock[lte"`},
+	}
+	for _, tt := range invalid {
+		resp, data := doRequest(t, app, fiber.MethodGet, "/product?"+tt.query, "")
+		var envelope utils.Response
+		if err := json.Unmarshal(data, &envelope); err != nil || resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("GET /product?%s returned %d, want 422: %s", tt.query, resp.StatusCode, data)
+		}
+		if want := product.ErrInvalidFilter.Error() + ": " + tt.message; envelope.Message != want {
+			t.Errorf("GET /product?%s returned message %q, want %q", tt.query, envelope.Message, want)
+		}
+	}
+}
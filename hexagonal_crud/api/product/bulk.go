@@ -0,0 +1,215 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"CRUD_Hexagonal/infrastructure"
+	"CRUD_Hexagonal/utils"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// bulkItemResult adalah hasil satu item pada response bulk. Item yang gagal berisi status HTTP yang akan dikembalikan
+// endpoint satuan untuk error yang sama, pesan error, dan daftar field yang tidak valid jika validasi gagal.
+type bulkItemResult struct {
+	*product.BulkResult
+	StatusCode int                `json:"status_code,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	Errors     []utils.FieldError `json:"errors,omitempty"`
+}
+
+// bulkReport adalah data response POST dan DELETE /product/bulk.
+type bulkReport struct {
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []bulkItemResult `json:"results"`
+}
+
+// bulkDeleteItem adalah satu item pada request DELETE /product/bulk, product dicari berdasarkan ID atau kode.
+type bulkDeleteItem struct {
+	ID   string `json:"product_id"`
+	Code string `json:"code"`
+}
+
+// Fungsi BulkCreate adalah handler untuk endpoint POST /product/bulk
+// Fungsi ini menyimpan banyak product sekaligus, atau memperbaruinya berdasarkan kode dengan ?mode=upsert
+func (h *adapter) BulkCreate(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi BulkCreate
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:BulkCreate")
+	defer span.End()
+
+	var upsert bool
+	switch ctx.Query("mode", product.BulkCreate) {
+	case product.BulkCreate:
+	case product.BulkUpsert:
+		upsert = true
+	default:
+		errorResponse(ctx, nil, product.ErrInvalidBulk)
+		return nil
+	}
+
+	// Mem-parsing request body berupa array product
+	var items []*product.Product
+	if err := ctx.BodyParser(&items); err != nil {
+		statusResponse(ctx, http.StatusBadRequest, nil, err)
+		return nil
+	}
+	if len(items) == 0 || len(items) > product.MaxBulkOperations {
+		errorResponse(ctx, nil, product.ErrInvalidBulk)
+		return nil
+	}
+
+	// Setiap item divalidasi seperti pada Create, item yang tidak valid tidak dikirim ke service
+	results := make([]bulkItemResult, len(items))
+	valid := make([]*product.Product, 0, len(items))
+	indexes := make([]int, 0, len(items))
+	for i, item := range items {
+		if item == nil {
+			results[i] = failedBulkItem(i, product.ErrInvalidBulk)
+			continue
+		}
+		if fieldErrors := utils.Validate(item); len(fieldErrors) > 0 {
+			results[i] = failedBulkItem(i, fieldErrors)
+			results[i].ID, results[i].Code = item.ID, item.Code
+			results[i].StatusCode, results[i].Errors = http.StatusUnprocessableEntity, fieldErrors
+			continue
+		}
+		valid = append(valid, item)
+		indexes = append(indexes, i)
+	}
+
+	if len(valid) > 0 {
+		written, err := h.storeService.BulkStore(c, valid, upsert)
+		if err != nil {
+			// Seluruh batch gagal, misalnya karena database tidak dapat dihubungi
+			errorResponse(ctx, nil, err)
+			return nil
+		}
+		mergeBulkResults(results, written, indexes)
+	}
+
+	bulkResponse(ctx, results)
+	return nil
+}
+
+// Fungsi BulkDelete adalah handler untuk endpoint DELETE /product/bulk
+// Fungsi ini menandai banyak product sebagai dihapus (soft delete) berdasarkan ID atau kode setiap item
+func (h *adapter) BulkDelete(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi BulkDelete
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:BulkDelete")
+	defer span.End()
+
+	// Mem-parsing request body berupa array {product_id} atau {code}
+	var items []*bulkDeleteItem
+	if err := ctx.BodyParser(&items); err != nil {
+		statusResponse(ctx, http.StatusBadRequest, nil, err)
+		return nil
+	}
+	if len(items) == 0 || len(items) > product.MaxBulkOperations {
+		errorResponse(ctx, nil, product.ErrInvalidBulk)
+		return nil
+	}
+
+	results := make([]bulkItemResult, len(items))
+	targets := make([]*product.Product, 0, len(items))
+	indexes := make([]int, 0, len(items))
+	for i, item := range items {
+		if item == nil {
+			results[i] = failedBulkItem(i, product.ErrInvalidBulk)
+			continue
+		}
+		target := &product.Product{Code: item.Code}
+		if item.ID != "" {
+			id, err := product.ParseID(item.ID)
+			if err != nil {
+				results[i] = failedBulkItem(i, err)
+				results[i].Code = item.Code
+				continue
+			}
+			target.ID = id
+		}
+		targets = append(targets, target)
+		indexes = append(indexes, i)
+	}
+
+	if len(targets) > 0 {
+		written, err := h.storeService.BulkDelete(c, targets)
+		if err != nil {
+			errorResponse(ctx, nil, err)
+			return nil
+		}
+		mergeBulkResults(results, written, indexes)
+	}
+
+	bulkResponse(ctx, results)
+	return nil
+}
+
+// failedBulkItem membuat hasil item yang gagal sebelum dikirim ke service.
+func failedBulkItem(index int, err error) bulkItemResult {
+	result := &product.BulkResult{Index: index}
+	result.Fail(err)
+	return bulkItemResult{BulkResult: result}
+}
+
+// mergeBulkResults menempatkan hasil dari service ke results sesuai index item pada request.
+func mergeBulkResults(results []bulkItemResult, written []*product.BulkResult, indexes []int) {
+	for j, result := range written {
+		result.Index = indexes[j]
+		results[indexes[j]] = bulkItemResult{BulkResult: result}
+	}
+}
+
+// bulkResponse menulis laporan hasil setiap item. Status 200 dikirim jika semua item berhasil,
+// dan 207 Multi-Status jika ada item yang gagal.
+func bulkResponse(ctx *fiber.Ctx, results []bulkItemResult) {
+	report := bulkReport{Total: len(results), Results: results}
+	for i := range results {
+		if results[i].Status != product.BulkFailed {
+			report.Succeeded++
+			continue
+		}
+		report.Failed++
+		if results[i].StatusCode == 0 {
+			results[i].StatusCode = statusCode(results[i].Err)
+		}
+		results[i].Error = problemDetail(results[i].StatusCode, results[i].Err)
+	}
+
+	code := http.StatusOK
+	if report.Failed > 0 {
+		code = http.StatusMultiStatus
+	}
+	utils.Respond(ctx, code, report, nil)
+}
+
+/*
+Penjelasan Kode:
+
+Endpoint Bulk:
+Sinkronisasi ERP sebelumnya memanggil POST /product ribuan kali satu per satu. Endpoint bulk menerima array dalam satu request:
+
+POST /product/bulk menerima array product seperti body POST /product. Secara default (?mode=create) setiap item disimpan
+sebagai product baru; dengan ?mode=upsert product dengan kode yang sama diperbarui dan kode yang belum digunakan dibuat baru.
+DELETE /product/bulk menerima array [{"product_id": "..."}, {"code": "..."}] dan menandai product sebagai dihapus (soft delete).
+
+Setiap item divalidasi dengan utils.Validate seperti pada Create. Item yang valid dikirim ke service dalam satu panggilan
+dan dijalankan melalui Repository.BulkWrite (BulkWrite pada MongoDB). Kegagalan satu item tidak membatalkan item lain
+(partial failure), dan response berisi hasil setiap item sesuai urutan request:
+
+	{
+	  "total": 3, "succeeded": 2, "failed": 1,
+	  "results": [
+	    {"index": 0, "status": "created", "product_id": "...", "code": "SKU-1"},
+	    {"index": 1, "status": "updated", "product_id": "...", "code": "SKU-2"},
+	    {"index": 2, "status": "failed", "code": "SKU-3", "status_code": 409, "error": "product code already exists"}
+	  ]
+	}
+
+status_code adalah status HTTP yang akan dikembalikan endpoint satuan untuk error yang sama (lihat errors.go), dan errors
+berisi daftar field yang tidak valid jika validasi item gagal. Response berstatus 200 jika semua item berhasil dan
+207 Multi-Status jika ada item yang gagal. Body yang tidak dapat diparsing menghasilkan 400, sedangkan array kosong,
+lebih dari product.MaxBulkOperations item, atau mode yang tidak dikenal menghasilkan 422. Jika seluruh batch gagal
+(misalnya database tidak dapat dihubungi), response error dikirim melalui errorResponse tanpa laporan per item.
+*/
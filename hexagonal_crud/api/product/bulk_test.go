@@ -0,0 +1,141 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	repository "CRUD_Hexagonal/repository/product"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// bulkRequest mengirim request bulk dan membaca laporan hasilnya.
+func bulkRequest(t *testing.T, app *fiber.App, method, target, body string, status int) bulkReport {
+	t.Helper()
+
+	resp, data := doRequest(t, app, method, target, body)
+	if resp.StatusCode != status {
+		t.Fatalf("%s %s returned %d, want %d: %s", method, target, resp.StatusCode, status, data)
+	}
+	var envelope struct {
+		Data bulkReport `json:"data"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	return envelope.Data
+}
+
+// checkBulkStatuses memeriksa status dan status HTTP setiap item sesuai urutan request.
+func checkBulkStatuses(t *testing.T, report bulkReport, statuses []string, codes []int) {
+	t.Helper()
+
+	if report.Total != len(statuses) || len(report.Results) != len(statuses) {
+		t.Fatalf("got report %+v, want %d results", report, len(statuses))
+	}
+	for i, result := range report.Results {
+		if result.Index != i || result.Status != statuses[i] || result.StatusCode != codes[i] {
+			t.Errorf("result %d is %+v with status code %d, want %s and %d", i, result.BulkResult, result.StatusCode,
+				statuses[i], codes[i])
+		}
+	}
+}
+
+func TestBulkCreateReportsEachItem(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	report := bulkRequest(t, app, fiber.MethodPost, "/product/bulk",
+		`[{"code":"SKU-1","product_name":"Kopi"},{"code":"SKU-2","product_name":""},null,{"code":"SKU-1","product_name":"Teh"}]`,
+		http.StatusMultiStatus)
+	checkBulkStatuses(t, report,
+		[]string{product.BulkCreated, product.BulkFailed, product.BulkFailed, product.BulkFailed},
+		[]int{0, http.StatusUnprocessableEntity, http.StatusUnprocessableEntity, http.StatusConflict})
+	if report.Succeeded != 1 || report.Failed != 3 {
+		t.Fatalf("got succeeded=%d failed=%d, want 1 and 3", report.Succeeded, report.Failed)
+	}
+	if errs := report.Results[1].Errors; len(errs) != 1 || errs[0].Field != "product_name" || report.Results[1].Code != "SKU-2" {
+		t.Errorf("got result %+v with errors %+v, want product_name of SKU-2", report.Results[1].BulkResult, errs)
+	}
+	if got := report.Results[3].Error; got != product.ErrCodeConflict.Error() {
+		t.Errorf("got error %q, want %q", got, product.ErrCodeConflict.Error())
+	}
+
+	// Request yang tidak dapat diproses sama sekali ditolak tanpa laporan per item
+	for _, tt := range []struct{ target, body string }{{"/product/bulk?mode=replace", `[{"product_name":"Kopi"}]`}, {"/product/bulk", `[]`}} {
+		if resp, data := doRequest(t, app, fiber.MethodPost, tt.target, tt.body); resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("POST %s with %s returned %d, want 422: %s", tt.target, tt.body, resp.StatusCode, data)
+		}
+	}
+}
+
+func TestBulkUpsertAndDelete(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	app := newTestApp(t, repo)
+	existing := createProduct(t, app, `{"code":"SKU-1","product_name":"Kopi","stock":5}`)
+
+	report := bulkRequest(t, app, fiber.MethodPost, "/product/bulk?mode=upsert",
+		`[{"code":"SKU-1","product_name":"Kopi Susu"},{"code":"SKU-2","product_name":"Teh"}]`, http.StatusOK)
+	checkBulkStatuses(t, report, []string{product.BulkUpdated, product.BulkCreated}, []int{0, 0})
+	if report.Results[0].ID != existing.ID || report.Results[1].ID.IsZero() {
+		t.Fatalf("got IDs %s and %s, want the existing ID and a new one", report.Results[0].ID, report.Results[1].ID)
+	}
+	resp, data := doRequest(t, app, fiber.MethodGet, "/product/code/SKU-1", "")
+	if stored := decodeProduct(t, data); resp.StatusCode != http.StatusOK || stored.Name != "Kopi Susu" || stored.Stock != 5 {
+		t.Fatalf("got stored %+v, want the new name and the stock left unchanged", stored)
+	}
+
+	// Stok yang berbeda pada produk yang cocok dicatat sebagai pergerakan correction sebesar selisihnya
+	report = bulkRequest(t, app, fiber.MethodPost, "/product/bulk?mode=upsert", `[{"code":"SKU-1","product_name":"Kopi Susu","stock":99}]`, http.StatusOK)
+	checkBulkStatuses(t, report, []string{product.BulkUpdated}, []int{0})
+	resp, data = doRequest(t, app, fiber.MethodGet, "/product/code/SKU-1", "")
+	if stored := decodeProduct(t, data); resp.StatusCode != http.StatusOK || stored.Stock != 99 || stored.Name != "Kopi Susu" {
+		t.Fatalf("got stored %+v, want stock 99", stored)
+	}
+	movements, _, err := repo.FindMovements(context.Background(), existing.ID, product.MovementFilter{Page: 1, Limit: 10})
+	if err != nil || len(movements) != 2 {
+		t.Fatalf("got movements %v and error %v, want the initial stock and the correction", movements, err)
+	}
+	if latest := movements[0]; latest.Reason != product.ReasonCorrection || latest.Quantity != 94 || latest.StockAfter != 99 {
+		t.Fatalf("got movement %+v, want a correction of 94", latest)
+	}
+
+	report = bulkRequest(t, app, fiber.MethodDelete, "/product/bulk",
+		`[{"code":"SKU-2"},{"product_id":"`+existing.ID.String()+`"},{"code":"SKU-X"}]`, http.StatusMultiStatus)
+	checkBulkStatuses(t, report, []string{product.BulkDeleted, product.BulkDeleted, product.BulkFailed},
+		[]int{0, 0, http.StatusNotFound})
+	if resp, data = doRequest(t, app, fiber.MethodGet, "/product/code/SKU-2", ""); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("GET /product/code/SKU-2 returned %d after the bulk delete, want 404: %s", resp.StatusCode, data)
+	}
+
+	// Kode produk yang dihapus tetap terpakai, sehingga upsert tidak membuat produk baru dengan kode tersebut
+	report = bulkRequest(t, app, fiber.MethodPost, "/product/bulk?mode=upsert", `[{"code":"SKU-2","product_name":"Teh Manis"}]`,
+		http.StatusMultiStatus)
+	checkBulkStatuses(t, report, []string{product.BulkFailed}, []int{http.StatusConflict})
+}
+
+func TestBulkCreateRemovesProductWhenOpeningStockFails(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	app := newTestApp(t, failingStockRepository{Repository: repo})
+
+	report := bulkRequest(t, app, fiber.MethodPost, "/product/bulk",
+		`[{"code":"SKU-1","product_name":"Kopi","stock":5},{"code":"SKU-2","product_name":"Teh"}]`, http.StatusMultiStatus)
+	checkBulkStatuses(t, report, []string{product.BulkFailed, product.BulkCreated}, []int{http.StatusServiceUnavailable, 0})
+	if !report.Results[0].ID.IsZero() {
+		t.Errorf("got ID %s for the removed product, want none", report.Results[0].ID)
+	}
+
+	// Produk yang stok awalnya gagal dicatat dihapus kembali, termasuk dari produk yang di-soft delete
+	got, _, err := repo.FindAll(context.Background(), product.Filter{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("FindAll returned error: %v", err)
+	}
+	if len(got) != 1 || got[0].Code != "SKU-2" {
+		t.Fatalf("repository has %d products after the bulk create, want only SKU-2", len(got))
+	}
+
+	// Item yang sama dapat dikirim ulang tanpa konflik kode
+	report = bulkRequest(t, app, fiber.MethodPost, "/product/bulk", `[{"code":"SKU-1","product_name":"Kopi"}]`, http.StatusOK)
+	checkBulkStatuses(t, report, []string{product.BulkCreated}, []int{0})
+}
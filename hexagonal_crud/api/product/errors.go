@@ -0,0 +1,145 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"CRUD_Hexagonal/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/exp/slog"
+)
+
+// errPreconditionRequired dikembalikan jika request yang mengubah product tidak menyertakan header If-Match
+var errPreconditionRequired = errors.New("If-Match header is required")
+
+// statusCode memetakan error ke status HTTP berdasarkan kategori error domain.
+// Error yang tidak termasuk kategori mana pun dianggap kesalahan server.
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, errPreconditionRequired):
+		return http.StatusPreconditionRequired
+	case errors.Is(err, product.ErrVersionConflict):
+		// Versi dari header If-Match tidak cocok, didahulukan dari kategori ErrConflict
+		return http.StatusPreconditionFailed
+	case errors.Is(err, product.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, product.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, product.ErrValidation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, product.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// problemTypes berisi URI type problem+json untuk setiap status error. Status yang tidak terdaftar
+// menggunakan "about:blank" sehingga title cukup dijelaskan oleh status HTTP.
+var problemTypes = map[int]string{
+	http.StatusNotFound:             "/problems/not-found",
+	http.StatusConflict:             "/problems/conflict",
+	http.StatusPreconditionFailed:   "/problems/version-conflict",
+	http.StatusPreconditionRequired: "/problems/precondition-required",
+	http.StatusUnprocessableEntity:  "/problems/validation",
+	http.StatusServiceUnavailable:   "/problems/unavailable",
+}
+
+// errorResponse menulis response error dengan status HTTP dari statusCode. Kesalahan server dicatat beserta
+// penyebab aslinya, sedangkan pesan yang dikirim ke klien hanya pesan error domain. Hasil utils.Validate yang
+// dikembalikan service (misalnya produk hasil patch yang tidak valid) ditulis seperti validationResponse.
+func errorResponse(ctx *fiber.Ctx, data interface{}, err error) {
+	var fieldErrors utils.ValidationErrors
+	if errors.As(err, &fieldErrors) {
+		validationResponse(ctx, fieldErrors)
+		return
+	}
+	statusResponse(ctx, statusCode(err), data, err)
+}
+
+// validationResponse menulis response 422 untuk hasil utils.Validate. Envelope berisi pesan yang digabung,
+// sedangkan problem+json berisi daftar field yang tidak valid pada errors.
+func validationResponse(ctx *fiber.Ctx, fieldErrors utils.ValidationErrors) {
+	writeError(ctx, http.StatusUnprocessableEntity, nil, fieldErrors, fieldErrors)
+}
+
+// statusResponse menulis response error dengan status HTTP yang ditentukan pemanggil,
+// misalnya 400 untuk body yang tidak dapat diparsing atau 415 untuk Content-Type yang tidak didukung.
+func statusResponse(ctx *fiber.Ctx, code int, data interface{}, err error) {
+	writeError(ctx, code, data, err, nil)
+}
+
+// writeError memilih format response error dari header Accept. Envelope utils.Response tetap menjadi default
+// (dalam format JSON, MessagePack, atau XML sesuai utils.Respond), problem+json hanya dikirim jika klien memintanya.
+func writeError(ctx *fiber.Ctx, code int, data interface{}, err error, fieldErrors []utils.FieldError) {
+	if code >= http.StatusInternalServerError {
+		slog.ErrorContext(ctx.UserContext(), "Request failed "+ctx.Method()+" "+ctx.Path(), slog.Any("err ", err), slog.Any("cause ", errors.Unwrap(err)))
+	}
+
+	// Format response bergantung pada header Accept, sehingga cache harus membedakannya
+	ctx.Vary(fiber.HeaderAccept)
+	message := problemDetail(code, err)
+	if !utils.AcceptsProblem(ctx) {
+		utils.Respond(ctx, code, data, errors.New(message))
+		return
+	}
+
+	utils.ResponseWithProblem(ctx, utils.Problem{
+		Type:     problemTypes[code],
+		Status:   code,
+		Detail:   message,
+		Instance: ctx.OriginalURL(),
+		Errors:   fieldErrors,
+	})
+}
+
+// problemDetail mengembalikan pesan error untuk field detail problem+json dan message envelope. Pesan error yang bukan
+// error domain pada kesalahan server bisa berisi detail internal (misalnya pesan driver database), sehingga diganti
+// dengan teks status HTTP.
+func problemDetail(code int, err error) string {
+	var domainErr *product.Error
+	if code >= http.StatusInternalServerError && !errors.As(err, &domainErr) {
+		return http.StatusText(code)
+	}
+	return err.Error()
+}
+
+/*
+Penjelasan Kode:
+
+Pemetaan Error Terpusat:
+Sebelumnya setiap handler memeriksa error satu per satu dan mengembalikan 400 atau 500 untuk error yang tidak dikenal.
+Sekarang handler cukup memanggil errorResponse, dan statusCode memetakan kategori error domain ke status HTTP:
+
+ErrNotFound (ErrProductNotFound, ErrReservationNotFound, ErrLocationNotFound): 404 Not Found.
+ErrConflict (ErrCodeConflict, ErrInsufficientStock, ErrReservationNotActive, ErrLocationCodeConflict, ErrPatchTestFailed): 409 Conflict.
+ErrValidation (ErrInvalidID dan seluruh error ErrInvalid... lainnya): 422 Unprocessable Entity.
+ErrUnavailable (database tidak dapat dihubungi atau timeout): 503 Service Unavailable.
+ErrVersionConflict dan header If-Match yang tidak ada: 412 Precondition Failed dan 428 Precondition Required.
+Error lain: 500 Internal Server Error dan dicatat dengan slog.
+
+Karena pemeriksaan menggunakan errors.Is, error yang dibungkus dengan fmt.Errorf("%w: ...") tetap dipetakan sesuai kategorinya.
+Menambahkan error domain baru tidak memerlukan perubahan pada lapisan API selama error tersebut dibuat dengan kategori yang sesuai.
+
+Format problem+json (RFC 7807):
+Secara default error dikirim dalam envelope utils.Response dengan pesan error pada field message. Klien yang mengirim
+header Accept: application/problem+json (lebih diutamakan daripada application/json) menerima response dengan
+Content-Type application/problem+json:
+
+	{
+	  "type": "/problems/validation",
+	  "title": "Unprocessable Entity",
+	  "status": 422,
+	  "detail": "product_name must be at most 255 characters",
+	  "instance": "/product",
+	  "errors": [{"field": "product_name", "rule": "max", "param": "255", "message": "product_name must be at most 255 characters"}]
+	}
+
+type adalah URI relatif dari problemTypes sesuai kategori error (not-found, conflict, version-conflict,
+precondition-required, validation, unavailable), atau "about:blank" untuk status lain seperti 400, 415, dan 500.
+title adalah teks status HTTP, detail adalah pesan error domain, dan instance adalah URL request. Array errors hanya
+diisi oleh validationResponse dengan hasil utils.Validate (lihat validation.go), sehingga SDK klien dapat menampilkan pesan per field.
+Pada kesalahan server yang bukan error domain, detail (dan message pada envelope) diganti dengan teks status agar pesan internal tidak bocor.
+Header Vary: Accept dikirim pada setiap response error karena isinya bergantung pada header Accept.
+*/
@@ -0,0 +1,162 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	repository "CRUD_Hexagonal/repository/product"
+	"CRUD_Hexagonal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// decodeProblem membaca response problem+json dan memeriksa Content-Type-nya.
+func decodeProblem(t *testing.T, resp *http.Response, body []byte) utils.Problem {
+	t.Helper()
+
+	if got := resp.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(got, utils.MIMEApplicationProblemJSON) {
+		t.Fatalf("got Content-Type %q, want %s: %s", got, utils.MIMEApplicationProblemJSON, body)
+	}
+	var problem utils.Problem
+	if err := json.Unmarshal(body, &problem); err != nil {
+		t.Fatalf("decoding %s: %v", body, err)
+	}
+	if problem.Status != resp.StatusCode {
+		t.Fatalf("got problem status %d in a %d response", problem.Status, resp.StatusCode)
+	}
+	return problem
+}
+
+func TestProblemResponses(t *testing.T) {
+	app := newTestApp(t, nil)
+	created := createProduct(t, app, `{"code":"SKU-1","product_name":"Kopi"}`)
+	target := "/product/" + created.ID.String()
+	missing := "/product/00000000-0000-4000-8000-000000000000"
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		headers  []string
+		status   int
+		typ      string
+		detail   string
+		instance string
+	}{
+		{"not found", fiber.MethodGet, missing, "", nil, http.StatusNotFound, "/problems/not-found",
+			product.ErrProductNotFound.Error(), missing},
+		{"conflict", fiber.MethodPost, "/product", `{"code":"SKU-1","product_name":"Teh"}`, nil, http.StatusConflict,
+			"/problems/conflict", product.ErrCodeConflict.Error(), "/product"},
+		{"precondition required", fiber.MethodDelete, target, "", nil, http.StatusPreconditionRequired,
+			"/problems/precondition-required", errPreconditionRequired.Error(), target},
+		{"version conflict", fiber.MethodDelete, target + "?reason=test", "", []string{fiber.HeaderIfMatch, `"99"`},
+			http.StatusPreconditionFailed, "/problems/version-conflict", product.ErrVersionConflict.Error(), target + "?reason=test"},
+		{"unparsable body", fiber.MethodPost, "/product", `{"product_name":`, nil, http.StatusBadRequest, "about:blank", "", "/product"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := append([]string{fiber.HeaderAccept, utils.MIMEApplicationProblemJSON}, tt.headers...)
+			resp, body := doRequest(t, app, tt.method, tt.target, tt.body, headers...)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("got status %d, want %d: %s", resp.StatusCode, tt.status, body)
+			}
+			problem := decodeProblem(t, resp, body)
+			if problem.Type != tt.typ || problem.Title != http.StatusText(tt.status) || problem.Instance != tt.instance {
+				t.Fatalf("got problem %+v, want type %s, title %q, and instance %s", problem, tt.typ, http.StatusText(tt.status), tt.instance)
+			}
+			if tt.detail != "" && problem.Detail != tt.detail {
+				t.Fatalf("got detail %q, want %q", problem.Detail, tt.detail)
+			}
+			if got := resp.Header.Get(fiber.HeaderVary); !strings.Contains(got, fiber.HeaderAccept) {
+				t.Fatalf("got Vary %q, want Accept", got)
+			}
+		})
+	}
+}
+
+func TestProblemValidationErrors(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	resp, body := doRequest(t, app, fiber.MethodPost, "/product", `{"product_name":"","stock":-1}`,
+		fiber.HeaderAccept, utils.MIMEApplicationProblemJSON)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("POST /product returned %d, want 422: %s", resp.StatusCode, body)
+	}
+	problem := decodeProblem(t, resp, body)
+	want := []utils.FieldError{
+		{Field: "product_name", Rule: "required", Message: "product_name is required"},
+		{Field: "stock", Rule: "gte", Param: "0", Message: "stock must be greater than or equal to 0"},
+	}
+	if problem.Type != "/problems/validation" || len(problem.Errors) != len(want) {
+		t.Fatalf("got problem %+v, want a validation problem with %d errors", problem, len(want))
+	}
+	for i := range want {
+		if problem.Errors[i] != want[i] {
+			t.Errorf("error %d is %+v, want %+v", i, problem.Errors[i], want[i])
+		}
+	}
+	if problem.Detail != utils.ValidationErrors(want).Error() {
+		t.Errorf("got detail %q, want the joined messages", problem.Detail)
+	}
+}
+
+func TestProblemIsOptIn(t *testing.T) {
+	app := newTestApp(t, nil)
+	missing := "/product/00000000-0000-4000-8000-000000000000"
+
+	// Envelope tetap menjadi default, termasuk jika application/json lebih diutamakan daripada problem+json
+	for _, accept := range []string{"", "*/*", "application/json, application/problem+json;q=0.5"} {
+		resp, body := doRequest(t, app, fiber.MethodGet, missing, "", fiber.HeaderAccept, accept)
+		var envelope utils.Response
+		if err := json.Unmarshal(body, &envelope); err != nil || resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Accept %q returned %d: %s", accept, resp.StatusCode, body)
+		}
+		if got := resp.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(got, fiber.MIMEApplicationJSON) {
+			t.Fatalf("Accept %q returned Content-Type %q, want JSON", accept, got)
+		}
+		if envelope.Status != http.StatusNotFound || envelope.Message != product.ErrProductNotFound.Error() {
+			t.Fatalf("Accept %q returned envelope %+v", accept, envelope)
+		}
+	}
+}
+
+// brokenRepository adalah repository yang gagal membaca product dengan error yang bukan error domain.
+type brokenRepository struct {
+	product.Repository
+}
+
+func (brokenRepository) Find(context.Context, product.ID) (*product.Product, error) {
+	return nil, errors.New("pq: connection reset by peer at 10.0.0.5:5432")
+}
+
+func TestProblemHidesInternalErrors(t *testing.T) {
+	app := newTestApp(t, brokenRepository{Repository: repository.NewMemoryRepository()})
+
+	resp, body := doRequest(t, app, fiber.MethodGet, "/product/00000000-0000-4000-8000-000000000000", "",
+		fiber.HeaderAccept, utils.MIMEApplicationProblemJSON)
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("GET /product/:id returned %d, want 500: %s", resp.StatusCode, body)
+	}
+	problem := decodeProblem(t, resp, body)
+	if problem.Type != "about:blank" || problem.Detail != http.StatusText(http.StatusInternalServerError) {
+		t.Fatalf("got problem %+v, want the status text without the driver error", problem)
+	}
+}
+
+func TestEnvelopeHidesInternalErrors(t *testing.T) {
+	app := newTestApp(t, brokenRepository{Repository: repository.NewMemoryRepository()})
+
+	resp, body := doRequest(t, app, fiber.MethodGet, "/product/00000000-0000-4000-8000-000000000000", "")
+	var envelope utils.Response
+	if err := json.Unmarshal(body, &envelope); err != nil || resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("GET /product/:id returned %d, want 500: %s", resp.StatusCode, body)
+	}
+	if envelope.Message != http.StatusText(http.StatusInternalServerError) {
+		t.Fatalf("got message %q, want the status text without the driver error", envelope.Message)
+	}
+}
@@ -0,0 +1,366 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"CRUD_Hexagonal/infrastructure"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/exp/slog"
+)
+
+// exportFlushRows adalah jumlah baris yang ditulis sebelum buffer response dikirim ke client.
+const exportFlushRows = 100
+
+// exportContentTypes memetakan nilai parameter format ke Content-Type response.
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"ndjson": "application/x-ndjson",
+	"xlsx":   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// exportColumns adalah kolom default pada export CSV dan XLSX. Field geo ditulis sebagai kolom latitude dan longitude.
+var exportColumns = []string{
+	"product_id", "code", "product_name", "description", "stock", "reserved", "available", "allocated",
+	"latitude", "longitude", "created_at", "updated_at", "deleted_at", "version",
+}
+
+// exportEncoder menulis produk ke response export dalam satu format.
+type exportEncoder interface {
+	// Write menulis satu produk.
+	Write(p *product.Product) error
+	// Flush mengirim baris yang masih ditampung ke writer.
+	Flush() error
+	// Close menulis sisa response setelah produk terakhir.
+	Close() error
+}
+
+// Fungsi Export adalah handler untuk endpoint GET /product/export
+// Fungsi ini mengirim semua product yang sesuai filter sebagai file CSV, NDJSON, atau XLSX secara streaming
+func (h *adapter) Export(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi Export
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:Export")
+	defer span.End()
+
+	format := strings.ToLower(ctx.Query("format", "csv"))
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		errorResponse(ctx, nil, product.ErrInvalidExportFormat)
+		return nil
+	}
+
+	// Filter sama dengan GET /product, parameter page, limit, dan cursor diabaikan oleh service
+	filter, err := parseFilter(ctx)
+	if err != nil {
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+	for _, field := range filter.Fields {
+		// Rincian stok per lokasi dibaca per produk sehingga tidak dapat di-stream
+		if field == "locations" {
+			errorResponse(ctx, nil, product.ErrInvalidFields)
+			return nil
+		}
+	}
+	// Setelah streaming dimulai status response tidak dapat diubah, sehingga kombinasi parameter divalidasi lebih dahulu
+	if err := filter.ValidateOrder(); err != nil {
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+
+	// Attachment menentukan Content-Type dari ekstensi file (.ndjson menjadi application/octet-stream), sehingga
+	// Content-Type format export ditulis setelahnya
+	ctx.Attachment("products." + format)
+	ctx.Set(fiber.HeaderContentType, contentType)
+	ctx.Status(fiber.StatusOK)
+
+	// Body ditulis setelah handler selesai, sehingga writer hanya memakai context dan filter yang sudah dibaca
+	streamCtx := context.WithoutCancel(c)
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		var encoder exportEncoder
+		switch format {
+		case "csv":
+			encoder = newCSVEncoder(w, tabularColumns(filter))
+		case "ndjson":
+			encoder = &ndjsonEncoder{w: w, fields: filter.Fields}
+		case "xlsx":
+			xlsx := newXLSXEncoder(w, tabularColumns(filter))
+			defer xlsx.workbook.Close()
+			encoder = xlsx
+		}
+
+		rows := 0
+		err := h.storeService.Export(streamCtx, filter, func(p *product.Product) error {
+			if err := encoder.Write(p); err != nil {
+				return err
+			}
+			rows++
+			if rows%exportFlushRows == 0 {
+				// Error flush berarti client sudah menutup koneksi, export dihentikan
+				return encoder.Flush()
+			}
+			return nil
+		})
+		if err == nil {
+			err = encoder.Close()
+		}
+		if err != nil {
+			// Header sudah terkirim, error hanya dapat dicatat dan response berakhir terpotong
+			slog.ErrorContext(streamCtx, "Export Error", slog.String("format", format), slog.Int("rows", rows), slog.Any("err ", err))
+		}
+	})
+	return nil
+}
+
+// tabularColumns menentukan kolom export CSV dan XLSX dari projection pada filter.
+// Tanpa projection seluruh kolom default ditulis, ditambah distance_km pada pencarian berdasarkan jarak.
+func tabularColumns(filter product.Filter) []string {
+	if len(filter.Fields) == 0 {
+		columns := append([]string{}, exportColumns...)
+		if filter.Near != nil {
+			columns = append(columns, "distance_km")
+		}
+		return columns
+	}
+
+	columns := make([]string, 0, len(filter.Fields)+1)
+	for _, field := range filter.Fields {
+		if field == "geo" {
+			columns = append(columns, "latitude", "longitude")
+			continue
+		}
+		columns = append(columns, field)
+	}
+	return columns
+}
+
+// exportValue mengembalikan nilai kolom export untuk produk p. Kolom yang kosong (misalnya koordinat
+// produk yang belum memiliki lokasi) menghasilkan nil.
+func exportValue(p *product.Product, column string) any {
+	switch column {
+	case "product_id":
+		return p.ID.String()
+	case "code":
+		return p.Code
+	case "product_name":
+		return p.Name
+	case "description":
+		return p.Description
+	case "stock":
+		return p.Stock
+	case "reserved":
+		return p.Reserved
+	case "available":
+		return p.Available
+	case "allocated":
+		return p.Allocated
+	case "latitude":
+		if p.Geo != nil {
+			return p.Geo.Latitude()
+		}
+	case "longitude":
+		if p.Geo != nil {
+			return p.Geo.Longitude()
+		}
+	case "distance_km":
+		if p.Distance != nil {
+			return *p.Distance
+		}
+	case "created_at":
+		return p.CreatedAt
+	case "updated_at":
+		return p.UpdatedAt
+	case "deleted_at":
+		return p.DeletedAt
+	case "version":
+		return p.Version
+	}
+	return nil
+}
+
+// csvEncoder menulis produk sebagai baris CSV dengan baris header berisi nama kolom.
+type csvEncoder struct {
+	w       *bufio.Writer
+	writer  *csv.Writer
+	columns []string
+	header  bool
+	record  []string
+}
+
+func newCSVEncoder(w *bufio.Writer, columns []string) *csvEncoder {
+	return &csvEncoder{w: w, writer: csv.NewWriter(w), columns: columns, record: make([]string, len(columns))}
+}
+
+func (e *csvEncoder) Write(p *product.Product) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	for i, column := range e.columns {
+		switch value := exportValue(p, column).(type) {
+		case nil:
+			e.record[i] = ""
+		case float64:
+			e.record[i] = strconv.FormatFloat(value, 'f', -1, 64)
+		default:
+			e.record[i] = fmt.Sprint(value)
+		}
+	}
+	return e.writer.Write(e.record)
+}
+
+func (e *csvEncoder) Flush() error {
+	e.writer.Flush()
+	if err := e.writer.Error(); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+func (e *csvEncoder) Close() error {
+	// Header tetap ditulis walaupun tidak ada produk yang sesuai filter
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+func (e *csvEncoder) writeHeader() error {
+	if e.header {
+		return nil
+	}
+	e.header = true
+	return e.writer.Write(e.columns)
+}
+
+// ndjsonEncoder menulis setiap produk sebagai satu objek JSON per baris, dengan projection seperti GET /product.
+type ndjsonEncoder struct {
+	w      *bufio.Writer
+	fields []string
+}
+
+func (e *ndjsonEncoder) Write(p *product.Product) error {
+	var line []byte
+	var err error
+	if len(e.fields) > 0 {
+		var projected []map[string]json.RawMessage
+		projected, err = projectProducts([]*product.Product{p}, e.fields)
+		if err == nil {
+			line, err = json.Marshal(projected[0])
+		}
+	} else {
+		line, err = json.Marshal(p)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := e.w.Write(line); err != nil {
+		return err
+	}
+	return e.w.WriteByte('\n')
+}
+
+func (e *ndjsonEncoder) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *ndjsonEncoder) Close() error {
+	return e.w.Flush()
+}
+
+// xlsxEncoder menulis produk ke sheet pertama workbook melalui StreamWriter excelize, yang memindahkan baris
+// ke file sementara ketika ukurannya besar. Format XLSX adalah arsip zip, sehingga workbook baru dikirim
+// ke client setelah baris terakhir ditulis.
+type xlsxEncoder struct {
+	w        *bufio.Writer
+	workbook *excelize.File
+	sheet    *excelize.StreamWriter
+	columns  []string
+	row      int
+	err      error
+}
+
+func newXLSXEncoder(w *bufio.Writer, columns []string) *xlsxEncoder {
+	e := &xlsxEncoder{w: w, workbook: excelize.NewFile(), columns: columns, row: 1}
+	e.sheet, e.err = e.workbook.NewStreamWriter(e.workbook.GetSheetName(0))
+	if e.err == nil {
+		header := make([]interface{}, len(columns))
+		for i, column := range columns {
+			header[i] = column
+		}
+		e.err = e.setRow(header)
+	}
+	return e
+}
+
+func (e *xlsxEncoder) Write(p *product.Product) error {
+	if e.err != nil {
+		return e.err
+	}
+	values := make([]interface{}, len(e.columns))
+	for i, column := range e.columns {
+		values[i] = exportValue(p, column)
+	}
+	return e.setRow(values)
+}
+
+// Flush hanya menulis workbook setelah baris terakhir, Flush di tengah export tidak melakukan apa-apa.
+func (e *xlsxEncoder) Flush() error {
+	return e.err
+}
+
+// Close menyelesaikan sheet lalu menulis workbook ke response. File sementara workbook dihapus oleh pemanggil.
+func (e *xlsxEncoder) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	if err := e.workbook.Write(e.w); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+func (e *xlsxEncoder) setRow(values []interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err == nil {
+		err = e.sheet.SetRow(cell, values)
+	}
+	if err != nil {
+		e.err = err
+		return err
+	}
+	e.row++
+	return nil
+}
+
+/*
+Penjelasan Kode:
+
+Export Produk:
+GET /product/export?format=csv|ndjson|xlsx mengunduh semua product yang sesuai filter sebagai file products.<format>
+(default csv). Filter sama dengan GET /product (keyword, include_deleted, ekspresi field[operator]=nilai, sort, pencarian
+berdasarkan jarak, dan fields), tetapi tanpa pagination: parameter page, limit, dan cursor diabaikan.
+
+Response dikirim secara streaming melalui SetBodyStreamWriter. Service membaca product satu per satu (cursor MongoDB,
+atau per halaman pada repository lain) dan setiap product langsung ditulis ke response, buffer dikirim ke client setiap
+exportFlushRows baris, sehingga seluruh katalog tidak pernah ditampung di memori. Format XLSX adalah pengecualian: baris
+ditulis ke StreamWriter excelize (yang memakai file sementara untuk sheet besar) dan workbook dikirim setelah baris terakhir.
+Middleware otelfiber tidak dijalankan untuk route ini (lihat cmd/main.go) karena membaca seluruh body response.
+
+Parameter fields memilih kolom. CSV dan XLSX memiliki baris header berisi nama kolom, field geo ditulis sebagai kolom
+latitude dan longitude, dan kolom distance_km ditambahkan pada pencarian berdasarkan jarak. NDJSON berisi satu objek JSON
+per baris dengan projection yang sama seperti GET /product. Field locations tidak dapat diekspor.
+
+Format yang tidak dikenal, parameter filter yang tidak valid, serta kombinasi sort dengan pencarian berdasarkan jarak
+menghasilkan 422 sebelum streaming dimulai. Error setelah streaming dimulai (misalnya koneksi database atau client
+terputus) hanya dicatat di log karena status response sudah terkirim.
+*/
@@ -0,0 +1,170 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// createExportProducts membuat produk yang diekspor pada pengujian, satu di antaranya dengan lokasi.
+func createExportProducts(t *testing.T, app *fiber.App) []*product.Product {
+	t.Helper()
+
+	return []*product.Product{
+		createProduct(t, app, `{"code":"SKU-1","product_name":"Kopi, Arabika","stock":5,`+
+			`"geo":{"type":"Point","coordinates":[106.8,-6.2]}}`),
+		createProduct(t, app, `{"code":"SKU-2","product_name":"Teh","stock":9}`),
+	}
+}
+
+// getExport mengunduh export dan memeriksa status, Content-Type, serta nama file attachment.
+func getExport(t *testing.T, app *fiber.App, format, query string) []byte {
+	t.Helper()
+
+	resp, data := doRequest(t, app, fiber.MethodGet, "/product/export?format="+format+query, "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /product/export?format=%s%s returned %d: %s", format, query, resp.StatusCode, data)
+	}
+	if got := resp.Header.Get(fiber.HeaderContentType); got != exportContentTypes[format] {
+		t.Fatalf("got Content-Type %q, want %q", got, exportContentTypes[format])
+	}
+	if got := resp.Header.Get(fiber.HeaderContentDisposition); !strings.Contains(got, `filename="products.`+format+`"`) {
+		t.Fatalf("got Content-Disposition %q, want an attachment named products.%s", got, format)
+	}
+	return data
+}
+
+func TestExportCSV(t *testing.T) {
+	app := newTestApp(t, nil)
+	products := createExportProducts(t, app)
+
+	records, err := csv.NewReader(bytes.NewReader(getExport(t, app, "csv", "&sort=code"))).ReadAll()
+	if err != nil {
+		t.Fatalf("decoding CSV: %v", err)
+	}
+	if len(records) != 3 || strings.Join(records[0], ",") != strings.Join(exportColumns, ",") {
+		t.Fatalf("got %d records with header %q, want the default columns and 2 rows", len(records), records[0])
+	}
+	first := products[0]
+	want := []string{first.ID.String(), "SKU-1", "Kopi, Arabika", "", "5", "0", "5", "0", "-6.2", "106.8",
+		strconv.FormatInt(first.CreatedAt, 10), "0", "0", strconv.FormatInt(first.Version, 10)}
+	if strings.Join(records[1], "|") != strings.Join(want, "|") {
+		t.Errorf("got row %q, want %q", records[1], want)
+	}
+	// Produk tanpa lokasi memiliki kolom koordinat yang kosong
+	if records[2][1] != "SKU-2" || records[2][8] != "" || records[2][9] != "" {
+		t.Errorf("got row %q, want SKU-2 without coordinates", records[2])
+	}
+
+	// Projection memilih kolom, geo ditulis sebagai latitude dan longitude
+	data := getExport(t, app, "csv", "&fields=code,geo&sort=-stock")
+	if got, want := string(data), "code,latitude,longitude\nSKU-2,,\nSKU-1,-6.2,106.8\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Header tetap ditulis walaupun tidak ada produk yang sesuai filter
+	if got := string(getExport(t, app, "csv", "&fields=code&stock[gt]=100")); got != "code\n" {
+		t.Errorf("got %q, want only the header", got)
+	}
+}
+
+func TestExportNDJSON(t *testing.T) {
+	app := newTestApp(t, nil)
+	products := createExportProducts(t, app)
+
+	var lines []map[string]json.RawMessage
+	scanner := bufio.NewScanner(bytes.NewReader(getExport(t, app, "ndjson", "&fields=code,stock&sort=code")))
+	for scanner.Scan() {
+		var line map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("decoding line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	for i, line := range lines {
+		if len(line) != 2 || string(line["code"]) != strconv.Quote(products[i].Code) ||
+			string(line["stock"]) != strconv.FormatInt(products[i].Stock, 10) {
+			t.Errorf("line %d is %v, want only code and stock of %s", i, line, products[i].Code)
+		}
+	}
+
+	// Tanpa projection setiap baris berisi product lengkap
+	data := getExport(t, app, "ndjson", "&keyword=Teh")
+	var exported product.Product
+	if err := json.Unmarshal(bytes.TrimSuffix(data, []byte("\n")), &exported); err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	if exported.ID != products[1].ID || exported.Name != "Teh" || exported.Available != 9 {
+		t.Errorf("got %+v, want the whole product Teh", exported)
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	app := newTestApp(t, nil)
+	createExportProducts(t, app)
+
+	workbook, err := excelize.OpenReader(bytes.NewReader(getExport(t, app, "xlsx", "&fields=code,product_name,stock,geo&sort=code")))
+	if err != nil {
+		t.Fatalf("opening workbook: %v", err)
+	}
+	defer workbook.Close()
+
+	rows, err := workbook.GetRows(workbook.GetSheetName(0))
+	if err != nil {
+		t.Fatalf("reading rows: %v", err)
+	}
+	want := [][]string{
+		{"code", "product_name", "stock", "latitude", "longitude"},
+		{"SKU-1", "Kopi, Arabika", "5", "-6.2", "106.8"},
+		{"SKU-2", "Teh", "9"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got rows %q, want %q", rows, want)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d is %q, want %q", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestExportRejectsInvalidParameters(t *testing.T) {
+	app := newTestApp(t, nil)
+	createExportProducts(t, app)
+
+	// Parameter divalidasi sebelum streaming dimulai, sehingga kesalahan dikirim sebagai response 422 biasa
+	tests := []struct {
+		query   string
+		message string
+	}{
+		{"format=pdf", product.ErrInvalidExportFormat.Error()},
+		{"fields=code,locations", product.ErrInvalidFields.Error()},
+		{"fields=password", product.ErrInvalidFields.Error()},
+		{"stock[like]=1", product.ErrInvalidFilter.Error()},
+	}
+	for _, tt := range tests {
+		resp, data := doRequest(t, app, fiber.MethodGet, "/product/export?"+tt.query, "")
+		var envelope productEnvelope
+		if err := json.Unmarshal(data, &envelope); err != nil || resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("GET /product/export?%s returned %d, want 422: %s", tt.query, resp.StatusCode, data)
+		}
+		if !strings.HasPrefix(envelope.Message, tt.message) {
+			t.Errorf("GET /product/export?%s returned message %q, want %q", tt.query, envelope.Message, tt.message)
+		}
+		if got := resp.Header.Get(fiber.HeaderContentDisposition); got != "" {
+			t.Errorf("GET /product/export?%s returned Content-Disposition %q, want none", tt.query, got)
+		}
+	}
+}
@@ -12,21 +12,103 @@ type ProductHandler interface {
 	// Metode ini diharapkan mengarahkan permintaan ke service di domain untuk mengambil data produk.
 	Get(ctx *fiber.Ctx)
 
+	// GetByCode mengambil satu entitas Product berdasarkan kode produk (SKU) yang diterima dari konteks request.
+	GetByCode(ctx *fiber.Ctx)
+
 	// Create membuat entitas Product baru dengan data yang diterima dari request.
 	// Metode ini akan mengarahkan data ke service di domain untuk disimpan ke dalam database.
 	Create(ctx *fiber.Ctx)
 
+	// BulkCreate membuat atau memperbarui (upsert) banyak entitas Product sekaligus dan mengembalikan hasil setiap item.
+	BulkCreate(ctx *fiber.Ctx)
+
+	// BulkDelete menandai banyak entitas Product sebagai dihapus (soft delete) berdasarkan ID atau kode setiap item.
+	BulkDelete(ctx *fiber.Ctx)
+
+	// ImportProducts membaca file CSV atau XLSX lalu memulai job import Product di latar belakang.
+	ImportProducts(ctx *fiber.Ctx)
+
+	// GetJob mengambil status dan progres job import berdasarkan ID.
+	GetJob(ctx *fiber.Ctx)
+
+	// GetJobErrors mengunduh laporan baris yang ditolak oleh job import dalam format CSV.
+	GetJobErrors(ctx *fiber.Ctx)
+
 	// Update memperbarui entitas Product yang sudah ada dengan data yang diterima dari request.
 	// Metode ini akan meneruskan data yang diperbarui ke service di domain untuk diupdate.
 	Update(ctx *fiber.Ctx)
 
-	// Delete menghapus entitas Product berdasarkan ID yang diterima dari konteks request.
+	// Patch menerapkan dokumen JSON Merge Patch atau JSON Patch pada entitas Product yang sudah ada.
+	// Berbeda dengan Update, nilai kosong dan null pada dokumen ikut diterapkan.
+	Patch(ctx *fiber.Ctx)
+
+	// Delete menandai entitas Product sebagai dihapus (soft delete) berdasarkan ID yang diterima dari konteks request.
 	// Permintaan ini akan diteruskan ke service di domain yang bertanggung jawab untuk menghapus data produk.
 	Delete(ctx *fiber.Ctx)
 
+	// DeleteByCode menandai entitas Product sebagai dihapus (soft delete) berdasarkan kode produk (SKU).
+	DeleteByCode(ctx *fiber.Ctx)
+
+	// Restore memulihkan entitas Product yang sudah di-soft delete berdasarkan ID yang diterima dari konteks request.
+	Restore(ctx *fiber.Ctx)
+
+	// Purge menghapus permanen entitas Product yang sudah di-soft delete lebih lama dari masa retensi.
+	// Metode ini ditujukan untuk endpoint admin.
+	Purge(ctx *fiber.Ctx)
+
+	// AdjustStock mengubah stok Product dengan jumlah bertanda dan kode alasan, lalu mencatat pergerakannya.
+	AdjustStock(ctx *fiber.Ctx)
+
+	// GetMovements mengambil riwayat pergerakan stok sebuah Product dengan pagination.
+	GetMovements(ctx *fiber.Ctx)
+
+	// Reserve menahan sejumlah stok Product selama TTL tertentu.
+	Reserve(ctx *fiber.Ctx)
+
+	// GetReservation mengambil satu reservasi stok berdasarkan ID.
+	GetReservation(ctx *fiber.Ctx)
+
+	// ConfirmReservation mengonfirmasi reservasi aktif sehingga stok Product berkurang.
+	ConfirmReservation(ctx *fiber.Ctx)
+
+	// ReleaseReservation membatalkan reservasi aktif dan melepas stok yang ditahan.
+	ReleaseReservation(ctx *fiber.Ctx)
+
+	// CreateLocation membuat lokasi baru (gudang atau toko) untuk menyimpan stok.
+	CreateLocation(ctx *fiber.Ctx)
+
+	// GetLocations mengambil daftar semua lokasi.
+	GetLocations(ctx *fiber.Ctx)
+
+	// GetLocation mengambil satu lokasi berdasarkan ID.
+	GetLocation(ctx *fiber.Ctx)
+
+	// GetLocationStock mengambil stok setiap Product pada sebuah lokasi.
+	GetLocationStock(ctx *fiber.Ctx)
+
+	// GetProductStock mengambil stok sebuah Product pada setiap lokasi.
+	GetProductStock(ctx *fiber.Ctx)
+
+	// TransferStock memindahkan stok Product dari satu lokasi ke lokasi lain.
+	TransferStock(ctx *fiber.Ctx)
+
+	// Negotiate adalah middleware yang menolak request yang mengubah data dengan 406 jika header Accept
+	// tidak dapat dipenuhi, sebelum data diubah.
+	Negotiate(ctx *fiber.Ctx)
+
+	// Idempotency adalah middleware yang memproses request dengan header Idempotency-Key hanya sekali
+	// dan memutar ulang response yang tersimpan untuk pengulangannya.
+	Idempotency(ctx *fiber.Ctx)
+
 	// GetAll mengambil daftar semua entitas Product yang tersedia.
 	// Service di domain akan mengembalikan daftar produk yang diminta.
 	GetAll(ctx *fiber.Ctx)
+
+	// Export mengunduh semua Product yang sesuai filter sebagai file CSV, NDJSON, atau XLSX secara streaming.
+	Export(ctx *fiber.Ctx)
+
+	// Search mencari Product berdasarkan kata pada nama dan deskripsi, diurutkan dari yang paling relevan.
+	Search(ctx *fiber.Ctx)
 }
 
 /*
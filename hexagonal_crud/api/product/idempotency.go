@@ -0,0 +1,139 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"CRUD_Hexagonal/infrastructure"
+	"CRUD_Hexagonal/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// idempotencyKeyHeader adalah header yang dikirim klien untuk menandai percobaan ulang request yang sama.
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// idempotentReplayedHeader ditambahkan pada response yang diputar ulang dari record yang tersimpan.
+	idempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// replayedHeaders adalah header response yang disimpan dan diputar ulang bersama body.
+var replayedHeaders = []string{fiber.HeaderContentType, fiber.HeaderETag, fiber.HeaderVary}
+
+// Fungsi Idempotency adalah middleware untuk request yang mengubah data (POST, PUT, PATCH, DELETE)
+// Request dengan header Idempotency-Key hanya diproses sekali, pengulangannya menerima response yang tersimpan
+func (h *adapter) Idempotency(ctx *fiber.Ctx) error {
+	key := ctx.Get(idempotencyKeyHeader)
+	if key == "" || isSafeMethod(ctx.Method()) {
+		return ctx.Next()
+	}
+
+	// Tracing dimulai untuk memantau eksekusi fungsi Idempotency
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:Idempotency")
+	defer span.End()
+
+	// Key yang digunakan ulang untuk request lain (422) atau selagi request pertama diproses (409) ditolak
+	fingerprint := requestFingerprint(ctx)
+	existing, err := h.storeService.ClaimIdempotencyKey(c, key, fingerprint)
+	if err != nil {
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+	if existing != nil {
+		replayResponse(ctx, existing)
+		return nil
+	}
+
+	// Menjalankan handler, lalu menyimpan response-nya untuk pengulangan berikutnya
+	if err := ctx.Next(); err != nil {
+		h.releaseIdempotencyKey(c, key)
+		return err
+	}
+
+	status := ctx.Response().StatusCode()
+	if status >= http.StatusInternalServerError {
+		// Kesalahan server bisa bersifat sementara, sehingga klien boleh mencoba ulang dengan key yang sama
+		h.releaseIdempotencyKey(c, key)
+		return nil
+	}
+
+	record := &product.IdempotencyRecord{
+		Key:    key,
+		Status: status,
+		Header: map[string]string{},
+		Body:   append([]byte(nil), ctx.Response().Body()...),
+	}
+	for _, name := range replayedHeaders {
+		if value := ctx.GetRespHeader(name); value != "" {
+			record.Header[name] = value
+		}
+	}
+	if err := h.storeService.CompleteIdempotencyKey(c, record); err != nil {
+		// Response tetap dikirim, hanya pengulangannya yang akan diproses ulang setelah klaim kedaluwarsa
+		slog.ErrorContext(c, "Failed to store idempotent response api:product:Idempotency", slog.Any("err ", err))
+	}
+	return nil
+}
+
+// releaseIdempotencyKey melepas klaim key sehingga request dapat diulang. Kegagalan hanya dicatat karena klaim
+// yang tidak dilepas akan kedaluwarsa dengan sendirinya.
+func (h *adapter) releaseIdempotencyKey(c context.Context, key string) {
+	if err := h.storeService.ReleaseIdempotencyKey(c, key); err != nil {
+		slog.ErrorContext(c, "Failed to release idempotency key api:product:Idempotency", slog.Any("err ", err))
+	}
+}
+
+// isSafeMethod mengembalikan true untuk method yang tidak mengubah data, sehingga Idempotency-Key diabaikan.
+func isSafeMethod(method string) bool {
+	return method == fiber.MethodGet || method == fiber.MethodHead || method == fiber.MethodOptions
+}
+
+// requestFingerprint menghitung hash SHA-256 dari method, URL, Content-Type, If-Match, format response, dan body request.
+// If-Match ikut dihitung karena menentukan versi yang diubah, sehingga request dengan ETag lain bukan pengulangan.
+// Content-Type hasil negosiasi Accept ikut dihitung karena response tersimpan sudah ditulis dalam format tersebut.
+func requestFingerprint(ctx *fiber.Ctx) string {
+	hash := sha256.New()
+	for _, part := range []string{ctx.Method(), ctx.OriginalURL(), ctx.Get(fiber.HeaderContentType), ctx.Get(fiber.HeaderIfMatch),
+		utils.NegotiatedContentType(ctx)} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(ctx.Body())
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replayResponse menulis response yang tersimpan beserta header Idempotent-Replayed.
+func replayResponse(ctx *fiber.Ctx, record *product.IdempotencyRecord) {
+	for name, value := range record.Header {
+		ctx.Set(name, value)
+	}
+	ctx.Set(idempotentReplayedHeader, "true")
+	ctx.Status(record.Status)
+	_ = ctx.Send(record.Body)
+}
+
+/*
+Penjelasan Kode:
+
+Middleware Idempotency:
+Klien pada jaringan yang tidak stabil dapat mengulang POST /product dan setiap pengulangan akan membuat product baru.
+Dengan header Idempotency-Key, request yang mengubah data hanya diproses sekali:
+
+1. Percobaan pertama mengklaim key bersama fingerprint request (SHA-256 dari method, URL, Content-Type, If-Match,
+   Content-Type response hasil negosiasi Accept, dan body), lalu handler dijalankan dan response-nya (status, body,
+   Content-Type, ETag, Vary) disimpan.
+2. Pengulangan dengan key dan request yang sama menerima response yang tersimpan dengan header Idempotent-Replayed: true,
+   tanpa menjalankan handler lagi.
+3. Key yang sama dengan body, endpoint, If-Match, atau format response (Accept) yang berbeda ditolak dengan 422
+   Unprocessable Entity, dan pengulangan yang datang selagi percobaan pertama masih diproses ditolak dengan 409 Conflict.
+   Karena If-Match termasuk fingerprint, response 412 atau 428 yang tersimpan tidak pernah diputar ulang untuk request
+   dengan ETag yang berbeda; klien yang membaca ulang product mengirim request berikutnya dengan key baru.
+
+Response 4xx ikut disimpan karena mengulang request yang sama akan menghasilkan penolakan yang sama, sedangkan response 5xx
+tidak disimpan dan klaimnya dilepas agar klien dapat mencoba ulang. Response disimpan selama IDEMPOTENCY_KEY_TTL
+(default 24 jam). Request tanpa header Idempotency-Key dan request GET, HEAD, atau OPTIONS tidak terpengaruh.
+*/
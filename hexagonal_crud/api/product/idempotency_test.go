@@ -0,0 +1,126 @@
+package product
+
+import (
+	repository "CRUD_Hexagonal/repository/product"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	app := newTestApp(t, nil)
+	body := `{"code":"SKU-1","product_name":"Kopi","stock":5}`
+
+	resp, first := doRequest(t, app, fiber.MethodPost, "/product", body, idempotencyKeyHeader, "key-1")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(idempotentReplayedHeader) != "" {
+		t.Fatalf("first POST returned %d with %s=%q: %s", resp.StatusCode, idempotentReplayedHeader,
+			resp.Header.Get(idempotentReplayedHeader), first)
+	}
+
+	// Pengulangan dengan key dan request yang sama menerima response yang tersimpan tanpa membuat product baru
+	resp, replayed := doRequest(t, app, fiber.MethodPost, "/product", body, idempotencyKeyHeader, "key-1")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(idempotentReplayedHeader) != "true" {
+		t.Fatalf("replayed POST returned %d with %s=%q: %s", resp.StatusCode, idempotentReplayedHeader,
+			resp.Header.Get(idempotentReplayedHeader), replayed)
+	}
+	if string(replayed) != string(first) {
+		t.Fatalf("got replayed body %s, want %s", replayed, first)
+	}
+
+	// Key yang sama untuk request lain ditolak
+	resp, data := doRequest(t, app, fiber.MethodPost, "/product", `{"code":"SKU-2","product_name":"Teh"}`,
+		idempotencyKeyHeader, "key-1")
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("POST with a reused key returned %d, want 422: %s", resp.StatusCode, data)
+	}
+
+	// Request tanpa key tidak terpengaruh dan kode yang sama menghasilkan konflik
+	resp, data = doRequest(t, app, fiber.MethodPost, "/product", body)
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("POST without a key returned %d, want 409: %s", resp.StatusCode, data)
+	}
+}
+
+func TestIdempotencyFingerprintIncludesIfMatch(t *testing.T) {
+	app := newTestApp(t, nil)
+	created := createProduct(t, app, `{"code":"SKU-1","product_name":"Kopi"}`)
+	target := "/product/" + created.ID.String()
+
+	// Tanpa If-Match request ditolak dengan 428, dan response tersebut disimpan untuk key-1
+	resp, data := doRequest(t, app, fiber.MethodPut, target, `{"product_name":"Kopi Susu"}`, idempotencyKeyHeader, "key-1")
+	if resp.StatusCode != http.StatusPreconditionRequired {
+		t.Fatalf("PUT without If-Match returned %d, want 428: %s", resp.StatusCode, data)
+	}
+
+	// Request yang sama dengan If-Match bukan pengulangan, sehingga 428 tidak diputar ulang
+	resp, data = doRequest(t, app, fiber.MethodPut, target, `{"product_name":"Kopi Susu"}`,
+		idempotencyKeyHeader, "key-1", fiber.HeaderIfMatch, etag(created.Version))
+	if resp.StatusCode != http.StatusUnprocessableEntity || resp.Header.Get(idempotentReplayedHeader) != "" {
+		t.Fatalf("PUT with If-Match and a reused key returned %d with %s=%q, want 422: %s", resp.StatusCode,
+			idempotentReplayedHeader, resp.Header.Get(idempotentReplayedHeader), data)
+	}
+
+	resp, data = doRequest(t, app, fiber.MethodPut, target, `{"product_name":"Kopi Susu"}`,
+		idempotencyKeyHeader, "key-2", fiber.HeaderIfMatch, etag(created.Version))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("PUT with If-Match returned %d: %s", resp.StatusCode, data)
+	}
+
+	// Pengulangan dengan ETag yang sama diputar ulang, sedangkan ETag lain dengan key yang sama ditolak
+	resp, _ = doRequest(t, app, fiber.MethodPut, target, `{"product_name":"Kopi Susu"}`,
+		idempotencyKeyHeader, "key-2", fiber.HeaderIfMatch, etag(created.Version))
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(idempotentReplayedHeader) != "true" {
+		t.Fatalf("repeated PUT returned %d with %s=%q, want a replayed 200", resp.StatusCode,
+			idempotentReplayedHeader, resp.Header.Get(idempotentReplayedHeader))
+	}
+	resp, data = doRequest(t, app, fiber.MethodPut, target, `{"product_name":"Kopi Susu"}`,
+		idempotencyKeyHeader, "key-2", fiber.HeaderIfMatch, etag(created.Version+1))
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("PUT with another If-Match and a reused key returned %d, want 422: %s", resp.StatusCode, data)
+	}
+}
+
+func TestIdempotencyFingerprintIncludesAccept(t *testing.T) {
+	app := newTestApp(t, nil)
+	body := `{"code":"SKU-1","product_name":"Kopi"}`
+
+	resp, first := doRequest(t, app, fiber.MethodPost, "/product", body, idempotencyKeyHeader, "key-1",
+		fiber.HeaderAccept, fiber.MIMEApplicationXML)
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), fiber.MIMEApplicationXML) {
+		t.Fatalf("first POST returned %d with Content-Type %q, want XML: %s", resp.StatusCode,
+			resp.Header.Get(fiber.HeaderContentType), first)
+	}
+
+	// Response tersimpan berformat XML, sehingga pengulangan yang meminta JSON ditolak alih-alih menerima XML
+	resp, data := doRequest(t, app, fiber.MethodPost, "/product", body, idempotencyKeyHeader, "key-1",
+		fiber.HeaderAccept, fiber.MIMEApplicationJSON)
+	if resp.StatusCode != http.StatusUnprocessableEntity || resp.Header.Get(idempotentReplayedHeader) != "" {
+		t.Fatalf("POST with another Accept and a reused key returned %d with %s=%q, want 422: %s", resp.StatusCode,
+			idempotentReplayedHeader, resp.Header.Get(idempotentReplayedHeader), data)
+	}
+
+	// Accept yang berbeda tetapi menghasilkan media type yang sama tetap merupakan pengulangan
+	resp, replayed := doRequest(t, app, fiber.MethodPost, "/product", body, idempotencyKeyHeader, "key-1",
+		fiber.HeaderAccept, fiber.MIMEApplicationXML+", application/json;q=0.5")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(idempotentReplayedHeader) != "true" || string(replayed) != string(first) {
+		t.Fatalf("repeated POST returned %d with %s=%q, want the replayed XML: %s", resp.StatusCode,
+			idempotentReplayedHeader, resp.Header.Get(idempotentReplayedHeader), replayed)
+	}
+}
+
+func TestIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	app := newTestApp(t, failingStockRepository{Repository: repo})
+	body := `{"code":"SKU-1","product_name":"Kopi","stock":5}`
+
+	// Response 5xx tidak disimpan, sehingga pengulangan dengan key yang sama diproses ulang
+	for i := 0; i < 2; i++ {
+		resp, data := doRequest(t, app, fiber.MethodPost, "/product", body, idempotencyKeyHeader, "key-1")
+		if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get(idempotentReplayedHeader) != "" {
+			t.Fatalf("attempt %d returned %d with %s=%q, want 503 that is not replayed: %s", i+1, resp.StatusCode,
+				idempotentReplayedHeader, resp.Header.Get(idempotentReplayedHeader), data)
+		}
+	}
+}
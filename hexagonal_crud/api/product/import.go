@@ -0,0 +1,218 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"CRUD_Hexagonal/infrastructure"
+	"CRUD_Hexagonal/utils"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// importFileField adalah nama field multipart yang berisi file import.
+const importFileField = "file"
+
+// errUnsupportedImportFormat dikembalikan jika file yang diunggah bukan CSV atau XLSX.
+var errUnsupportedImportFormat = errors.New("import file must be .csv or .xlsx")
+
+// Fungsi ImportProducts adalah handler untuk endpoint POST /product/import
+// Fungsi ini membaca file CSV atau XLSX dari form multipart lalu memulai job import di latar belakang
+func (h *adapter) ImportProducts(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi ImportProducts
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:ImportProducts")
+	defer span.End()
+
+	file, err := ctx.FormFile(importFileField)
+	if err != nil {
+		// Request bukan multipart atau tidak berisi field file
+		statusResponse(ctx, http.StatusBadRequest, nil, err)
+		return nil
+	}
+
+	var read func(io.Reader) ([][]string, error)
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv":
+		read = readCSV
+	case ".xlsx":
+		read = readXLSX
+	default:
+		statusResponse(ctx, http.StatusUnsupportedMediaType, nil, errUnsupportedImportFormat)
+		return nil
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		statusResponse(ctx, http.StatusBadRequest, nil, err)
+		return nil
+	}
+	defer content.Close()
+
+	records, err := read(content)
+	if err != nil {
+		// File yang rusak atau tidak sesuai format ekstensinya
+		errorResponse(ctx, nil, fmt.Errorf("%w: %v", product.ErrInvalidImport, err))
+		return nil
+	}
+	if len(records) == 0 {
+		errorResponse(ctx, nil, fmt.Errorf("%w: file is empty", product.ErrInvalidImport))
+		return nil
+	}
+
+	job, err := h.storeService.ImportProducts(c, product.ImportRequest{
+		Filename: filepath.Base(file.Filename),
+		Mode:     ctx.Query("mode", product.BulkCreate),
+		DryRun:   ctx.QueryBool("dry_run"),
+		Header:   records[0],
+		Rows:     records[1:],
+	})
+	if err != nil {
+		// Header yang tidak valid, mode yang tidak dikenal, atau jumlah baris yang melebihi batas (422)
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+
+	// Job diproses di latar belakang, progresnya dapat dipantau melalui URL pada header Location
+	ctx.Location("/jobs/" + job.ID.String())
+	utils.Respond(ctx, http.StatusAccepted, job, nil)
+	return nil
+}
+
+// Fungsi GetJob adalah handler untuk endpoint GET /jobs/:id
+// Fungsi ini mengambil status dan progres job import berdasarkan id dari parameter URL
+func (h *adapter) GetJob(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi GetJob
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:GetJob")
+	defer span.End()
+
+	job, err := h.findJob(c, ctx)
+	if err != nil {
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+	utils.Respond(ctx, http.StatusOK, job, nil)
+	return nil
+}
+
+// Fungsi GetJobErrors adalah handler untuk endpoint GET /jobs/:id/errors
+// Fungsi ini mengunduh laporan baris yang ditolak dalam format CSV
+func (h *adapter) GetJobErrors(ctx *fiber.Ctx) error {
+	// Tracing dimulai untuk memantau eksekusi fungsi GetJobErrors
+	c, span := infrastructure.Tracer().Start(ctx.UserContext(), "api:store:GetJobErrors")
+	defer span.End()
+
+	job, err := h.findJob(c, ctx)
+	if err != nil {
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+
+	report, err := errorReport(job)
+	if err != nil {
+		errorResponse(ctx, nil, err)
+		return nil
+	}
+
+	ctx.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	ctx.Attachment("import-" + job.ID.String() + "-errors.csv")
+	return ctx.Status(http.StatusOK).Send(report)
+}
+
+// findJob memparsing id dari parameter URL lalu mencari job import melalui service.
+func (h *adapter) findJob(c context.Context, ctx *fiber.Ctx) (*product.ImportJob, error) {
+	id, err := product.ParseID(ctx.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	return h.storeService.FindImportJob(c, id)
+}
+
+// errorReport menulis baris yang ditolak sebagai CSV dengan kolom row, kolom asli file, dan error,
+// sehingga file dapat diperbaiki di spreadsheet lalu diunggah ulang. Baris diurutkan sesuai urutan pada file, karena
+// baris yang gagal disimpan dicatat setelah baris yang gagal validasi pada batch yang sama.
+func errorReport(job *product.ImportJob) ([]byte, error) {
+	rowErrs := append([]product.ImportRowError(nil), job.Errors...)
+	sort.SliceStable(rowErrs, func(i, j int) bool { return rowErrs[i].Row < rowErrs[j].Row })
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := append(append([]string{"row"}, job.Header...), "error")
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+	for _, rowErr := range rowErrs {
+		values := make([]string, len(job.Header))
+		copy(values, rowErr.Values)
+		if len(rowErr.Values) > len(values) {
+			values = rowErr.Values
+		}
+		record := append(append([]string{strconv.Itoa(rowErr.Row)}, values...), rowErr.Message)
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// readCSV membaca semua baris file CSV. Jumlah kolom setiap baris boleh berbeda.
+func readCSV(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// File CSV yang disimpan dari Excel diawali byte order mark UTF-8
+	if len(records) > 0 && len(records[0]) > 0 {
+		records[0][0] = strings.TrimPrefix(records[0][0], "\ufeff")
+	}
+	return records, nil
+}
+
+// readXLSX membaca semua baris pada sheet pertama file XLSX.
+func readXLSX(r io.Reader) ([][]string, error) {
+	workbook, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer workbook.Close()
+
+	sheets := workbook.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil
+	}
+	return workbook.GetRows(sheets[0])
+}
+
+/*
+Penjelasan Kode:
+
+Import Produk dari CSV dan XLSX:
+POST /product/import menerima form multipart dengan field file berisi file .csv atau .xlsx (sheet pertama). Baris pertama
+adalah header yang dipetakan ke field Product (code/sku, product_name/name, stock, description, latitude/lat,
+longitude/lng/lon; lihat product.ImportColumns), kolom lain diabaikan. Query ?mode=upsert memperbarui produk dengan kode
+yang sama, dan ?dry_run=true hanya memvalidasi baris dan menampilkan perubahan yang akan dilakukan tanpa menyimpannya.
+
+File dibaca seluruhnya di handler, lalu service menyimpan job dan memproses baris di latar belakang. Response berstatus
+202 Accepted berisi job dengan status pending dan header Location: /jobs/:id. Ekstensi file lain menghasilkan 415,
+request tanpa field file menghasilkan 400, sedangkan file yang rusak, kosong, tanpa kolom product_name, atau berisi lebih
+dari product.MaxImportRows baris menghasilkan 422. Ukuran file dibatasi oleh batas body Fiber (default 4 MB).
+
+GET /jobs/:id mengembalikan status job (pending, running, completed, failed), progres dalam persen, jumlah produk yang
+dibuat, diperbarui, dan ditolak, serta daftar perubahan pada dry-run. GET /jobs/:id/errors mengunduh laporan baris yang
+ditolak dalam format CSV: kolom row (nomor baris pada file, header adalah baris 1), kolom asli file, dan kolom error
+berisi alasan penolakan, diurutkan sesuai nomor baris. Laporan dapat diunduh selama job berjalan dan berisi baris yang sudah diproses.
+*/
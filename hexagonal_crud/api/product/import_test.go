@@ -0,0 +1,267 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// jobEnvelope adalah envelope JSON dengan satu job import pada data.
+type jobEnvelope struct {
+	Data *product.ImportJob `json:"data"`
+}
+
+// uploadImport mengirim file sebagai form multipart ke POST /product/import dengan query yang diberikan.
+func uploadImport(t *testing.T, app *fiber.App, query, filename string, content []byte) (*http.Response, []byte) {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile(importFileField, filename)
+	if err != nil {
+		t.Fatalf("creating form file: %v", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		t.Fatalf("writing form file: %v", err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatalf("closing form: %v", err)
+	}
+
+	req := httptest.NewRequest(fiber.MethodPost, "/product/import"+query, &body)
+	req.Header.Set(fiber.HeaderContentType, form.FormDataContentType())
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("POST /product/import returned error: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading POST /product/import body: %v", err)
+	}
+	return resp, data
+}
+
+// startImport mengunggah file dan mengembalikan job yang dibuat.
+func startImport(t *testing.T, app *fiber.App, query, filename string, content []byte) *product.ImportJob {
+	t.Helper()
+
+	resp, data := uploadImport(t, app, query, filename, content)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST /product/import%s returned %d, want 202: %s", query, resp.StatusCode, data)
+	}
+	var envelope jobEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Data == nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	if got, want := resp.Header.Get(fiber.HeaderLocation), "/jobs/"+envelope.Data.ID.String(); got != want {
+		t.Fatalf("got Location %q, want %q", got, want)
+	}
+	return envelope.Data
+}
+
+// waitJob memantau GET /jobs/:id sampai job selesai atau gagal, karena baris diproses di latar belakang.
+func waitJob(t *testing.T, app *fiber.App, id product.ID) *product.ImportJob {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, data := doRequest(t, app, fiber.MethodGet, "/jobs/"+id.String(), "")
+		var envelope jobEnvelope
+		if err := json.Unmarshal(data, &envelope); err != nil || resp.StatusCode != http.StatusOK || envelope.Data == nil {
+			t.Fatalf("GET /jobs/:id returned %d: %s", resp.StatusCode, data)
+		}
+		if job := envelope.Data; job.Status == product.JobCompleted || job.Status == product.JobFailed {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is still %s", id, envelope.Data.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	app := newTestApp(t, nil)
+	existing := createProduct(t, app, `{"code":"SKU-1","product_name":"Kopi","stock":5}`)
+
+	file := "sku,name,stock\nSKU-1,Kopi Susu,3\nSKU-2,Teh,1\nSKU-3,,2\nSKU-2,Teh Manis,4\n"
+	job := waitJob(t, app, startImport(t, app, "?mode=upsert&dry_run=true", "products.csv", []byte(file)).ID)
+
+	if job.Status != product.JobCompleted || !job.DryRun || job.Progress != 100 || job.TotalRows != 4 {
+		t.Fatalf("got job %+v, want a completed dry-run of 4 rows", job)
+	}
+	if job.Created != 1 || job.Updated != 2 || job.Rejected != 1 {
+		t.Fatalf("got created=%d updated=%d rejected=%d, want 1, 2, 1", job.Created, job.Updated, job.Rejected)
+	}
+	// Baris kedua dengan SKU-2 memperbarui produk yang dibuat oleh baris sebelumnya
+	want := []product.ImportChange{
+		{Row: 2, Action: product.BulkUpdated, ProductID: existing.ID, Code: "SKU-1", Name: "Kopi Susu"},
+		{Row: 3, Action: product.BulkCreated, Code: "SKU-2", Name: "Teh"},
+		{Row: 5, Action: product.BulkUpdated, Code: "SKU-2", Name: "Teh Manis"},
+	}
+	if len(job.Changes) != len(want) {
+		t.Fatalf("got changes %+v, want %+v", job.Changes, want)
+	}
+	for i := range want {
+		if job.Changes[i] != want[i] {
+			t.Errorf("change %d is %+v, want %+v", i, job.Changes[i], want[i])
+		}
+	}
+
+	// Dry-run tidak menyimpan perubahan apa pun
+	resp, data := doRequest(t, app, fiber.MethodGet, "/product/code/SKU-1", "")
+	if stored := decodeProduct(t, data); resp.StatusCode != http.StatusOK || stored.Name != "Kopi" || stored.Stock != 5 {
+		t.Fatalf("got stored %+v, want SKU-1 unchanged", stored)
+	}
+	if resp, data = doRequest(t, app, fiber.MethodGet, "/product/code/SKU-2", ""); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("GET /product/code/SKU-2 returned %d, want 404: %s", resp.StatusCode, data)
+	}
+}
+
+func TestImportUpsertCorrectsStock(t *testing.T) {
+	app := newTestApp(t, nil)
+	createProduct(t, app, `{"code":"SKU-1","product_name":"Kopi","stock":5}`)
+
+	// Stok pada baris yang memperbarui produk dicatat sebagai correction, stok 0 dianggap tidak dikirim
+	file := "code,product_name,stock\nSKU-1,Kopi Susu,12\nSKU-2,Teh,0\n"
+	job := waitJob(t, app, startImport(t, app, "?mode=upsert", "products.csv", []byte(file)).ID)
+	if job.Status != product.JobCompleted || job.Updated != 1 || job.Created != 1 || job.Rejected != 0 {
+		t.Fatalf("got job %+v, want one updated and one created row", job)
+	}
+	resp, data := doRequest(t, app, fiber.MethodGet, "/product/code/SKU-1", "")
+	if stored := decodeProduct(t, data); resp.StatusCode != http.StatusOK || stored.Name != "Kopi Susu" || stored.Stock != 12 {
+		t.Fatalf("got stored %+v, want the new name and stock 12", stored)
+	}
+}
+
+func TestImportErrorReport(t *testing.T) {
+	app := newTestApp(t, nil)
+	createProduct(t, app, `{"code":"SKU-1","product_name":"Kopi"}`)
+
+	// File CSV dari Excel diawali byte order mark dan dapat berisi baris kosong. Kolom yang tidak dikenal diabaikan
+	// tetapi tetap ada pada laporan
+	file := "\ufeffcode,product_name,stock,warna\nSKU-2,Teh,4,hijau\nSKU-1,Kopi Susu,1,coklat\n,Gula,banyak,putih\n,,,\n,Garam,-1\n"
+	job := waitJob(t, app, startImport(t, app, "", "products.csv", []byte(file)).ID)
+
+	if job.Status != product.JobCompleted || job.DryRun || job.TotalRows != 4 || job.Changes != nil {
+		t.Fatalf("got job %+v, want a completed import of 4 rows", job)
+	}
+	if job.Created != 1 || job.Updated != 0 || job.Rejected != 3 {
+		t.Fatalf("got created=%d updated=%d rejected=%d, want 1, 0, 3", job.Created, job.Updated, job.Rejected)
+	}
+	resp, data := doRequest(t, app, fiber.MethodGet, "/product/code/SKU-2", "")
+	if resp.StatusCode != http.StatusOK || decodeProduct(t, data).Stock != 4 {
+		t.Fatalf("GET /product/code/SKU-2 returned %d: %s", resp.StatusCode, data)
+	}
+
+	resp, data = doRequest(t, app, fiber.MethodGet, "/jobs/"+job.ID.String()+"/errors", "")
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), "text/csv") {
+		t.Fatalf("GET /jobs/:id/errors returned %d %s: %s", resp.StatusCode, resp.Header.Get(fiber.HeaderContentType), data)
+	}
+	if got, want := resp.Header.Get(fiber.HeaderContentDisposition), `import-`+job.ID.String()+`-errors.csv`; !strings.Contains(got, want) {
+		t.Errorf("got Content-Disposition %q, want an attachment named %s", got, want)
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	want := [][]string{
+		{"row", "code", "product_name", "stock", "warna", "error"},
+		{"3", "SKU-1", "Kopi Susu", "1", "coklat", product.ErrCodeConflict.Error()},
+		{"4", "", "Gula", "banyak", "putih", "stock must be an integer"},
+		{"6", "", "Garam", "-1", "", "stock must be greater than or equal to 0"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d: %s", len(records), len(want), data)
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("record %d is %q, want %q", i, records[i], want[i])
+		}
+	}
+}
+
+func TestImportXLSX(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	workbook := excelize.NewFile()
+	defer workbook.Close()
+	sheet := workbook.GetSheetName(0)
+	for i, row := range [][]interface{}{{"SKU", "Name", "Lat", "Lng"}, {"SKU-1", "Kopi", -6.2, 106.8}} {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := workbook.SetSheetRow(sheet, cell, &row); err != nil {
+			t.Fatalf("writing row %d: %v", i+1, err)
+		}
+	}
+	var content bytes.Buffer
+	if err := workbook.Write(&content); err != nil {
+		t.Fatalf("writing workbook: %v", err)
+	}
+
+	job := waitJob(t, app, startImport(t, app, "", "products.xlsx", content.Bytes()).ID)
+	if job.Status != product.JobCompleted || job.Created != 1 || job.Rejected != 0 {
+		t.Fatalf("got job %+v, want one created product", job)
+	}
+	resp, data := doRequest(t, app, fiber.MethodGet, "/product/code/SKU-1", "")
+	stored := decodeProduct(t, data)
+	if resp.StatusCode != http.StatusOK || stored.Name != "Kopi" || stored.Geo == nil {
+		t.Fatalf("got stored %+v, want Kopi with a location", stored)
+	}
+}
+
+func TestImportRejectsInvalidFiles(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	tests := []struct {
+		name     string
+		query    string
+		filename string
+		content  string
+		status   int
+		message  string
+	}{
+		{"unsupported format", "", "products.txt", "product_name\nKopi\n", http.StatusUnsupportedMediaType,
+			errUnsupportedImportFormat.Error()},
+		{"empty file", "", "products.csv", "", http.StatusUnprocessableEntity, product.ErrInvalidImport.Error() + ": file is empty"},
+		{"missing column", "", "products.csv", "code\nSKU-1\n", http.StatusUnprocessableEntity,
+			product.ErrInvalidImport.Error() + ": missing product_name column"},
+		{"duplicate column", "", "products.csv", "sku,code,name\nA,B,Kopi\n", http.StatusUnprocessableEntity,
+			product.ErrInvalidImport.Error() + ": column code appears more than once"},
+		{"no data rows", "", "products.csv", "product_name\n,\n", http.StatusUnprocessableEntity,
+			product.ErrInvalidImport.Error() + ": file has no data rows"},
+		{"unknown mode", "?mode=replace", "products.csv", "product_name\nKopi\n", http.StatusUnprocessableEntity,
+			product.ErrInvalidImport.Error() + `: unknown mode "replace"`},
+		{"corrupt workbook", "", "products.xlsx", "bukan xlsx", http.StatusUnprocessableEntity, product.ErrInvalidImport.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, data := uploadImport(t, app, tt.query, tt.filename, []byte(tt.content))
+			var envelope productEnvelope
+			if err := json.Unmarshal(data, &envelope); err != nil || resp.StatusCode != tt.status {
+				t.Fatalf("got status %d, want %d: %s", resp.StatusCode, tt.status, data)
+			}
+			if !strings.HasPrefix(envelope.Message, tt.message) {
+				t.Fatalf("got message %q, want %q", envelope.Message, tt.message)
+			}
+		})
+	}
+
+	// Job yang tidak ada menghasilkan 404, baik untuk status maupun laporan error
+	for _, target := range []string{"/jobs/00000000-0000-4000-8000-000000000000", "/jobs/00000000-0000-4000-8000-000000000000/errors"} {
+		if resp, data := doRequest(t, app, fiber.MethodGet, target, ""); resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("GET %s returned %d, want 404: %s", target, resp.StatusCode, data)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package product
+
+import (
+	"CRUD_Hexagonal/utils"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Fungsi Negotiate adalah middleware untuk request yang mengubah data (POST, PUT, PATCH, DELETE)
+// Request dengan header Accept yang tidak dapat dipenuhi ditolak dengan 406 sebelum handler mengubah data
+func (h *adapter) Negotiate(ctx *fiber.Ctx) error {
+	if isSafeMethod(ctx.Method()) {
+		return ctx.Next()
+	}
+	if err := utils.CheckAcceptable(ctx); err != nil {
+		statusResponse(ctx, http.StatusNotAcceptable, nil, err)
+		return nil
+	}
+	return ctx.Next()
+}
+
+/*
+Penjelasan Kode:
+
+Content Negotiation:
+Response product ditulis dengan utils.Respond, yang memilih format envelope dari header Accept (mengikuti nilai q dan
+wildcard): application/json (default, juga jika header Accept tidak ada), application/msgpack (atau application/x-msgpack
+dan application/vnd.msgpack) untuk pemindai genggam yang membutuhkan format biner yang ringkas, application/xml atau
+text/xml, dan text/csv khusus untuk endpoint daftar seperti GET /product dan GET /product/search. Semua format memakai
+nama field dan urutan yang sama dengan JSON. Pada CSV setiap item data menjadi satu baris (objek bertingkat seperti geo
+menjadi kolom geo.type dan geo.coordinates), sedangkan pagination dikirim pada header X-Total-Count, X-Current-Page,
+X-Last-Page, dan X-Next-Cursor. Header Vary: Accept dikirim pada setiap response.
+
+Response yang berhasil dengan header Accept yang tidak dapat dipenuhi (misalnya text/csv untuk GET /product/:id) menjadi
+406 Not Acceptable dengan daftar format yang didukung, sedangkan response error tetap dikirim sebagai JSON agar pesan
+errornya tidak hilang. Middleware Negotiate memeriksa header Accept pada request yang mengubah data sebelum handler
+dijalankan, sehingga product tidak disimpan atau diubah jika response-nya tidak dapat dikirim. Middleware ini dipasang
+sebelum Idempotency sehingga response 406 tidak disimpan. GET /product/export dan GET /jobs/:id/errors menentukan
+format file sendiri dan tidak terpengaruh.
+*/
@@ -0,0 +1,62 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"CRUD_Hexagonal/utils"
+	"regexp"
+	"strings"
+)
+
+// skuPattern adalah format kode produk: huruf, angka, titik, garis bawah, atau tanda hubung, diawali huruf atau angka.
+var skuPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// init mendaftarkan aturan validasi khusus yang digunakan oleh tag validate pada product.Product.
+// Pendaftaran dilakukan sebelum handler menerima request karena validator bersama tidak aman diubah secara bersamaan.
+func init() {
+	rules := []struct {
+		tag     string
+		message string
+		valid   func(value interface{}) bool
+	}{
+		{tag: "sku", message: "must contain only letters, digits, '.', '_' or '-'", valid: validSKU},
+		{tag: "geopoint", message: "must be a GeoJSON Point with valid coordinates", valid: validGeoPoint},
+	}
+	for _, rule := range rules {
+		if err := utils.RegisterRule(rule.tag, rule.message, rule.valid); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// validSKU memeriksa kode product. Spasi di awal dan akhir diabaikan karena service merapikan kode sebelum disimpan.
+func validSKU(value interface{}) bool {
+	code, ok := value.(string)
+	return ok && skuPattern.MatchString(strings.TrimSpace(code))
+}
+
+// validGeoPoint memeriksa koordinat product dengan aturan yang sama seperti GeoPoint.Validate pada domain.
+func validGeoPoint(value interface{}) bool {
+	switch point := value.(type) {
+	case product.GeoPoint:
+		return point.Validate() == nil
+	case *product.GeoPoint:
+		return point != nil && point.Validate() == nil
+	}
+	return false
+}
+
+/*
+Penjelasan Kode:
+
+Aturan Validasi Product:
+Aturan validasi product ditulis sebagai tag validate pada product.Product (domain/product/product.go), misalnya
+product_name wajib diisi dan maksimal 255 karakter, stock tidak boleh negatif, dan description maksimal 2000 karakter.
+Handler Create memvalidasi seluruh field dengan utils.Validate, sedangkan Update menggunakan utils.ValidatePartial
+karena field yang kosong pada Update berarti tidak diubah, sehingga aturan seperti required tidak berlaku.
+
+Aturan Khusus:
+Tag sku dan geopoint bukan aturan bawaan validator, sehingga didaftarkan dengan utils.RegisterRule di fungsi init.
+sku membatasi karakter kode product, dan geopoint menggunakan GeoPoint.Validate agar aturan koordinat tetap berada di domain.
+Kegagalan validasi dikembalikan sebagai utils.ValidationErrors yang berisi field, rule, param, dan message untuk setiap
+aturan yang gagal, lalu diteruskan apa adanya ke validationResponse (errors.go).
+*/
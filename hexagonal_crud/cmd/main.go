@@ -3,11 +3,13 @@ package main
 import (
 	"CRUD_Hexagonal/api/product"                  // Mengimpor handler untuk produk
 	_ "CRUD_Hexagonal/api/product"                // Mengimpor handler (dengan underscore untuk side effect)
+	domain "CRUD_Hexagonal/domain/product"        // Mengimpor domain produk untuk interface repository
 	"CRUD_Hexagonal/infrastructure"               // Mengimpor setup infrastruktur
 	storeRepo "CRUD_Hexagonal/repository/product" // Mengimpor repository produk
 	storeServ "CRUD_Hexagonal/service/product"    // Mengimpor service produk
 	"context"                                     // Mengimpor context untuk manajemen lifecycle aplikasi
 	"errors"                                      // Mengimpor errors untuk manajemen error
+	"net/url"                                     // Mengimpor net/url untuk membaca skema DSN
 
 	otelfiber "github.com/gofiber/contrib/otelfiber/v2"              // Mengimpor middleware OpenTelemetry untuk Fiber
 	"github.com/gofiber/fiber/v2"                                    // Mengimpor framework Fiber
@@ -26,6 +28,12 @@ func main() {
 	viper.AutomaticEnv()
 	ctx := context.Background() // Membuat context dasar untuk aplikasi
 
+	// Nilai default konfigurasi jika variabel lingkungan tidak diatur
+	viper.SetDefault("PRODUCT_PURGE_RETENTION", "720h")   // Masa retensi produk yang sudah di-soft delete (30 hari)
+	viper.SetDefault("RESERVATION_DEFAULT_TTL", "15m")    // Lama default reservasi stok
+	viper.SetDefault("RESERVATION_SWEEP_INTERVAL", "30s") // Jeda antar pemeriksaan reservasi yang kedaluwarsa
+	viper.SetDefault("IDEMPOTENCY_KEY_TTL", "24h")        // Lama response request dengan Idempotency-Key disimpan
+
 	// Konfigurasi logger untuk aplikasi dengan JSON output
 	logHandler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		AddSource: true,
@@ -52,14 +60,16 @@ func main() {
 		err = errors.Join(err, otelShutdown(context.Background())) // Menutup OTel dengan benar
 	}()
 
-	// Inisialisasi MongoDB
-	mongo := infrastructure.NewMongo(ctx, os.Getenv("MONGO_DSN"), os.Getenv("MONGO_DB_NAME"))
-	mongo = mongo.Connect() // Menghubungkan ke MongoDB
-
 	// Inisialisasi repository dan service untuk produk
-	storeRepository := storeRepo.NewstoreRepository(mongo.Client, mongo.DB, "products") // Membuat repository untuk produk
-	storeService := storeServ.NewStoreService(storeRepository)                          // Membuat service produk dengan menggunakan repository
-	handler := product.NewStoreHandler(storeService)                                    // Membuat handler untuk produk dengan menggunakan service
+	purgeRetention := viper.GetDuration("PRODUCT_PURGE_RETENTION")                                             // Masa retensi sebelum produk yang dihapus dapat di-purge
+	storeRepository := newRepository(ctx, viper.GetString("DATABASE_DSN"))                                     // Membuat repository untuk produk sesuai skema DSN
+	reservationTTL := viper.GetDuration("RESERVATION_DEFAULT_TTL")                                             // Lama default reservasi stok
+	idempotencyTTL := viper.GetDuration("IDEMPOTENCY_KEY_TTL")                                                 // Lama response request dengan Idempotency-Key disimpan
+	storeService := storeServ.NewStoreService(storeRepository, purgeRetention, reservationTTL, idempotencyTTL) // Membuat service produk dengan menggunakan repository
+	handler := product.NewStoreHandler(storeService)                                                           // Membuat handler untuk produk dengan menggunakan service
+
+	// Menjalankan sweeper yang melepas reservasi stok yang sudah kedaluwarsa di latar belakang
+	go storeServ.RunReservationSweeper(ctx, storeService, viper.GetDuration("RESERVATION_SWEEP_INTERVAL"))
 
 	// Inisialisasi aplikasi Fiber
 	app := fiber.New()
@@ -69,8 +79,17 @@ func main() {
 		Format: "[${time}] ${ip}  ${status} - ${latency} ${method} ${path}\n", // Format log request
 	}))
 
-	// Middleware untuk integrasi OpenTelemetry dengan Fiber
-	app.Use(otelfiber.Middleware())
+	// Middleware untuk integrasi OpenTelemetry dengan Fiber. Export dilewati karena middleware membaca seluruh
+	// body response untuk metrik ukuran response, sehingga body streaming akan ditampung di memori
+	app.Use(otelfiber.Middleware(otelfiber.WithNext(func(c *fiber.Ctx) bool {
+		return c.Path() == "/product/export"
+	})))
+
+	// Middleware content negotiation, request yang mengubah data dengan header Accept yang tidak didukung ditolak dengan 406
+	app.Use(handler.Negotiate)
+
+	// Middleware Idempotency-Key untuk semua request yang mengubah data
+	app.Use(handler.Idempotency)
 
 	// Route untuk homepage
 	app.Get("/", func(c *fiber.Ctx) error {
@@ -78,16 +97,89 @@ func main() {
 	})
 
 	// Route untuk CRUD API produk
-	app.Get("/product/:id", handler.Get)       // Mendapatkan produk berdasarkan ID
-	app.Get("/product", handler.GetAll)        // Mendapatkan semua produk
-	app.Post("/product", handler.Create)       // Membuat produk baru
-	app.Put("/product/:id", handler.Update)    // Memperbarui produk berdasarkan ID
-	app.Delete("/product/:id", handler.Delete) // Menghapus produk berdasarkan ID
+	app.Get("/product/search", handler.Search)          // Mencari produk berdasarkan kata, didaftarkan sebelum /product/:id
+	app.Post("/product/bulk", handler.BulkCreate)       // Membuat atau memperbarui (upsert) banyak produk sekaligus
+	app.Delete("/product/bulk", handler.BulkDelete)     // Menandai banyak produk sebagai dihapus, didaftarkan sebelum /product/:id
+	app.Post("/product/import", handler.ImportProducts) // Memulai job import produk dari file CSV atau XLSX
+	app.Get("/product/export", handler.Export)          // Mengunduh produk sebagai CSV, NDJSON, atau XLSX, didaftarkan sebelum /product/:id
+	app.Get("/product/:id", handler.Get)                // Mendapatkan produk berdasarkan ID
+	app.Get("/product", handler.GetAll)                 // Mendapatkan semua produk
+	app.Post("/product", handler.Create)                // Membuat produk baru
+	app.Put("/product/:id", handler.Update)             // Memperbarui produk berdasarkan ID
+	app.Patch("/product/:id", handler.Patch)            // Memperbarui sebagian field produk dengan JSON Merge Patch atau JSON Patch
+	app.Delete("/product/:id", handler.Delete)          // Menandai produk sebagai dihapus (soft delete) berdasarkan ID
+	app.Post("/product/:id/restore", handler.Restore)   // Memulihkan produk yang sudah di-soft delete
+
+	// Route untuk produk berdasarkan kode (SKU)
+	app.Get("/product/code/:code", handler.GetByCode)       // Mendapatkan produk berdasarkan kode
+	app.Delete("/product/code/:code", handler.DeleteByCode) // Menandai produk sebagai dihapus berdasarkan kode
+
+	// Route untuk stok produk
+	app.Post("/product/:id/stock/adjust", handler.AdjustStock)     // Mengubah stok produk dengan jumlah bertanda dan kode alasan
+	app.Get("/product/:id/stock/movements", handler.GetMovements)  // Mendapatkan riwayat pergerakan stok produk
+	app.Get("/product/:id/stock", handler.GetProductStock)         // Mendapatkan stok produk per lokasi
+	app.Post("/product/:id/stock/transfer", handler.TransferStock) // Memindahkan stok produk antar lokasi
+
+	// Route untuk lokasi (gudang atau toko)
+	app.Post("/locations", handler.CreateLocation)            // Membuat lokasi baru
+	app.Get("/locations", handler.GetLocations)               // Mendapatkan semua lokasi
+	app.Get("/locations/:id", handler.GetLocation)            // Mendapatkan lokasi berdasarkan ID
+	app.Get("/locations/:id/stock", handler.GetLocationStock) // Mendapatkan stok semua produk pada lokasi
+
+	// Route untuk reservasi stok
+	app.Post("/product/:id/reservations", handler.Reserve)            // Menahan stok produk selama TTL tertentu
+	app.Get("/reservations/:id", handler.GetReservation)              // Mendapatkan reservasi berdasarkan ID
+	app.Post("/reservations/:id/confirm", handler.ConfirmReservation) // Mengonfirmasi reservasi dan mengurangi stok
+	app.Post("/reservations/:id/release", handler.ReleaseReservation) // Membatalkan reservasi dan melepas stok yang ditahan
+
+	// Route untuk job import
+	app.Get("/jobs/:id", handler.GetJob)              // Mendapatkan status dan progres job import
+	app.Get("/jobs/:id/errors", handler.GetJobErrors) // Mengunduh laporan baris yang ditolak dalam format CSV
+
+	// Route admin
+	app.Post("/admin/product/purge", handler.Purge) // Menghapus permanen produk yang sudah melewati masa retensi
 
 	// Menjalankan server pada port 3000
 	app.Listen(":3000")
 }
 
+// newRepository memilih implementasi repository produk berdasarkan skema DSN.
+// memory:// menggunakan repository in-memory, postgres:// atau postgresql:// menggunakan PostgreSQL,
+// selain itu MongoDB digunakan.
+// Jika DSN kosong, MONGO_DSN digunakan agar konfigurasi lama tetap berjalan.
+func newRepository(ctx context.Context, dsn string) domain.Repository {
+	if dsn == "" {
+		dsn = os.Getenv("MONGO_DSN")
+	}
+
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		log.Fatalf("invalid DATABASE_DSN: %v", err)
+	}
+
+	switch parsed.Scheme {
+	case "memory":
+		slog.InfoContext(ctx, "Using in-memory product repository.")
+		return storeRepo.NewMemoryRepository()
+	case "postgres", "postgresql":
+		// Inisialisasi PostgreSQL dan menjalankan migrasi skema
+		postgres := infrastructure.NewPostgres(ctx, dsn)
+		postgres = postgres.Connect() // Menghubungkan ke PostgreSQL
+		if err := storeRepo.MigratePostgres(ctx, postgres.DB); err != nil {
+			log.Fatalf("failed to migrate postgres: %v", err)
+		}
+		return storeRepo.NewPostgresRepository(postgres.DB)
+	default:
+		// Inisialisasi MongoDB
+		mongo := infrastructure.NewMongo(ctx, dsn, os.Getenv("MONGO_DB_NAME"))
+		mongo = mongo.Connect() // Menghubungkan ke MongoDB
+		if err := storeRepo.EnsureIndexes(ctx, mongo.Client, mongo.DB, "products"); err != nil {
+			log.Fatalf("failed to create mongo indexes: %v", err)
+		}
+		return storeRepo.NewstoreRepository(mongo.Client, mongo.DB, "products")
+	}
+}
+
 /*
 Fungsi dan Kode yang Dijelaskan:
 Viper Initialization (viper.AutomaticEnv):
@@ -99,23 +191,45 @@ Bagian ini mengatur logger menggunakan slog, dengan output JSON. Ini berguna unt
 OpenTelemetry Setup:
 
 OpenTelemetry diatur untuk membantu dalam observabilitas aplikasi (melacak performa, logging, tracing). Jika ada kesalahan dalam pengaturan, aplikasi akan berhenti.
-MongoDB Initialization:
+Repository Selection (newRepository):
 
-Bagian ini menginisialisasi koneksi ke MongoDB menggunakan detail yang diberikan melalui variabel lingkungan. Koneksi ini kemudian digunakan untuk mengakses database.
+Repository produk dipilih berdasarkan skema DATABASE_DSN. Skema memory:// menjalankan service dengan repository in-memory tanpa database (cocok untuk laptop dan CI), postgres:// atau postgresql:// menggunakan PostgreSQL dan menjalankan migrasi skema saat aplikasi dimulai, sedangkan mongodb:// menginisialisasi koneksi ke MongoDB menggunakan detail yang diberikan melalui variabel lingkungan dan memastikan index unik kode produk sudah dibuat. Jika DATABASE_DSN kosong, MONGO_DSN digunakan.
 Repository and Service Initialization:
 
 Membuat storeRepository untuk menangani akses data produk dan storeService untuk mengelola logika bisnis terkait produk. handler kemudian digunakan untuk menghubungkan service dengan API.
 Fiber Web Framework Setup:
 
-Fiber adalah framework web yang digunakan untuk membangun API ini. Middleware seperti logger dan otelfiber diintegrasikan untuk logging dan tracing.
+Fiber adalah framework web yang digunakan untuk membangun API ini. Middleware seperti logger dan otelfiber diintegrasikan untuk logging dan tracing; otelfiber tidak dijalankan untuk GET /product/export agar body streaming tidak dibaca seluruhnya ke memori. Response ditulis dalam format JSON, MessagePack, XML, atau CSV (khusus endpoint daftar) sesuai header Accept, dan middleware handler.Negotiate menolak request yang mengubah data dengan 406 jika header Accept tidak dapat dipenuhi. Middleware handler.Idempotency memproses request POST, PUT, PATCH, dan DELETE yang membawa header Idempotency-Key hanya sekali dan memutar ulang response yang tersimpan untuk pengulangannya selama IDEMPOTENCY_KEY_TTL (default 24 jam).
 Route Definitions:
 
 Mendefinisikan berbagai endpoint untuk API CRUD produk, seperti:
-GET /product/:id: Mengambil data produk berdasarkan ID.
-GET /product: Mengambil semua data produk.
+GET /product/:id: Mengambil data produk berdasarkan ID. Versi produk dikirim sebagai header ETag.
+GET /product/search?q=: Mencari produk berdasarkan kata pada nama dan deskripsi, diurutkan dari yang paling relevan, beserta skor dan highlight.
+GET /product: Mengambil semua data produk. Dengan ?latitude=..&longitude=..&radius_km=.. hasil diurutkan berdasarkan jarak dan setiap produk berisi distance_km. Dengan ?cursor=&limit=.. hasil dibaca dengan cursor pagination dan response berisi next_cursor. Dengan ?sort=-stock,product_name hasil diurutkan sesuai field yang diminta, dan dengan ?fields=product_id,product_name,stock setiap produk hanya berisi field yang dipilih. Ekspresi filter seperti ?stock[lte]=5&created_at[gte]=2024-01-01&product_id[in]=a,b,c membatasi hasil dengan field dan operator yang didukung.
 POST /product: Menambahkan produk baru.
-PUT /product/:id: Memperbarui data produk berdasarkan ID.
-DELETE /product/:id: Menghapus produk berdasarkan ID.
+GET /product/export?format=csv|ndjson|xlsx: Mengunduh semua produk yang sesuai filter GET /product (tanpa pagination) sebagai file secara streaming. Parameter fields memilih kolom.
+POST /product/bulk: Menambahkan banyak produk sekaligus dari array, atau memperbaruinya berdasarkan kode dengan ?mode=upsert. Response berisi hasil setiap item dengan status 200 jika semua berhasil atau 207 Multi-Status jika ada item yang gagal.
+POST /product/import: Mengunggah file CSV atau XLSX (field multipart file) untuk diimpor sebagai job asinkron. ?mode=upsert memperbarui produk dengan kode yang sama dan ?dry_run=true hanya menampilkan perubahan tanpa menyimpannya. Response 202 berisi job dan header Location.
+GET /jobs/:id: Mengambil status, progres, dan ringkasan hasil job import.
+GET /jobs/:id/errors: Mengunduh laporan baris yang ditolak oleh job import dalam format CSV.
+DELETE /product/bulk: Menandai banyak produk sebagai dihapus berdasarkan array {product_id} atau {code}, dengan laporan hasil setiap item seperti POST /product/bulk.
+PUT /product/:id: Memperbarui data produk berdasarkan ID. Wajib menyertakan header If-Match berisi ETag terakhir; tanpa header menghasilkan 428 dan versi yang tidak cocok menghasilkan 412.
+PATCH /product/:id: Memperbarui sebagian field produk dengan JSON Merge Patch (application/merge-patch+json) atau JSON Patch (application/json-patch+json). Nilai kosong dan null ikut diterapkan. Wajib menyertakan header If-Match seperti PUT.
+DELETE /product/:id: Menandai produk sebagai dihapus (soft delete) berdasarkan ID. Wajib menyertakan header If-Match seperti PUT.
+POST /product/:id/restore: Memulihkan produk yang sudah di-soft delete.
+GET /product/code/:code: Mengambil data produk berdasarkan kode produk (SKU) beserta header ETag.
+DELETE /product/code/:code: Menandai produk sebagai dihapus berdasarkan kode produk (SKU). Wajib menyertakan header If-Match seperti PUT.
+POST /product/:id/stock/adjust: Mengubah stok produk dan mencatat pergerakannya ke buku besar stok.
+GET /product/:id/stock/movements: Mengambil riwayat pergerakan stok produk dengan pagination.
+POST /product/:id/reservations: Menahan stok produk untuk proses checkout selama ttl_seconds (default RESERVATION_DEFAULT_TTL).
+GET /reservations/:id: Mengambil data reservasi stok.
+POST /reservations/:id/confirm: Mengonfirmasi reservasi sehingga stok produk berkurang.
+POST /reservations/:id/release: Membatalkan reservasi dan melepas stok yang ditahan. Reservasi yang kedaluwarsa dilepas otomatis oleh sweeper setiap RESERVATION_SWEEP_INTERVAL.
+GET /product/:id/stock: Mengambil stok produk pada setiap lokasi.
+POST /product/:id/stock/transfer: Memindahkan stok produk antar lokasi. from_location_id atau to_location_id yang kosong berarti stok yang belum dialokasikan.
+POST /locations, GET /locations, GET /locations/:id: Mengelola lokasi penyimpanan stok (warehouse atau store).
+GET /locations/:id/stock: Mengambil stok semua produk pada sebuah lokasi.
+POST /admin/product/purge: Menghapus permanen produk yang sudah di-soft delete lebih lama dari PRODUCT_PURGE_RETENTION.
 Server Listening:
 
 Aplikasi mendengarkan pada port 3000 untuk menerima request.
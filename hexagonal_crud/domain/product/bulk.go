@@ -0,0 +1,57 @@
+package product
+
+// MaxBulkOperations adalah jumlah maksimum item pada satu request bulk.
+const MaxBulkOperations = 1000
+
+// ErrInvalidBulk dikembalikan ketika request bulk kosong, melebihi MaxBulkOperations, atau mode-nya tidak dikenal.
+var ErrInvalidBulk = newError(ErrValidation, "invalid bulk request")
+
+// Jenis operasi bulk.
+const (
+	BulkCreate = "create" // Menyimpan produk baru, ErrCodeConflict jika kode sudah digunakan
+	BulkUpsert = "upsert" // Memperbarui produk dengan kode yang sama jika ada, selain itu menyimpan produk baru
+	BulkDelete = "delete" // Menandai produk sebagai dihapus (soft delete) berdasarkan ID atau kode
+)
+
+// Hasil setiap operasi bulk.
+const (
+	BulkCreated = "created"
+	BulkUpdated = "updated"
+	BulkDeleted = "deleted"
+	BulkFailed  = "failed"
+)
+
+// BulkOperation adalah satu operasi pada BulkWrite.
+type BulkOperation struct {
+	Action  string   // BulkCreate, BulkUpsert, atau BulkDelete
+	Product *Product // Data produk untuk create dan upsert (upsert wajib memiliki Code); untuk delete cukup ID atau Code
+}
+
+// BulkResult adalah hasil satu operasi bulk, urutannya sama dengan operasi yang dikirim.
+type BulkResult struct {
+	Index  int    `json:"index"`                // Posisi operasi pada request
+	Status string `json:"status"`               // BulkCreated, BulkUpdated, BulkDeleted, atau BulkFailed
+	ID     ID     `json:"product_id,omitempty"` // ID produk yang dibuat, diperbarui, atau dihapus
+	Code   string `json:"code,omitempty"`       // Kode produk (SKU) jika ada
+	Err    error  `json:"-"`                    // Penyebab kegagalan jika Status adalah BulkFailed
+}
+
+// Fail menandai hasil sebagai gagal dengan err.
+func (r *BulkResult) Fail(err error) {
+	r.Status = BulkFailed
+	r.Err = err
+}
+
+/*
+Penjelasan Fungsi Kode:
+Struct BulkOperation dan BulkResult:
+
+Sinkronisasi data dari ERP mengirim ribuan produk sekaligus. Alih-alih memanggil Store satu per satu, service mengirim seluruh
+operasi ke Repository.BulkWrite dalam satu panggilan. Operasi dijalankan tanpa urutan (unordered) dengan semantik partial
+failure: kegagalan satu operasi (misalnya ErrCodeConflict atau ErrProductNotFound) hanya dicatat pada BulkResult milik
+operasi tersebut, sedangkan operasi lain tetap dijalankan. Error yang dikembalikan langsung oleh BulkWrite berarti seluruh
+batch gagal, misalnya karena database tidak dapat dihubungi.
+Upsert mencocokkan produk berdasarkan Code. Produk yang ditemukan diperbarui seperti Update (hanya field yang tidak kosong,
+stok tidak ditulis oleh BulkWrite), sedangkan kode yang belum digunakan membuat produk baru. Service mencatat stok yang diminta
+setelah BulkWrite sebagai pergerakan stok, initial untuk produk baru dan correction untuk produk yang diperbarui.
+*/
@@ -0,0 +1,131 @@
+package product
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidFilter dikembalikan ketika ekspresi filter berisi field, operator, atau nilai yang tidak valid.
+var ErrInvalidFilter = newError(ErrValidation, "invalid filter")
+
+// Operator adalah operator perbandingan pada ekspresi filter, misalnya "lte" pada stock[lte]=5.
+type Operator string
+
+const (
+	OpEq  Operator = "eq"  // Sama dengan
+	OpNe  Operator = "ne"  // Tidak sama dengan
+	OpGt  Operator = "gt"  // Lebih besar dari
+	OpGte Operator = "gte" // Lebih besar dari atau sama dengan
+	OpLt  Operator = "lt"  // Lebih kecil dari
+	OpLte Operator = "lte" // Lebih kecil dari atau sama dengan
+	OpIn  Operator = "in"  // Salah satu dari daftar nilai yang dipisahkan koma
+)
+
+// FieldKind menentukan jenis nilai sebuah field yang dapat difilter.
+type FieldKind int
+
+const (
+	KindText   FieldKind = iota // Nilai teks, hanya mendukung eq, ne, dan in
+	KindNumber                  // Nilai bilangan bulat, mendukung seluruh operator
+	KindTime                    // UNIX timestamp, dapat ditulis sebagai angka atau tanggal RFC 3339
+)
+
+// FilterableFields adalah whitelist field (nama JSON) yang dapat digunakan pada ekspresi filter beserta jenis nilainya.
+var FilterableFields = map[string]FieldKind{
+	"product_id":   KindText,
+	"code":         KindText,
+	"product_name": KindText,
+	"stock":        KindNumber,
+	"reserved":     KindNumber,
+	"allocated":    KindNumber,
+	"created_at":   KindTime,
+	"updated_at":   KindTime,
+	"deleted_at":   KindTime,
+}
+
+// Condition adalah satu node pada AST filter: perbandingan sebuah field dengan satu nilai atau,
+// untuk operator in, beberapa nilai. Nilai bertipe string untuk KindText dan int64 untuk KindNumber/KindTime.
+// Seluruh Condition pada Filter.Conditions digabungkan dengan AND.
+type Condition struct {
+	Field    string   `json:"field"`
+	Operator Operator `json:"operator"`
+	Values   []any    `json:"values"`
+}
+
+// ParseCondition membaca satu parameter filter berbentuk field[operator]=nilai, misalnya stock[lte]=5.
+// Parameter tanpa tanda kurung bukan ekspresi filter sehingga menghasilkan nil tanpa error.
+func ParseCondition(key, raw string) (*Condition, error) {
+	open := strings.IndexByte(key, '[')
+	if open < 0 {
+		return nil, nil
+	}
+	if !strings.HasSuffix(key, "]") {
+		return nil, fmt.Errorf("%w: malformed parameter %q", ErrInvalidFilter, key)
+	}
+	field, operator := key[:open], Operator(key[open+1:len(key)-1])
+
+	kind, ok := FilterableFields[field]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidFilter, field)
+	}
+	switch operator {
+	case OpEq, OpNe, OpIn:
+	case OpGt, OpGte, OpLt, OpLte:
+		if kind == KindText {
+			return nil, fmt.Errorf("%w: operator %q is not supported for field %q", ErrInvalidFilter, operator, field)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, operator)
+	}
+
+	parts := []string{raw}
+	if operator == OpIn {
+		parts = strings.Split(raw, ",")
+	}
+	condition := &Condition{Field: field, Operator: operator}
+	for _, part := range parts {
+		value, err := parseConditionValue(kind, strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid value %q for field %q", ErrInvalidFilter, part, field)
+		}
+		condition.Values = append(condition.Values, value)
+	}
+	return condition, nil
+}
+
+// parseConditionValue mengubah teks nilai filter menjadi string atau int64 sesuai jenis field.
+func parseConditionValue(kind FieldKind, raw string) (any, error) {
+	if raw == "" {
+		return nil, ErrInvalidFilter
+	}
+	switch kind {
+	case KindNumber:
+		return strconv.ParseInt(raw, 10, 64)
+	case KindTime:
+		if unix, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return unix, nil
+		}
+		for _, layout := range []string{time.RFC3339, time.DateOnly} {
+			if t, err := time.Parse(layout, raw); err == nil {
+				return t.Unix(), nil
+			}
+		}
+		return nil, ErrInvalidFilter
+	}
+	return raw, nil
+}
+
+/*
+Penjelasan Fungsi Kode:
+Ekspresi Filter:
+
+Selain Keyword, GET /product menerima ekspresi filter berbentuk field[operator]=nilai, misalnya stock[lte]=5, created_at[gte]=2024-01-01, atau product_id[in]=a,b,c. Beberapa ekspresi dapat digabungkan dan seluruhnya harus terpenuhi (AND).
+Struct Condition:
+
+Condition adalah AST filter yang netral terhadap backend. Lapisan API memparsing query parameter menjadi Condition, lalu setiap repository menerjemahkannya ke bahasa query miliknya sendiri (dokumen filter MongoDB, klausa WHERE PostgreSQL, atau perbandingan langsung pada repository memory).
+Fungsi ParseCondition:
+
+Field harus ada di FilterableFields dan operator harus didukung oleh jenis field tersebut: field teks hanya mendukung eq, ne, dan in, sedangkan field angka dan waktu mendukung seluruh operator. Nilai waktu dapat ditulis sebagai UNIX timestamp, tanggal RFC 3339, atau tanggal YYYY-MM-DD (UTC). Kesalahan dibungkus dengan ErrInvalidFilter beserta penjelasannya sehingga lapisan API dapat mengembalikan 422 dengan pesan yang jelas.
+*/
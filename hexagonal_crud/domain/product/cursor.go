@@ -0,0 +1,33 @@
+package product
+
+import (
+	"encoding/base64"
+)
+
+// ErrInvalidCursor dikembalikan ketika cursor pagination tidak dapat dibaca
+// atau digunakan bersama pencarian yang tidak mendukung cursor.
+var ErrInvalidCursor = newError(ErrValidation, "invalid cursor")
+
+// EncodeCursor membungkus posisi milik repository (misalnya ObjectID atau nomor urut) menjadi cursor yang opaque bagi klien.
+func EncodeCursor(position string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(position))
+}
+
+// DecodeCursor membuka cursor yang dibuat oleh EncodeCursor dan mengembalikan posisi milik repository.
+func DecodeCursor(cursor string) (string, error) {
+	position, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(position) == 0 {
+		return "", ErrInvalidCursor
+	}
+	return string(position), nil
+}
+
+/*
+Penjelasan Fungsi Kode:
+Cursor Pagination:
+
+Cursor menandai posisi produk terakhir pada halaman sebelumnya, sehingga halaman berikutnya dibaca dengan kondisi "setelah posisi ini" (keyset pagination) tanpa skip. Cara ini tetap cepat pada koleksi besar dan tidak menghasilkan duplikat ketika produk baru disisipkan di antara dua permintaan.
+Fungsi EncodeCursor dan DecodeCursor:
+
+Isi cursor ditentukan oleh masing-masing repository (ObjectID pada MongoDB, kolom position pada PostgreSQL, nomor urut penyisipan pada repository memory). Domain hanya membungkusnya dengan base64 URL-safe agar klien memperlakukannya sebagai nilai opaque. Cursor yang tidak dapat dibaca menghasilkan ErrInvalidCursor yang dipetakan ke 422 oleh lapisan API.
+*/
@@ -0,0 +1,136 @@
+package product
+
+import "errors"
+
+// Kategori error domain. Setiap error domain termasuk ke salah satu kategori ini, sehingga lapisan API dapat
+// memetakannya ke status HTTP dengan errors.Is tanpa perlu mengenal setiap error satu per satu.
+var (
+	// ErrNotFound adalah kategori untuk produk, reservasi, atau lokasi yang tidak ditemukan.
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict adalah kategori untuk perubahan yang bertentangan dengan data yang tersimpan.
+	ErrConflict = errors.New("conflict")
+
+	// ErrValidation adalah kategori untuk input yang tidak valid, termasuk ID yang tidak valid.
+	ErrValidation = errors.New("validation failed")
+
+	// ErrUnavailable adalah kategori untuk database yang tidak dapat dihubungi atau tidak merespons tepat waktu.
+	ErrUnavailable = errors.New("service unavailable")
+)
+
+// Error adalah error domain dengan kategori. errors.Is(err, Kind) bernilai true untuk setiap error dengan kategori tersebut.
+type Error struct {
+	Kind    error  // Salah satu dari ErrNotFound, ErrConflict, ErrValidation, atau ErrUnavailable
+	Message string // Pesan yang aman ditampilkan kepada klien
+	Err     error  // Penyebab asli (misalnya error driver database), tidak ditampilkan kepada klien
+}
+
+// newError membuat error domain dengan kategori dan pesan tertentu.
+func newError(kind error, message string) error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Unavailable membungkus error koneksi atau timeout dari database menjadi error dengan kategori ErrUnavailable.
+func Unavailable(err error) error {
+	return &Error{Kind: ErrUnavailable, Message: "database unavailable", Err: err}
+}
+
+// Error mengembalikan pesan error.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is membuat errors.Is mengenali kategori error.
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap mengembalikan penyebab asli error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// ErrProductNotFound dikembalikan ketika produk tidak ditemukan atau sudah di-soft delete.
+	ErrProductNotFound = newError(ErrNotFound, "product not found")
+
+	// ErrCodeConflict dikembalikan ketika kode produk (SKU) sudah digunakan oleh produk lain.
+	ErrCodeConflict = newError(ErrConflict, "product code already exists")
+
+	// ErrInsufficientStock dikembalikan ketika perubahan stok akan membuat stok produk menjadi negatif.
+	ErrInsufficientStock = newError(ErrConflict, "insufficient stock")
+
+	// ErrInvalidStockAdjustment dikembalikan ketika jumlah perubahan stok 0 atau kode alasan tidak dikenal.
+	ErrInvalidStockAdjustment = newError(ErrValidation, "invalid stock adjustment")
+
+	// ErrStockNotUpdatable dikembalikan ketika Update meminta stok yang berbeda dari stok produk saat ini.
+	ErrStockNotUpdatable = newError(ErrValidation, "stock cannot be changed by update")
+
+	// ErrInvalidReservation dikembalikan ketika jumlah reservasi tidak lebih dari 0 atau lama reservasi tidak valid.
+	ErrInvalidReservation = newError(ErrValidation, "invalid reservation")
+
+	// ErrReservationNotFound dikembalikan ketika reservasi tidak ditemukan.
+	ErrReservationNotFound = newError(ErrNotFound, "reservation not found")
+
+	// ErrReservationNotActive dikembalikan ketika reservasi sudah dikonfirmasi, dilepas, atau kedaluwarsa.
+	ErrReservationNotActive = newError(ErrConflict, "reservation is not active")
+
+	// ErrInvalidLocation dikembalikan ketika kode, nama, atau jenis lokasi tidak valid.
+	ErrInvalidLocation = newError(ErrValidation, "invalid location")
+
+	// ErrLocationNotFound dikembalikan ketika lokasi tidak ditemukan.
+	ErrLocationNotFound = newError(ErrNotFound, "location not found")
+
+	// ErrLocationCodeConflict dikembalikan ketika kode lokasi sudah digunakan oleh lokasi lain.
+	ErrLocationCodeConflict = newError(ErrConflict, "location code already exists")
+
+	// ErrInvalidTransfer dikembalikan ketika jumlah perpindahan stok tidak lebih dari 0
+	// atau lokasi asal sama dengan lokasi tujuan.
+	ErrInvalidTransfer = newError(ErrValidation, "invalid stock transfer")
+
+	// ErrInvalidGeoPoint dikembalikan ketika koordinat produk bukan GeoJSON Point yang valid.
+	ErrInvalidGeoPoint = newError(ErrValidation, "invalid geo point")
+
+	// ErrInvalidGeoQuery dikembalikan ketika parameter pencarian berdasarkan jarak tidak valid.
+	ErrInvalidGeoQuery = newError(ErrValidation, "invalid geo query")
+
+	// ErrVersionConflict dikembalikan ketika versi produk tidak sama dengan versi yang diharapkan pemanggil.
+	ErrVersionConflict = newError(ErrConflict, "product version conflict")
+)
+
+/*
+Penjelasan Fungsi Kode:
+Kategori Error dan Struct Error:
+
+Error domain dikelompokkan ke dalam empat kategori: ErrNotFound, ErrConflict, ErrValidation, dan ErrUnavailable. Setiap error dibuat dengan newError sehingga errors.Is(err, ErrNotFound) bernilai true untuk ErrProductNotFound, ErrReservationNotFound, dan ErrLocationNotFound, juga ketika error tersebut dibungkus dengan fmt.Errorf("%w: ..."). Lapisan API cukup memetakan kategori ke status HTTP (404, 409, 422, 503) di satu tempat, dan error yang tidak termasuk kategori mana pun dianggap kesalahan server (500).
+Fungsi Unavailable:
+
+Repository database membungkus error koneksi dan timeout dengan Unavailable. Pesan yang dikirim ke klien hanya "database unavailable", sedangkan error asli dari driver tetap dapat dibaca melalui errors.Unwrap untuk logging.
+Variabel ErrProductNotFound:
+
+ErrProductNotFound dikembalikan oleh setiap repository ketika produk tidak ada atau sudah di-soft delete, menggantikan pesan ad-hoc "error Finding a store" dan "store not found".
+Variabel ErrCodeConflict:
+
+ErrCodeConflict adalah error milik domain yang dikembalikan oleh setiap repository ketika Store atau Update melanggar keunikan kode produk. Karena error ini didefinisikan di domain, lapisan API dapat memeriksanya dengan errors.Is dan mengembalikan status 409 Conflict tanpa perlu mengetahui detail error dari MongoDB atau PostgreSQL.
+Variabel ErrInsufficientStock:
+
+ErrInsufficientStock dikembalikan oleh repository ketika pengurangan stok akan membuat stok produk menjadi negatif atau lebih kecil dari stok yang sedang ditahan reservasi, ketika reservasi meminta lebih banyak dari stok yang tersedia, dan ketika stok sebuah lokasi (atau stok yang belum dialokasikan) tidak mencukupi. Pemeriksaan dilakukan secara atomik bersama perubahan stok sehingga aman dari race condition. Lapisan API mengembalikan status 409 Conflict.
+Variabel ErrInvalidStockAdjustment:
+
+ErrInvalidStockAdjustment dikembalikan oleh service ketika permintaan perubahan stok tidak valid. Lapisan API mengembalikan status 422 Unprocessable Entity.
+Variabel ErrStockNotUpdatable:
+
+ErrStockNotUpdatable dikembalikan oleh service ketika Update (PUT) berisi stok yang berbeda dari stok produk saat ini. Stok hanya berubah melalui buku besar stok, sehingga perubahan tersebut ditolak alih-alih diabaikan diam-diam. Lapisan API mengembalikan status 422 Unprocessable Entity beserta petunjuk endpoint yang harus digunakan.
+Variabel ErrInvalidReservation, ErrReservationNotFound, dan ErrReservationNotActive:
+
+Error untuk reservasi stok. ErrInvalidReservation dikembalikan oleh service dan dipetakan ke 422, ErrReservationNotFound dipetakan ke 404, dan ErrReservationNotActive (termasuk reservasi yang sudah melewati batas waktu tetapi belum disapu) dipetakan ke 409.
+Variabel ErrInvalidLocation, ErrLocationNotFound, ErrLocationCodeConflict, dan ErrInvalidTransfer:
+
+Error untuk lokasi dan perpindahan stok. ErrInvalidLocation dan ErrInvalidTransfer dipetakan ke 422, ErrLocationNotFound ke 404, dan ErrLocationCodeConflict ke 409.
+Variabel ErrInvalidGeoPoint dan ErrInvalidGeoQuery:
+
+Error untuk koordinat produk dan pencarian berdasarkan jarak. Keduanya dipetakan ke 422 Unprocessable Entity.
+Variabel ErrVersionConflict:
+
+ErrVersionConflict dikembalikan oleh Update, Patch, Delete, dan DeleteById ketika versi produk sudah diubah oleh permintaan lain sejak dibaca (optimistic concurrency control). Lapisan API membandingkan versi dari header If-Match dan mengembalikan status 412 Precondition Failed.
+*/
@@ -0,0 +1,105 @@
+package product
+
+import (
+	"math"
+	"strconv"
+)
+
+// GeoPointType adalah nilai field type untuk GeoJSON Point.
+const GeoPointType = "Point"
+
+// EarthRadiusKM adalah jari-jari bumi dalam kilometer yang digunakan untuk menghitung jarak.
+// Nilainya sama dengan yang digunakan MongoDB untuk index 2dsphere.
+const EarthRadiusKM = 6378.1
+
+// GeoPoint adalah koordinat produk dalam format GeoJSON Point.
+// Urutan Coordinates mengikuti GeoJSON, yaitu [longitude, latitude].
+type GeoPoint struct {
+	Type        string    `json:"type" bson:"type"`               // Selalu "Point"
+	Coordinates []float64 `json:"coordinates" bson:"coordinates"` // [longitude, latitude]
+}
+
+// NewGeoPoint membuat GeoPoint dari latitude dan longitude.
+func NewGeoPoint(latitude, longitude float64) *GeoPoint {
+	return &GeoPoint{Type: GeoPointType, Coordinates: []float64{longitude, latitude}}
+}
+
+// Validate memeriksa bahwa GeoPoint adalah Point dengan latitude -90..90 dan longitude -180..180.
+func (p *GeoPoint) Validate() error {
+	if p.Type != GeoPointType || len(p.Coordinates) != 2 || !validCoordinate(p.Latitude(), p.Longitude()) {
+		return ErrInvalidGeoPoint
+	}
+	return nil
+}
+
+// Latitude mengembalikan koordinat lintang.
+func (p *GeoPoint) Latitude() float64 {
+	return p.Coordinates[1]
+}
+
+// Longitude mengembalikan koordinat bujur.
+func (p *GeoPoint) Longitude() float64 {
+	return p.Coordinates[0]
+}
+
+// DistanceKM menghitung jarak lingkaran besar (haversine) ke koordinat lain dalam kilometer.
+func (p *GeoPoint) DistanceKM(latitude, longitude float64) float64 {
+	lat1, lat2 := p.Latitude()*math.Pi/180, latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (longitude - p.Longitude()) * math.Pi / 180
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * EarthRadiusKM * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// GeoQuery adalah kriteria pencarian produk berdasarkan jarak dari sebuah titik.
+type GeoQuery struct {
+	Latitude  float64 `json:"latitude"`  // Koordinat lintang titik pusat pencarian
+	Longitude float64 `json:"longitude"` // Koordinat bujur titik pusat pencarian
+	RadiusKM  float64 `json:"radius_km"` // Jarak maksimum dalam kilometer, 0 berarti tanpa batas
+}
+
+// ParseGeoQuery memparsing parameter latitude, longitude, dan radius_km (misalnya dari query string).
+// Jika latitude dan longitude kosong, nil dikembalikan tanpa error karena pencarian jarak tidak diminta.
+func ParseGeoQuery(latitude, longitude, radiusKM string) (*GeoQuery, error) {
+	if latitude == "" && longitude == "" && radiusKM == "" {
+		return nil, nil
+	}
+
+	lat, errLat := strconv.ParseFloat(latitude, 64)
+	lng, errLng := strconv.ParseFloat(longitude, 64)
+	if errLat != nil || errLng != nil || !validCoordinate(lat, lng) {
+		return nil, ErrInvalidGeoQuery
+	}
+
+	query := GeoQuery{Latitude: lat, Longitude: lng}
+	if radiusKM != "" {
+		radius, err := strconv.ParseFloat(radiusKM, 64)
+		if err != nil || radius <= 0 || math.IsInf(radius, 0) {
+			return nil, ErrInvalidGeoQuery
+		}
+		query.RadiusKM = radius
+	}
+	return &query, nil
+}
+
+// validCoordinate memeriksa rentang latitude dan longitude.
+func validCoordinate(latitude, longitude float64) bool {
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180
+}
+
+/*
+Penjelasan Fungsi Kode:
+Struct GeoPoint:
+
+GeoPoint menyimpan koordinat produk dalam format GeoJSON Point sehingga dapat langsung diindeks oleh index 2dsphere pada MongoDB. Urutan koordinat mengikuti GeoJSON, yaitu longitude lalu latitude, sedangkan Latitude dan Longitude membantu membaca nilainya tanpa perlu mengingat urutan tersebut.
+Fungsi Validate:
+
+Validate digunakan oleh service sebelum produk disimpan. Koordinat di luar rentang atau type selain Point ditolak dengan ErrInvalidGeoPoint.
+Fungsi DistanceKM:
+
+DistanceKM menghitung jarak dengan rumus haversine menggunakan EarthRadiusKM. Repository memory dan PostgreSQL menggunakan rumus yang sama sehingga jarak yang dikembalikan setara dengan $geoNear pada MongoDB.
+Struct GeoQuery dan Fungsi ParseGeoQuery:
+
+GeoQuery adalah kriteria pencarian berdasarkan jarak pada Filter.Near. ParseGeoQuery digunakan oleh lapisan HTTP untuk membaca parameter latitude, longitude, dan radius_km. Latitude dan longitude wajib diisi bersamaan, dan radius_km harus lebih dari 0 jika diisi. Parameter yang tidak valid menghasilkan ErrInvalidGeoQuery.
+*/
@@ -0,0 +1,55 @@
+package product
+
+// ErrInvalidID dikembalikan ketika teks tidak dapat diparsing menjadi ID produk
+// atau ID tidak sesuai dengan format ID milik repository (ObjectID, UUID).
+var ErrInvalidID = newError(ErrValidation, "invalid product id")
+
+// maxIDLength adalah panjang maksimum ID produk dalam bentuk teks.
+const maxIDLength = 64
+
+// ID adalah identitas unik produk yang dimiliki oleh domain.
+// Nilainya bersifat opaque: setiap repository bebas menentukan format penyimpanannya
+// (misalnya ObjectID pada MongoDB atau UUID pada PostgreSQL) dan menerjemahkannya ke/dari ID.
+type ID string
+
+// ParseID memparsing teks (misalnya parameter URL) menjadi ID produk.
+// ID harus tidak kosong, paling panjang 64 karakter, dan hanya berisi huruf, angka, '-' atau '_'.
+// Validasi format khusus backend (ObjectID, UUID) dilakukan oleh masing-masing repository.
+func ParseID(s string) (ID, error) {
+	if s == "" || len(s) > maxIDLength {
+		return "", ErrInvalidID
+	}
+	for _, c := range s {
+		isAlphaNumeric := (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !isAlphaNumeric && c != '-' && c != '_' {
+			return "", ErrInvalidID
+		}
+	}
+	return ID(s), nil
+}
+
+// String mengembalikan representasi teks dari ID.
+func (id ID) String() string {
+	return string(id)
+}
+
+// IsZero memeriksa apakah ID masih kosong (produk belum disimpan).
+func (id ID) IsZero() bool {
+	return id == ""
+}
+
+/*
+Penjelasan Fungsi Kode:
+Tipe ID:
+
+ID didefinisikan di package domain agar entitas Product tidak bergantung pada tipe ID milik database tertentu seperti primitive.ObjectID dari driver MongoDB. Dengan begitu domain tetap bersih sesuai batas arsitektur heksagonal, dan backend lain seperti PostgreSQL dapat menggunakan format ID-nya sendiri.
+Fungsi ParseID:
+
+ParseID digunakan oleh lapisan HTTP untuk mengubah parameter URL menjadi ID. Fungsi ini hanya memeriksa bentuk umum ID sehingga tetap netral terhadap backend. Jika teks tidak valid, ErrInvalidID dikembalikan.
+Fungsi String:
+
+String mengembalikan nilai ID dalam bentuk teks, misalnya untuk dikirim melalui URL atau JSON.
+Fungsi IsZero:
+
+IsZero mengembalikan true jika ID masih kosong. Fungsi ini juga dipakai oleh encoder BSON untuk opsi omitempty sehingga MongoDB tetap membuat _id secara otomatis saat produk baru disimpan.
+*/
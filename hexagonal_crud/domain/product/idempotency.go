@@ -0,0 +1,44 @@
+package product
+
+// MaxIdempotencyKeyLength adalah panjang maksimum header Idempotency-Key.
+const MaxIdempotencyKeyLength = 255
+
+var (
+	// ErrInvalidIdempotencyKey dikembalikan ketika Idempotency-Key kosong atau terlalu panjang.
+	ErrInvalidIdempotencyKey = newError(ErrValidation, "invalid idempotency key")
+
+	// ErrIdempotencyKeyReused dikembalikan ketika Idempotency-Key digunakan ulang untuk request yang berbeda.
+	ErrIdempotencyKeyReused = newError(ErrValidation, "idempotency key was used for a different request")
+
+	// ErrIdempotencyKeyInProgress dikembalikan ketika request lain dengan Idempotency-Key yang sama masih diproses.
+	ErrIdempotencyKeyInProgress = newError(ErrConflict, "a request with this idempotency key is still in progress")
+)
+
+// IdempotencyRecord menyimpan hasil request yang dikirim dengan header Idempotency-Key,
+// sehingga request yang diulang dengan key yang sama menerima response yang sama.
+type IdempotencyRecord struct {
+	Key         string            `json:"key" bson:"_id"`                 // Nilai header Idempotency-Key
+	Fingerprint string            `json:"fingerprint" bson:"fingerprint"` // Hash dari method, URL, dan body request
+	Status      int               `json:"status" bson:"status"`           // Status HTTP response, 0 selama request masih diproses
+	Header      map[string]string `json:"header" bson:"header"`           // Header response yang diputar ulang, misalnya Content-Type dan ETag
+	Body        []byte            `json:"body" bson:"body"`               // Body response
+	CreatedAt   int64             `json:"created_at" bson:"created_at"`   // Waktu (timestamp) saat key pertama kali digunakan
+	ExpiresAt   int64             `json:"expires_at" bson:"expires_at"`   // Waktu (timestamp) setelah key dapat digunakan kembali
+}
+
+// Completed mengembalikan true jika response untuk key ini sudah disimpan.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
+
+/*
+Penjelasan Fungsi Kode:
+Struct IdempotencyRecord:
+
+Klien yang mengulang request (misalnya aplikasi mobile pada jaringan yang tidak stabil) mengirim header Idempotency-Key yang sama
+pada setiap percobaan. Percobaan pertama mengklaim key dengan IdempotencyRecord berstatus 0 (sedang diproses), lalu response-nya
+disimpan pada Status, Header, dan Body. Percobaan berikutnya menerima response yang tersimpan tanpa menjalankan ulang perubahan.
+Fingerprint memastikan key yang sama tidak digunakan untuk request lain: request dengan body berbeda ditolak dengan
+ErrIdempotencyKeyReused (422), dan request yang datang selagi percobaan pertama masih diproses ditolak dengan
+ErrIdempotencyKeyInProgress (409). Setelah ExpiresAt terlewati, record dihapus dan key dapat digunakan kembali.
+*/
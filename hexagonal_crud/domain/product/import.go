@@ -0,0 +1,110 @@
+package product
+
+// MaxImportRows adalah jumlah maksimum baris data pada satu file import.
+const MaxImportRows = 10000
+
+var (
+	// ErrInvalidImport dikembalikan ketika file import kosong, melebihi MaxImportRows, atau header kolomnya tidak valid.
+	ErrInvalidImport = newError(ErrValidation, "invalid import file")
+
+	// ErrJobNotFound dikembalikan ketika job dengan ID tertentu tidak ditemukan.
+	ErrJobNotFound = newError(ErrNotFound, "job not found")
+)
+
+// Status job import.
+const (
+	JobPending   = "pending"   // Job sudah dibuat dan menunggu diproses
+	JobRunning   = "running"   // Baris sedang divalidasi dan disimpan
+	JobCompleted = "completed" // Semua baris sudah diproses, sebagian baris mungkin ditolak
+	JobFailed    = "failed"    // Job berhenti karena kesalahan yang tidak terkait satu baris, misalnya database tidak dapat dihubungi
+)
+
+// ImportColumns memetakan nama kolom pada file import (huruf kecil) ke field Product.
+// Nama kolom mengikuti tag json Product, ditambah beberapa alias yang umum di spreadsheet.
+var ImportColumns = map[string]string{
+	"code":         "code",
+	"sku":          "code",
+	"product_name": "product_name",
+	"name":         "product_name",
+	"stock":        "stock",
+	"description":  "description",
+	"latitude":     "latitude",
+	"lat":          "latitude",
+	"longitude":    "longitude",
+	"lng":          "longitude",
+	"lon":          "longitude",
+}
+
+// ImportRequest adalah isi file import yang sudah dibaca oleh lapisan API.
+type ImportRequest struct {
+	Filename string     // Nama file yang diunggah
+	Mode     string     // BulkCreate atau BulkUpsert
+	DryRun   bool       // Jika true, baris hanya divalidasi dan perubahannya ditampilkan tanpa disimpan
+	Header   []string   // Nama kolom pada baris pertama file
+	Rows     [][]string // Baris data setelah header
+}
+
+// ImportJob adalah job asinkron yang memproses file import produk.
+type ImportJob struct {
+	ID            ID     `json:"job_id,omitempty" bson:"_id,omitempty"`    // ID unik job
+	Status        string `json:"status" bson:"status"`                     // JobPending, JobRunning, JobCompleted, atau JobFailed
+	Mode          string `json:"mode" bson:"mode"`                         // BulkCreate atau BulkUpsert
+	DryRun        bool   `json:"dry_run" bson:"dry_run"`                   // Job hanya menampilkan perubahan tanpa menyimpannya
+	Filename      string `json:"filename" bson:"filename"`                 // Nama file yang diunggah
+	TotalRows     int    `json:"total_rows" bson:"total_rows"`             // Jumlah baris data pada file
+	ProcessedRows int    `json:"processed_rows" bson:"processed_rows"`     // Jumlah baris yang sudah diproses
+	Progress      int    `json:"progress" bson:"-"`                        // Persentase baris yang sudah diproses, dihitung oleh service
+	Created       int    `json:"created" bson:"created"`                   // Jumlah produk yang dibuat (atau akan dibuat pada dry-run)
+	Updated       int    `json:"updated" bson:"updated"`                   // Jumlah produk yang diperbarui (atau akan diperbarui pada dry-run)
+	Rejected      int    `json:"rejected" bson:"rejected"`                 // Jumlah baris yang ditolak, rinciannya ada pada laporan error
+	Error         string `json:"error,omitempty" bson:"error,omitempty"`   // Penyebab job gagal jika Status adalah JobFailed
+	CreatedAt     int64  `json:"created_at" bson:"created_at"`             // Waktu (timestamp) saat job dibuat
+	UpdatedAt     int64  `json:"updated_at" bson:"updated_at"`             // Waktu (timestamp) saat progres job terakhir diperbarui
+	FinishedAt    int64  `json:"finished_at,omitempty" bson:"finished_at"` // Waktu (timestamp) saat job selesai atau gagal
+
+	Changes []ImportChange   `json:"changes,omitempty" bson:"changes,omitempty"` // Perubahan yang akan dilakukan, hanya diisi pada dry-run
+	Header  []string         `json:"-" bson:"header"`                            // Nama kolom pada file, digunakan untuk laporan error
+	Errors  []ImportRowError `json:"-" bson:"errors,omitempty"`                  // Baris yang ditolak beserta alasannya
+}
+
+// ImportChange adalah perubahan yang akan dilakukan oleh satu baris pada dry-run.
+type ImportChange struct {
+	Row       int    `json:"row" bson:"row"`                                   // Nomor baris pada file, baris header adalah baris 1
+	Action    string `json:"action" bson:"action"`                             // BulkCreated atau BulkUpdated
+	ProductID ID     `json:"product_id,omitempty" bson:"product_id,omitempty"` // ID produk yang akan diperbarui
+	Code      string `json:"code,omitempty" bson:"code,omitempty"`             // Kode produk (SKU)
+	Name      string `json:"product_name" bson:"product_name"`                 // Nama produk
+}
+
+// ImportRowError adalah baris yang ditolak beserta alasannya.
+type ImportRowError struct {
+	Row     int      `json:"row" bson:"row"`         // Nomor baris pada file, baris header adalah baris 1
+	Values  []string `json:"values" bson:"values"`   // Isi baris sesuai urutan kolom pada file
+	Message string   `json:"message" bson:"message"` // Alasan baris ditolak
+}
+
+// ComputeProgress menghitung persentase baris yang sudah diproses.
+func (j *ImportJob) ComputeProgress() {
+	switch {
+	case j.Status == JobCompleted:
+		j.Progress = 100
+	case j.TotalRows > 0:
+		j.Progress = j.ProcessedRows * 100 / j.TotalRows
+	default:
+		j.Progress = 0
+	}
+}
+
+/*
+Penjelasan Fungsi Kode:
+Struct ImportJob:
+
+Merchandiser mengelola daftar produk di spreadsheet. File CSV atau XLSX diunggah melalui POST /product/import dan diproses
+sebagai job asinkron: request langsung dijawab dengan ImportJob berstatus pending, lalu baris-baris diproses di latar belakang
+dan progresnya (ProcessedRows, Progress, Created, Updated, Rejected) dapat dipantau melalui GET /jobs/:id.
+Kolom pada baris pertama file dipetakan ke field Product melalui ImportColumns (tidak peka huruf besar kecil), kolom lain
+diabaikan. Setiap baris divalidasi seperti POST /product; baris yang tidak valid atau gagal disimpan dicatat pada Errors
+beserta isi barisnya, sehingga laporan error dapat diunduh, diperbaiki di spreadsheet, lalu diunggah ulang.
+Pada dry-run tidak ada produk yang disimpan. Created dan Updated berisi jumlah produk yang akan dibuat atau diperbarui,
+dan Changes berisi rincian perubahan setiap baris untuk ditinjau sebelum file diunggah ulang tanpa dry-run.
+*/
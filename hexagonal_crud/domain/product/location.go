@@ -0,0 +1,65 @@
+package product
+
+// Jenis lokasi penyimpanan stok.
+const (
+	LocationWarehouse = "warehouse" // Gudang
+	LocationStore     = "store"     // Toko
+)
+
+// Location merepresentasikan tempat penyimpanan stok, seperti gudang atau toko.
+type Location struct {
+	ID        ID     `json:"location_id,omitempty" bson:"_id,omitempty"` // ID unik lokasi
+	Code      string `json:"code" bson:"code"`                           // Kode unik lokasi
+	Name      string `json:"name" bson:"name"`                           // Nama lokasi
+	Type      string `json:"type" bson:"type"`                           // Jenis lokasi (warehouse atau store)
+	CreatedAt int64  `json:"created_at" bson:"created_at"`               // Waktu (timestamp) saat lokasi dibuat
+}
+
+// LocationStock adalah jumlah stok sebuah produk pada satu lokasi.
+type LocationStock struct {
+	ProductID  ID    `json:"product_id" bson:"product_id"`   // ID produk
+	LocationID ID    `json:"location_id" bson:"location_id"` // ID lokasi
+	Quantity   int64 `json:"quantity" bson:"quantity"`       // Jumlah stok produk pada lokasi ini
+	UpdatedAt  int64 `json:"updated_at" bson:"updated_at"`   // Waktu (timestamp) saat stok lokasi terakhir berubah
+}
+
+// LocationStockFilter digunakan untuk mencari stok per lokasi berdasarkan produk atau lokasi.
+type LocationStockFilter struct {
+	ProductIDs []ID // Hanya stok milik produk-produk ini, kosong berarti semua produk
+	LocationID ID   // Hanya stok pada lokasi ini, kosong berarti semua lokasi
+}
+
+// StockTransfer adalah perpindahan stok sebuah produk antar lokasi.
+// Lokasi asal atau tujuan yang kosong berarti stok yang belum dialokasikan ke lokasi mana pun.
+type StockTransfer struct {
+	ProductID      ID     `json:"product_id"`                 // ID produk yang dipindahkan
+	FromLocationID ID     `json:"from_location_id,omitempty"` // ID lokasi asal
+	ToLocationID   ID     `json:"to_location_id,omitempty"`   // ID lokasi tujuan
+	Quantity       int64  `json:"quantity"`                   // Jumlah unit yang dipindahkan, harus lebih dari 0
+	Note           string `json:"note,omitempty"`             // Catatan tambahan dari pengguna
+	CreatedAt      int64  `json:"created_at"`                 // Waktu (timestamp) saat perpindahan dilakukan
+}
+
+// ValidLocationType memeriksa apakah jenis lokasi termasuk dalam daftar yang diizinkan.
+func ValidLocationType(locationType string) bool {
+	return locationType == LocationWarehouse || locationType == LocationStore
+}
+
+/*
+Penjelasan Fungsi Kode:
+Struct Location:
+
+Location adalah gudang atau toko tempat stok disimpan. Kode lokasi bersifat unik sehingga dapat dipetakan ke sistem lain (misalnya ERP).
+Struct LocationStock:
+
+LocationStock menyimpan jumlah stok sebuah produk pada satu lokasi dan tidak pernah bernilai negatif. Jumlah seluruh LocationStock sebuah produk disimpan pada Product.Allocated, sedangkan sisanya (Product.Stock - Product.Allocated) adalah stok yang belum dialokasikan ke lokasi mana pun.
+Struct LocationStockFilter:
+
+LocationStockFilter digunakan untuk membaca stok per lokasi milik satu atau beberapa produk (rincian pada response produk) atau seluruh stok pada satu lokasi.
+Struct StockTransfer:
+
+StockTransfer memindahkan stok antar lokasi secara atomik tanpa mengubah total stok produk. Lokasi asal yang kosong berarti mengalokasikan stok yang belum dialokasikan ke lokasi tujuan, dan lokasi tujuan yang kosong berarti mengembalikan stok lokasi menjadi stok yang belum dialokasikan.
+Fungsi ValidLocationType:
+
+ValidLocationType digunakan oleh service untuk menolak jenis lokasi yang tidak dikenal.
+*/
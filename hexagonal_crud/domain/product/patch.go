@@ -0,0 +1,485 @@
+package product
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPatch dikembalikan ketika dokumen patch tidak valid atau mengubah field yang tidak boleh diubah.
+	ErrInvalidPatch = newError(ErrValidation, "invalid patch")
+
+	// ErrPatchTestFailed dikembalikan ketika operasi "test" pada JSON Patch tidak cocok dengan data produk saat ini.
+	ErrPatchTestFailed = newError(ErrConflict, "patch test operation failed")
+)
+
+// PatchType adalah media type dokumen patch yang didukung.
+type PatchType string
+
+const (
+	MergePatch PatchType = "application/merge-patch+json" // JSON Merge Patch (RFC 7396)
+	JSONPatch  PatchType = "application/json-patch+json"  // JSON Patch (RFC 6902)
+)
+
+// PatchableFields adalah daftar field (nama JSON) yang boleh diubah melalui patch. Perubahan stock tidak ditulis
+// langsung, tetapi dicatat service sebagai pergerakan stok correction; field lain dikelola oleh repository.
+var PatchableFields = []string{"code", "product_name", "description", "geo", "stock"}
+
+// Changes adalah kumpulan perubahan eksplisit pada field produk, dikunci dengan nama field JSON.
+// Berbeda dengan Update, nilai nol ("" atau 0) dan nil tetap diterapkan sehingga field dapat dikosongkan.
+type Changes map[string]any
+
+// Fields mengembalikan nama field yang diubah secara berurutan.
+func (c Changes) Fields() []string {
+	fields := make([]string, 0, len(c))
+	for field := range c {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// ApplyTo menerapkan perubahan pada field PatchableFields milik produk p, misalnya untuk memvalidasi produk hasil patch
+// sebelum perubahan ditulis ke repository. Field lain seperti updated_at diabaikan.
+func (c Changes) ApplyTo(p *Product) {
+	for field, value := range c {
+		switch field {
+		case "code":
+			p.Code, _ = value.(string)
+		case "product_name":
+			p.Name, _ = value.(string)
+		case "description":
+			p.Description, _ = value.(string)
+		case "geo":
+			p.Geo, _ = value.(*GeoPoint)
+		case "stock":
+			p.Stock, _ = value.(int64)
+		}
+	}
+}
+
+// Revert mengembalikan perubahan yang berisi nilai field PatchableFields milik produk p sebelum c diterapkan,
+// sehingga patch yang langkah berikutnya gagal dapat dibatalkan. Field lain seperti updated_at diabaikan.
+func (c Changes) Revert(p *Product) Changes {
+	reverted := Changes{}
+	for field := range c {
+		switch field {
+		case "code":
+			reverted[field] = p.Code
+		case "product_name":
+			reverted[field] = p.Name
+		case "description":
+			reverted[field] = p.Description
+		case "geo":
+			reverted[field] = p.Geo
+		case "stock":
+			reverted[field] = p.Stock
+		}
+	}
+	return reverted
+}
+
+// PatchDocument adalah dokumen patch mentah beserta media type-nya.
+type PatchDocument struct {
+	Type PatchType // Media type dokumen, menentukan cara dokumen diterapkan
+	Body []byte    // Isi dokumen dalam format JSON
+}
+
+// patchOperation adalah satu operasi JSON Patch (RFC 6902).
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"` // Kosong jika tidak diisi, berisi "null" jika bernilai null
+}
+
+// Apply menerapkan dokumen patch pada salinan JSON produk saat ini lalu mengembalikan field yang berubah.
+// Field di luar PatchableFields yang berubah ditolak dengan ErrInvalidPatch, sedangkan operasi "test" yang
+// tidak cocok menghasilkan ErrPatchTestFailed. Produk current tidak diubah.
+func (d PatchDocument) Apply(current *Product) (Changes, error) {
+	original, err := productDocument(current)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := productDocument(current)
+	if err != nil {
+		return nil, err
+	}
+
+	switch d.Type {
+	case MergePatch:
+		var patch any
+		if err := json.Unmarshal(d.Body, &patch); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		object, ok := patch.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: merge patch must be a JSON object", ErrInvalidPatch)
+		}
+		patched = mergePatch(patched, object).(map[string]any)
+	case JSONPatch:
+		var operations []patchOperation
+		if err := json.Unmarshal(d.Body, &operations); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		var document any = patched
+		for i, operation := range operations {
+			if document, err = operation.apply(document); err != nil {
+				return nil, fmt.Errorf("operation %d: %w", i, err)
+			}
+		}
+		object, ok := document.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: patched document must be a JSON object", ErrInvalidPatch)
+		}
+		patched = object
+	default:
+		return nil, fmt.Errorf("%w: unsupported patch type %q", ErrInvalidPatch, d.Type)
+	}
+
+	// Hanya field yang nilainya berbeda dari produk saat ini yang menjadi perubahan
+	keys := make(map[string]bool)
+	for key := range original {
+		keys[key] = true
+	}
+	for key := range patched {
+		keys[key] = true
+	}
+	changes := Changes{}
+	for key := range keys {
+		if reflect.DeepEqual(original[key], patched[key]) {
+			continue
+		}
+		if !contains(PatchableFields, key) {
+			return nil, fmt.Errorf("%w: field %q cannot be patched", ErrInvalidPatch, key)
+		}
+		value, err := patchValue(key, patched[key])
+		if err != nil {
+			return nil, err
+		}
+		changes[key] = value
+	}
+	return changes, nil
+}
+
+// productDocument mengubah produk menjadi dokumen JSON generik (map, slice, string, float64, bool, nil).
+func productDocument(p *Product) (map[string]any, error) {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var document map[string]any
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// patchValue mengubah nilai JSON generik sebuah field menjadi tipe Go field tersebut pada Product.
+// Nilai null atau field yang dihapus menjadi nilai nol (string kosong atau GeoPoint nil), kecuali stock yang wajib diisi.
+func patchValue(field string, value any) (any, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch field {
+	case "geo":
+		var point *GeoPoint
+		if err := json.Unmarshal(raw, &point); err != nil {
+			return nil, fmt.Errorf("%w: field %q: %v", ErrInvalidPatch, field, err)
+		}
+		if point != nil {
+			if err := point.Validate(); err != nil {
+				return nil, err
+			}
+		}
+		return point, nil
+	case "stock":
+		// Stok harus berupa bilangan bulat, null atau field yang dihapus tidak dianggap sebagai 0
+		var stock *int64
+		if err := json.Unmarshal(raw, &stock); err != nil || stock == nil {
+			return nil, fmt.Errorf("%w: field %q must be an integer", ErrInvalidPatch, field)
+		}
+		return *stock, nil
+	default:
+		var text *string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, fmt.Errorf("%w: field %q must be a string", ErrInvalidPatch, field)
+		}
+		if text == nil {
+			return "", nil
+		}
+		return *text, nil
+	}
+}
+
+// mergePatch menerapkan JSON Merge Patch (RFC 7396): object digabung secara rekursif,
+// nilai null menghapus field, dan nilai lain menggantikan nilai lama.
+func mergePatch(target any, patch any) any {
+	object, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	result, ok := target.(map[string]any)
+	if !ok {
+		result = map[string]any{}
+	}
+	for key, value := range object {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = mergePatch(result[key], value)
+	}
+	return result
+}
+
+// apply menerapkan satu operasi JSON Patch pada document dan mengembalikan dokumen hasilnya.
+func (o patchOperation) apply(document any) (any, error) {
+	path, err := parsePointer(o.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	value := func() (any, error) {
+		if len(o.Value) == 0 {
+			return nil, fmt.Errorf("%w: %q operation requires a value", ErrInvalidPatch, o.Op)
+		}
+		var v any
+		if err := json.Unmarshal(o.Value, &v); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		return v, nil
+	}
+
+	switch o.Op {
+	case "add":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(document, path, v)
+	case "remove":
+		document, _, err = pointerRemove(document, path)
+		return document, err
+	case "replace":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		if document, _, err = pointerRemove(document, path); err != nil {
+			return nil, err
+		}
+		return pointerAdd(document, path, v)
+	case "move", "copy":
+		from, err := parsePointer(o.From)
+		if err != nil {
+			return nil, err
+		}
+		v, err := pointerGet(document, from)
+		if err != nil {
+			return nil, err
+		}
+		if o.Op == "move" {
+			if document, _, err = pointerRemove(document, from); err != nil {
+				return nil, err
+			}
+		} else {
+			// Nilai disalin agar perubahan berikutnya pada path tujuan tidak mengubah path asal
+			if v, err = deepCopy(v); err != nil {
+				return nil, err
+			}
+		}
+		return pointerAdd(document, path, v)
+	case "test":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		current, err := pointerGet(document, path)
+		if err != nil || !reflect.DeepEqual(current, v) {
+			return nil, fmt.Errorf("%w: %s", ErrPatchTestFailed, o.Path)
+		}
+		return document, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidPatch, o.Op)
+	}
+}
+
+// parsePointer memecah JSON Pointer (RFC 6901) menjadi token, "" berarti seluruh dokumen.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: invalid path %q", ErrInvalidPatch, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// pointerGet mengambil nilai pada path di dalam document.
+func pointerGet(document any, path []string) (any, error) {
+	for _, token := range path {
+		switch node := document.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: path not found %q", ErrInvalidPatch, token)
+			}
+			document = value
+		case []any:
+			index, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			document = node[index]
+		default:
+			return nil, fmt.Errorf("%w: path not found %q", ErrInvalidPatch, token)
+		}
+	}
+	return document, nil
+}
+
+// pointerAdd menambahkan atau mengganti nilai pada path. Untuk array, nilai disisipkan pada index
+// dan token "-" berarti di akhir array.
+func pointerAdd(document any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := pointerGet(document, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]any:
+		node[token] = value
+		return document, nil
+	case []any:
+		index := len(node)
+		if token != "-" {
+			if index, err = arrayIndex(token, len(node)); err != nil {
+				return nil, err
+			}
+		}
+		node = append(node[:index], append([]any{value}, node[index:]...)...)
+		return replaceParent(document, path[:len(path)-1], node)
+	default:
+		return nil, fmt.Errorf("%w: path not found %q", ErrInvalidPatch, token)
+	}
+}
+
+// pointerRemove menghapus nilai pada path dan mengembalikan dokumen hasilnya beserta nilai yang dihapus.
+func pointerRemove(document any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, document, nil
+	}
+	parent, err := pointerGet(document, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	token := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]any:
+		value, ok := node[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: path not found %q", ErrInvalidPatch, token)
+		}
+		delete(node, token)
+		return document, value, nil
+	case []any:
+		index, err := arrayIndex(token, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := node[index]
+		node = append(node[:index:index], node[index+1:]...)
+		document, err = replaceParent(document, path[:len(path)-1], node)
+		return document, value, err
+	default:
+		return nil, nil, fmt.Errorf("%w: path not found %q", ErrInvalidPatch, token)
+	}
+}
+
+// replaceParent mengganti array pada path dengan node, karena panjang slice berubah setelah penyisipan atau penghapusan.
+func replaceParent(document any, path []string, node []any) (any, error) {
+	if len(path) == 0 {
+		return node, nil
+	}
+	parent, err := pointerGet(document, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch p := parent.(type) {
+	case map[string]any:
+		p[token] = node
+	case []any:
+		index, err := arrayIndex(token, len(p)-1)
+		if err != nil {
+			return nil, err
+		}
+		p[index] = node
+	}
+	return document, nil
+}
+
+// arrayIndex memparsing token index array yang harus berada di antara 0 dan max.
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+	return index, nil
+}
+
+// deepCopy membuat salinan nilai JSON generik.
+func deepCopy(value any) (any, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var copied any
+	err = json.Unmarshal(raw, &copied)
+	return copied, err
+}
+
+/*
+Penjelasan Kode:
+
+Dokumen Patch:
+PATCH /product/:id menerima dua format dokumen. JSON Merge Patch (RFC 7396, application/merge-patch+json) berisi object
+dengan field yang ingin diubah; nilai null menghapus (mengosongkan) field tersebut. JSON Patch (RFC 6902,
+application/json-patch+json) berisi daftar operasi add, remove, replace, move, copy, dan test dengan path JSON Pointer
+(RFC 6901), misalnya {"op": "replace", "path": "/description", "value": ""}.
+
+Penerapan:
+Apply tidak mengubah produk secara langsung. Produk saat ini diubah menjadi dokumen JSON, dokumen patch diterapkan pada
+salinannya, lalu hasilnya dibandingkan dengan dokumen asli. Field yang berbeda menjadi Changes dengan tipe Go yang sesuai
+(string, int64, atau *GeoPoint), sehingga nilai kosong dan null tetap diterapkan oleh repository. Operasi test dapat membaca field
+apa pun (misalnya /version), tetapi perubahan pada field di luar PatchableFields ditolak dengan ErrInvalidPatch.
+
+Field yang Dapat Diubah:
+code, product_name, description, geo, dan stock. Stock tidak ditulis seperti field lain: service mencatat selisih stok
+baru dan stok saat ini sebagai pergerakan stok correction, sehingga {"stock": 0} mengosongkan stok dan perubahannya tetap
+tercatat pada riwayat pergerakan stok. Nilai stock harus bilangan bulat; null atau operasi remove pada /stock ditolak.
+Field lain seperti reserved, product_id, version, dan timestamp dikelola oleh service dan repository, dan patch yang
+mengubahnya ditolak dengan ErrInvalidPatch.
+
+Validasi:
+Changes.ApplyTo menerapkan perubahan pada salinan produk sehingga service dapat memvalidasi produk hasil patch dengan
+tag validate yang sama seperti Create (misalnya product_name tidak boleh dikosongkan) sebelum perubahan ditulis.
+Changes.Revert membuat perubahan kebalikannya, yang dipakai service untuk membatalkan field yang sudah ditulis jika
+koreksi stok pada patch yang sama gagal.
+*/
@@ -1,24 +1,47 @@
 package product
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
-
 // Product merepresentasikan struktur data untuk entitas produk dalam sistem.
 type Product struct {
-	ID        primitive.ObjectID `json:"product_id,omitempty" bson:"_id,omitempty"` // ID unik produk yang dihasilkan oleh MongoDB
-	Name      string             `json:"product_name" bson:"product_name"`          // Nama produk
-	Stock     int64              `json:"stock" bson:"stock"`                        // Jumlah stok produk yang tersedia
-	CreatedAt int64              `json:"created_at"`                                // Waktu (timestamp) saat produk dibuat
-	UpdatedAt int64              `json:"updated_at"`                                // Waktu (timestamp) saat produk terakhir kali diperbarui
-	DeletedAt int64              `json:"deleted_at"`                                // Waktu (timestamp) saat produk dihapus atau ditandai sebagai dihapus
+	ID        ID     `json:"product_id,omitempty" bson:"_id,omitempty"`                    // ID unik produk yang dihasilkan oleh repository
+	Code      string `json:"code" bson:"code" validate:"omitempty,max=64,sku"`             // Kode unik produk (SKU), kosong jika belum ditentukan
+	Name      string `json:"product_name" bson:"product_name" validate:"required,max=255"` // Nama produk
+	Stock     int64  `json:"stock" bson:"stock" validate:"gte=0"`                          // Jumlah stok fisik produk (on-hand)
+	Reserved  int64  `json:"reserved" bson:"reserved"`                                     // Jumlah stok yang sedang ditahan oleh reservasi aktif
+	Available int64  `json:"available" bson:"-"`                                           // Jumlah stok yang masih dapat dijual (Stock - Reserved), dihitung oleh service
+	Allocated int64  `json:"allocated" bson:"allocated"`                                   // Jumlah stok yang sudah dialokasikan ke lokasi (gudang atau toko)
+	CreatedAt int64  `json:"created_at" bson:"created_at"`                                 // Waktu (timestamp) saat produk dibuat
+	UpdatedAt int64  `json:"updated_at" bson:"updated_at"`                                 // Waktu (timestamp) saat produk terakhir kali diperbarui
+	DeletedAt int64  `json:"deleted_at" bson:"deleted_at"`                                 // Waktu (timestamp) saat produk ditandai sebagai dihapus (soft delete), 0 jika belum dihapus
+
+	Locations []LocationStock `json:"locations,omitempty" bson:"-"`                           // Rincian stok per lokasi, diisi oleh service
+	Geo       *GeoPoint       `json:"geo" bson:"geo,omitempty" validate:"omitempty,geopoint"` // Koordinat produk dalam format GeoJSON Point, nil jika belum ditentukan
+	Distance  *float64        `json:"distance_km,omitempty" bson:"-"`                         // Jarak dari titik pencarian dalam kilometer, hanya diisi pada pencarian berdasarkan jarak
+
+	Description string `json:"description" bson:"description" validate:"max=2000"` // Deskripsi produk, ikut diindeks untuk pencarian teks
+
+	Version int64 `json:"version" bson:"version"` // Nomor versi produk, bertambah setiap kali produk diubah, digunakan sebagai ETag
+}
+
+// ComputeAvailable menghitung stok yang masih dapat dijual dari stok fisik dikurangi stok yang ditahan.
+func (p *Product) ComputeAvailable() {
+	p.Available = p.Stock - p.Reserved
 }
 
 // Filter digunakan untuk menentukan kriteria pencarian atau pemfilteran produk.
 type Filter struct {
-	Page      int    `json:"page"`      // Nomor halaman saat ini untuk pagination
-	Limit     int    `json:"limit"`     // Jumlah maksimal item yang ditampilkan per halaman
-	Latitude  string `json:"latitude"`  // Koordinat lintang untuk pencarian berbasis lokasi
-	Longitude string `json:"longitude"` // Koordinat bujur untuk pencarian berbasis lokasi
-	Keyword   string `json:"keyword"`   // Kata kunci untuk mencari produk berdasarkan nama atau atribut lainnya
+	Page    int    `json:"page"`    // Nomor halaman saat ini untuk pagination
+	Limit   int    `json:"limit"`   // Jumlah maksimal item yang ditampilkan per halaman
+	Keyword string `json:"keyword"` // Kata kunci untuk mencari produk berdasarkan nama atau atribut lainnya
+
+	IncludeDeleted bool      `json:"include_deleted"` // Sertakan produk yang sudah di-soft delete dalam hasil pencarian
+	Near           *GeoQuery `json:"near"`            // Pencarian berdasarkan jarak, hasil diurutkan dari yang terdekat
+	UseCursor      bool      `json:"use_cursor"`      // Gunakan cursor pagination sebagai pengganti Page
+	Cursor         string    `json:"cursor"`          // Cursor dari halaman sebelumnya, kosong untuk halaman pertama pada mode cursor
+
+	Sort   []SortField `json:"sort"`   // Urutan hasil, kosong berarti urutan penyisipan
+	Fields []string    `json:"fields"` // Field yang dikembalikan (projection), kosong berarti seluruh field
+
+	Conditions []Condition `json:"conditions"` // Ekspresi filter yang digabungkan dengan AND, lihat condition.go
 }
 
 /*
@@ -29,22 +52,42 @@ Deklarasi Package:
 package product: Menyatakan bahwa file ini berada dalam package product. Package ini mengelompokkan kode yang berhubungan dengan produk agar lebih modular.
 Import Statements:
 
-import "go.mongodb.org/mongo-driver/bson/primitive": Mengimpor package yang digunakan untuk bekerja dengan tipe data ObjectID dari MongoDB, yang digunakan untuk mengelola ID produk.
+Package ini tidak mengimpor driver database apa pun. Tipe ID untuk produk didefinisikan sendiri oleh domain (lihat id.go).
 Struct Product:
 
 Struct Product digunakan untuk merepresentasikan data produk dalam sistem.
 Field ID:
-ID adalah ID unik produk yang dihasilkan oleh MongoDB dan disimpan dalam field _id.
+ID adalah ID unik produk yang dihasilkan oleh repository (ObjectID pada MongoDB, UUID pada PostgreSQL) dan disimpan dalam field _id pada MongoDB.
+Field Code:
+Code menyimpan kode bisnis produk (SKU). Kode bersifat unik di seluruh produk, termasuk produk yang sudah di-soft delete, dan dapat digunakan untuk mencari atau menghapus produk selain melalui ID.
 Field Name:
 Name menyimpan nama produk. Ketika data dikonversi menjadi JSON, field ini akan disebut product_name.
 Field Stock:
-Stock menyimpan jumlah stok yang tersedia untuk produk ini.
+Stock menyimpan jumlah stok fisik (on-hand) untuk produk ini. Stok hanya berubah melalui buku besar stok.
+Field Reserved:
+Reserved menyimpan jumlah stok yang sedang ditahan oleh reservasi aktif. Stok yang ditahan tidak dapat direservasi ulang atau dikurangi melalui AdjustStock.
+Field Available:
+Available adalah stok yang masih dapat dijual, yaitu Stock dikurangi Reserved. Field ini tidak disimpan di database dan dihitung dengan ComputeAvailable sebelum produk dikembalikan oleh service.
+Field Allocated:
+Allocated adalah jumlah stok seluruh lokasi produk ini. Stock tetap menjadi total stok produk, dan Stock - Allocated adalah stok yang belum dialokasikan ke lokasi mana pun. Perubahan stok tanpa lokasi dan konfirmasi reservasi hanya dapat memakai stok yang belum dialokasikan.
+Field Locations:
+Locations adalah rincian stok per lokasi. Field ini tidak disimpan bersama produk dan diisi oleh service dari data LocationStock.
+Field Geo:
+Geo menyimpan koordinat produk dalam format GeoJSON Point ({"type": "Point", "coordinates": [longitude, latitude]}). Pada MongoDB field ini diindeks dengan index 2dsphere.
+Field Distance:
+Distance adalah jarak produk dari titik pencarian dalam kilometer. Field ini tidak disimpan dan hanya diisi oleh repository ketika FindAll dipanggil dengan Filter.Near.
 Field CreatedAt:
 CreatedAt menyimpan waktu saat produk ini pertama kali dibuat dalam format UNIX timestamp.
 Field UpdatedAt:
 UpdatedAt menyimpan waktu saat produk ini terakhir kali diperbarui. Ini berguna untuk melacak perubahan yang dilakukan pada produk.
+Field Description:
+Description menyimpan deskripsi produk. Bersama Name, field ini diindeks untuk pencarian teks (lihat search.go).
+Field Version:
+Version adalah nomor versi untuk optimistic concurrency control. Repository mengisi 1 saat produk disimpan dan menambahkannya secara atomik pada setiap perubahan produk, termasuk perubahan stok, reservasi, soft delete, dan restore. Pada Update, Version yang diisi pemanggil adalah versi yang diharapkan: perubahan ditolak dengan ErrVersionConflict jika versi produk sudah berbeda, dan nilai 0 berarti tanpa pemeriksaan versi. Setelah Update berhasil, Version berisi versi yang baru.
 Field DeletedAt:
-DeletedAt menyimpan waktu saat produk ini dihapus atau ditandai sebagai dihapus, juga dalam format UNIX timestamp.
+DeletedAt menyimpan waktu saat produk ini ditandai sebagai dihapus (soft delete), juga dalam format UNIX timestamp. Nilai 0 berarti produk masih aktif. Produk yang sudah ditandai dihapus tidak lagi muncul di Find/FindAll, dapat dipulihkan (restore), dan akan dihapus permanen oleh proses purge setelah melewati masa retensi.
+Tag validate:
+Tag validate pada Product adalah aturan validasi input dari klien: product_name wajib diisi dan maksimal 255 karakter, code maksimal 64 karakter dengan format sku, stock tidak boleh negatif, description maksimal 2000 karakter, dan geo harus berupa GeoJSON Point yang valid (geopoint). Aturan sku dan geopoint didaftarkan oleh lapisan API (lihat api/product/validation.go). Field yang diisi oleh server seperti Reserved, Version, dan CreatedAt tidak memiliki tag validate.
 Struct Filter:
 
 Struct Filter digunakan untuk memfasilitasi pencarian atau pemfilteran produk berdasarkan kriteria tertentu.
@@ -52,12 +95,18 @@ Field Page:
 Page digunakan untuk pagination, menunjukkan halaman mana yang sedang diakses.
 Field Limit:
 Limit menentukan jumlah maksimal produk yang akan dikembalikan per halaman.
-Field Latitude:
-Latitude digunakan untuk menyimpan koordinat lintang (latitude) untuk pencarian berbasis lokasi.
-Field Longitude:
-Longitude digunakan untuk menyimpan koordinat bujur (longitude) dalam pencarian berbasis lokasi.
 Field Keyword:
 Keyword digunakan untuk pencarian berdasarkan kata kunci, memungkinkan pengguna mencari produk berdasarkan nama atau atribut lain yang relevan.
+Field IncludeDeleted:
+IncludeDeleted digunakan untuk menyertakan produk yang sudah di-soft delete dalam hasil FindAll. Secara default produk yang sudah dihapus tidak ditampilkan.
+Field Near:
+Near digunakan untuk pencarian berdasarkan jarak (lihat GeoQuery di geo.go). Jika diisi, hanya produk yang memiliki Geo dan berada dalam radius yang dikembalikan, diurutkan dari yang terdekat, dan setiap produk berisi Distance.
+Field UseCursor dan Cursor:
+UseCursor mengaktifkan cursor (keyset) pagination (lihat cursor.go). Pada mode ini Page diabaikan, total tidak dihitung, dan repository mengembalikan Pagination dengan NextCursor untuk halaman berikutnya. Cursor kosong berarti halaman pertama. Mode cursor tidak dapat digabungkan dengan Near.
+Field Sort dan Fields:
+Sort menentukan urutan hasil berdasarkan field yang ada di whitelist SortableFields, sedangkan Fields membatasi field yang dibaca dan dikembalikan (projection). Keduanya dibaca dari parameter sort dan fields dengan ParseSort dan ParseFields (lihat query.go). Sort tidak dapat digabungkan dengan Near maupun mode cursor karena keduanya memiliki urutan sendiri.
+Field Conditions:
+Conditions berisi ekspresi filter seperti stock[lte]=5 atau product_id[in]=a,b,c yang diparsing dengan ParseCondition (lihat condition.go). Seluruh kondisi harus terpenuhi dan diterjemahkan oleh masing-masing repository.
 Tujuan Komentar:
 Komentar dalam bahasa Indonesia ini ditambahkan untuk menjelaskan tujuan dan fungsi dari setiap bagian kode. Komentar ini penting untuk memudahkan pemahaman kode, baik bagi Anda sendiri di masa depan atau bagi pengembang lain yang bekerja dengan kode ini.
 
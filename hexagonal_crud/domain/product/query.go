@@ -0,0 +1,126 @@
+package product
+
+import (
+	"strings"
+)
+
+var (
+	// ErrInvalidSort dikembalikan ketika parameter sort berisi field yang tidak dapat diurutkan
+	// atau digunakan bersama pencarian yang memiliki urutan sendiri.
+	ErrInvalidSort = newError(ErrValidation, "invalid sort")
+
+	// ErrInvalidFields dikembalikan ketika parameter fields berisi field yang tidak dikenal.
+	ErrInvalidFields = newError(ErrValidation, "invalid fields")
+
+	// ErrInvalidExportFormat dikembalikan ketika format export tidak didukung.
+	ErrInvalidExportFormat = newError(ErrValidation, "invalid export format")
+)
+
+// SortableFields adalah daftar field (nama JSON) yang boleh digunakan pada parameter sort.
+var SortableFields = []string{"code", "product_name", "stock", "reserved", "allocated", "created_at", "updated_at"}
+
+// SelectableFields adalah daftar field (nama JSON) yang boleh dipilih pada parameter fields.
+var SelectableFields = []string{
+	"product_id", "code", "product_name", "stock", "reserved", "available", "allocated",
+	"created_at", "updated_at", "deleted_at", "locations", "geo", "distance_km", "description", "version",
+}
+
+// SortField adalah satu kriteria pengurutan hasil pencarian produk.
+type SortField struct {
+	Field string `json:"field"` // Nama JSON field yang diurutkan, salah satu dari SortableFields
+	Desc  bool   `json:"desc"`  // Urutkan dari nilai terbesar jika true
+}
+
+// ParseSort membaca parameter sort seperti "-stock,product_name". Awalan "-" berarti urutan menurun.
+// String kosong menghasilkan nil sehingga repository memakai urutan penyisipan.
+func ParseSort(raw string) ([]SortField, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var sorts []SortField
+	seen := map[string]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		sort := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !contains(SortableFields, sort.Field) || seen[sort.Field] {
+			return nil, ErrInvalidSort
+		}
+		seen[sort.Field] = true
+		sorts = append(sorts, sort)
+	}
+	return sorts, nil
+}
+
+// ParseFields membaca parameter fields seperti "product_id,product_name,stock".
+// String kosong menghasilkan nil yang berarti seluruh field dikembalikan.
+func ParseFields(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if !contains(SelectableFields, field) {
+			return nil, ErrInvalidFields
+		}
+		if !contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// Selects menentukan apakah field (nama JSON) perlu diisi untuk filter ini.
+// Filter tanpa Fields memilih seluruh field.
+func (f Filter) Selects(field string) bool {
+	if len(f.Fields) == 0 {
+		return true
+	}
+	if contains(f.Fields, field) {
+		return true
+	}
+	// Available dihitung dari Stock dan Reserved, sehingga keduanya ikut dibaca
+	if field == "stock" || field == "reserved" {
+		return contains(f.Fields, "available")
+	}
+	return false
+}
+
+// ValidateOrder memeriksa bahwa cursor pagination, pencarian berdasarkan jarak, dan parameter sort
+// tidak digunakan bersamaan, karena cursor dan jarak memiliki urutan sendiri.
+func (f Filter) ValidateOrder() error {
+	if f.UseCursor && f.Near != nil {
+		return ErrInvalidCursor
+	}
+	if len(f.Sort) > 0 && (f.UseCursor || f.Near != nil) {
+		return ErrInvalidSort
+	}
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Penjelasan Fungsi Kode:
+Variabel SortableFields dan SelectableFields:
+
+Kedua daftar ini adalah whitelist untuk parameter sort dan fields pada GET /product. Nama yang digunakan adalah nama JSON sehingga klien memakai nama yang sama dengan response, dan setiap repository menerjemahkannya ke nama field atau kolom miliknya sendiri. Field di luar whitelist ditolak dengan ErrInvalidSort atau ErrInvalidFields yang dipetakan ke 422 oleh lapisan API.
+Fungsi ParseSort:
+
+Beberapa kriteria dipisahkan dengan koma dan diterapkan berurutan. Repository selalu menambahkan urutan penyisipan sebagai kriteria terakhir agar hasil pagination tetap stabil ketika nilai yang diurutkan sama.
+Fungsi ParseFields dan Selects:
+
+Projection diteruskan sampai ke repository sehingga hanya field yang diminta yang dibaca dari database. Selects dipakai oleh repository dan service untuk memeriksa apakah sebuah field perlu diisi, termasuk Stock dan Reserved yang dibutuhkan untuk menghitung Available. ID produk selalu dibaca oleh repository karena diperlukan untuk cursor dan rincian stok per lokasi.
+Fungsi ValidateOrder:
+
+Cursor pagination mengikuti urutan penyisipan dan pencarian berdasarkan jarak mengurutkan hasil dari yang terdekat, sehingga keduanya tidak dapat digabungkan satu sama lain atau dengan parameter sort. Pemeriksaan ini dipakai oleh FindAll dan Export, dan oleh endpoint export sebelum response mulai dikirim.
+*/
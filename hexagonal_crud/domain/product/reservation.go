@@ -0,0 +1,39 @@
+package product
+
+// Status reservasi stok.
+const (
+	ReservationActive    = "active"    // Stok sedang ditahan dan belum melewati batas waktu
+	ReservationConfirmed = "confirmed" // Reservasi dikonfirmasi dan stok sudah dikurangi
+	ReservationReleased  = "released"  // Reservasi dibatalkan oleh pengguna
+	ReservationExpired   = "expired"   // Reservasi dilepas otomatis oleh sweeper karena melewati batas waktu
+)
+
+// Reservation merepresentasikan penahanan sejumlah stok produk selama pesanan sedang dikonfirmasi.
+type Reservation struct {
+	ID        ID     `json:"reservation_id,omitempty" bson:"_id,omitempty"` // ID unik reservasi
+	ProductID ID     `json:"product_id" bson:"product_id"`                  // ID produk yang stoknya ditahan
+	Quantity  int64  `json:"quantity" bson:"quantity"`                      // Jumlah unit yang ditahan
+	Status    string `json:"status" bson:"status"`                          // Status reservasi (active, confirmed, released, expired)
+	ExpiresAt int64  `json:"expires_at" bson:"expires_at"`                  // Waktu (timestamp) saat reservasi aktif kedaluwarsa
+	CreatedAt int64  `json:"created_at" bson:"created_at"`                  // Waktu (timestamp) saat reservasi dibuat
+	UpdatedAt int64  `json:"updated_at" bson:"updated_at"`                  // Waktu (timestamp) saat status reservasi terakhir berubah
+}
+
+// ReservationRequest adalah permintaan untuk menahan stok produk.
+type ReservationRequest struct {
+	Quantity   int64 `json:"quantity"`    // Jumlah unit yang ditahan, harus lebih dari 0
+	TTLSeconds int64 `json:"ttl_seconds"` // Lama reservasi dalam detik, 0 berarti menggunakan nilai default
+}
+
+/*
+Penjelasan Fungsi Kode:
+Status Reservasi:
+
+Reservasi dibuat dengan status active. Dari status active, reservasi dapat berpindah ke confirmed (stok dikurangi), released (dibatalkan pengguna), atau expired (dilepas oleh sweeper). Reservasi yang tidak lagi active tidak dapat berubah status lagi.
+Struct Reservation:
+
+Reservation menahan sejumlah unit stok sehingga unit tersebut tidak dapat dijual ke pesanan lain. Selama reservasi aktif, jumlahnya ditambahkan ke Product.Reserved sehingga Product.Available berkurang, sedangkan Product.Stock (stok fisik) baru berkurang saat reservasi dikonfirmasi.
+Struct ReservationRequest:
+
+ReservationRequest adalah data yang dikirim melalui endpoint POST /product/:id/reservations. TTLSeconds menentukan berapa lama stok ditahan sebelum dilepas otomatis.
+*/
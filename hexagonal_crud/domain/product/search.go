@@ -0,0 +1,99 @@
+package product
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidSearch dikembalikan ketika kata kunci pencarian teks kosong atau terlalu panjang.
+var ErrInvalidSearch = newError(ErrValidation, "invalid search query")
+
+const (
+	maxSearchTerms  = 16  // Jumlah maksimum kata yang digunakan dari sebuah kata kunci pencarian
+	maxSearchLength = 256 // Panjang maksimum kata kunci pencarian dalam byte
+)
+
+// SearchQuery berisi parameter pencarian teks (full-text) pada nama dan deskripsi produk.
+type SearchQuery struct {
+	Query string `json:"q"`     // Kata kunci pencarian dari pengguna
+	Page  int    `json:"page"`  // Nomor halaman saat ini untuk pagination
+	Limit int    `json:"limit"` // Jumlah maksimal hasil per halaman
+}
+
+// Terms memecah kata kunci menjadi kata-kata huruf kecil yang hanya berisi huruf dan angka, tanpa duplikat.
+// Karakter lain (tanda kutip, operator, metakarakter regex) dibuang sehingga aman diteruskan ke database.
+func (q SearchQuery) Terms() []string {
+	var terms []string
+	for _, term := range SplitWords(q.Query) {
+		if !contains(terms, term) && len(terms) < maxSearchTerms {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// Validate memeriksa bahwa kata kunci berisi setidaknya satu kata dan tidak lebih dari 256 byte.
+func (q SearchQuery) Validate() error {
+	if len(q.Query) > maxSearchLength || len(q.Terms()) == 0 {
+		return ErrInvalidSearch
+	}
+	return nil
+}
+
+// SplitWords memecah teks menjadi kata-kata huruf kecil yang hanya berisi huruf dan angka.
+func SplitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// SearchHit adalah satu hasil pencarian teks beserta skor relevansi dan potongan teks yang disorot.
+type SearchHit struct {
+	Product    *Product          `json:"product"`              // Produk yang cocok
+	Score      float64           `json:"score"`                // Skor relevansi dari repository, semakin besar semakin relevan
+	Highlights map[string]string `json:"highlights,omitempty"` // Teks field (nama JSON) dengan kata yang cocok dibungkus <em>
+}
+
+// Highlight meng-escape teks sebagai HTML lalu membungkus setiap kata yang cocok dengan terms menggunakan <em>.
+// Nilai ok bernilai false jika tidak ada kata yang cocok.
+func Highlight(text string, terms []string) (highlighted string, ok bool) {
+	var b strings.Builder
+	word := []rune{}
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		escaped := html.EscapeString(string(word))
+		if contains(terms, strings.ToLower(string(word))) {
+			b.WriteString("<em>" + escaped + "</em>")
+			ok = true
+		} else {
+			b.WriteString(escaped)
+		}
+		word = word[:0]
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			word = append(word, r)
+			continue
+		}
+		flush()
+		b.WriteString(html.EscapeString(string(r)))
+	}
+	flush()
+	return b.String(), ok
+}
+
+/*
+Penjelasan Fungsi Kode:
+Struct SearchQuery:
+
+SearchQuery digunakan oleh GET /product/search?q=. Berbeda dengan Filter.Keyword yang mencocokkan sebagian nama produk, pencarian teks mencocokkan kata utuh pada nama dan deskripsi produk menggunakan index teks (index text pada MongoDB dan tsvector dengan index GIN pada PostgreSQL).
+Fungsi Terms:
+
+Input pengguna tidak pernah diteruskan langsung sebagai pola regex atau sintaks pencarian database. Terms hanya menyisakan kata yang berisi huruf dan angka, sehingga operator seperti tanda kutip atau tanda minus pada $text MongoDB dan operator tsquery PostgreSQL tidak dapat disisipkan. Produk cocok jika memuat salah satu kata.
+Struct SearchHit dan Fungsi Highlight:
+
+Skor relevansi dihitung oleh masing-masing repository (textScore pada MongoDB, ts_rank pada PostgreSQL, dan jumlah kata yang cocok pada repository memory) sehingga hanya urutannya yang dapat dibandingkan antar backend. Kata pada nama produk diberi bobot lebih besar dari kata pada deskripsi. Highlight dibuat oleh service dengan meng-escape teks terlebih dahulu agar aman ditampilkan sebagai HTML.
+*/
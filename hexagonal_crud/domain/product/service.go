@@ -3,14 +3,15 @@ package product
 import (
 	"CRUD_Hexagonal/utils"
 	"context"
-
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // ProductInterface mendefinisikan kontrak (interface) untuk layanan (service) produk.
 type ProductInterface interface {
 	// Find mencari produk berdasarkan ID dan mengembalikan produk jika ditemukan.
-	Find(ctx context.Context, id string) (*Product, error)
+	Find(ctx context.Context, id ID) (*Product, error)
+
+	// FindByCode mencari produk berdasarkan kode produk (SKU) dan mengembalikan produk jika ditemukan.
+	FindByCode(ctx context.Context, code string) (*Product, error)
 
 	// Store menyimpan produk baru ke dalam database dan mengembalikan produk yang disimpan.
 	Store(ctx context.Context, product *Product) (*Product, error)
@@ -18,35 +19,223 @@ type ProductInterface interface {
 	// Update memperbarui data produk yang ada di dalam database.
 	Update(ctx context.Context, dataStore *Product) error
 
+	// Patch menerapkan dokumen JSON Merge Patch atau JSON Patch pada produk dan mengembalikan produk hasilnya.
+	// version adalah versi produk yang diharapkan, 0 berarti tanpa pemeriksaan versi.
+	Patch(ctx context.Context, id ID, document PatchDocument, version int64) (*Product, error)
+
 	// FindAll mencari semua produk berdasarkan filter yang diberikan dan mengembalikan daftar produk serta pagination.
 	FindAll(ctx context.Context, filter Filter) ([]*Product, *utils.Pagination, error)
 
-	// Delete menghapus produk dari database berdasarkan kode produk.
-	Delete(ctx context.Context, code string) error
+	// Export membaca semua produk yang sesuai dengan filter tanpa pagination dan memanggil fn untuk setiap produk
+	// secara berurutan. Error dari fn menghentikan export dan dikembalikan apa adanya.
+	Export(ctx context.Context, filter Filter, fn func(*Product) error) error
+
+	// Search mencari produk berdasarkan kata pada nama dan deskripsi, diurutkan dari yang paling relevan,
+	// beserta skor relevansi dan teks yang disorot. ErrInvalidSearch dikembalikan jika kata kunci tidak valid.
+	Search(ctx context.Context, query SearchQuery) ([]*SearchHit, *utils.Pagination, error)
+
+	// Delete menandai produk sebagai dihapus (soft delete) berdasarkan kode produk.
+	// version adalah versi produk yang diharapkan, 0 berarti tanpa pemeriksaan versi.
+	Delete(ctx context.Context, code string, version int64) error
+
+	// DeleteById menandai produk sebagai dihapus (soft delete) berdasarkan ID produk.
+	// version adalah versi produk yang diharapkan, 0 berarti tanpa pemeriksaan versi.
+	DeleteById(ctx context.Context, id ID, version int64) error
+
+	// Restore memulihkan produk yang sudah di-soft delete dan mengembalikan produk yang dipulihkan.
+	Restore(ctx context.Context, id ID) (*Product, error)
+
+	// Purge menghapus permanen produk yang sudah di-soft delete lebih lama dari masa retensi
+	// dan mengembalikan jumlah produk yang dihapus.
+	Purge(ctx context.Context) (int64, error)
+
+	// AdjustStock mengubah stok produk sebesar jumlah bertanda dengan kode alasan tertentu
+	// dan mengembalikan pergerakan stok yang dicatat.
+	AdjustStock(ctx context.Context, id ID, adjustment StockAdjustment) (*StockMovement, error)
+
+	// FindMovements mengembalikan riwayat pergerakan stok produk, dari yang terbaru, beserta pagination.
+	FindMovements(ctx context.Context, id ID, filter MovementFilter) ([]*StockMovement, *utils.Pagination, error)
+
+	// Reserve menahan sejumlah stok produk selama waktu tertentu.
+	Reserve(ctx context.Context, id ID, request ReservationRequest) (*Reservation, error)
+
+	// FindReservation mencari reservasi berdasarkan ID.
+	FindReservation(ctx context.Context, reservationID ID) (*Reservation, error)
+
+	// ConfirmReservation mengonfirmasi reservasi aktif dan mengurangi stok produk.
+	ConfirmReservation(ctx context.Context, reservationID ID) (*Reservation, error)
+
+	// ReleaseReservation membatalkan reservasi aktif dan melepas stok yang ditahan.
+	ReleaseReservation(ctx context.Context, reservationID ID) (*Reservation, error)
+
+	// ReleaseExpiredReservations melepas reservasi aktif yang sudah kedaluwarsa
+	// dan mengembalikan jumlah reservasi yang dilepas.
+	ReleaseExpiredReservations(ctx context.Context) (int64, error)
+
+	// CreateLocation menyimpan lokasi (gudang atau toko) baru.
+	CreateLocation(ctx context.Context, location *Location) (*Location, error)
+
+	// FindLocation mencari lokasi berdasarkan ID.
+	FindLocation(ctx context.Context, locationID ID) (*Location, error)
+
+	// FindLocations mengembalikan semua lokasi.
+	FindLocations(ctx context.Context) ([]*Location, error)
+
+	// FindStockByProduct mengembalikan stok sebuah produk pada setiap lokasi.
+	FindStockByProduct(ctx context.Context, id ID) ([]*LocationStock, error)
+
+	// FindStockByLocation mengembalikan stok semua produk pada sebuah lokasi.
+	FindStockByLocation(ctx context.Context, locationID ID) ([]*LocationStock, error)
+
+	// TransferStock memindahkan stok sebuah produk antar lokasi secara atomik.
+	TransferStock(ctx context.Context, id ID, transfer StockTransfer) (*StockTransfer, error)
 
-	// DeleteById menghapus produk dari database berdasarkan ID produk.
-	DeleteById(ctx context.Context, id string) error
+	// BulkStore menyimpan banyak produk sekaligus. Jika upsert bernilai true, produk dengan kode yang sudah digunakan
+	// diperbarui, selain itu kode yang sudah digunakan menghasilkan ErrCodeConflict pada hasil item tersebut.
+	// Hasil setiap item dikembalikan sesuai urutan, dan error hanya dikembalikan jika seluruh batch gagal.
+	BulkStore(ctx context.Context, products []*Product, upsert bool) ([]*BulkResult, error)
+
+	// BulkDelete menandai banyak produk sebagai dihapus (soft delete) berdasarkan ID atau kode setiap item.
+	BulkDelete(ctx context.Context, products []*Product) ([]*BulkResult, error)
+
+	// ClaimIdempotencyKey mengklaim Idempotency-Key untuk request dengan fingerprint tertentu. Hasilnya nil jika key
+	// baru diklaim dan request harus diproses, atau record dengan response tersimpan jika request ini adalah pengulangan.
+	// ErrIdempotencyKeyReused dikembalikan jika key digunakan untuk request lain, dan ErrIdempotencyKeyInProgress
+	// jika request pertama dengan key tersebut masih diproses.
+	ClaimIdempotencyKey(ctx context.Context, key string, fingerprint string) (*IdempotencyRecord, error)
+
+	// CompleteIdempotencyKey menyimpan response request untuk key yang sudah diklaim.
+	CompleteIdempotencyKey(ctx context.Context, record *IdempotencyRecord) error
+
+	// ReleaseIdempotencyKey melepas key yang sudah diklaim tanpa menyimpan response, sehingga request dapat diulang.
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+
+	// ImportProducts memeriksa header file import, menyimpan job baru, lalu memproses baris-barisnya di latar belakang.
+	// Job dikembalikan dengan status pending; ErrInvalidImport dikembalikan jika file tidak dapat diproses sama sekali.
+	ImportProducts(ctx context.Context, request ImportRequest) (*ImportJob, error)
+
+	// FindImportJob mencari job import berdasarkan ID beserta progresnya.
+	FindImportJob(ctx context.Context, id ID) (*ImportJob, error)
 }
 
 // Repository mendefinisikan kontrak (interface) untuk repository produk yang akan berinteraksi langsung dengan database.
 type Repository interface {
 	// Find mencari produk berdasarkan ID dan mengembalikan produk jika ditemukan.
-	Find(ctx context.Context, id string) (*Product, error)
+	Find(ctx context.Context, id ID) (*Product, error)
+
+	// FindByCode mencari produk berdasarkan kode produk (SKU) dan mengembalikan produk jika ditemukan.
+	FindByCode(ctx context.Context, code string) (*Product, error)
 
-	// Store menyimpan produk baru ke dalam database dan mengembalikan ObjectID dari produk yang disimpan.
-	Store(ctx context.Context, dataStore *Product) (primitive.ObjectID, error)
+	// Store menyimpan produk baru ke dalam database dan mengembalikan ID dari produk yang disimpan.
+	// ErrCodeConflict dikembalikan jika kode produk sudah digunakan.
+	Store(ctx context.Context, dataStore *Product) (ID, error)
 
 	// Update memperbarui data produk yang ada di dalam database.
+	// ErrCodeConflict dikembalikan jika kode produk sudah digunakan oleh produk lain, dan ErrVersionConflict
+	// jika dataStore.Version bukan 0 dan tidak sama dengan versi produk. Versi baru ditulis ke dataStore.Version.
 	Update(ctx context.Context, dataStore *Product) error
 
+	// Patch menerapkan changes pada produk yang belum di-soft delete, termasuk nilai nol dan nil, lalu menaikkan versinya.
+	// ErrCodeConflict dikembalikan jika kode produk sudah digunakan oleh produk lain, ErrInvalidPatch jika changes
+	// berisi field yang tidak dapat diubah, dan ErrVersionConflict jika version bukan 0 dan tidak sama dengan versi produk.
+	Patch(ctx context.Context, id ID, changes Changes, version int64) error
+
 	// FindAll mencari semua produk berdasarkan filter yang diberikan dan mengembalikan daftar produk serta pagination.
 	FindAll(ctx context.Context, filter Filter) ([]*Product, *utils.Pagination, error)
 
-	// Delete menghapus produk dari database berdasarkan kode produk.
-	Delete(ctx context.Context, code string) error
+	// Export membaca semua produk yang sesuai dengan filter (mengabaikan pagination) satu per satu dan memanggil fn
+	// untuk setiap produk, tanpa menampung seluruh hasil di memori. Error dari fn menghentikan export.
+	Export(ctx context.Context, filter Filter, fn func(*Product) error) error
+
+	// Search mencari produk yang belum di-soft delete dan memuat salah satu kata pada query.Terms() di nama
+	// atau deskripsi, diurutkan dari skor relevansi tertinggi. Highlights diisi oleh service.
+	Search(ctx context.Context, query SearchQuery) ([]*SearchHit, *utils.Pagination, error)
+
+	// Delete menandai produk sebagai dihapus (soft delete) berdasarkan kode produk.
+	// ErrVersionConflict dikembalikan jika version bukan 0 dan tidak sama dengan versi produk.
+	Delete(ctx context.Context, code string, version int64) error
+
+	// DeleteById menandai produk sebagai dihapus (soft delete) dengan mengisi DeletedAt.
+	// ErrVersionConflict dikembalikan jika version bukan 0 dan tidak sama dengan versi produk.
+	DeleteById(ctx context.Context, id ID, version int64) error
+
+	// Restore mengosongkan kembali DeletedAt pada produk yang sudah di-soft delete.
+	Restore(ctx context.Context, id ID) error
+
+	// Purge menghapus permanen produk yang DeletedAt-nya lebih lama atau sama dengan deletedBefore (UNIX timestamp)
+	// dan mengembalikan jumlah dokumen yang dihapus.
+	Purge(ctx context.Context, deletedBefore int64) (int64, error)
+
+	// Remove menghapus permanen satu produk berdasarkan ID tanpa memeriksa DeletedAt. Fungsi ini dipakai service
+	// untuk membatalkan Store yang langkah berikutnya gagal. ErrProductNotFound dikembalikan jika produk tidak ada.
+	Remove(ctx context.Context, id ID) error
+
+	// AdjustStock menambahkan movement.Quantity ke stok produk secara atomik dan mencatat movement ke buku besar.
+	// ErrInsufficientStock dikembalikan jika stok akan menjadi negatif, dan ErrVersionConflict jika movement.ExpectedVersion
+	// diisi tetapi berbeda dengan versi produk saat ini.
+	AdjustStock(ctx context.Context, movement *StockMovement) (*StockMovement, error)
+
+	// FindMovements mengembalikan riwayat pergerakan stok produk, dari yang terbaru, beserta pagination.
+	FindMovements(ctx context.Context, productID ID, filter MovementFilter) ([]*StockMovement, *utils.Pagination, error)
+
+	// Reserve menambahkan reservation.Quantity ke stok yang ditahan produk secara atomik, hanya jika
+	// stok yang tersedia mencukupi, lalu menyimpan reservasi. ErrInsufficientStock dikembalikan jika tidak mencukupi.
+	Reserve(ctx context.Context, reservation *Reservation) (*Reservation, error)
+
+	// FindReservation mencari reservasi berdasarkan ID. ErrReservationNotFound dikembalikan jika tidak ada.
+	FindReservation(ctx context.Context, reservationID ID) (*Reservation, error)
+
+	// ConfirmReservation mengubah reservasi aktif yang belum kedaluwarsa pada waktu confirmedAt menjadi confirmed,
+	// mengurangi stok dan stok yang ditahan produk, lalu mencatat pergerakan stok dengan kode alasan sale.
+	ConfirmReservation(ctx context.Context, reservationID ID, confirmedAt int64) (*Reservation, error)
+
+	// ReleaseReservation mengubah reservasi aktif menjadi status released atau expired dan melepas stok yang ditahan.
+	ReleaseReservation(ctx context.Context, reservationID ID, status string, releasedAt int64) (*Reservation, error)
 
-	// DeleteById menghapus produk dari database berdasarkan ID produk.
-	DeleteById(ctx context.Context, id string) error
+	// FindExpiredReservations mengembalikan paling banyak limit reservasi aktif yang kedaluwarsa pada atau sebelum waktu before.
+	FindExpiredReservations(ctx context.Context, before int64, limit int) ([]*Reservation, error)
+
+	// StoreLocation menyimpan lokasi baru dan mengembalikan ID-nya. ErrLocationCodeConflict dikembalikan jika kode sudah digunakan.
+	StoreLocation(ctx context.Context, location *Location) (ID, error)
+
+	// FindLocation mencari lokasi berdasarkan ID. ErrLocationNotFound dikembalikan jika tidak ada.
+	FindLocation(ctx context.Context, locationID ID) (*Location, error)
+
+	// FindLocations mengembalikan semua lokasi sesuai urutan pembuatan.
+	FindLocations(ctx context.Context) ([]*Location, error)
+
+	// FindLocationStocks mengembalikan stok per lokasi yang sesuai dengan filter.
+	FindLocationStocks(ctx context.Context, filter LocationStockFilter) ([]*LocationStock, error)
+
+	// TransferStock memindahkan stok antar lokasi (atau dari/ke stok yang belum dialokasikan) secara atomik
+	// dan mencatat pergerakannya. ErrInsufficientStock dikembalikan jika stok asal tidak mencukupi.
+	TransferStock(ctx context.Context, transfer *StockTransfer) (*StockTransfer, error)
+
+	// BulkWrite menjalankan operations tanpa urutan dan mengembalikan satu BulkResult untuk setiap operasi sesuai
+	// urutannya. Kegagalan satu operasi dicatat pada hasilnya tanpa membatalkan operasi lain; error hanya dikembalikan
+	// jika seluruh batch gagal. Produk yang dibuat dimulai dari versi 1 dan setiap perubahan menaikkan versinya.
+	BulkWrite(ctx context.Context, operations []BulkOperation) ([]*BulkResult, error)
+
+	// ClaimIdempotencyKey menghapus record yang ExpiresAt-nya sudah lewat pada waktu record.CreatedAt, lalu menyimpan
+	// record sebagai klaim baru jika key belum digunakan dan mengembalikan nil. Jika key sudah digunakan, record yang
+	// tersimpan dikembalikan tanpa perubahan. Pemeriksaan dan penyimpanan dilakukan secara atomik.
+	ClaimIdempotencyKey(ctx context.Context, record *IdempotencyRecord) (*IdempotencyRecord, error)
+
+	// CompleteIdempotencyKey menyimpan Status, Header, Body, dan ExpiresAt pada record yang masih diproses.
+	// Record yang sudah selesai, dilepas, atau kedaluwarsa tidak diubah.
+	CompleteIdempotencyKey(ctx context.Context, record *IdempotencyRecord) error
+
+	// ReleaseIdempotencyKey menghapus record dengan key tersebut jika masih diproses.
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+
+	// StoreImportJob menyimpan job import baru dan mengembalikannya dengan ID yang dibuat oleh repository.
+	StoreImportJob(ctx context.Context, job *ImportJob) (*ImportJob, error)
+
+	// UpdateImportJob menyimpan status, progres, dan hasil job import. ErrJobNotFound dikembalikan jika job tidak ada.
+	UpdateImportJob(ctx context.Context, job *ImportJob) error
+
+	// FindImportJob mencari job import berdasarkan ID. ErrJobNotFound dikembalikan jika tidak ada.
+	FindImportJob(ctx context.Context, id ID) (*ImportJob, error)
 }
 
 /*
@@ -56,51 +245,135 @@ Deklarasi Package:
 package product: Menyatakan bahwa file ini adalah bagian dari package product. Package ini mengelompokkan kode yang berhubungan dengan manajemen produk.
 Import Statements:
 
-import ("CRUD_Hexagonal/utils" "context" "go.mongodb.org/mongo-driver/bson/primitive"): Mengimpor package yang dibutuhkan seperti utils untuk pagination, context untuk manajemen konteks, dan primitive untuk menangani tipe data ObjectID dari MongoDB.
+import ("CRUD_Hexagonal/utils" "context"): Mengimpor package yang dibutuhkan seperti utils untuk pagination dan context untuk manajemen konteks. Domain tidak bergantung pada driver database mana pun.
 Interface ProductInterface:
 
 ProductInterface adalah kontrak untuk layanan produk yang mendefinisikan fungsi-fungsi yang harus diimplementasikan oleh service yang mengelola produk.
 
 Find:
 
-Find(ctx context.Context, id string) (*Product, error): Fungsi ini bertanggung jawab untuk mencari produk berdasarkan id dan mengembalikan produk tersebut jika ditemukan.
+Find(ctx context.Context, id ID) (*Product, error): Fungsi ini bertanggung jawab untuk mencari produk berdasarkan id dan mengembalikan produk tersebut jika ditemukan.
+FindByCode:
+
+FindByCode(ctx context.Context, code string) (*Product, error): Fungsi ini mencari produk berdasarkan kode produk (SKU).
 Store:
 
 Store(ctx context.Context, product *Product) (*Product, error): Fungsi ini digunakan untuk menyimpan produk baru ke dalam database dan mengembalikan produk yang baru disimpan.
 Update:
 
 Update(ctx context.Context, dataStore *Product) error: Fungsi ini memperbarui informasi produk yang ada di dalam database.
+Patch:
+
+Patch(ctx context.Context, id ID, document PatchDocument, version int64) (*Product, error): Fungsi ini menerapkan dokumen JSON Merge Patch (RFC 7396) atau JSON Patch (RFC 6902) pada produk. Berbeda dengan Update, nilai kosong dan null ikut diterapkan sehingga field seperti description atau geo dapat dikosongkan. Perubahan stock (termasuk menjadi 0) dicatat sebagai pergerakan stok correction sebesar selisih stok baru dan stok saat ini.
 FindAll:
 
 FindAll(ctx context.Context, filter Filter) ([]*Product, *utils.Pagination, error): Fungsi ini mencari semua produk berdasarkan filter yang diberikan dan mengembalikan daftar produk serta informasi pagination.
+Export:
+
+Export(ctx context.Context, filter Filter, fn func(*Product) error) error: Fungsi ini digunakan oleh endpoint GET /product/export untuk membaca seluruh katalog dengan filter yang sama seperti FindAll. Setiap produk diteruskan ke fn begitu dibaca sehingga response dapat dikirim secara streaming.
+Search:
+
+Search(ctx context.Context, query SearchQuery) ([]*SearchHit, *utils.Pagination, error): Fungsi ini menjalankan pencarian teks pada nama dan deskripsi produk, lalu mengembalikan hasil yang diurutkan berdasarkan relevansi beserta skor dan highlight.
 Delete:
 
-Delete(ctx context.Context, code string) error: Fungsi ini menghapus produk dari database berdasarkan kode produk.
+Delete(ctx context.Context, code string, version int64) error: Fungsi ini menandai produk sebagai dihapus (soft delete) berdasarkan kode produk.
 DeleteById:
 
-DeleteById(ctx context.Context, id string) error: Fungsi ini menghapus produk dari database berdasarkan ID produk.
+DeleteById(ctx context.Context, id ID, version int64) error: Fungsi ini menandai produk sebagai dihapus (soft delete) berdasarkan ID produk. Data produk tetap ada di database sampai di-purge. Parameter version pada Delete dan DeleteById, serta Product.Version pada Update, adalah versi yang diharapkan untuk optimistic concurrency control.
+Restore:
+
+Restore(ctx context.Context, id ID) (*Product, error): Fungsi ini memulihkan produk yang sudah di-soft delete dan mengembalikan data produk tersebut.
+Purge:
+
+Purge(ctx context.Context) (int64, error): Fungsi ini menghapus permanen produk yang sudah di-soft delete lebih lama dari masa retensi yang dikonfigurasi, lalu mengembalikan jumlah produk yang dihapus.
+AdjustStock:
+
+AdjustStock(ctx context.Context, id ID, adjustment StockAdjustment) (*StockMovement, error): Fungsi ini memvalidasi permintaan perubahan stok lalu mengubah stok produk dan mencatat pergerakannya. Stok tidak lagi diubah melalui Update.
+FindMovements:
+
+FindMovements(ctx context.Context, id ID, filter MovementFilter) ([]*StockMovement, *utils.Pagination, error): Fungsi ini mengembalikan riwayat pergerakan stok produk.
+Reserve, FindReservation, ConfirmReservation, ReleaseReservation:
+
+Fungsi-fungsi ini mengelola siklus hidup reservasi stok untuk proses checkout: stok ditahan selama TTL, lalu dikonfirmasi (stok berkurang) atau dilepas.
+ReleaseExpiredReservations:
+
+ReleaseExpiredReservations(ctx context.Context) (int64, error): Fungsi ini dipanggil secara berkala oleh sweeper di latar belakang untuk melepas reservasi yang melewati batas waktu.
+CreateLocation, FindLocation, FindLocations, FindStockByProduct, FindStockByLocation, TransferStock:
+
+Fungsi-fungsi ini mengelola stok di beberapa gudang dan toko. Total stok tetap berada pada Product.Stock, sedangkan rincian per lokasi dapat dibaca berdasarkan produk atau berdasarkan lokasi dan dipindahkan antar lokasi tanpa mengubah total.
+BulkStore, BulkDelete:
+
+Fungsi-fungsi ini digunakan untuk sinkronisasi banyak produk sekaligus (lihat bulk.go). Setiap item diperiksa seperti Store, Update, dan Delete; item yang tidak valid ditandai gagal tanpa membatalkan item lain.
+ClaimIdempotencyKey, CompleteIdempotencyKey, ReleaseIdempotencyKey:
+
+Fungsi-fungsi ini digunakan oleh middleware Idempotency-Key pada lapisan API. Key yang diklaim berlaku selama masa idempotency (IDEMPOTENCY_KEY_TTL) setelah response disimpan, sedangkan klaim yang belum selesai hanya berlaku sebentar agar key tidak terkunci jika proses berhenti di tengah request.
+ImportProducts, FindImportJob:
+
+Fungsi-fungsi ini digunakan untuk import produk dari file CSV atau XLSX (lihat import.go). Baris diproses secara asinkron dalam beberapa batch melalui BulkStore, dan progres job disimpan setelah setiap batch sehingga dapat dipantau melalui GET /jobs/:id.
 Interface Repository:
 
 Repository adalah kontrak untuk repository produk yang berinteraksi langsung dengan database. Interface ini mendefinisikan fungsi-fungsi yang harus diimplementasikan oleh repository.
 
 Find:
 
-Find(ctx context.Context, id string) (*Product, error): Sama seperti di ProductInterface, fungsi ini mencari produk berdasarkan ID dan mengembalikan produk jika ditemukan.
+Find(ctx context.Context, id ID) (*Product, error): Sama seperti di ProductInterface, fungsi ini mencari produk berdasarkan ID dan mengembalikan produk jika ditemukan.
+FindByCode:
+
+FindByCode(ctx context.Context, code string) (*Product, error): Fungsi ini mencari produk yang belum dihapus berdasarkan kode produk (SKU).
 Store:
 
-Store(ctx context.Context, dataStore *Product) (primitive.ObjectID, error): Fungsi ini menyimpan produk baru ke dalam database dan mengembalikan ObjectID dari produk yang baru disimpan.
+Store(ctx context.Context, dataStore *Product) (ID, error): Fungsi ini menyimpan produk baru ke dalam database dan mengembalikan ID domain dari produk yang baru disimpan. Setiap repository menerjemahkan ID penyimpanannya sendiri (ObjectID, UUID) menjadi ID.
 Update:
 
-Update(ctx context.Context, dataStore *Product) error: Fungsi ini memperbarui data produk yang ada di dalam database.
+Update(ctx context.Context, dataStore *Product) error: Fungsi ini memperbarui data produk yang ada di dalam database. Field yang bernilai kosong dilewati.
+Patch:
+
+Patch(ctx context.Context, id ID, changes Changes, version int64) error: Fungsi ini memperbarui tepat field yang ada pada changes, termasuk nilai nol dan nil, dalam satu operasi atomik bersama pemeriksaan dan penambahan versi.
 FindAll:
 
 FindAll(ctx context.Context, filter Filter) ([]*Product, *utils.Pagination, error): Fungsi ini mencari semua produk berdasarkan filter yang diberikan dan mengembalikan daftar produk serta pagination.
+Export:
+
+Export(ctx context.Context, filter Filter, fn func(*Product) error) error: Fungsi ini membaca produk satu per satu, MongoDB menggunakan cursor dari aggregation sedangkan repository lain membaca hasil FindAll per halaman.
+Search:
+
+Search(ctx context.Context, query SearchQuery) ([]*SearchHit, *utils.Pagination, error): Fungsi ini menjalankan pencarian teks menggunakan index teks milik database dan mengembalikan skor relevansi untuk setiap produk.
 Delete:
 
-Delete(ctx context.Context, code string) error: Fungsi ini menghapus produk dari database berdasarkan kode produk.
+Delete(ctx context.Context, code string, version int64) error: Fungsi ini menandai produk sebagai dihapus (soft delete) berdasarkan kode produk.
 DeleteById:
 
-DeleteById(ctx context.Context, id string) error: Fungsi ini menghapus produk dari database berdasarkan ID produk.
+DeleteById(ctx context.Context, id ID, version int64) error: Fungsi ini menandai produk sebagai dihapus dengan mengisi DeletedAt, tanpa menghapus dokumennya. Pemeriksaan versi dan penambahan versi dilakukan dalam satu operasi atomik sehingga dua perubahan bersamaan tidak saling menimpa.
+Restore:
+
+Restore(ctx context.Context, id ID) error: Fungsi ini mengosongkan kembali DeletedAt sehingga produk kembali aktif.
+Purge:
+
+Purge(ctx context.Context, deletedBefore int64) (int64, error): Fungsi ini menghapus permanen produk yang DeletedAt-nya tidak lebih baru dari deletedBefore.
+Remove:
+
+Remove(ctx context.Context, id ID) error: Fungsi ini menghapus permanen satu produk. Store pada service menyimpan produk lalu mencatat stok awal ke buku besar dalam dua langkah; jika pencatatan stok gagal, produk dihapus kembali dengan Remove agar tidak ada produk dengan stok tanpa catatan pergerakan.
+AdjustStock:
+
+AdjustStock(ctx context.Context, movement *StockMovement) (*StockMovement, error): Fungsi ini mengubah stok produk secara atomik (seperti $inc pada MongoDB) dan menolak perubahan yang membuat stok negatif, lalu menambahkan catatan ke koleksi pergerakan stok yang bersifat append-only. Jika ExpectedVersion diisi, versi produk diperiksa pada operasi atomik yang sama sehingga koreksi yang dihitung dari stok yang dibaca tidak menimpa perubahan lain.
+FindMovements:
+
+FindMovements(ctx context.Context, productID ID, filter MovementFilter) ([]*StockMovement, *utils.Pagination, error): Fungsi ini membaca riwayat pergerakan stok dengan pagination.
+Reserve, FindReservation, ConfirmReservation, ReleaseReservation, FindExpiredReservations:
+
+Fungsi-fungsi ini menyimpan reservasi stok. Perubahan status reservasi hanya berlaku untuk reservasi yang masih active sehingga satu reservasi tidak dapat dikonfirmasi dan dilepas sekaligus, dan setiap perubahan status diikuti perubahan Product.Reserved dengan jumlah yang sama.
+StoreLocation, FindLocation, FindLocations, FindLocationStocks, TransferStock:
+
+Fungsi-fungsi ini menyimpan lokasi dan stok per lokasi. Setiap perubahan stok lokasi diikuti perubahan Product.Allocated dengan jumlah yang sama sehingga Product.Allocated selalu sama dengan jumlah stok seluruh lokasi produk.
+BulkWrite:
+
+Fungsi ini menjalankan banyak operasi create, upsert, dan delete dalam satu panggilan. MongoDB menggunakan BulkWrite tanpa urutan, sedangkan repository lain menjalankan operasi satu per satu dengan semantik yang sama.
+ClaimIdempotencyKey, CompleteIdempotencyKey, ReleaseIdempotencyKey:
+
+Fungsi-fungsi ini menyimpan record Idempotency-Key (lihat idempotency.go). Klaim bersifat atomik (primary key atau index unik pada key) sehingga dua request bersamaan dengan key yang sama tidak dapat sama-sama diproses. Record yang kedaluwarsa dihapus saat klaim berikutnya.
+StoreImportJob, UpdateImportJob, FindImportJob:
+
+Fungsi-fungsi ini menyimpan job import beserta progres dan baris yang ditolak. UpdateImportJob menimpa seluruh data job kecuali ID dan CreatedAt.
 Tujuan Komentar:
 Komentar dalam kode ini bertujuan untuk memberikan penjelasan tentang fungsi-fungsi dan interface yang didefinisikan dalam kode, serta bagaimana fungsi tersebut digunakan dalam konteks pengelolaan produk dalam aplikasi. Komentar ini sangat membantu untuk pemahaman dan pemeliharaan kode di masa depan.
 
@@ -0,0 +1,78 @@
+package product
+
+// Kode alasan (reason code) yang diizinkan untuk pergerakan stok.
+const (
+	ReasonInitial    = "initial"    // Stok awal saat produk dibuat
+	ReasonPurchase   = "purchase"   // Penerimaan barang dari pemasok
+	ReasonSale       = "sale"       // Penjualan ke pelanggan
+	ReasonReturn     = "return"     // Retur dari pelanggan
+	ReasonDamage     = "damage"     // Barang rusak atau hilang
+	ReasonCorrection = "correction" // Koreksi hasil stock opname
+	ReasonTransfer   = "transfer"   // Perpindahan stok antar lokasi
+)
+
+// reasons adalah daftar kode alasan yang valid.
+var reasons = map[string]bool{
+	ReasonInitial:    true,
+	ReasonPurchase:   true,
+	ReasonSale:       true,
+	ReasonReturn:     true,
+	ReasonDamage:     true,
+	ReasonCorrection: true,
+	ReasonTransfer:   true,
+}
+
+// StockMovement merepresentasikan satu catatan pada buku besar (ledger) pergerakan stok produk.
+// Catatan ini hanya ditambahkan dan tidak pernah diubah, sehingga riwayat perubahan stok dapat direkonsiliasi.
+type StockMovement struct {
+	ID         ID     `json:"movement_id,omitempty" bson:"_id,omitempty"` // ID unik pergerakan stok
+	ProductID  ID     `json:"product_id" bson:"product_id"`               // ID produk yang stoknya berubah
+	Quantity   int64  `json:"quantity" bson:"quantity"`                   // Jumlah perubahan stok, positif menambah dan negatif mengurangi
+	Reason     string `json:"reason" bson:"reason"`                       // Kode alasan perubahan stok
+	Note       string `json:"note,omitempty" bson:"note,omitempty"`       // Catatan tambahan dari pengguna
+	StockAfter int64  `json:"stock_after" bson:"stock_after"`             // Stok produk setelah perubahan diterapkan
+	CreatedAt  int64  `json:"created_at" bson:"created_at"`               // Waktu (timestamp) saat perubahan dicatat
+
+	LocationID ID `json:"location_id,omitempty" bson:"location_id,omitempty"` // ID lokasi yang stoknya berubah, kosong untuk stok yang belum dialokasikan
+
+	ExpectedVersion int64 `json:"-" bson:"-"` // Versi produk yang diharapkan saat perubahan diterapkan, 0 berarti tanpa pemeriksaan versi
+}
+
+// StockAdjustment adalah permintaan untuk mengubah stok produk.
+type StockAdjustment struct {
+	Quantity int64  `json:"quantity"` // Jumlah perubahan stok, tidak boleh 0
+	Reason   string `json:"reason"`   // Kode alasan perubahan stok
+	Note     string `json:"note"`     // Catatan tambahan (opsional)
+
+	LocationID ID `json:"location_id"` // Lokasi yang stoknya berubah (opsional)
+}
+
+// MovementFilter digunakan untuk pagination riwayat pergerakan stok.
+type MovementFilter struct {
+	Page  int `json:"page"`  // Nomor halaman saat ini untuk pagination
+	Limit int `json:"limit"` // Jumlah maksimal item yang ditampilkan per halaman
+}
+
+// ValidReason memeriksa apakah kode alasan termasuk dalam daftar yang diizinkan.
+func ValidReason(reason string) bool {
+	return reasons[reason]
+}
+
+/*
+Penjelasan Fungsi Kode:
+Kode Alasan:
+
+Setiap perubahan stok wajib memiliki kode alasan seperti purchase, sale, return, damage, atau correction. Kode initial dicatat otomatis saat produk baru dibuat dengan stok awal, dan kode transfer dicatat otomatis saat stok dipindahkan antar lokasi.
+Struct StockMovement:
+
+StockMovement adalah satu baris pada buku besar stok. Quantity bertanda (signed): nilai positif menambah stok dan nilai negatif mengurangi stok. StockAfter menyimpan stok produk setelah perubahan sehingga riwayat dapat dicocokkan dengan nilai Product.Stock. LocationID menunjukkan lokasi yang stoknya berubah. ExpectedVersion tidak disimpan pada buku besar; jika diisi, repository menolak perubahan dengan ErrVersionConflict bila versi produk sudah berbeda, misalnya ketika PATCH /product/:id mengubah stock menjadi pergerakan correction sebesar selisih stok baru dan stok yang dibaca.
+Struct StockAdjustment:
+
+StockAdjustment adalah data yang dikirim melalui endpoint POST /product/:id/stock/adjust. Jika LocationID diisi, stok lokasi tersebut ikut berubah.
+Struct MovementFilter:
+
+MovementFilter digunakan untuk pagination pada endpoint GET /product/:id/stock/movements.
+Fungsi ValidReason:
+
+ValidReason digunakan oleh service untuk menolak kode alasan yang tidak dikenal.
+*/
@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // Mendaftarkan driver "pgx" untuk database/sql
+)
+
+// AdapterPostgres adalah struktur yang menyimpan konfigurasi dan koneksi PostgreSQL
+type AdapterPostgres struct {
+	PostgresDSN string          // Data Source Name untuk koneksi PostgreSQL
+	ctx         context.Context // Context untuk mengelola waktu hidup permintaan
+	DB          *sql.DB         // Pool koneksi PostgreSQL yang digunakan untuk berinteraksi dengan database
+}
+
+// NewPostgres adalah konstruktor untuk AdapterPostgres. Ini membuat instance baru dari AdapterPostgres dengan
+// DSN yang diberikan.
+func NewPostgres(ctx context.Context, postgresDSN string) *AdapterPostgres {
+	return &AdapterPostgres{ctx: ctx, PostgresDSN: postgresDSN}
+}
+
+// Connect membuka pool koneksi ke PostgreSQL menggunakan driver pgx dan memastikan database dapat dihubungi.
+func (p *AdapterPostgres) Connect() *AdapterPostgres {
+	// Membuka pool koneksi, koneksi sebenarnya dibuat saat pertama kali digunakan
+	db, err := sql.Open("pgx", p.PostgresDSN)
+	if err != nil {
+		// Jika DSN tidak valid, log kesalahan tersebut
+		slog.ErrorContext(p.ctx, "error open postgres", slog.Any("err", err))
+	}
+
+	// Memastikan database dapat dihubungi
+	if err == nil {
+		if err = db.PingContext(p.ctx); err != nil {
+			slog.ErrorContext(p.ctx, "error connect to postgres", slog.Any("err", err))
+		}
+	}
+
+	// Jika koneksi berhasil, log informasi bahwa PostgreSQL terhubung
+	if err == nil {
+		slog.InfoContext(p.ctx, "Postgres connected.")
+	}
+
+	// Mengembalikan instance AdapterPostgres yang baru dengan pool koneksi yang diatur
+	return &AdapterPostgres{
+		PostgresDSN: p.PostgresDSN,
+		ctx:         p.ctx,
+		DB:          db,
+	}
+}
+
+/*
+Penjelasan Fungsi dan Komentar dalam Kode:
+Struct AdapterPostgres:
+
+AdapterPostgres menyimpan DSN, context, dan pool koneksi *sql.DB. Strukturnya mengikuti AdapterMongo agar cara inisialisasi database di cmd/main.go tetap seragam.
+Fungsi NewPostgres:
+
+Constructor yang hanya menyimpan context dan DSN. Koneksi dibuka saat Connect dipanggil.
+Fungsi Connect:
+
+Membuka pool koneksi menggunakan driver pgx melalui database/sql, lalu melakukan ping untuk memastikan database dapat dihubungi. Kesalahan dicatat dengan slog seperti pada AdapterMongo.
+*/
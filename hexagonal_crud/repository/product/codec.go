@@ -0,0 +1,77 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// idType adalah tipe reflect dari product.ID yang didaftarkan pada registry BSON.
+var idType = reflect.TypeOf(product.ID(""))
+
+// newRegistry membuat registry BSON yang menerjemahkan product.ID ke/dari ObjectID MongoDB.
+func newRegistry() *bsoncodec.Registry {
+	registry := bson.NewRegistry()
+	registry.RegisterTypeEncoder(idType, bsoncodec.ValueEncoderFunc(encodeID))
+	registry.RegisterTypeDecoder(idType, bsoncodec.ValueDecoderFunc(decodeID))
+	return registry
+}
+
+// encodeID menulis product.ID sebagai ObjectID jika berupa hex ObjectID yang valid,
+// selain itu ID ditulis apa adanya sebagai string.
+func encodeID(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || val.Type() != idType {
+		return bsoncodec.ValueEncoderError{Name: "encodeID", Types: []reflect.Type{idType}, Received: val}
+	}
+
+	if objectID, err := primitive.ObjectIDFromHex(val.String()); err == nil {
+		return vw.WriteObjectID(objectID)
+	}
+	return vw.WriteString(val.String())
+}
+
+// decodeID membaca ObjectID atau string dari dokumen MongoDB menjadi product.ID.
+func decodeID(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != idType {
+		return bsoncodec.ValueDecoderError{Name: "decodeID", Types: []reflect.Type{idType}, Received: val}
+	}
+
+	switch vr.Type() {
+	case bsontype.ObjectID:
+		objectID, err := vr.ReadObjectID()
+		if err != nil {
+			return err
+		}
+		val.SetString(objectID.Hex())
+	case bsontype.String:
+		str, err := vr.ReadString()
+		if err != nil {
+			return err
+		}
+		val.SetString(str)
+	case bsontype.Null:
+		if err := vr.ReadNull(); err != nil {
+			return err
+		}
+		val.SetString("")
+	default:
+		return fmt.Errorf("cannot decode %v into a product.ID", vr.Type())
+	}
+	return nil
+}
+
+/*
+Penjelasan Fungsi dan Komentar dalam Kode:
+Registry BSON untuk product.ID:
+
+Domain menyimpan ID produk sebagai product.ID (string) agar tidak bergantung pada driver MongoDB. File ini berisi codec yang menerjemahkan ID tersebut di dalam repository: saat menulis ke MongoDB, ID yang berupa hex ObjectID disimpan sebagai ObjectID sehingga dokumen lama tetap kompatibel, dan saat membaca, ObjectID dikonversi kembali menjadi hex string.
+Fungsi newRegistry:
+
+Membuat registry BSON default lalu mendaftarkan encoder dan decoder untuk product.ID. Registry ini dipasang pada koleksi produk di storeRepository.
+*/
@@ -0,0 +1,1359 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"CRUD_Hexagonal/infrastructure"
+	"CRUD_Hexagonal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memoryRepository adalah implementasi interface Repository yang menyimpan data produk (store)
+// di dalam memori. Repository ini aman digunakan secara konkuren dan ditujukan untuk pengujian
+// serta menjalankan service secara lokal tanpa database.
+type memoryRepository struct {
+	mu           sync.RWMutex
+	products     map[product.ID]*product.Product
+	order        []product.ID                        // Urutan penyisipan, meniru urutan natural koleksi MongoDB
+	movements    []*product.StockMovement            // Buku besar pergerakan stok, hanya ditambahkan (append-only)
+	reservations map[product.ID]*product.Reservation // Reservasi stok berdasarkan ID reservasi
+	positions    map[product.ID]int64                // Nomor urut penyisipan produk, digunakan sebagai posisi cursor
+	sequence     int64                               // Nomor urut penyisipan terakhir
+
+	locations      map[product.ID]*product.Location            // Lokasi berdasarkan ID lokasi
+	locationOrder  []product.ID                                // Urutan pembuatan lokasi
+	locationStocks map[locationStockKey]*product.LocationStock // Stok per produk per lokasi
+	stockOrder     []locationStockKey                          // Urutan pembuatan stok lokasi
+
+	idempotency map[string]*product.IdempotencyRecord // Record Idempotency-Key berdasarkan key
+	importJobs  map[product.ID]*product.ImportJob     // Job import berdasarkan ID job
+}
+
+// locationStockKey adalah kunci stok sebuah produk pada satu lokasi.
+type locationStockKey struct {
+	productID  product.ID
+	locationID product.ID
+}
+
+// NewMemoryRepository adalah constructor yang digunakan untuk membuat instance baru dari memoryRepository.
+func NewMemoryRepository() product.Repository {
+	return &memoryRepository{
+		products:     make(map[product.ID]*product.Product),
+		reservations: make(map[product.ID]*product.Reservation),
+		positions:    make(map[product.ID]int64),
+
+		locations:      make(map[product.ID]*product.Location),
+		locationStocks: make(map[locationStockKey]*product.LocationStock),
+
+		idempotency: make(map[string]*product.IdempotencyRecord),
+		importJobs:  make(map[product.ID]*product.ImportJob),
+	}
+}
+
+// Find berfungsi untuk mencari satu produk (store) berdasarkan ID yang diberikan.
+func (r *memoryRepository) Find(ctx context.Context, id product.ID) (*product.Product, error) {
+	// Mulai tracing untuk fungsi Find
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:Find")
+	defer span.End()
+
+	// Menerjemahkan ID domain ke UUID yang digunakan sebagai kunci penyimpanan
+	key, err := memoryKey(id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.products[key]
+	if !ok || stored.DeletedAt > 0 {
+		return nil, product.ErrProductNotFound
+	}
+
+	storeData := *stored
+	return &storeData, nil
+}
+
+// FindByCode berfungsi untuk mencari satu produk (store) berdasarkan kode produk (SKU).
+func (r *memoryRepository) FindByCode(ctx context.Context, code string) (*product.Product, error) {
+	// Mulai tracing untuk fungsi FindByCode
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:FindByCode")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := r.findCode(code)
+	if stored == nil || stored.DeletedAt > 0 {
+		return nil, product.ErrProductNotFound
+	}
+
+	storeData := *stored
+	return &storeData, nil
+}
+
+// FindAll berfungsi untuk mencari semua produk (store) dengan filter tertentu dan mendukung pagination.
+func (r *memoryRepository) FindAll(ctx context.Context, filter product.Filter) ([]*product.Product, *utils.Pagination, error) {
+	// Mulai tracing untuk fungsi FindAll
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:FindAll")
+	defer span.End()
+
+	// Pengaturan pagination
+	var currentPage, limit int
+	if filter.Limit <= 0 || filter.Page <= 0 {
+		currentPage, limit = 1, 10
+	} else {
+		currentPage, limit = filter.Page, filter.Limit
+	}
+	if filter.UseCursor && filter.Limit > 0 {
+		// Mode cursor tidak memakai nomor halaman
+		limit = filter.Limit
+	}
+	skip := (currentPage - 1) * limit
+
+	// Membuat pola pencarian keyword yang tidak peka huruf besar/kecil seperti $regex dengan opsi "i".
+	// Keyword di-escape sehingga metakarakter regex dari pengguna dicocokkan sebagai teks biasa.
+	var keyword *regexp.Regexp
+	if filter.Keyword != "" {
+		pattern, err := regexp.Compile("(?i)" + regexp.QuoteMeta(filter.Keyword))
+		if err != nil {
+			return nil, nil, err
+		}
+		keyword = pattern
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Mode cursor dimulai setelah nomor urut penyisipan pada cursor, sehingga tetap benar walaupun produk pada cursor sudah di-purge
+	start := 0
+	if filter.UseCursor && filter.Cursor != "" {
+		position, err := product.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		after, err := strconv.ParseInt(position, 10, 64)
+		if err != nil {
+			return nil, nil, product.ErrInvalidCursor
+		}
+		start = sort.Search(len(r.order), func(i int) bool {
+			return r.positions[r.order[i]] > after
+		})
+	}
+
+	var matched []*product.Product
+	for _, id := range r.order[start:] {
+		stored := r.products[id]
+
+		// Produk yang sudah di-soft delete hanya disertakan jika diminta
+		if !filter.IncludeDeleted && stored.DeletedAt > 0 {
+			continue
+		}
+
+		if keyword != nil && !keyword.MatchString(stored.Name) {
+			continue
+		}
+
+		if !matchConditions(stored, filter.Conditions) {
+			continue
+		}
+
+		// Pencarian berdasarkan jarak hanya mencakup produk yang memiliki koordinat di dalam radius
+		if filter.Near != nil {
+			if stored.Geo == nil {
+				continue
+			}
+			distance := stored.Geo.DistanceKM(filter.Near.Latitude, filter.Near.Longitude)
+			if filter.Near.RadiusKM > 0 && distance > filter.Near.RadiusKM {
+				continue
+			}
+			elem := *stored
+			elem.Distance = &distance
+			stored = &elem
+		}
+
+		matched = append(matched, stored)
+	}
+
+	// Hasil pencarian berdasarkan jarak diurutkan dari yang terdekat seperti $geoNear
+	if filter.Near != nil {
+		sort.SliceStable(matched, func(i, j int) bool {
+			return *matched[i].Distance < *matched[j].Distance
+		})
+	}
+
+	// Urutan dari parameter sort, SliceStable mempertahankan urutan penyisipan untuk nilai yang sama
+	if len(filter.Sort) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool {
+			return lessBySort(matched[i], matched[j], filter.Sort)
+		})
+	}
+
+	// Mode cursor mengembalikan halaman setelah cursor tanpa total
+	if filter.UseCursor {
+		pagination := utils.Pagination{Limit: limit, Cursor: true}
+		if len(matched) > limit {
+			matched = matched[:limit]
+			pagination.HasNext = true
+			pagination.NextCursor = product.EncodeCursor(strconv.FormatInt(r.positions[matched[limit-1].ID], 10))
+		}
+
+		var stores []*product.Product
+		for _, stored := range matched {
+			stores = append(stores, projectProduct(stored, filter))
+		}
+		return stores, &pagination, nil
+	}
+
+	// Membuat struktur pagination
+	pagination := utils.Pagination{
+		Total:       len(matched),
+		Limit:       limit,
+		CurrentPage: currentPage,
+	}
+
+	// Menerapkan skip dan limit pada hasil pencarian
+	var stores []*product.Product
+	for i := skip; i < len(matched) && i < skip+limit; i++ {
+		stores = append(stores, projectProduct(matched[i], filter))
+	}
+
+	return stores, &pagination, nil
+}
+
+// Search berfungsi untuk mencari produk berdasarkan kata pada nama dan deskripsi. Skor setiap produk adalah
+// jumlah kata yang cocok, dengan bobot 2 untuk nama dan 1 untuk deskripsi seperti bobot index teks MongoDB.
+func (r *memoryRepository) Search(ctx context.Context, query product.SearchQuery) ([]*product.SearchHit, *utils.Pagination, error) {
+	// Mulai tracing untuk fungsi Search
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:Search")
+	defer span.End()
+
+	// Pengaturan pagination
+	var currentPage, limit int
+	if query.Limit <= 0 || query.Page <= 0 {
+		currentPage, limit = 1, 10
+	} else {
+		currentPage, limit = query.Page, query.Limit
+	}
+	skip := (currentPage - 1) * limit
+
+	terms := query.Terms()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var hits []*product.SearchHit
+	for _, id := range r.order {
+		stored := r.products[id]
+		if stored.DeletedAt > 0 {
+			continue
+		}
+
+		name, description := searchWords(stored.Name), searchWords(stored.Description)
+		var score float64
+		for _, term := range terms {
+			if name[term] {
+				score += 2
+			}
+			if description[term] {
+				score++
+			}
+		}
+		if score > 0 {
+			elem := *stored
+			hits = append(hits, &product.SearchHit{Product: &elem, Score: score})
+		}
+	}
+
+	// Hasil diurutkan dari skor tertinggi, SliceStable mempertahankan urutan penyisipan untuk skor yang sama
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+
+	pagination := utils.Pagination{
+		Total:       len(hits),
+		Limit:       limit,
+		CurrentPage: currentPage,
+	}
+	if skip >= len(hits) {
+		return nil, &pagination, nil
+	}
+	return hits[skip:min(skip+limit, len(hits))], &pagination, nil
+}
+
+// searchWords memecah teks menjadi kumpulan kata huruf kecil dengan aturan yang sama dengan SearchQuery.Terms.
+func searchWords(text string) map[string]bool {
+	words := map[string]bool{}
+	for _, word := range product.SplitWords(text) {
+		words[word] = true
+	}
+	return words
+}
+
+// lessBySort membandingkan dua produk berdasarkan kriteria sort secara berurutan.
+func lessBySort(a, b *product.Product, sorts []product.SortField) bool {
+	for _, s := range sorts {
+		var cmp int
+		switch s.Field {
+		case "code":
+			cmp = strings.Compare(a.Code, b.Code)
+		case "product_name":
+			cmp = strings.Compare(a.Name, b.Name)
+		default:
+			cmp = compareInt(sortInt(a, s.Field), sortInt(b, s.Field))
+		}
+		if cmp != 0 {
+			return (cmp < 0) != s.Desc
+		}
+	}
+	return false
+}
+
+// matchConditions memeriksa apakah produk memenuhi seluruh ekspresi filter.
+func matchConditions(p *product.Product, conditions []product.Condition) bool {
+	for _, c := range conditions {
+		matched := false
+		for _, value := range c.Values {
+			var cmp int
+			switch v := value.(type) {
+			case string:
+				cmp = strings.Compare(textValue(p, c.Field), v)
+			case int64:
+				cmp = compareInt(sortInt(p, c.Field), v)
+			}
+
+			switch c.Operator {
+			case product.OpEq, product.OpIn:
+				matched = matched || cmp == 0
+			case product.OpNe:
+				matched = cmp != 0
+			case product.OpGt:
+				matched = cmp > 0
+			case product.OpGte:
+				matched = cmp >= 0
+			case product.OpLt:
+				matched = cmp < 0
+			case product.OpLte:
+				matched = cmp <= 0
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// textValue mengembalikan nilai field teks yang dapat difilter.
+func textValue(p *product.Product, field string) string {
+	switch field {
+	case "product_id":
+		return p.ID.String()
+	case "code":
+		return p.Code
+	case "product_name":
+		return p.Name
+	}
+	return ""
+}
+
+// sortInt mengembalikan nilai field numerik yang dapat diurutkan atau difilter.
+func sortInt(p *product.Product, field string) int64 {
+	switch field {
+	case "stock":
+		return p.Stock
+	case "reserved":
+		return p.Reserved
+	case "allocated":
+		return p.Allocated
+	case "created_at":
+		return p.CreatedAt
+	case "updated_at":
+		return p.UpdatedAt
+	case "deleted_at":
+		return p.DeletedAt
+	}
+	return 0
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// projectProduct menyalin produk dengan hanya mengisi field yang dipilih filter, seperti projection pada MongoDB.
+// ID selalu disalin.
+func projectProduct(stored *product.Product, filter product.Filter) *product.Product {
+	if len(filter.Fields) == 0 {
+		elem := *stored
+		return &elem
+	}
+
+	src := reflect.ValueOf(stored).Elem()
+	elem := product.Product{ID: stored.ID}
+	dst := reflect.ValueOf(&elem).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		name, _, _ := strings.Cut(src.Type().Field(i).Tag.Get("json"), ",")
+		if filter.Selects(name) {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+	return &elem
+}
+
+// Store berfungsi untuk menyimpan data produk (store) baru ke dalam memori.
+func (r *memoryRepository) Store(ctx context.Context, dataStore *product.Product) (product.ID, error) {
+	// Mulai tracing untuk fungsi Store
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:Store")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Membuat ID baru (UUID) jika belum ditentukan, seperti yang dilakukan driver MongoDB
+	storeData := *dataStore
+	if storeData.ID.IsZero() {
+		storeData.ID = product.ID(uuid.NewString())
+	}
+
+	if _, exists := r.products[storeData.ID]; exists {
+		return "", errors.New("error writing to repository")
+	}
+
+	// Kode produk harus unik, sama seperti index unik pada MongoDB
+	if r.findCode(storeData.Code) != nil {
+		return "", product.ErrCodeConflict
+	}
+
+	// Setiap produk baru dimulai dari versi 1
+	storeData.Version, dataStore.Version = 1, 1
+	r.products[storeData.ID] = &storeData
+	r.order = append(r.order, storeData.ID)
+	r.sequence++
+	r.positions[storeData.ID] = r.sequence
+
+	return storeData.ID, nil
+}
+
+// Update berfungsi untuk memperbarui data produk (store) yang sudah ada di dalam memori.
+// Seperti repository MongoDB, hanya field yang tidak kosong yang diperbarui.
+func (r *memoryRepository) Update(ctx context.Context, dataStore *product.Product) error {
+	// Mulai tracing untuk fungsi Update
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:Update")
+	defer span.End()
+
+	key, err := memoryKey(dataStore.ID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Produk yang tidak ada atau sudah di-soft delete tidak dapat diperbarui
+	stored, ok := r.products[key]
+	if !ok || stored.DeletedAt > 0 {
+		return product.ErrProductNotFound
+	}
+
+	// Versi yang diharapkan harus sama dengan versi produk saat ini
+	if dataStore.Version != 0 && dataStore.Version != stored.Version {
+		return product.ErrVersionConflict
+	}
+
+	// Kode produk tidak boleh sama dengan kode milik produk lain
+	if owner := r.findCode(dataStore.Code); owner != nil && owner.ID != stored.ID {
+		return product.ErrCodeConflict
+	}
+
+	// Menggunakan refleksi untuk menyalin setiap field yang tidak kosong
+	values := reflect.ValueOf(*dataStore)
+	types := values.Type()
+	target := reflect.ValueOf(stored).Elem()
+	for i := 0; i < values.NumField(); i++ {
+		if updatableField(types.Field(i)) && !utils.IsEmptyStruct(values.Field(i)) {
+			target.Field(i).Set(values.Field(i))
+		}
+	}
+	stored.Version++
+	dataStore.Version = stored.Version
+
+	return nil
+}
+
+// Patch berfungsi untuk menerapkan perubahan eksplisit pada produk, termasuk nilai nol dan nil.
+func (r *memoryRepository) Patch(ctx context.Context, id product.ID, changes product.Changes, version int64) error {
+	// Mulai tracing untuk fungsi Patch
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:Patch")
+	defer span.End()
+
+	fields, err := patchFields(changes)
+	if err != nil {
+		return err
+	}
+
+	key, err := memoryKey(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.products[key]
+	if !ok || stored.DeletedAt > 0 {
+		return product.ErrProductNotFound
+	}
+
+	// Versi yang diharapkan harus sama dengan versi produk saat ini
+	if version != 0 && version != stored.Version {
+		return product.ErrVersionConflict
+	}
+
+	// Kode produk tidak boleh sama dengan kode milik produk lain
+	if code, ok := changes["code"].(string); ok {
+		if owner := r.findCode(code); owner != nil && owner.ID != stored.ID {
+			return product.ErrCodeConflict
+		}
+	}
+
+	target := reflect.ValueOf(stored).Elem()
+	for _, f := range fields {
+		target.Field(f.index).Set(f.value)
+	}
+	stored.Version++
+
+	return nil
+}
+
+// DeleteById berfungsi untuk menandai produk (store) sebagai dihapus (soft delete) berdasarkan ID yang diberikan.
+func (r *memoryRepository) DeleteById(ctx context.Context, id product.ID, version int64) error {
+	// Mulai tracing untuk fungsi DeleteById
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:DeleteById")
+	defer span.End()
+
+	// Periksa apakah ID kosong
+	if id.IsZero() {
+		return product.ErrInvalidID
+	}
+
+	key, err := memoryKey(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.products[key]
+	if !ok || stored.DeletedAt > 0 {
+		return product.ErrProductNotFound
+	}
+	if version != 0 && version != stored.Version {
+		return product.ErrVersionConflict
+	}
+
+	stored.DeletedAt = time.Now().UTC().Unix()
+	stored.Version++
+	return nil
+}
+
+// Restore berfungsi untuk memulihkan produk (store) yang sudah di-soft delete berdasarkan ID yang diberikan.
+func (r *memoryRepository) Restore(ctx context.Context, id product.ID) error {
+	// Mulai tracing untuk fungsi Restore
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:Restore")
+	defer span.End()
+
+	key, err := memoryKey(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.products[key]
+	if !ok || stored.DeletedAt == 0 {
+		return product.ErrProductNotFound
+	}
+
+	stored.DeletedAt = 0
+	stored.Version++
+	return nil
+}
+
+// Purge berfungsi untuk menghapus permanen produk (store) yang sudah di-soft delete
+// pada atau sebelum waktu deletedBefore.
+func (r *memoryRepository) Purge(ctx context.Context, deletedBefore int64) (int64, error) {
+	// Mulai tracing untuk fungsi Purge
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:Purge")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	order := r.order[:0]
+	for _, id := range r.order {
+		stored := r.products[id]
+		if stored.DeletedAt > 0 && stored.DeletedAt <= deletedBefore {
+			delete(r.products, id)
+			delete(r.positions, id)
+			purged++
+			continue
+		}
+		order = append(order, id)
+	}
+	r.order = order
+
+	return purged, nil
+}
+
+// Remove berfungsi untuk menghapus permanen satu produk (store) berdasarkan ID yang diberikan.
+func (r *memoryRepository) Remove(ctx context.Context, id product.ID) error {
+	// Mulai tracing untuk fungsi Remove
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:Remove")
+	defer span.End()
+
+	key, err := memoryKey(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[key]; !ok {
+		return product.ErrProductNotFound
+	}
+	delete(r.products, key)
+	delete(r.positions, key)
+	for i, ordered := range r.order {
+		if ordered == key {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Delete berfungsi untuk menandai produk (store) sebagai dihapus (soft delete) berdasarkan kode produk (SKU).
+func (r *memoryRepository) Delete(ctx context.Context, code string, version int64) error {
+	// Mulai tracing untuk fungsi Delete
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:Delete")
+	defer span.End()
+
+	// Periksa apakah kode kosong
+	if code == "" {
+		return product.ErrProductNotFound
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.findCode(code)
+	if stored == nil || stored.DeletedAt > 0 {
+		return product.ErrProductNotFound
+	}
+	if version != 0 && version != stored.Version {
+		return product.ErrVersionConflict
+	}
+
+	stored.DeletedAt = time.Now().UTC().Unix()
+	stored.Version++
+	return nil
+}
+
+// AdjustStock berfungsi untuk mengubah stok produk dan mencatat pergerakannya di bawah satu lock,
+// sehingga perubahan stok dan pencatatan buku besar terjadi secara atomik.
+func (r *memoryRepository) AdjustStock(ctx context.Context, movement *product.StockMovement) (*product.StockMovement, error) {
+	// Mulai tracing untuk fungsi AdjustStock
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:AdjustStock")
+	defer span.End()
+
+	key, err := memoryKey(movement.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.products[key]
+	if !ok || stored.DeletedAt > 0 {
+		return nil, product.ErrProductNotFound
+	}
+
+	// Versi yang diharapkan, jika diisi, harus sama dengan versi produk saat ini
+	if movement.ExpectedVersion != 0 && movement.ExpectedVersion != stored.Version {
+		return nil, product.ErrVersionConflict
+	}
+
+	// Stok tidak boleh menjadi negatif atau lebih kecil dari stok yang sedang ditahan reservasi
+	if stored.Stock+movement.Quantity < stored.Reserved {
+		return nil, product.ErrInsufficientStock
+	}
+
+	recorded := *movement
+	if movement.LocationID.IsZero() {
+		// Perubahan tanpa lokasi hanya memakai stok yang belum dialokasikan
+		if stored.Stock+movement.Quantity < stored.Allocated {
+			return nil, product.ErrInsufficientStock
+		}
+	} else {
+		locationKey, err := memoryKey(movement.LocationID)
+		if err != nil {
+			return nil, product.ErrLocationNotFound
+		}
+		if !r.changeLocationStock(key, locationKey, movement.Quantity, movement.CreatedAt) {
+			return nil, product.ErrInsufficientStock
+		}
+		stored.Allocated += movement.Quantity
+		recorded.LocationID = locationKey
+	}
+	stored.Stock += movement.Quantity
+	stored.Version++
+
+	recorded.ID = product.ID(uuid.NewString())
+	recorded.ProductID = key
+	recorded.StockAfter = stored.Stock
+	r.movements = append(r.movements, &recorded)
+
+	result := recorded
+	return &result, nil
+}
+
+// FindMovements berfungsi untuk membaca riwayat pergerakan stok sebuah produk, dari yang terbaru, dengan pagination.
+func (r *memoryRepository) FindMovements(ctx context.Context, productID product.ID, filter product.MovementFilter) ([]*product.StockMovement, *utils.Pagination, error) {
+	// Mulai tracing untuk fungsi FindMovements
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:FindMovements")
+	defer span.End()
+
+	key, err := memoryKey(productID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Pengaturan pagination
+	var currentPage, limit int
+	if filter.Limit <= 0 || filter.Page <= 0 {
+		currentPage, limit = 1, 10
+	} else {
+		currentPage, limit = filter.Page, filter.Limit
+	}
+	skip := (currentPage - 1) * limit
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Membaca buku besar dari belakang agar pergerakan terbaru berada di urutan pertama
+	var matched []*product.StockMovement
+	for i := len(r.movements) - 1; i >= 0; i-- {
+		if r.movements[i].ProductID == key {
+			matched = append(matched, r.movements[i])
+		}
+	}
+
+	pagination := utils.Pagination{
+		Total:       len(matched),
+		Limit:       limit,
+		CurrentPage: currentPage,
+	}
+
+	var movements []*product.StockMovement
+	for i := skip; i < len(matched) && i < skip+limit; i++ {
+		elem := *matched[i]
+		movements = append(movements, &elem)
+	}
+
+	return movements, &pagination, nil
+}
+
+// Reserve berfungsi untuk menahan stok produk dan menyimpan reservasi di bawah satu lock.
+func (r *memoryRepository) Reserve(ctx context.Context, reservation *product.Reservation) (*product.Reservation, error) {
+	// Mulai tracing untuk fungsi Reserve
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:Reserve")
+	defer span.End()
+
+	key, err := memoryKey(reservation.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.products[key]
+	if !ok || stored.DeletedAt > 0 {
+		return nil, product.ErrProductNotFound
+	}
+
+	// Reservasi hanya dapat mengambil stok yang belum ditahan reservasi lain
+	if stored.Stock-stored.Reserved < reservation.Quantity {
+		return nil, product.ErrInsufficientStock
+	}
+	stored.Reserved += reservation.Quantity
+	stored.Version++
+
+	recorded := *reservation
+	recorded.ID = product.ID(uuid.NewString())
+	recorded.ProductID = key
+	r.reservations[recorded.ID] = &recorded
+
+	result := recorded
+	return &result, nil
+}
+
+// FindReservation berfungsi untuk mencari reservasi berdasarkan ID.
+func (r *memoryRepository) FindReservation(ctx context.Context, reservationID product.ID) (*product.Reservation, error) {
+	// Mulai tracing untuk fungsi FindReservation
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:FindReservation")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := r.findReservation(reservationID)
+	if stored == nil {
+		return nil, product.ErrReservationNotFound
+	}
+
+	result := *stored
+	return &result, nil
+}
+
+// ConfirmReservation berfungsi untuk mengonfirmasi reservasi aktif, mengurangi stok produk,
+// dan mencatat pergerakan stok di bawah satu lock.
+func (r *memoryRepository) ConfirmReservation(ctx context.Context, reservationID product.ID, confirmedAt int64) (*product.Reservation, error) {
+	// Mulai tracing untuk fungsi ConfirmReservation
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:ConfirmReservation")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.findReservation(reservationID)
+	if stored == nil {
+		return nil, product.ErrReservationNotFound
+	}
+
+	// Reservasi yang sudah melewati batas waktu tidak dapat dikonfirmasi walaupun belum disapu
+	if stored.Status != product.ReservationActive || stored.ExpiresAt <= confirmedAt {
+		return nil, product.ErrReservationNotActive
+	}
+
+	storeData, ok := r.products[stored.ProductID]
+	if !ok {
+		return nil, product.ErrProductNotFound
+	}
+
+	// Reservasi memakai stok yang belum dialokasikan ke lokasi
+	if storeData.Stock-stored.Quantity < storeData.Allocated {
+		return nil, product.ErrInsufficientStock
+	}
+	storeData.Stock -= stored.Quantity
+	storeData.Reserved -= stored.Quantity
+	storeData.Version++
+
+	r.movements = append(r.movements, &product.StockMovement{
+		ID:         product.ID(uuid.NewString()),
+		ProductID:  stored.ProductID,
+		Quantity:   -stored.Quantity,
+		Reason:     product.ReasonSale,
+		Note:       "reservation " + stored.ID.String(),
+		StockAfter: storeData.Stock,
+		CreatedAt:  confirmedAt,
+	})
+
+	stored.Status = product.ReservationConfirmed
+	stored.UpdatedAt = confirmedAt
+
+	result := *stored
+	return &result, nil
+}
+
+// ReleaseReservation berfungsi untuk mengubah reservasi aktif menjadi released atau expired
+// dan melepas stok yang ditahan di bawah satu lock.
+func (r *memoryRepository) ReleaseReservation(ctx context.Context, reservationID product.ID, status string, releasedAt int64) (*product.Reservation, error) {
+	// Mulai tracing untuk fungsi ReleaseReservation
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:ReleaseReservation")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.findReservation(reservationID)
+	if stored == nil {
+		return nil, product.ErrReservationNotFound
+	}
+	if stored.Status != product.ReservationActive {
+		return nil, product.ErrReservationNotActive
+	}
+
+	// Produk yang sudah di-purge tidak lagi memiliki stok yang perlu dilepas
+	if storeData, ok := r.products[stored.ProductID]; ok {
+		storeData.Reserved -= stored.Quantity
+		storeData.Version++
+	}
+
+	stored.Status = status
+	stored.UpdatedAt = releasedAt
+
+	result := *stored
+	return &result, nil
+}
+
+// FindExpiredReservations berfungsi untuk mencari reservasi aktif yang kedaluwarsa, dari yang paling lama.
+func (r *memoryRepository) FindExpiredReservations(ctx context.Context, before int64, limit int) ([]*product.Reservation, error) {
+	// Mulai tracing untuk fungsi FindExpiredReservations
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:FindExpiredReservations")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var expired []*product.Reservation
+	for _, stored := range r.reservations {
+		if stored.Status == product.ReservationActive && stored.ExpiresAt <= before {
+			elem := *stored
+			expired = append(expired, &elem)
+		}
+	}
+
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].ExpiresAt < expired[j].ExpiresAt
+	})
+	if len(expired) > limit {
+		expired = expired[:limit]
+	}
+
+	return expired, nil
+}
+
+// StoreLocation berfungsi untuk menyimpan lokasi baru ke dalam memori.
+func (r *memoryRepository) StoreLocation(ctx context.Context, location *product.Location) (product.ID, error) {
+	// Mulai tracing untuk fungsi StoreLocation
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:StoreLocation")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Kode lokasi harus unik
+	for _, stored := range r.locations {
+		if stored.Code == location.Code {
+			return "", product.ErrLocationCodeConflict
+		}
+	}
+
+	stored := *location
+	stored.ID = product.ID(uuid.NewString())
+	r.locations[stored.ID] = &stored
+	r.locationOrder = append(r.locationOrder, stored.ID)
+
+	return stored.ID, nil
+}
+
+// FindLocation berfungsi untuk mencari lokasi berdasarkan ID.
+func (r *memoryRepository) FindLocation(ctx context.Context, locationID product.ID) (*product.Location, error) {
+	// Mulai tracing untuk fungsi FindLocation
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:FindLocation")
+	defer span.End()
+
+	key, err := memoryKey(locationID)
+	if err != nil {
+		return nil, product.ErrLocationNotFound
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.locations[key]
+	if !ok {
+		return nil, product.ErrLocationNotFound
+	}
+
+	location := *stored
+	return &location, nil
+}
+
+// FindLocations berfungsi untuk mengembalikan semua lokasi sesuai urutan pembuatan.
+func (r *memoryRepository) FindLocations(ctx context.Context) ([]*product.Location, error) {
+	// Mulai tracing untuk fungsi FindLocations
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:FindLocations")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var locations []*product.Location
+	for _, id := range r.locationOrder {
+		location := *r.locations[id]
+		locations = append(locations, &location)
+	}
+	return locations, nil
+}
+
+// FindLocationStocks berfungsi untuk mengembalikan stok per lokasi yang sesuai dengan filter.
+func (r *memoryRepository) FindLocationStocks(ctx context.Context, filter product.LocationStockFilter) ([]*product.LocationStock, error) {
+	// Mulai tracing untuk fungsi FindLocationStocks
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:FindLocationStocks")
+	defer span.End()
+
+	// Menerjemahkan ID pada filter menjadi kunci penyimpanan, ID yang tidak valid tidak akan pernah cocok
+	productIDs := make(map[product.ID]bool)
+	for _, id := range filter.ProductIDs {
+		if key, err := memoryKey(id); err == nil {
+			productIDs[key] = true
+		}
+	}
+	var locationID product.ID
+	if !filter.LocationID.IsZero() {
+		key, err := memoryKey(filter.LocationID)
+		if err != nil {
+			return nil, nil
+		}
+		locationID = key
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var stocks []*product.LocationStock
+	for _, key := range r.stockOrder {
+		if len(filter.ProductIDs) > 0 && !productIDs[key.productID] {
+			continue
+		}
+		if !locationID.IsZero() && key.locationID != locationID {
+			continue
+		}
+		stock := *r.locationStocks[key]
+		stocks = append(stocks, &stock)
+	}
+	return stocks, nil
+}
+
+// TransferStock berfungsi untuk memindahkan stok antar lokasi dan mencatat pergerakannya di bawah satu lock.
+func (r *memoryRepository) TransferStock(ctx context.Context, transfer *product.StockTransfer) (*product.StockTransfer, error) {
+	// Mulai tracing untuk fungsi TransferStock
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:TransferStock")
+	defer span.End()
+
+	key, err := memoryKey(transfer.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := *transfer
+	recorded.ProductID = key
+	if !transfer.FromLocationID.IsZero() {
+		if recorded.FromLocationID, err = memoryKey(transfer.FromLocationID); err != nil {
+			return nil, product.ErrLocationNotFound
+		}
+	}
+	if !transfer.ToLocationID.IsZero() {
+		if recorded.ToLocationID, err = memoryKey(transfer.ToLocationID); err != nil {
+			return nil, product.ErrLocationNotFound
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.products[key]
+	if !ok || stored.DeletedAt > 0 {
+		return nil, product.ErrProductNotFound
+	}
+
+	// Mengambil stok dari lokasi asal atau dari stok yang belum dialokasikan
+	if recorded.FromLocationID.IsZero() {
+		if stored.Stock-stored.Allocated < recorded.Quantity {
+			return nil, product.ErrInsufficientStock
+		}
+		stored.Allocated += recorded.Quantity
+	} else if !r.changeLocationStock(key, recorded.FromLocationID, -recorded.Quantity, recorded.CreatedAt) {
+		return nil, product.ErrInsufficientStock
+	}
+
+	// Menambahkan stok ke lokasi tujuan atau mengembalikannya menjadi stok yang belum dialokasikan
+	if recorded.ToLocationID.IsZero() {
+		stored.Allocated -= recorded.Quantity
+	} else {
+		r.changeLocationStock(key, recorded.ToLocationID, recorded.Quantity, recorded.CreatedAt)
+	}
+
+	stored.Version++
+
+	// Mencatat perpindahan ke buku besar, total stok produk tidak berubah
+	for _, movement := range transferMovements(&recorded, stored.Stock) {
+		movement.ID = product.ID(uuid.NewString())
+		r.movements = append(r.movements, movement)
+	}
+
+	return &recorded, nil
+}
+
+// Export berfungsi untuk membaca semua produk yang sesuai filter dan meneruskannya satu per satu ke fn.
+func (r *memoryRepository) Export(ctx context.Context, filter product.Filter, fn func(*product.Product) error) error {
+	// Mulai tracing untuk fungsi Export
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:memory:Export")
+	defer span.End()
+
+	return exportPages(ctx, r, filter, fn)
+}
+
+// BulkWrite berfungsi untuk menjalankan banyak operasi create, upsert, dan delete satu per satu.
+func (r *memoryRepository) BulkWrite(ctx context.Context, operations []product.BulkOperation) ([]*product.BulkResult, error) {
+	// Mulai tracing untuk fungsi BulkWrite
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:memory:BulkWrite")
+	defer span.End()
+
+	return bulkWriteEach(ctx, r, operations)
+}
+
+// ClaimIdempotencyKey berfungsi untuk menyimpan klaim Idempotency-Key baru, atau mengembalikan record yang sudah ada.
+func (r *memoryRepository) ClaimIdempotencyKey(ctx context.Context, record *product.IdempotencyRecord) (*product.IdempotencyRecord, error) {
+	// Mulai tracing untuk fungsi ClaimIdempotencyKey
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:ClaimIdempotencyKey")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Record yang sudah kedaluwarsa dihapus sehingga key-nya dapat diklaim kembali
+	for key, stored := range r.idempotency {
+		if stored.ExpiresAt <= record.CreatedAt {
+			delete(r.idempotency, key)
+		}
+	}
+
+	if stored, ok := r.idempotency[record.Key]; ok {
+		return copyIdempotencyRecord(stored), nil
+	}
+	r.idempotency[record.Key] = copyIdempotencyRecord(record)
+	return nil, nil
+}
+
+// CompleteIdempotencyKey berfungsi untuk menyimpan response pada record Idempotency-Key yang masih diproses.
+func (r *memoryRepository) CompleteIdempotencyKey(ctx context.Context, record *product.IdempotencyRecord) error {
+	// Mulai tracing untuk fungsi CompleteIdempotencyKey
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:CompleteIdempotencyKey")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.idempotency[record.Key]
+	if !ok || stored.Completed() {
+		return nil
+	}
+	completed := copyIdempotencyRecord(record)
+	completed.Fingerprint = stored.Fingerprint
+	completed.CreatedAt = stored.CreatedAt
+	r.idempotency[record.Key] = completed
+	return nil
+}
+
+// ReleaseIdempotencyKey berfungsi untuk menghapus record Idempotency-Key yang masih diproses.
+func (r *memoryRepository) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	// Mulai tracing untuk fungsi ReleaseIdempotencyKey
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:ReleaseIdempotencyKey")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.idempotency[key]; ok && !stored.Completed() {
+		delete(r.idempotency, key)
+	}
+	return nil
+}
+
+// StoreImportJob berfungsi untuk menyimpan job import baru dengan ID UUID.
+func (r *memoryRepository) StoreImportJob(ctx context.Context, job *product.ImportJob) (*product.ImportJob, error) {
+	// Mulai tracing untuk fungsi StoreImportJob
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:StoreImportJob")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := copyImportJob(job)
+	stored.ID = product.ID(uuid.NewString())
+	r.importJobs[stored.ID] = stored
+	return copyImportJob(stored), nil
+}
+
+// UpdateImportJob berfungsi untuk menyimpan status, progres, dan hasil job import.
+func (r *memoryRepository) UpdateImportJob(ctx context.Context, job *product.ImportJob) error {
+	// Mulai tracing untuk fungsi UpdateImportJob
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:UpdateImportJob")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, err := memoryKey(job.ID)
+	if err != nil {
+		return product.ErrJobNotFound
+	}
+	stored, ok := r.importJobs[key]
+	if !ok {
+		return product.ErrJobNotFound
+	}
+
+	updated := copyImportJob(job)
+	updated.ID, updated.CreatedAt = stored.ID, stored.CreatedAt
+	r.importJobs[key] = updated
+	return nil
+}
+
+// FindImportJob berfungsi untuk mencari job import berdasarkan ID.
+func (r *memoryRepository) FindImportJob(ctx context.Context, id product.ID) (*product.ImportJob, error) {
+	// Mulai tracing untuk fungsi FindImportJob
+	_, span := infrastructure.Tracer().Start(ctx, "repository:memory:FindImportJob")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, err := memoryKey(id)
+	if err != nil {
+		return nil, product.ErrJobNotFound
+	}
+	stored, ok := r.importJobs[key]
+	if !ok {
+		return nil, product.ErrJobNotFound
+	}
+	return copyImportJob(stored), nil
+}
+
+// copyImportJob menyalin job beserta slice di dalamnya agar data yang tersimpan tidak berbagi memori dengan pemanggil.
+func copyImportJob(job *product.ImportJob) *product.ImportJob {
+	copied := *job
+	copied.Header = append([]string(nil), job.Header...)
+	copied.Changes = append([]product.ImportChange(nil), job.Changes...)
+	copied.Errors = make([]product.ImportRowError, len(job.Errors))
+	for i, rowErr := range job.Errors {
+		copied.Errors[i] = rowErr
+		copied.Errors[i].Values = append([]string(nil), rowErr.Values...)
+	}
+	return &copied
+}
+
+// copyIdempotencyRecord menyalin record beserta header dan body-nya agar data yang tersimpan tidak berbagi memori dengan pemanggil.
+func copyIdempotencyRecord(record *product.IdempotencyRecord) *product.IdempotencyRecord {
+	copied := *record
+	if record.Header != nil {
+		copied.Header = make(map[string]string, len(record.Header))
+		for name, value := range record.Header {
+			copied.Header[name] = value
+		}
+	}
+	if record.Body != nil {
+		copied.Body = append([]byte(nil), record.Body...)
+	}
+	return &copied
+}
+
+// changeLocationStock menambahkan quantity ke stok produk pada sebuah lokasi.
+// Perubahan ditolak (false) jika stok lokasi akan menjadi negatif. Pemanggil harus sudah memegang lock.
+func (r *memoryRepository) changeLocationStock(productID, locationID product.ID, quantity, updatedAt int64) bool {
+	key := locationStockKey{productID: productID, locationID: locationID}
+	stock, ok := r.locationStocks[key]
+	if !ok {
+		stock = &product.LocationStock{ProductID: productID, LocationID: locationID}
+	}
+	if stock.Quantity+quantity < 0 {
+		return false
+	}
+	if !ok {
+		r.locationStocks[key] = stock
+		r.stockOrder = append(r.stockOrder, key)
+	}
+
+	stock.Quantity += quantity
+	stock.UpdatedAt = updatedAt
+	return true
+}
+
+// findReservation mencari reservasi berdasarkan ID, nil jika ID tidak valid atau tidak ditemukan.
+// Pemanggil harus sudah memegang lock.
+func (r *memoryRepository) findReservation(reservationID product.ID) *product.Reservation {
+	key, err := memoryKey(reservationID)
+	if err != nil {
+		return nil
+	}
+	return r.reservations[key]
+}
+
+// findCode mencari produk dengan kode tertentu, termasuk produk yang sudah di-soft delete.
+// Pemanggil harus sudah memegang lock.
+func (r *memoryRepository) findCode(code string) *product.Product {
+	if code == "" {
+		return nil
+	}
+	for _, stored := range r.products {
+		if stored.Code == code {
+			return stored
+		}
+	}
+	return nil
+}
+
+// memoryKey menerjemahkan ID domain menjadi kunci penyimpanan dalam bentuk UUID kanonik.
+func memoryKey(id product.ID) (product.ID, error) {
+	parsed, err := uuid.Parse(id.String())
+	if err != nil {
+		// Pesan error parser tidak diteruskan ke klien, cukup nilai ID yang ditolak
+		return "", fmt.Errorf("%w: %q", product.ErrInvalidID, id.String())
+	}
+	return product.ID(parsed.String()), nil
+}
+
+/*
+Penjelasan Fungsi dan Komentar dalam Kode:
+Struct memoryRepository:
+
+memoryRepository adalah implementasi dari interface product.Repository yang menyimpan data di dalam map. Akses ke map dilindungi oleh sync.RWMutex sehingga aman dipakai dari banyak goroutine sekaligus. Slice order menyimpan urutan penyisipan agar hasil FindAll berurutan seperti koleksi MongoDB.
+Fungsi NewMemoryRepository:
+
+Constructor untuk membuat repository in-memory yang masih kosong. Repository ini dipilih dari cmd/main.go dengan DATABASE_DSN=memory:// sehingga service dapat dijalankan di laptop atau CI tanpa MongoDB.
+Perilaku yang Disamakan dengan Repository MongoDB:
+
+ID dibuat sebagai UUID sehingga repository ini tidak bergantung pada driver MongoDB, pagination default adalah halaman 1 dengan limit 10, keyword dicocokkan dengan nama produk secara case-insensitive, Update hanya mengubah field yang tidak kosong, pesan error saat data tidak ditemukan sama dengan repository MongoDB, dan kode produk dijaga keunikannya seperti index unik pada MongoDB (ErrCodeConflict).
+Buku Besar Stok:
+
+AdjustStock dan FindMovements menyimpan pergerakan stok di slice movements. Perubahan stok dan pencatatan pergerakan dilakukan di bawah lock yang sama sehingga keduanya selalu konsisten, dan perubahan yang membuat stok negatif atau lebih kecil dari stok yang ditahan ditolak dengan ErrInsufficientStock. ExpectedVersion yang diisi diperiksa di bawah lock yang sama.
+Reservasi Stok:
+
+Reservasi disimpan di map reservations. Reserve, ConfirmReservation, dan ReleaseReservation mengubah status reservasi bersama Product.Reserved (dan Product.Stock saat konfirmasi) di bawah lock yang sama.
+Data yang dikembalikan selalu berupa salinan sehingga perubahan oleh pemanggil tidak memengaruhi data yang tersimpan.
+Lokasi dan Stok per Lokasi:
+
+Lokasi disimpan di map locations dan stok per lokasi di map locationStocks dengan kunci produk dan lokasi. Perubahan stok lokasi, Product.Allocated, dan buku besar stok dilakukan di bawah lock yang sama.
+Idempotency-Key:
+
+Record Idempotency-Key disimpan di map idempotency dengan kunci berupa nilai key. Klaim diperiksa dan disimpan di bawah lock yang sama sehingga hanya satu request yang dapat mengklaim sebuah key, dan record yang sudah kedaluwarsa dihapus setiap kali ada klaim baru.
+Job Import:
+
+Job import disimpan di map importJobs dengan ID UUID. Setiap job disalin beserta header, perubahan, dan baris yang ditolak saat disimpan dan dibaca, sehingga job yang sedang diproses di latar belakang tidak berbagi memori dengan job yang dibaca melalui GET /jobs/:id.
+Pencarian Teks:
+
+Search memecah nama dan deskripsi produk menjadi kata dengan aturan yang sama dengan SearchQuery.Terms, lalu memberi skor 2 untuk setiap kata yang cocok pada nama dan 1 pada deskripsi. Keyword pada FindAll di-escape dengan regexp.QuoteMeta sehingga dicocokkan sebagai teks biasa.
+*/
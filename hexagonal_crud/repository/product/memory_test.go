@@ -0,0 +1,13 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"CRUD_Hexagonal/repository/product/producttest"
+	"testing"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	producttest.RunRepositorySuite(t, func(t *testing.T) product.Repository {
+		return NewMemoryRepository()
+	})
+}
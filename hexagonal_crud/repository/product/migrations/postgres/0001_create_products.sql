@@ -0,0 +1,14 @@
+-- Tabel produk untuk repository PostgreSQL.
+-- id dibuat oleh database (UUID), position menjaga urutan penyisipan seperti urutan natural koleksi MongoDB.
+CREATE TABLE IF NOT EXISTS products (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    position     BIGINT GENERATED ALWAYS AS IDENTITY,
+    product_name TEXT   NOT NULL DEFAULT '',
+    stock        BIGINT NOT NULL DEFAULT 0,
+    created_at   BIGINT NOT NULL DEFAULT 0,
+    updated_at   BIGINT NOT NULL DEFAULT 0,
+    deleted_at   BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS products_position_idx ON products (position);
+CREATE INDEX IF NOT EXISTS products_deleted_at_idx ON products (deleted_at);
@@ -0,0 +1,4 @@
+-- Kode produk (SKU) yang unik, kosong berarti produk belum memiliki kode.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS code TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS products_code_idx ON products (code) WHERE code <> '';
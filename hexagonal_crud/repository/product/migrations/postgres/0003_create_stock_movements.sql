@@ -0,0 +1,14 @@
+-- Buku besar pergerakan stok, hanya ditambahkan (append-only).
+-- Tidak menggunakan foreign key agar riwayat tetap tersimpan walaupun produk sudah di-purge, sama seperti koleksi MongoDB.
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    position    BIGINT GENERATED ALWAYS AS IDENTITY,
+    product_id  UUID   NOT NULL,
+    quantity    BIGINT NOT NULL,
+    reason      TEXT   NOT NULL,
+    note        TEXT   NOT NULL DEFAULT '',
+    stock_after BIGINT NOT NULL,
+    created_at  BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS stock_movements_product_idx ON stock_movements (product_id, position DESC);
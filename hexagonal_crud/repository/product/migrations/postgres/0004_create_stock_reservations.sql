@@ -0,0 +1,15 @@
+-- Stok yang sedang ditahan oleh reservasi aktif.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reserved BIGINT NOT NULL DEFAULT 0;
+
+-- Reservasi stok untuk proses checkout.
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID   NOT NULL,
+    quantity   BIGINT NOT NULL,
+    status     TEXT   NOT NULL,
+    expires_at BIGINT NOT NULL,
+    created_at BIGINT NOT NULL DEFAULT 0,
+    updated_at BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS stock_reservations_status_expires_at_idx ON stock_reservations (status, expires_at);
@@ -0,0 +1,28 @@
+-- Jumlah stok produk yang sudah dialokasikan ke lokasi.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS allocated BIGINT NOT NULL DEFAULT 0;
+
+-- Lokasi pergerakan stok, kosong untuk stok yang belum dialokasikan.
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS location_id UUID;
+
+-- Lokasi penyimpanan stok (gudang atau toko).
+CREATE TABLE IF NOT EXISTS locations (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    position   BIGINT GENERATED ALWAYS AS IDENTITY,
+    code       TEXT   NOT NULL,
+    name       TEXT   NOT NULL,
+    type       TEXT   NOT NULL,
+    created_at BIGINT NOT NULL DEFAULT 0,
+    CONSTRAINT locations_code_key UNIQUE (code)
+);
+
+-- Stok sebuah produk pada satu lokasi, tidak pernah negatif.
+CREATE TABLE IF NOT EXISTS location_stocks (
+    product_id  UUID   NOT NULL,
+    location_id UUID   NOT NULL,
+    position    BIGINT GENERATED ALWAYS AS IDENTITY,
+    quantity    BIGINT NOT NULL CHECK (quantity >= 0),
+    updated_at  BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, location_id)
+);
+
+CREATE INDEX IF NOT EXISTS location_stocks_location_idx ON location_stocks (location_id);
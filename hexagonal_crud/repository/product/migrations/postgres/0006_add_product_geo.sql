@@ -0,0 +1,4 @@
+-- Koordinat produk, NULL jika produk belum memiliki lokasi.
+-- Jarak dihitung dengan rumus haversine sehingga tidak memerlukan ekstensi PostGIS.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
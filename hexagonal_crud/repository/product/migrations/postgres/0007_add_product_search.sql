@@ -0,0 +1,8 @@
+-- Deskripsi produk dan kolom tsvector untuk pencarian teks pada nama dan deskripsi.
+-- Konfigurasi 'simple' tidak melakukan stemming sehingga kata dalam bahasa Indonesia dicocokkan apa adanya,
+-- dan bobot A (nama) lebih besar dari bobot B (deskripsi) pada ts_rank.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', product_name), 'A') || setweight(to_tsvector('simple', description), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS products_search_idx ON products USING GIN (search_vector);
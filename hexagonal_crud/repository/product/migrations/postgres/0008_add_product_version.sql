@@ -0,0 +1,3 @@
+-- Nomor versi produk untuk optimistic concurrency control (ETag/If-Match).
+-- Setiap UPDATE pada tabel products menambahkan version = version + 1.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
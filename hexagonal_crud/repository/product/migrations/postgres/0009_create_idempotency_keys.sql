@@ -0,0 +1,13 @@
+-- Record Idempotency-Key: klaim request yang sedang diproses dan response yang diputar ulang.
+-- status 0 berarti request pertama dengan key tersebut masih diproses.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key         TEXT PRIMARY KEY,
+    fingerprint TEXT    NOT NULL,
+    status      INTEGER NOT NULL DEFAULT 0,
+    header      JSONB   NOT NULL DEFAULT '{}',
+    body        BYTEA,
+    created_at  BIGINT  NOT NULL,
+    expires_at  BIGINT  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
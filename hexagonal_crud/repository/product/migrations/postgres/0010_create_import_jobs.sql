@@ -0,0 +1,21 @@
+-- Job import produk dari file CSV atau XLSX beserta progresnya.
+-- header, changes, dan errors disimpan sebagai JSONB karena hanya dibaca bersama job.
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    status         TEXT    NOT NULL,
+    mode           TEXT    NOT NULL,
+    dry_run        BOOLEAN NOT NULL DEFAULT FALSE,
+    filename       TEXT    NOT NULL DEFAULT '',
+    total_rows     INTEGER NOT NULL DEFAULT 0,
+    processed_rows INTEGER NOT NULL DEFAULT 0,
+    created        INTEGER NOT NULL DEFAULT 0,
+    updated        INTEGER NOT NULL DEFAULT 0,
+    rejected       INTEGER NOT NULL DEFAULT 0,
+    error          TEXT    NOT NULL DEFAULT '',
+    header         JSONB   NOT NULL DEFAULT '[]',
+    changes        JSONB   NOT NULL DEFAULT '[]',
+    errors         JSONB   NOT NULL DEFAULT '[]',
+    created_at     BIGINT  NOT NULL DEFAULT 0,
+    updated_at     BIGINT  NOT NULL DEFAULT 0,
+    finished_at    BIGINT  NOT NULL DEFAULT 0
+);
@@ -0,0 +1,1777 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"CRUD_Hexagonal/infrastructure"
+	"CRUD_Hexagonal/utils"
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/exp/slog"
+)
+
+// postgresMigrations berisi file migrasi skema PostgreSQL yang dijalankan berurutan berdasarkan nama file.
+//
+//go:embed migrations/postgres/*.sql
+var postgresMigrations embed.FS
+
+// productColumns adalah daftar kolom yang dibaca dari tabel products, urutannya sama dengan scanProduct.
+const productColumns = "id::text, code, product_name, stock, reserved, allocated, created_at, updated_at, deleted_at, latitude, longitude, description, version"
+
+// productFields memetakan nama JSON field produk ke kolom pada productColumns (urutannya sama) beserta nilai
+// pengganti yang dibaca ketika field tersebut tidak dipilih pada projection.
+var productFields = []struct {
+	field, column, zero string
+}{
+	{"product_id", "id::text", ""},
+	{"code", "code", "''"},
+	{"product_name", "product_name", "''"},
+	{"stock", "stock", "0"},
+	{"reserved", "reserved", "0"},
+	{"allocated", "allocated", "0"},
+	{"created_at", "created_at", "0"},
+	{"updated_at", "updated_at", "0"},
+	{"deleted_at", "deleted_at", "0"},
+	{"geo", "latitude", "NULL::double precision"},
+	{"geo", "longitude", "NULL::double precision"},
+	{"description", "description", "''"},
+	{"version", "version", "0"},
+}
+
+// movementColumns adalah daftar kolom yang dibaca dari tabel stock_movements, urutannya sama dengan scanMovement.
+const movementColumns = "id::text, product_id::text, quantity, reason, note, stock_after, created_at, COALESCE(location_id::text, '')"
+
+// reservationColumns adalah daftar kolom yang dibaca dari tabel stock_reservations, urutannya sama dengan scanReservation.
+const reservationColumns = "id::text, product_id::text, quantity, status, expires_at, created_at, updated_at"
+
+// locationColumns adalah daftar kolom yang dibaca dari tabel locations, urutannya sama dengan scanLocation.
+const locationColumns = "id::text, code, name, type, created_at"
+
+// locationStockColumns adalah daftar kolom yang dibaca dari tabel location_stocks, urutannya sama dengan scanLocationStock.
+const locationStockColumns = "product_id::text, location_id::text, quantity, updated_at"
+
+// importJobColumns adalah daftar kolom yang dibaca dari tabel import_jobs, urutannya sama dengan scanImportJob.
+const importJobColumns = "id::text, status, mode, dry_run, filename, total_rows, processed_rows, created, updated, rejected, error, header, changes, errors, created_at, updated_at, finished_at"
+
+// postgresRepository adalah struct yang mengimplementasikan interface Repository
+// untuk berinteraksi dengan tabel PostgreSQL yang menyimpan data produk (store).
+type postgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository adalah constructor yang digunakan untuk membuat instance baru dari postgresRepository.
+// Skema tabel harus sudah dibuat dengan MigratePostgres. Error koneksi dan timeout dikembalikan sebagai product.ErrUnavailable.
+func NewPostgresRepository(db *sql.DB) product.Repository {
+	return withUnavailable(&postgresRepository{db: db}, postgresUnavailable)
+}
+
+// MigratePostgres menjalankan migrasi skema yang belum pernah dijalankan.
+// Setiap migrasi dijalankan di dalam transaksi dan dicatat pada tabel schema_migrations.
+func MigratePostgres(ctx context.Context, db *sql.DB) error {
+	// Mulai tracing untuk fungsi MigratePostgres
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:Migrate")
+	defer span.End()
+
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	// fs.ReadDir mengembalikan file yang sudah terurut berdasarkan nama
+	entries, err := fs.ReadDir(postgresMigrations, "migrations/postgres")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := applyMigration(ctx, db, entry.Name()); err != nil {
+			return fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+// applyMigration menjalankan satu file migrasi jika belum tercatat di schema_migrations.
+func applyMigration(ctx context.Context, db *sql.DB, version string) error {
+	script, err := postgresMigrations.ReadFile("migrations/postgres/" + version)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Mengunci migrasi agar beberapa instance yang berjalan bersamaan tidak menjalankan migrasi yang sama
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext('schema_migrations'))"); err != nil {
+		return err
+	}
+
+	var applied bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", version).Scan(&applied)
+	if err != nil {
+		return err
+	}
+	if applied {
+		return tx.Commit()
+	}
+
+	if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)", version, time.Now().UTC().Unix()); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Postgres migration applied.", slog.String("version", version))
+	return tx.Commit()
+}
+
+// Find berfungsi untuk mencari satu produk (store) berdasarkan ID yang diberikan.
+func (r *postgresRepository) Find(ctx context.Context, id product.ID) (*product.Product, error) {
+	// Mulai tracing untuk fungsi Find
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:Find")
+	defer span.End()
+
+	// Memastikan ID berupa UUID yang valid sebelum dikirim ke database
+	if err := parseUUID(id); err != nil {
+		return nil, err
+	}
+
+	row := r.db.QueryRowContext(ctx, "SELECT "+productColumns+" FROM products WHERE id = $1 AND deleted_at = 0", id.String())
+	storeData, err := scanProduct(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, product.ErrProductNotFound
+		}
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, err
+	}
+	return storeData, nil
+}
+
+// FindByCode berfungsi untuk mencari satu produk (store) berdasarkan kode produk (SKU).
+func (r *postgresRepository) FindByCode(ctx context.Context, code string) (*product.Product, error) {
+	// Mulai tracing untuk fungsi FindByCode
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:FindByCode")
+	defer span.End()
+
+	// Produk tanpa kode tidak dapat dicari berdasarkan kode
+	if code == "" {
+		return nil, product.ErrProductNotFound
+	}
+
+	row := r.db.QueryRowContext(ctx, "SELECT "+productColumns+" FROM products WHERE code = $1 AND deleted_at = 0", code)
+	storeData, err := scanProduct(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, product.ErrProductNotFound
+		}
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, err
+	}
+	return storeData, nil
+}
+
+// FindAll berfungsi untuk mencari semua produk (store) dengan filter tertentu dan mendukung pagination.
+func (r *postgresRepository) FindAll(ctx context.Context, filter product.Filter) ([]*product.Product, *utils.Pagination, error) {
+	// Mulai tracing untuk fungsi FindAll
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:FindAll")
+	defer span.End()
+
+	// Pengaturan pagination
+	var currentPage, limit int
+	if filter.Limit <= 0 || filter.Page <= 0 {
+		currentPage, limit = 1, 10
+	} else {
+		currentPage, limit = filter.Page, filter.Limit
+	}
+	if filter.UseCursor && filter.Limit > 0 {
+		// Mode cursor tidak memakai nomor halaman
+		limit = filter.Limit
+	}
+	skip := (currentPage - 1) * limit
+
+	// Membuat kondisi WHERE, seluruh nilai dari pengguna dikirim sebagai parameter
+	var conditions []string
+	var args []any
+
+	// Produk yang sudah di-soft delete hanya disertakan jika diminta
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "deleted_at = 0")
+	}
+
+	// Pencarian keyword dengan regex yang tidak peka huruf besar/kecil, setara dengan $regex opsi "i".
+	// Keyword di-escape sehingga metakarakter regex dari pengguna dicocokkan sebagai teks biasa.
+	if filter.Keyword != "" {
+		args = append(args, regexp.QuoteMeta(filter.Keyword))
+		conditions = append(conditions, fmt.Sprintf("product_name ~* $%d", len(args)))
+	}
+
+	// Ekspresi filter diterjemahkan menjadi kondisi WHERE dengan parameter
+	for _, c := range filter.Conditions {
+		var condition string
+		condition, args = conditionClause(c, args)
+		conditions = append(conditions, condition)
+	}
+
+	// Pencarian berdasarkan jarak hanya mencakup produk yang memiliki koordinat di dalam radius,
+	// dan hasilnya diurutkan dari yang terdekat seperti $geoNear pada MongoDB
+	selectColumns, orderBy := selectedColumns(filter), orderByClause(filter.Sort)
+	if filter.Near != nil {
+		args = append(args, filter.Near.Latitude, filter.Near.Longitude)
+		distance := distanceExpression(len(args)-1, len(args))
+		conditions = append(conditions, "latitude IS NOT NULL")
+		if filter.Near.RadiusKM > 0 {
+			args = append(args, filter.Near.RadiusKM)
+			conditions = append(conditions, fmt.Sprintf("%s <= $%d", distance, len(args)))
+		}
+		selectColumns, orderBy = selectColumns+", "+distance, distance+", position"
+	}
+
+	// Mode cursor membaca baris setelah position pada cursor tanpa offset dan tanpa menghitung total
+	if filter.UseCursor {
+		if filter.Cursor != "" {
+			position, err := product.DecodeCursor(filter.Cursor)
+			if err != nil {
+				return nil, nil, err
+			}
+			after, err := strconv.ParseInt(position, 10, 64)
+			if err != nil {
+				return nil, nil, product.ErrInvalidCursor
+			}
+			args = append(args, after)
+			conditions = append(conditions, fmt.Sprintf("position > $%d", len(args)))
+		}
+		return r.findAfter(ctx, selectColumns, conditions, args, limit)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Menghitung total baris yang sesuai dengan filter untuk pagination
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT count(*) FROM products"+where, args...).Scan(&total); err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, nil, err
+	}
+
+	// Membuat struktur pagination
+	pagination := utils.Pagination{
+		Total:       total,
+		Limit:       limit,
+		CurrentPage: currentPage,
+	}
+
+	// Menjalankan query dengan urutan penyisipan dan pagination
+	args = append(args, limit, skip)
+	query := fmt.Sprintf("SELECT %s FROM products%s ORDER BY %s LIMIT $%d OFFSET $%d", selectColumns, where, orderBy, len(args)-1, len(args))
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var stores []*product.Product
+	for rows.Next() {
+		// Kolom jarak hanya dibaca pada pencarian berdasarkan jarak
+		var distance float64
+		var extra []any
+		if filter.Near != nil {
+			extra = append(extra, &distance)
+		}
+
+		elem, err := scanProduct(rows, extra...)
+		if err != nil {
+			slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+			continue
+		}
+		if filter.Near != nil {
+			elem.Distance = &distance
+		}
+		stores = append(stores, elem)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return stores, &pagination, nil
+}
+
+// Search berfungsi untuk mencari produk berdasarkan kata pada nama dan deskripsi menggunakan kolom search_vector.
+func (r *postgresRepository) Search(ctx context.Context, query product.SearchQuery) ([]*product.SearchHit, *utils.Pagination, error) {
+	// Mulai tracing untuk fungsi Search
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:Search")
+	defer span.End()
+
+	// Pengaturan pagination
+	var currentPage, limit int
+	if query.Limit <= 0 || query.Page <= 0 {
+		currentPage, limit = 1, 10
+	} else {
+		currentPage, limit = query.Page, query.Limit
+	}
+	skip := (currentPage - 1) * limit
+
+	// Kata yang sudah dibersihkan hanya berisi huruf dan angka, sehingga aman digabungkan dengan operator | (OR)
+	// tsquery dan tetap dikirim sebagai parameter
+	tsquery := strings.Join(query.Terms(), " | ")
+	const where = " WHERE deleted_at = 0 AND search_vector @@ to_tsquery('simple', $1)"
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT count(*) FROM products"+where, tsquery).Scan(&total); err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, nil, err
+	}
+	pagination := utils.Pagination{
+		Total:       total,
+		Limit:       limit,
+		CurrentPage: currentPage,
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+productColumns+", ts_rank(search_vector, to_tsquery('simple', $1)) AS score FROM products"+where+" ORDER BY score DESC, position LIMIT $2 OFFSET $3",
+		tsquery, limit, skip,
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var hits []*product.SearchHit
+	for rows.Next() {
+		var score float64
+		elem, err := scanProduct(rows, &score)
+		if err != nil {
+			slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+			continue
+		}
+		hits = append(hits, &product.SearchHit{Product: elem, Score: score})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return hits, &pagination, nil
+}
+
+// findAfter menjalankan cursor (keyset) pagination berdasarkan kolom position. Cursor berisi position baris terakhir
+// pada halaman sebelumnya, dan satu baris tambahan dibaca untuk mengetahui apakah masih ada halaman berikutnya.
+func (r *postgresRepository) findAfter(ctx context.Context, selectColumns string, conditions []string, args []any, limit int) ([]*product.Product, *utils.Pagination, error) {
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, limit+1)
+	query := fmt.Sprintf("SELECT %s, position FROM products%s ORDER BY position LIMIT $%d", selectColumns, where, len(args))
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var stores []*product.Product
+	var positions []int64
+	for rows.Next() {
+		var position int64
+		elem, err := scanProduct(rows, &position)
+		if err != nil {
+			slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+			continue
+		}
+		stores = append(stores, elem)
+		positions = append(positions, position)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// Cursor halaman berikutnya hanya dibuat jika baris tambahan ditemukan
+	pagination := utils.Pagination{Limit: limit, Cursor: true}
+	if len(stores) > limit {
+		stores = stores[:limit]
+		pagination.HasNext = true
+		pagination.NextCursor = product.EncodeCursor(strconv.FormatInt(positions[limit-1], 10))
+	}
+	return stores, &pagination, nil
+}
+
+// Store berfungsi untuk menyimpan data produk (store) baru ke dalam database.
+func (r *postgresRepository) Store(ctx context.Context, dataStore *product.Product) (product.ID, error) {
+	// Mulai tracing untuk fungsi Store
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:Store")
+	defer span.End()
+
+	// ID dibuat oleh database kecuali sudah ditentukan oleh pemanggil
+	var id string
+	var err error
+	latitude, longitude := geoColumns(dataStore.Geo)
+	if dataStore.ID.IsZero() {
+		err = r.db.QueryRowContext(ctx,
+			"INSERT INTO products (code, product_name, stock, created_at, updated_at, deleted_at, latitude, longitude, description) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id::text",
+			dataStore.Code, dataStore.Name, dataStore.Stock, dataStore.CreatedAt, dataStore.UpdatedAt, dataStore.DeletedAt, latitude, longitude, dataStore.Description,
+		).Scan(&id)
+	} else {
+		err = r.db.QueryRowContext(ctx,
+			"INSERT INTO products (id, code, product_name, stock, created_at, updated_at, deleted_at, latitude, longitude, description) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id::text",
+			dataStore.ID.String(), dataStore.Code, dataStore.Name, dataStore.Stock, dataStore.CreatedAt, dataStore.UpdatedAt, dataStore.DeletedAt, latitude, longitude, dataStore.Description,
+		).Scan(&id)
+	}
+	if err != nil {
+		// Index unik pada kode produk menolak kode yang sudah digunakan
+		if isUniqueViolation(err, "products_code_idx") {
+			return "", product.ErrCodeConflict
+		}
+		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+		return "", errors.New("error writing to repository")
+	}
+
+	// Kolom version bernilai default 1 untuk setiap produk baru
+	dataStore.Version = 1
+	return product.ID(id), nil
+}
+
+// Update berfungsi untuk memperbarui data produk (store) yang sudah ada di dalam database.
+// Seperti repository MongoDB, hanya field yang tidak kosong yang diperbarui.
+func (r *postgresRepository) Update(ctx context.Context, dataStore *product.Product) error {
+	// Mulai tracing untuk fungsi Update
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:Update")
+	defer span.End()
+
+	if err := parseUUID(dataStore.ID); err != nil {
+		return err
+	}
+
+	// Menggunakan refleksi untuk membuat klausa SET dari field yang tidak kosong.
+	// Nama kolom diambil dari tag bson sehingga sama dengan nama field pada MongoDB.
+	var sets []string
+	var args []any
+	values := reflect.ValueOf(*dataStore)
+	types := values.Type()
+	for i := 0; i < values.NumField(); i++ {
+		if !updatableField(types.Field(i)) || utils.IsEmptyStruct(values.Field(i)) {
+			continue
+		}
+
+		// Koordinat disimpan pada dua kolom latitude dan longitude
+		if point, ok := values.Field(i).Interface().(*product.GeoPoint); ok {
+			latitude, longitude := geoColumns(point)
+			args = append(args, latitude, longitude)
+			sets = append(sets, fmt.Sprintf("latitude = $%d, longitude = $%d", len(args)-1, len(args)))
+			continue
+		}
+
+		args = append(args, values.Field(i).Interface())
+		sets = append(sets, fmt.Sprintf("%s = $%d", columnName(types.Field(i)), len(args)))
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+
+	sets = append(sets, "version = version + 1")
+
+	// Produk yang sudah di-soft delete tidak dapat diperbarui, dan jika versi yang diharapkan diisi
+	// hanya baris dengan versi tersebut yang diperbarui
+	args = append(args, dataStore.ID.String())
+	where := fmt.Sprintf("id = $%d AND deleted_at = 0", len(args))
+	if dataStore.Version != 0 {
+		args = append(args, dataStore.Version)
+		where += fmt.Sprintf(" AND version = $%d", len(args))
+	}
+	query := fmt.Sprintf("UPDATE products SET %s WHERE %s RETURNING version", strings.Join(sets, ", "), where)
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&dataStore.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Produk yang tidak ada tidak dapat diperbarui, sedangkan produk dengan versi lain ditolak
+		return r.versionConflict(ctx, "id = $1", dataStore.ID.String(), dataStore.Version, product.ErrProductNotFound)
+	}
+	if err != nil {
+		// Index unik pada kode produk menolak kode yang sudah digunakan produk lain
+		if isUniqueViolation(err, "products_code_idx") {
+			return product.ErrCodeConflict
+		}
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return err
+	}
+
+	return nil
+}
+
+// Patch berfungsi untuk menerapkan perubahan eksplisit pada produk, termasuk nilai nol dan nil.
+func (r *postgresRepository) Patch(ctx context.Context, id product.ID, changes product.Changes, version int64) error {
+	// Mulai tracing untuk fungsi Patch
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:Patch")
+	defer span.End()
+
+	fields, err := patchFields(changes)
+	if err != nil {
+		return err
+	}
+
+	if err := parseUUID(id); err != nil {
+		return err
+	}
+
+	// Klausa SET dibuat dari setiap perubahan, nama kolom diambil dari tag bson
+	var sets []string
+	var args []any
+	for _, f := range fields {
+		// Koordinat disimpan pada dua kolom latitude dan longitude, nil mengosongkan keduanya
+		if point, ok := f.value.Interface().(*product.GeoPoint); ok {
+			latitude, longitude := geoColumns(point)
+			args = append(args, latitude, longitude)
+			sets = append(sets, fmt.Sprintf("latitude = $%d, longitude = $%d", len(args)-1, len(args)))
+			continue
+		}
+
+		args = append(args, f.value.Interface())
+		sets = append(sets, fmt.Sprintf("%s = $%d", columnName(f.field), len(args)))
+	}
+	sets = append(sets, "version = version + 1")
+
+	// Pemeriksaan versi dan perubahan dilakukan dalam satu statement
+	args = append(args, id.String())
+	where := fmt.Sprintf("id = $%d AND deleted_at = 0", len(args))
+	if version != 0 {
+		args = append(args, version)
+		where += fmt.Sprintf(" AND version = $%d", len(args))
+	}
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf("UPDATE products SET %s WHERE %s", strings.Join(sets, ", "), where), args...)
+	if err != nil {
+		// Index unik pada kode produk menolak kode yang sudah digunakan produk lain
+		if isUniqueViolation(err, "products_code_idx") {
+			return product.ErrCodeConflict
+		}
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return r.versionConflict(ctx, "id = $1", id.String(), version, product.ErrProductNotFound)
+	}
+
+	return nil
+}
+
+// DeleteById berfungsi untuk menandai produk (store) sebagai dihapus (soft delete) berdasarkan ID yang diberikan.
+func (r *postgresRepository) DeleteById(ctx context.Context, id product.ID, version int64) error {
+	// Mulai tracing untuk fungsi DeleteById
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:DeleteById")
+	defer span.End()
+
+	// Periksa apakah ID kosong
+	if id.IsZero() {
+		return product.ErrInvalidID
+	}
+
+	if err := parseUUID(id); err != nil {
+		return err
+	}
+
+	return r.softDelete(ctx, "id = $1", id.String(), version)
+}
+
+// Restore berfungsi untuk memulihkan produk (store) yang sudah di-soft delete berdasarkan ID yang diberikan.
+func (r *postgresRepository) Restore(ctx context.Context, id product.ID) error {
+	// Mulai tracing untuk fungsi Restore
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:Restore")
+	defer span.End()
+
+	if err := parseUUID(id); err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, "UPDATE products SET deleted_at = 0, version = version + 1 WHERE id = $1 AND deleted_at > 0", id.String())
+	if err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return err
+	}
+
+	return requireAffected(result, product.ErrProductNotFound)
+}
+
+// Purge berfungsi untuk menghapus permanen produk (store) yang sudah di-soft delete
+// pada atau sebelum waktu deletedBefore.
+func (r *postgresRepository) Purge(ctx context.Context, deletedBefore int64) (int64, error) {
+	// Mulai tracing untuk fungsi Purge
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:Purge")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM products WHERE deleted_at > 0 AND deleted_at <= $1", deletedBefore)
+	if err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// Remove berfungsi untuk menghapus permanen satu produk (store) berdasarkan ID yang diberikan.
+func (r *postgresRepository) Remove(ctx context.Context, id product.ID) error {
+	// Mulai tracing untuk fungsi Remove
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:Remove")
+	defer span.End()
+
+	if err := parseUUID(id); err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM products WHERE id = $1", id.String())
+	if err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return err
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return product.ErrProductNotFound
+	}
+	return nil
+}
+
+// Delete berfungsi untuk menandai produk (store) sebagai dihapus (soft delete) berdasarkan kode produk (SKU).
+func (r *postgresRepository) Delete(ctx context.Context, code string, version int64) error {
+	// Mulai tracing untuk fungsi Delete
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:Delete")
+	defer span.End()
+
+	// Periksa apakah kode kosong
+	if code == "" {
+		return product.ErrProductNotFound
+	}
+
+	return r.softDelete(ctx, "code = $1", code, version)
+}
+
+// softDelete mengisi deleted_at dan menambah versi pada produk yang cocok dengan kondisi key (misalnya "id = $1").
+// Jika version bukan 0, hanya produk dengan versi tersebut yang diubah, dan ErrVersionConflict dikembalikan
+// jika produk ada dengan versi lain.
+func (r *postgresRepository) softDelete(ctx context.Context, key string, value any, version int64) error {
+	query := "UPDATE products SET deleted_at = $2, version = version + 1 WHERE " + key + " AND deleted_at = 0"
+	args := []any{value, time.Now().UTC().Unix()}
+	if version != 0 {
+		query += " AND version = $3"
+		args = append(args, version)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected > 0 {
+		return err
+	}
+	return r.versionConflict(ctx, key, value, version, product.ErrProductNotFound)
+}
+
+// versionConflict mengembalikan ErrVersionConflict jika version bukan 0 dan produk yang cocok dengan kondisi key
+// masih ada dan belum di-soft delete, selain itu notFound dikembalikan.
+func (r *postgresRepository) versionConflict(ctx context.Context, key string, value any, version int64, notFound error) error {
+	if version == 0 {
+		return notFound
+	}
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM products WHERE "+key+" AND deleted_at = 0)", value).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return product.ErrVersionConflict
+	}
+	return notFound
+}
+
+// AdjustStock berfungsi untuk mengubah stok produk dan mencatat pergerakannya di dalam satu transaksi.
+// Kondisi stok tidak lebih kecil dari stok yang ditahan (dan dari stok yang sudah dialokasikan jika tanpa lokasi)
+// serta versi yang diharapkan (jika diisi) diperiksa di dalam klausa WHERE sehingga aman dari race condition.
+func (r *postgresRepository) AdjustStock(ctx context.Context, movement *product.StockMovement) (*product.StockMovement, error) {
+	// Mulai tracing untuk fungsi AdjustStock
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:AdjustStock")
+	defer span.End()
+
+	if err := parseUUID(movement.ProductID); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Perubahan pada sebuah lokasi juga mengubah jumlah stok yang sudah dialokasikan,
+	// sedangkan perubahan tanpa lokasi hanya dapat memakai stok yang belum dialokasikan
+	// Versi yang diharapkan ($3) hanya diperiksa jika diisi
+	query := "UPDATE products SET stock = stock + $1, version = version + 1 WHERE id = $2 AND deleted_at = 0 AND ($3::bigint = 0 OR version = $3) AND stock + $1 >= reserved AND stock + $1 >= allocated RETURNING stock"
+	if !movement.LocationID.IsZero() {
+		if _, err := uuid.Parse(movement.LocationID.String()); err != nil {
+			return nil, product.ErrLocationNotFound
+		}
+		query = "UPDATE products SET stock = stock + $1, allocated = allocated + $1, version = version + 1 WHERE id = $2 AND deleted_at = 0 AND ($3::bigint = 0 OR version = $3) AND stock + $1 >= reserved RETURNING stock"
+	}
+
+	stored := *movement
+	err = tx.QueryRowContext(ctx, query, movement.Quantity, movement.ProductID.String(), movement.ExpectedVersion).Scan(&stored.StockAfter)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+			return nil, err
+		}
+
+		// Membedakan produk yang tidak ditemukan, versi yang tidak cocok, dan stok yang tidak mencukupi
+		var version int64
+		err = tx.QueryRowContext(ctx, "SELECT version FROM products WHERE id = $1 AND deleted_at = 0", movement.ProductID.String()).Scan(&version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, product.ErrProductNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if movement.ExpectedVersion != 0 && movement.ExpectedVersion != version {
+			return nil, product.ErrVersionConflict
+		}
+		return nil, product.ErrInsufficientStock
+	}
+
+	// Mengubah stok lokasi, stok lokasi tidak boleh menjadi negatif
+	if !movement.LocationID.IsZero() {
+		if err := changeLocationStock(ctx, tx, movement.ProductID, movement.LocationID, movement.Quantity, movement.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	// Mencatat pergerakan stok ke buku besar
+	if err := insertMovement(ctx, tx, &stored); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// FindMovements berfungsi untuk membaca riwayat pergerakan stok sebuah produk, dari yang terbaru, dengan pagination.
+func (r *postgresRepository) FindMovements(ctx context.Context, productID product.ID, filter product.MovementFilter) ([]*product.StockMovement, *utils.Pagination, error) {
+	// Mulai tracing untuk fungsi FindMovements
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:FindMovements")
+	defer span.End()
+
+	if err := parseUUID(productID); err != nil {
+		return nil, nil, err
+	}
+
+	// Pengaturan pagination
+	var currentPage, limit int
+	if filter.Limit <= 0 || filter.Page <= 0 {
+		currentPage, limit = 1, 10
+	} else {
+		currentPage, limit = filter.Page, filter.Limit
+	}
+	skip := (currentPage - 1) * limit
+
+	// Menghitung total pergerakan stok untuk pagination
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT count(*) FROM stock_movements WHERE product_id = $1", productID.String()).Scan(&total); err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, nil, err
+	}
+
+	pagination := utils.Pagination{
+		Total:       total,
+		Limit:       limit,
+		CurrentPage: currentPage,
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+movementColumns+" FROM stock_movements WHERE product_id = $1 ORDER BY position DESC LIMIT $2 OFFSET $3",
+		productID.String(), limit, skip,
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var movements []*product.StockMovement
+	for rows.Next() {
+		elem, err := scanMovement(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+			continue
+		}
+		movements = append(movements, elem)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return movements, &pagination, nil
+}
+
+// Reserve berfungsi untuk menahan stok produk dan menyimpan reservasi di dalam satu transaksi.
+// Kondisi stok tersedia (stock - reserved) diperiksa di dalam klausa WHERE sehingga aman dari race condition.
+func (r *postgresRepository) Reserve(ctx context.Context, reservation *product.Reservation) (*product.Reservation, error) {
+	// Mulai tracing untuk fungsi Reserve
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:Reserve")
+	defer span.End()
+
+	if err := parseUUID(reservation.ProductID); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE products SET reserved = reserved + $1, version = version + 1 WHERE id = $2 AND deleted_at = 0 AND stock - reserved >= $1",
+		reservation.Quantity, reservation.ProductID.String(),
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, err
+	}
+	if err := requireAffected(result, product.ErrProductNotFound); err != nil {
+		// Membedakan produk yang tidak ditemukan dengan stok yang tidak mencukupi
+		var exists bool
+		err = tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND deleted_at = 0)", reservation.ProductID.String()).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, product.ErrProductNotFound
+		}
+		return nil, product.ErrInsufficientStock
+	}
+
+	stored := *reservation
+	var id string
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO stock_reservations (product_id, quantity, status, expires_at, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id::text",
+		stored.ProductID.String(), stored.Quantity, stored.Status, stored.ExpiresAt, stored.CreatedAt, stored.UpdatedAt,
+	).Scan(&id)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+		return nil, errors.New("error writing to repository")
+	}
+	stored.ID = product.ID(id)
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// FindReservation berfungsi untuk mencari reservasi berdasarkan ID.
+func (r *postgresRepository) FindReservation(ctx context.Context, reservationID product.ID) (*product.Reservation, error) {
+	// Mulai tracing untuk fungsi FindReservation
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:FindReservation")
+	defer span.End()
+
+	// ID yang bukan UUID tidak mungkin dimiliki reservasi mana pun
+	if _, err := uuid.Parse(reservationID.String()); err != nil {
+		return nil, product.ErrReservationNotFound
+	}
+
+	row := r.db.QueryRowContext(ctx, "SELECT "+reservationColumns+" FROM stock_reservations WHERE id = $1", reservationID.String())
+	reservation, err := scanReservation(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, product.ErrReservationNotFound
+		}
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// ConfirmReservation berfungsi untuk mengonfirmasi reservasi aktif, mengurangi stok dan stok yang ditahan produk,
+// lalu mencatat pergerakan stok di dalam satu transaksi.
+func (r *postgresRepository) ConfirmReservation(ctx context.Context, reservationID product.ID, confirmedAt int64) (*product.Reservation, error) {
+	// Mulai tracing untuk fungsi ConfirmReservation
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:ConfirmReservation")
+	defer span.End()
+
+	if _, err := uuid.Parse(reservationID.String()); err != nil {
+		return nil, product.ErrReservationNotFound
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Reservasi yang sudah melewati batas waktu tidak dapat dikonfirmasi walaupun belum disapu
+	reservation, err := transitionReservation(ctx, tx, reservationID, product.ReservationConfirmed, confirmedAt, "AND expires_at > $4", confirmedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mengurangi stok fisik dan stok yang ditahan dengan jumlah yang sama.
+	// Reservasi memakai stok yang belum dialokasikan ke lokasi.
+	movement := product.StockMovement{
+		ProductID: reservation.ProductID,
+		Quantity:  -reservation.Quantity,
+		Reason:    product.ReasonSale,
+		Note:      "reservation " + reservation.ID.String(),
+		CreatedAt: confirmedAt,
+	}
+	err = tx.QueryRowContext(ctx,
+		"UPDATE products SET stock = stock - $1, reserved = reserved - $1, version = version + 1 WHERE id = $2 AND stock - $1 >= allocated RETURNING stock",
+		reservation.Quantity, reservation.ProductID.String(),
+	).Scan(&movement.StockAfter)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Transaksi dibatalkan sehingga reservasi tetap active
+			var exists bool
+			err = tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)", reservation.ProductID.String()).Scan(&exists)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				return nil, product.ErrProductNotFound
+			}
+			return nil, product.ErrInsufficientStock
+		}
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, err
+	}
+
+	// Mencatat pengurangan stok ke buku besar
+	if err := insertMovement(ctx, tx, &movement); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// ReleaseReservation berfungsi untuk mengubah reservasi aktif menjadi released atau expired
+// lalu melepas stok yang ditahan pada produk di dalam satu transaksi.
+func (r *postgresRepository) ReleaseReservation(ctx context.Context, reservationID product.ID, status string, releasedAt int64) (*product.Reservation, error) {
+	// Mulai tracing untuk fungsi ReleaseReservation
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:ReleaseReservation")
+	defer span.End()
+
+	if _, err := uuid.Parse(reservationID.String()); err != nil {
+		return nil, product.ErrReservationNotFound
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	reservation, err := transitionReservation(ctx, tx, reservationID, status, releasedAt, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Melepas stok yang ditahan, produk yang sudah di-purge tidak lagi cocok dan diabaikan
+	_, err = tx.ExecContext(ctx, "UPDATE products SET reserved = reserved - $1, version = version + 1 WHERE id = $2", reservation.Quantity, reservation.ProductID.String())
+	if err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// FindExpiredReservations berfungsi untuk mencari reservasi aktif yang kedaluwarsa, dari yang paling lama.
+func (r *postgresRepository) FindExpiredReservations(ctx context.Context, before int64, limit int) ([]*product.Reservation, error) {
+	// Mulai tracing untuk fungsi FindExpiredReservations
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:FindExpiredReservations")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+reservationColumns+" FROM stock_reservations WHERE status = $1 AND expires_at <= $2 ORDER BY expires_at LIMIT $3",
+		product.ReservationActive, before, limit,
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations []*product.Reservation
+	for rows.Next() {
+		elem, err := scanReservation(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+			continue
+		}
+		reservations = append(reservations, elem)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// StoreLocation berfungsi untuk menyimpan lokasi baru ke dalam tabel locations.
+func (r *postgresRepository) StoreLocation(ctx context.Context, location *product.Location) (product.ID, error) {
+	// Mulai tracing untuk fungsi StoreLocation
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:StoreLocation")
+	defer span.End()
+
+	var id string
+	err := r.db.QueryRowContext(ctx,
+		"INSERT INTO locations (code, name, type, created_at) VALUES ($1, $2, $3, $4) RETURNING id::text",
+		location.Code, location.Name, location.Type, location.CreatedAt,
+	).Scan(&id)
+	if err != nil {
+		// Constraint unik pada kode lokasi menolak kode yang sudah digunakan
+		if isUniqueViolation(err, "locations_code_key") {
+			return "", product.ErrLocationCodeConflict
+		}
+		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+		return "", errors.New("error writing to repository")
+	}
+	return product.ID(id), nil
+}
+
+// FindLocation berfungsi untuk mencari lokasi berdasarkan ID.
+func (r *postgresRepository) FindLocation(ctx context.Context, locationID product.ID) (*product.Location, error) {
+	// Mulai tracing untuk fungsi FindLocation
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:FindLocation")
+	defer span.End()
+
+	// ID yang bukan UUID tidak mungkin dimiliki lokasi mana pun
+	if _, err := uuid.Parse(locationID.String()); err != nil {
+		return nil, product.ErrLocationNotFound
+	}
+
+	row := r.db.QueryRowContext(ctx, "SELECT "+locationColumns+" FROM locations WHERE id = $1", locationID.String())
+	location, err := scanLocation(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, product.ErrLocationNotFound
+		}
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, err
+	}
+	return location, nil
+}
+
+// FindLocations berfungsi untuk mengembalikan semua lokasi sesuai urutan pembuatan.
+func (r *postgresRepository) FindLocations(ctx context.Context) ([]*product.Location, error) {
+	// Mulai tracing untuk fungsi FindLocations
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:FindLocations")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT "+locationColumns+" FROM locations ORDER BY position")
+	if err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []*product.Location
+	for rows.Next() {
+		elem, err := scanLocation(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+			continue
+		}
+		locations = append(locations, elem)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return locations, nil
+}
+
+// FindLocationStocks berfungsi untuk mengembalikan stok per lokasi yang sesuai dengan filter.
+func (r *postgresRepository) FindLocationStocks(ctx context.Context, filter product.LocationStockFilter) ([]*product.LocationStock, error) {
+	// Mulai tracing untuk fungsi FindLocationStocks
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:FindLocationStocks")
+	defer span.End()
+
+	// ID yang bukan UUID tidak mungkin memiliki stok, sehingga dilewati
+	var conditions []string
+	var args []any
+	if len(filter.ProductIDs) > 0 {
+		productIDs := make([]string, 0, len(filter.ProductIDs))
+		for _, id := range filter.ProductIDs {
+			if _, err := uuid.Parse(id.String()); err == nil {
+				productIDs = append(productIDs, id.String())
+			}
+		}
+		if len(productIDs) == 0 {
+			return nil, nil
+		}
+		args = append(args, productIDs)
+		conditions = append(conditions, fmt.Sprintf("product_id::text = ANY($%d)", len(args)))
+	}
+	if !filter.LocationID.IsZero() {
+		if _, err := uuid.Parse(filter.LocationID.String()); err != nil {
+			return nil, nil
+		}
+		args = append(args, filter.LocationID.String())
+		conditions = append(conditions, fmt.Sprintf("location_id = $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT "+locationStockColumns+" FROM location_stocks"+where+" ORDER BY position", args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stocks []*product.LocationStock
+	for rows.Next() {
+		elem, err := scanLocationStock(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+			continue
+		}
+		stocks = append(stocks, elem)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stocks, nil
+}
+
+// TransferStock berfungsi untuk memindahkan stok antar lokasi dan mencatat pergerakannya di dalam satu transaksi.
+// Lokasi asal atau tujuan yang kosong berarti stok yang belum dialokasikan ke lokasi mana pun.
+func (r *postgresRepository) TransferStock(ctx context.Context, transfer *product.StockTransfer) (*product.StockTransfer, error) {
+	// Mulai tracing untuk fungsi TransferStock
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:TransferStock")
+	defer span.End()
+
+	if err := parseUUID(transfer.ProductID); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Mengunci baris produk agar stok tidak berubah selama perpindahan
+	var stock, allocated int64
+	err = tx.QueryRowContext(ctx,
+		"SELECT stock, allocated FROM products WHERE id = $1 AND deleted_at = 0 FOR UPDATE",
+		transfer.ProductID.String(),
+	).Scan(&stock, &allocated)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, product.ErrProductNotFound
+		}
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, err
+	}
+
+	// Mengambil stok dari lokasi asal atau dari stok yang belum dialokasikan
+	if transfer.FromLocationID.IsZero() {
+		if stock-allocated < transfer.Quantity {
+			return nil, product.ErrInsufficientStock
+		}
+		allocated += transfer.Quantity
+	} else if err := changeLocationStock(ctx, tx, transfer.ProductID, transfer.FromLocationID, -transfer.Quantity, transfer.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	// Menambahkan stok ke lokasi tujuan atau mengembalikannya menjadi stok yang belum dialokasikan
+	if transfer.ToLocationID.IsZero() {
+		allocated -= transfer.Quantity
+	} else if err := changeLocationStock(ctx, tx, transfer.ProductID, transfer.ToLocationID, transfer.Quantity, transfer.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE products SET allocated = $1, version = version + 1 WHERE id = $2", allocated, transfer.ProductID.String()); err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, err
+	}
+
+	// Mencatat perpindahan ke buku besar, total stok produk tidak berubah
+	for _, movement := range transferMovements(transfer, stock) {
+		if err := insertMovement(ctx, tx, movement); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	recorded := *transfer
+	return &recorded, nil
+}
+
+// changeLocationStock menambahkan quantity ke stok produk pada sebuah lokasi di dalam transaksi tx.
+// Baris stok lokasi dibuat jika belum ada, dan ErrInsufficientStock dikembalikan jika stok lokasi akan menjadi negatif.
+func changeLocationStock(ctx context.Context, tx *sql.Tx, productID, locationID product.ID, quantity, updatedAt int64) error {
+	if _, err := uuid.Parse(locationID.String()); err != nil {
+		return product.ErrLocationNotFound
+	}
+
+	// Pengurangan stok hanya berlaku jika stok lokasi mencukupi
+	if quantity < 0 {
+		result, err := tx.ExecContext(ctx,
+			"UPDATE location_stocks SET quantity = quantity + $1, updated_at = $2 WHERE product_id = $3 AND location_id = $4 AND quantity + $1 >= 0",
+			quantity, updatedAt, productID.String(), locationID.String(),
+		)
+		if err != nil {
+			slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return product.ErrInsufficientStock
+		}
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO location_stocks (product_id, location_id, quantity, updated_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (product_id, location_id) DO UPDATE SET quantity = location_stocks.quantity + EXCLUDED.quantity, updated_at = EXCLUDED.updated_at`,
+		productID.String(), locationID.String(), quantity, updatedAt,
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return err
+	}
+	return nil
+}
+
+// insertMovement menyisipkan satu pergerakan stok ke buku besar di dalam transaksi tx dan mengisi ID-nya.
+func insertMovement(ctx context.Context, tx *sql.Tx, movement *product.StockMovement) error {
+	var id string
+	err := tx.QueryRowContext(ctx,
+		"INSERT INTO stock_movements (product_id, quantity, reason, note, stock_after, created_at, location_id) VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::uuid) RETURNING id::text",
+		movement.ProductID.String(), movement.Quantity, movement.Reason, movement.Note, movement.StockAfter, movement.CreatedAt, movement.LocationID.String(),
+	).Scan(&id)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+		return errors.New("error writing to repository")
+	}
+	movement.ID = product.ID(id)
+	return nil
+}
+
+// transitionReservation mengubah status reservasi aktif di dalam transaksi tx dan mengembalikan reservasi setelah diubah.
+// condition adalah kondisi tambahan pada klausa WHERE dengan parameter mulai dari $4.
+// Jika tidak ada yang cocok, ErrReservationNotFound atau ErrReservationNotActive dikembalikan.
+func transitionReservation(ctx context.Context, tx *sql.Tx, reservationID product.ID, status string, at int64, condition string, args ...any) (*product.Reservation, error) {
+	query := "UPDATE stock_reservations SET status = $1, updated_at = $2 WHERE id = $3 AND status = 'active' " + condition + " RETURNING " + reservationColumns
+	row := tx.QueryRowContext(ctx, query, append([]any{status, at, reservationID.String()}, args...)...)
+	reservation, err := scanReservation(row)
+	if err == nil {
+		return reservation, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, err
+	}
+
+	// Membedakan reservasi yang tidak ada dengan reservasi yang sudah tidak aktif
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM stock_reservations WHERE id = $1)", reservationID.String()).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, product.ErrReservationNotFound
+	}
+	return nil, product.ErrReservationNotActive
+}
+
+// rowScanner adalah kontrak bersama *sql.Row dan *sql.Rows untuk membaca satu baris.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanProduct membaca satu baris dengan urutan kolom productColumns menjadi Product.
+// Kolom tambahan setelah productColumns dibaca ke dalam extra.
+func scanProduct(row rowScanner, extra ...any) (*product.Product, error) {
+	var storeData product.Product
+	var id string
+	var latitude, longitude sql.NullFloat64
+	dest := []any{&id, &storeData.Code, &storeData.Name, &storeData.Stock, &storeData.Reserved, &storeData.Allocated, &storeData.CreatedAt, &storeData.UpdatedAt, &storeData.DeletedAt, &latitude, &longitude, &storeData.Description, &storeData.Version}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	storeData.ID = product.ID(id)
+	if latitude.Valid && longitude.Valid {
+		storeData.Geo = product.NewGeoPoint(latitude.Float64, longitude.Float64)
+	}
+	return &storeData, nil
+}
+
+// selectedColumns mengembalikan daftar kolom produk untuk projection pada Filter.Fields. Kolom yang tidak dipilih
+// diganti dengan nilai kosong sehingga jumlah dan urutan kolom tetap sama dengan scanProduct. ID selalu dibaca.
+func selectedColumns(filter product.Filter) string {
+	if len(filter.Fields) == 0 {
+		return productColumns
+	}
+
+	columns := make([]string, 0, len(productFields))
+	for _, f := range productFields {
+		if f.field == "product_id" || filter.Selects(f.field) {
+			columns = append(columns, f.column)
+		} else {
+			columns = append(columns, f.zero)
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+// conditionOperators memetakan operator AST filter ke operator SQL.
+var conditionOperators = map[product.Operator]string{
+	product.OpEq:  "=",
+	product.OpNe:  "<>",
+	product.OpGt:  ">",
+	product.OpGte: ">=",
+	product.OpLt:  "<",
+	product.OpLte: "<=",
+}
+
+// conditionClause menerjemahkan satu node AST filter menjadi kondisi WHERE dan menambahkan nilainya ke args.
+// Nama JSON field sama dengan nama kolom kecuali product_id yang dibandingkan sebagai teks agar nilai yang
+// bukan UUID tidak menyebabkan error, melainkan tidak cocok dengan baris mana pun.
+func conditionClause(c product.Condition, args []any) (string, []any) {
+	column := c.Field
+	if column == "product_id" {
+		column = "id::text"
+	}
+
+	if c.Operator == product.OpIn {
+		placeholders := make([]string, 0, len(c.Values))
+		for _, value := range c.Values {
+			args = append(args, value)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", ")), args
+	}
+
+	args = append(args, c.Values[0])
+	return fmt.Sprintf("%s %s $%d", column, conditionOperators[c.Operator], len(args)), args
+}
+
+// orderByClause membuat klausa ORDER BY dari parameter sort. Nama JSON field yang dapat diurutkan sama dengan
+// nama kolom, dan position selalu ditambahkan sebagai kriteria terakhir agar urutan stabil untuk nilai yang sama.
+func orderByClause(sorts []product.SortField) string {
+	var terms []string
+	for _, s := range sorts {
+		if s.Desc {
+			terms = append(terms, s.Field+" DESC")
+		} else {
+			terms = append(terms, s.Field)
+		}
+	}
+	return strings.Join(append(terms, "position"), ", ")
+}
+
+// geoColumns mengubah koordinat produk menjadi nilai kolom latitude dan longitude, NULL jika produk tidak memiliki koordinat.
+func geoColumns(point *product.GeoPoint) (sql.NullFloat64, sql.NullFloat64) {
+	if point == nil {
+		return sql.NullFloat64{}, sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: point.Latitude(), Valid: true}, sql.NullFloat64{Float64: point.Longitude(), Valid: true}
+}
+
+// distanceExpression mengembalikan ekspresi SQL jarak haversine dalam kilometer dari kolom latitude dan longitude
+// ke titik pada parameter $latParam dan $lngParam, sama dengan product.GeoPoint.DistanceKM.
+func distanceExpression(latParam, lngParam int) string {
+	return fmt.Sprintf(
+		"(2 * %v * asin(least(1, sqrt(power(sin(radians(latitude - $%[2]d) / 2), 2) + cos(radians($%[2]d)) * cos(radians(latitude)) * power(sin(radians(longitude - $%[3]d) / 2), 2)))))",
+		product.EarthRadiusKM, latParam, lngParam,
+	)
+}
+
+// scanMovement membaca satu baris dengan urutan kolom movementColumns menjadi StockMovement.
+func scanMovement(row rowScanner) (*product.StockMovement, error) {
+	var movement product.StockMovement
+	var id, productID, locationID string
+	err := row.Scan(&id, &productID, &movement.Quantity, &movement.Reason, &movement.Note, &movement.StockAfter, &movement.CreatedAt, &locationID)
+	if err != nil {
+		return nil, err
+	}
+	movement.ID = product.ID(id)
+	movement.ProductID = product.ID(productID)
+	movement.LocationID = product.ID(locationID)
+	return &movement, nil
+}
+
+// scanReservation membaca satu baris dengan urutan kolom reservationColumns menjadi Reservation.
+func scanReservation(row rowScanner) (*product.Reservation, error) {
+	var reservation product.Reservation
+	var id, productID string
+	err := row.Scan(&id, &productID, &reservation.Quantity, &reservation.Status, &reservation.ExpiresAt, &reservation.CreatedAt, &reservation.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	reservation.ID = product.ID(id)
+	reservation.ProductID = product.ID(productID)
+	return &reservation, nil
+}
+
+// scanLocation membaca satu baris dengan urutan kolom locationColumns menjadi Location.
+func scanLocation(row rowScanner) (*product.Location, error) {
+	var location product.Location
+	var id string
+	err := row.Scan(&id, &location.Code, &location.Name, &location.Type, &location.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	location.ID = product.ID(id)
+	return &location, nil
+}
+
+// scanLocationStock membaca satu baris dengan urutan kolom locationStockColumns menjadi LocationStock.
+func scanLocationStock(row rowScanner) (*product.LocationStock, error) {
+	var stock product.LocationStock
+	var productID, locationID string
+	err := row.Scan(&productID, &locationID, &stock.Quantity, &stock.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	stock.ProductID = product.ID(productID)
+	stock.LocationID = product.ID(locationID)
+	return &stock, nil
+}
+
+// isUniqueViolation memeriksa apakah err adalah pelanggaran index unik dengan nama constraint tertentu.
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
+}
+
+// requireAffected mengembalikan notFound jika tidak ada baris yang terpengaruh.
+func requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// parseUUID memastikan ID domain berupa UUID yang valid sebelum dikirim ke database,
+// ID dengan format lain ditolak dengan product.ErrInvalidID.
+func parseUUID(id product.ID) error {
+	if _, err := uuid.Parse(id.String()); err != nil {
+		// Pesan error parser tidak diteruskan ke klien, cukup nilai ID yang ditolak
+		return fmt.Errorf("%w: %q", product.ErrInvalidID, id.String())
+	}
+	return nil
+}
+
+// Export berfungsi untuk membaca semua produk yang sesuai filter dan meneruskannya satu per satu ke fn.
+func (r *postgresRepository) Export(ctx context.Context, filter product.Filter, fn func(*product.Product) error) error {
+	// Mulai tracing untuk fungsi Export
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:Export")
+	defer span.End()
+
+	return exportPages(ctx, r, filter, fn)
+}
+
+// BulkWrite berfungsi untuk menjalankan banyak operasi create, upsert, dan delete satu per satu.
+func (r *postgresRepository) BulkWrite(ctx context.Context, operations []product.BulkOperation) ([]*product.BulkResult, error) {
+	// Mulai tracing untuk fungsi BulkWrite
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:BulkWrite")
+	defer span.End()
+
+	return bulkWriteEach(ctx, r, operations)
+}
+
+// ClaimIdempotencyKey berfungsi untuk menyimpan klaim Idempotency-Key baru, atau mengembalikan record yang sudah ada.
+// Primary key pada kolom key memastikan dua klaim bersamaan dengan key yang sama tidak dapat sama-sama berhasil.
+func (r *postgresRepository) ClaimIdempotencyKey(ctx context.Context, record *product.IdempotencyRecord) (*product.IdempotencyRecord, error) {
+	// Mulai tracing untuk fungsi ClaimIdempotencyKey
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:ClaimIdempotencyKey")
+	defer span.End()
+
+	// Record yang sudah kedaluwarsa dihapus sehingga key-nya dapat diklaim kembali
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= $1", record.CreatedAt); err != nil {
+		slog.ErrorContext(ctx, "Error deleting expired idempotency keys", slog.Any("err ", err))
+		return nil, err
+	}
+
+	// Record yang sedang diperiksa dapat dilepas di antara INSERT dan SELECT, sehingga klaim dicoba sekali lagi
+	for attempt := 0; attempt < 2; attempt++ {
+		result, err := r.db.ExecContext(ctx,
+			`INSERT INTO idempotency_keys (key, fingerprint, created_at, expires_at) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (key) DO NOTHING`,
+			record.Key, record.Fingerprint, record.CreatedAt, record.ExpiresAt,
+		)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+			return nil, err
+		}
+		if affected, err := result.RowsAffected(); err != nil || affected == 1 {
+			return nil, err
+		}
+
+		var existing product.IdempotencyRecord
+		var header []byte
+		err = r.db.QueryRowContext(ctx,
+			"SELECT key, fingerprint, status, header, body, created_at, expires_at FROM idempotency_keys WHERE key = $1",
+			record.Key,
+		).Scan(&existing.Key, &existing.Fingerprint, &existing.Status, &header, &existing.Body, &existing.CreatedAt, &existing.ExpiresAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+			return nil, err
+		}
+		if err := json.Unmarshal(header, &existing.Header); err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+	return nil, product.ErrIdempotencyKeyInProgress
+}
+
+// CompleteIdempotencyKey berfungsi untuk menyimpan response pada record Idempotency-Key yang masih diproses.
+func (r *postgresRepository) CompleteIdempotencyKey(ctx context.Context, record *product.IdempotencyRecord) error {
+	// Mulai tracing untuk fungsi CompleteIdempotencyKey
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:CompleteIdempotencyKey")
+	defer span.End()
+
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return err
+	}
+	if record.Header == nil {
+		header = []byte("{}")
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		"UPDATE idempotency_keys SET status = $2, header = $3, body = $4, expires_at = $5 WHERE key = $1 AND status = 0",
+		record.Key, record.Status, header, record.Body, record.ExpiresAt,
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+	}
+	return err
+}
+
+// ReleaseIdempotencyKey berfungsi untuk menghapus record Idempotency-Key yang masih diproses.
+func (r *postgresRepository) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	// Mulai tracing untuk fungsi ReleaseIdempotencyKey
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:ReleaseIdempotencyKey")
+	defer span.End()
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE key = $1 AND status = 0", key)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error deleting from repository", slog.Any("err ", err))
+	}
+	return err
+}
+
+// StoreImportJob berfungsi untuk menyimpan job import baru dengan UUID yang dibuat oleh database.
+func (r *postgresRepository) StoreImportJob(ctx context.Context, job *product.ImportJob) (*product.ImportJob, error) {
+	// Mulai tracing untuk fungsi StoreImportJob
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:StoreImportJob")
+	defer span.End()
+
+	header, changes, rowErrors, err := marshalImportJob(job)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := *job
+	var id string
+	err = r.db.QueryRowContext(ctx,
+		`INSERT INTO import_jobs (status, mode, dry_run, filename, total_rows, processed_rows, created, updated, rejected, error, header, changes, errors, created_at, updated_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) RETURNING id::text`,
+		job.Status, job.Mode, job.DryRun, job.Filename, job.TotalRows, job.ProcessedRows, job.Created, job.Updated, job.Rejected,
+		job.Error, header, changes, rowErrors, job.CreatedAt, job.UpdatedAt, job.FinishedAt,
+	).Scan(&id)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+		return nil, err
+	}
+	stored.ID = product.ID(id)
+	return &stored, nil
+}
+
+// UpdateImportJob berfungsi untuk menyimpan status, progres, dan hasil job import.
+func (r *postgresRepository) UpdateImportJob(ctx context.Context, job *product.ImportJob) error {
+	// Mulai tracing untuk fungsi UpdateImportJob
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:UpdateImportJob")
+	defer span.End()
+
+	// ID yang bukan UUID tidak mungkin dimiliki job mana pun
+	if _, err := uuid.Parse(job.ID.String()); err != nil {
+		return product.ErrJobNotFound
+	}
+
+	header, changes, rowErrors, err := marshalImportJob(job)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE import_jobs SET status = $2, mode = $3, dry_run = $4, filename = $5, total_rows = $6, processed_rows = $7,
+		created = $8, updated = $9, rejected = $10, error = $11, header = $12, changes = $13, errors = $14, updated_at = $15, finished_at = $16
+		WHERE id = $1`,
+		job.ID.String(), job.Status, job.Mode, job.DryRun, job.Filename, job.TotalRows, job.ProcessedRows,
+		job.Created, job.Updated, job.Rejected, job.Error, header, changes, rowErrors, job.UpdatedAt, job.FinishedAt,
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return product.ErrJobNotFound
+	}
+	return nil
+}
+
+// FindImportJob berfungsi untuk mencari job import berdasarkan ID.
+func (r *postgresRepository) FindImportJob(ctx context.Context, id product.ID) (*product.ImportJob, error) {
+	// Mulai tracing untuk fungsi FindImportJob
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:postgres:FindImportJob")
+	defer span.End()
+
+	// ID yang bukan UUID tidak mungkin dimiliki job mana pun
+	if _, err := uuid.Parse(id.String()); err != nil {
+		return nil, product.ErrJobNotFound
+	}
+
+	row := r.db.QueryRowContext(ctx, "SELECT "+importJobColumns+" FROM import_jobs WHERE id = $1", id.String())
+	job, err := scanImportJob(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, product.ErrJobNotFound
+		}
+		slog.ErrorContext(ctx, "Query Error", slog.Any("err ", err))
+		return nil, err
+	}
+	return job, nil
+}
+
+// marshalImportJob mengubah header, perubahan, dan baris yang ditolak menjadi JSON untuk kolom JSONB.
+// Slice yang kosong disimpan sebagai array kosong sesuai nilai default kolom.
+func marshalImportJob(job *product.ImportJob) (header, changes, rowErrors []byte, err error) {
+	values := []interface{}{job.Header, job.Changes, job.Errors}
+	encoded := make([][]byte, len(values))
+	for i, value := range values {
+		if encoded[i], err = json.Marshal(value); err != nil {
+			return nil, nil, nil, err
+		}
+		if string(encoded[i]) == "null" {
+			encoded[i] = []byte("[]")
+		}
+	}
+	return encoded[0], encoded[1], encoded[2], nil
+}
+
+// scanImportJob membaca satu baris dengan urutan kolom importJobColumns menjadi ImportJob.
+func scanImportJob(row rowScanner) (*product.ImportJob, error) {
+	var job product.ImportJob
+	var id string
+	var header, changes, rowErrors []byte
+	err := row.Scan(&id, &job.Status, &job.Mode, &job.DryRun, &job.Filename, &job.TotalRows, &job.ProcessedRows,
+		&job.Created, &job.Updated, &job.Rejected, &job.Error, &header, &changes, &rowErrors,
+		&job.CreatedAt, &job.UpdatedAt, &job.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	job.ID = product.ID(id)
+
+	if err := json.Unmarshal(header, &job.Header); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(changes, &job.Changes); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rowErrors, &job.Errors); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+/*
+Penjelasan Fungsi dan Komentar dalam Kode:
+Struct postgresRepository:
+
+postgresRepository adalah implementasi dari interface product.Repository untuk PostgreSQL. Repository ini menggunakan database/sql dengan driver pgx dan berada di belakang batas yang sama dengan repository MongoDB, sehingga service dan API tidak perlu diubah.
+Migrasi Skema (MigratePostgres):
+
+File SQL di folder migrations/postgres disematkan ke dalam binary dengan go:embed dan dijalankan berurutan. Versi yang sudah dijalankan dicatat pada tabel schema_migrations, dan advisory lock mencegah dua instance menjalankan migrasi bersamaan.
+ID Produk:
+
+PostgreSQL menggunakan UUID yang dibuat oleh database. UUID ini diterjemahkan menjadi product.ID sehingga domain tidak bergantung pada primitive.ObjectID.
+Pencarian dan Pagination:
+
+Semua nilai dari pengguna, termasuk keyword, dikirim sebagai parameter query sehingga aman dari SQL injection. Total pada pagination dihitung dengan count(*) menggunakan kondisi WHERE yang sama dengan query data, dan urutan hasil mengikuti kolom position (urutan penyisipan).
+Pencarian Berdasarkan Jarak:
+
+Koordinat produk disimpan pada kolom latitude dan longitude. Jarak dihitung dengan rumus haversine di dalam query (lihat distanceExpression) sehingga tidak memerlukan PostGIS, lalu hasil diurutkan dari yang terdekat seperti $geoNear pada MongoDB.
+Buku Besar Stok:
+
+AdjustStock menjalankan UPDATE stock = stock + $1 dengan syarat stok hasilnya tidak negatif, lalu mencatat baris baru di tabel stock_movements dalam transaksi yang sama. Jika salah satu langkah gagal, keduanya dibatalkan. ExpectedVersion yang diisi ikut diperiksa pada klausa WHERE (version = $3).
+Reservasi Stok:
+
+Reserve, ConfirmReservation, dan ReleaseReservation mengubah tabel stock_reservations dan kolom reserved (serta stock saat konfirmasi) pada tabel products di dalam satu transaksi. Perubahan status hanya berlaku untuk reservasi yang masih active.
+Lokasi dan Stok per Lokasi:
+
+Stok per lokasi disimpan pada tabel location_stocks dengan constraint quantity >= 0, dan jumlahnya disimpan pada kolom products.allocated. Perubahan stok pada lokasi dan TransferStock mengubah kedua tabel di dalam satu transaksi, sehingga total stok produk selalu sama dengan stok yang belum dialokasikan ditambah stok seluruh lokasi.
+Perilaku yang Disamakan dengan Repository MongoDB:
+
+Pagination default, pembaruan parsial pada Update, soft delete, restore, purge, keunikan kode produk (ErrCodeConflict), serta pesan error saat data tidak ditemukan dibuat sama dengan repository MongoDB dan diverifikasi dengan suite producttest.
+Pencarian Teks:
+
+Search menggunakan kolom search_vector (tsvector dengan konfigurasi 'simple') yang diindeks GIN dan diurutkan dengan ts_rank. Kata hasil SearchQuery.Terms digabungkan dengan operator | sehingga produk cocok jika memuat salah satu kata, dan keyword pada FindAll di-escape dengan regexp.QuoteMeta sebelum digunakan dengan operator ~*.
+
+Idempotency-Key:
+
+Record Idempotency-Key disimpan di tabel idempotency_keys (migrasi 0009) dengan key sebagai primary key. Klaim menggunakan INSERT ... ON CONFLICT DO NOTHING, dan jika tidak ada baris yang disisipkan, record yang sudah ada dibaca kembali. Header response disimpan sebagai JSONB dan body sebagai BYTEA. Record yang kedaluwarsa dihapus sebelum setiap klaim menggunakan index expires_at.
+Job Import:
+
+Job import disimpan di tabel import_jobs (migrasi 0010) dengan UUID yang dibuat oleh database. Progres disimpan pada kolom biasa, sedangkan header file, perubahan pada dry-run, dan baris yang ditolak disimpan sebagai JSONB karena selalu dibaca bersama job.
+*/
@@ -0,0 +1,40 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"CRUD_Hexagonal/repository/product/producttest"
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// TestPostgresRepository menjalankan suite conformance terhadap PostgreSQL sungguhan.
+// Pengujian ini dilewati jika POSTGRES_TEST_DSN tidak diatur.
+func TestPostgresRepository(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	if err := MigratePostgres(ctx, db); err != nil {
+		t.Fatalf("migrate postgres: %v", err)
+	}
+
+	producttest.RunRepositorySuite(t, func(t *testing.T) product.Repository {
+		// Setiap subtest dimulai dengan tabel kosong
+		if _, err := db.ExecContext(ctx, "TRUNCATE products, stock_movements, stock_reservations, locations, location_stocks, idempotency_keys, import_jobs"); err != nil {
+			t.Fatalf("truncate products: %v", err)
+		}
+		return NewPostgresRepository(db)
+	})
+}
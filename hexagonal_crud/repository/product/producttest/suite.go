@@ -0,0 +1,1547 @@
+// Package producttest menyediakan suite pengujian kesesuaian (conformance) untuk implementasi
+// product.Repository. Setiap backend baru cukup menjalankan RunRepositorySuite untuk membuktikan
+// bahwa perilakunya sama dengan repository MongoDB.
+package producttest
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"CRUD_Hexagonal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+	"time"
+)
+
+// NewRepositoryFunc membuat repository baru yang masih kosong untuk setiap subtest.
+type NewRepositoryFunc func(t *testing.T) product.Repository
+
+// RunRepositorySuite menjalankan seluruh skenario pengujian terhadap repository yang dibuat oleh newRepository.
+func RunRepositorySuite(t *testing.T, newRepository NewRepositoryFunc) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo product.Repository)
+	}{
+		{"StoreAndFind", testStoreAndFind},
+		{"FindNotFound", testFindNotFound},
+		{"FindAllDefaultPagination", testFindAllDefaultPagination},
+		{"FindAllPagination", testFindAllPagination},
+		{"FindAllPageOutOfRange", testFindAllPageOutOfRange},
+		{"FindAllNear", testFindAllNear},
+		{"FindAllKeywordTotal", testFindAllKeywordTotal},
+		{"FindAllCursor", testFindAllCursor},
+		{"FindAllSortAndFields", testFindAllSortAndFields},
+		{"FindAllConditions", testFindAllConditions},
+		{"FindAllKeywordLiteral", testFindAllKeywordLiteral},
+		{"Search", testSearch},
+		{"UpdatePartial", testUpdatePartial},
+		{"UpdateVersionConflict", testUpdateVersionConflict},
+		{"Patch", testPatch},
+		{"DeleteById", testDeleteById},
+		{"DeleteByIdNotFound", testDeleteByIdNotFound},
+		{"RestoreAndPurge", testRestoreAndPurge},
+		{"Remove", testRemove},
+		{"FindByCode", testFindByCode},
+		{"CodeConflict", testCodeConflict},
+		{"DeleteByCode", testDeleteByCode},
+		{"AdjustStock", testAdjustStock},
+		{"AdjustStockNotFound", testAdjustStockNotFound},
+		{"PatchStockCorrection", testPatchStockCorrection},
+		{"FindMovementsPagination", testFindMovementsPagination},
+		{"ReserveAndConfirm", testReserveAndConfirm},
+		{"ReserveAndRelease", testReserveAndRelease},
+		{"ReservationNotFound", testReservationNotFound},
+		{"FindExpiredReservations", testFindExpiredReservations},
+		{"StoreAndFindLocation", testStoreAndFindLocation},
+		{"AdjustLocationStock", testAdjustLocationStock},
+		{"TransferStock", testTransferStock},
+		{"ConfirmReservationAllocatedStock", testConfirmReservationAllocatedStock},
+		{"IdempotencyKey", testIdempotencyKey},
+		{"BulkWrite", testBulkWrite},
+		{"ImportJob", testImportJob},
+		{"Export", testExport},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepository(t))
+		})
+	}
+}
+
+func testStoreAndFind(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+
+	id := mustStore(t, repo, &product.Product{Name: "Kopi Susu", Stock: 12, CreatedAt: 1700000000})
+	if id.IsZero() {
+		t.Fatal("Store returned an empty ID")
+	}
+
+	got, err := repo.Find(ctx, id)
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	if got.ID != id || got.Name != "Kopi Susu" || got.Stock != 12 || got.CreatedAt != 1700000000 {
+		t.Fatalf("Find returned %+v, want the stored product", got)
+	}
+}
+
+func testFindNotFound(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+
+	_, err := repo.Find(ctx, unknownID(t, repo))
+	assertError(t, err, product.ErrProductNotFound)
+
+	// Setiap error domain termasuk ke kategori yang dipetakan lapisan API ke status HTTP
+	assertError(t, err, product.ErrNotFound)
+
+	_, err = repo.Find(ctx, product.ID("not-an-id"))
+	assertError(t, err, product.ErrInvalidID)
+}
+
+func testFindAllDefaultPagination(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	storeProducts(t, repo, 12)
+
+	// Page atau limit yang tidak valid jatuh ke halaman 1 dengan limit 10
+	for _, filter := range []product.Filter{{}, {Page: 0, Limit: 5}, {Page: 2, Limit: 0}, {Page: -1, Limit: -1}} {
+		got, pagination, err := repo.FindAll(ctx, filter)
+		if err != nil {
+			t.Fatalf("FindAll(%+v) returned error: %v", filter, err)
+		}
+		if len(got) != 10 {
+			t.Fatalf("FindAll(%+v) returned %d products, want 10", filter, len(got))
+		}
+		assertPagination(t, pagination, 12, 10, 1)
+	}
+}
+
+func testFindAllPagination(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	stored := storeProducts(t, repo, 12)
+
+	got, pagination, err := repo.FindAll(ctx, product.Filter{Page: 3, Limit: 5})
+	if err != nil {
+		t.Fatalf("FindAll returned error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("FindAll returned %d products, want 2", len(got))
+	}
+	assertPagination(t, pagination, 12, 5, 3)
+
+	// Urutan hasil mengikuti urutan penyimpanan
+	for i, p := range got {
+		if want := stored[10+i]; p.Name != want {
+			t.Fatalf("FindAll item %d is %q, want %q", i, p.Name, want)
+		}
+	}
+}
+
+func testFindAllPageOutOfRange(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	storeProducts(t, repo, 3)
+
+	got, pagination, err := repo.FindAll(ctx, product.Filter{Page: 5, Limit: 10})
+	if err != nil {
+		t.Fatalf("FindAll returned error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("FindAll returned %d products, want 0", len(got))
+	}
+	assertPagination(t, pagination, 3, 10, 5)
+}
+
+func testFindAllNear(t *testing.T, repo product.Repository) {
+	// Monas sebagai titik pusat, Bogor sekitar 47 km, Bandung sekitar 120 km, dan satu produk tanpa koordinat
+	mustStore(t, repo, &product.Product{Name: "Bandung", Geo: product.NewGeoPoint(-6.9175, 107.6191)})
+	mustStore(t, repo, &product.Product{Name: "Tanpa Lokasi"})
+	mustStore(t, repo, &product.Product{Name: "Bogor", Geo: product.NewGeoPoint(-6.5971, 106.8060)})
+	mustStore(t, repo, &product.Product{Name: "Monas", Geo: product.NewGeoPoint(-6.1754, 106.8272)})
+
+	near := &product.GeoQuery{Latitude: -6.1754, Longitude: 106.8272}
+	got, pagination, err := repo.FindAll(context.Background(), product.Filter{Page: 1, Limit: 10, Near: near})
+	if err != nil {
+		t.Fatalf("FindAll returned error: %v", err)
+	}
+	assertPagination(t, pagination, 3, 10, 1)
+
+	// Hasil diurutkan dari yang terdekat dan setiap produk berisi jarak dalam kilometer
+	wantNames := []string{"Monas", "Bogor", "Bandung"}
+	wantDistances := []float64{0, 47, 120}
+	if len(got) != len(wantNames) {
+		t.Fatalf("got %d products, want %d", len(got), len(wantNames))
+	}
+	for i, p := range got {
+		if p.Name != wantNames[i] || p.Distance == nil {
+			t.Fatalf("got product %d %q, want %q with distance", i, p.Name, wantNames[i])
+		}
+		if math.Abs(*p.Distance-wantDistances[i]) > 2 {
+			t.Fatalf("got distance %.1f km for %q, want about %.0f km", *p.Distance, p.Name, wantDistances[i])
+		}
+		if p.Geo == nil {
+			t.Fatalf("got product %q without geo point", p.Name)
+		}
+	}
+
+	// Radius membatasi produk yang dikembalikan
+	near.RadiusKM = 50
+	got, pagination, err = repo.FindAll(context.Background(), product.Filter{Page: 1, Limit: 10, Near: near})
+	if err != nil {
+		t.Fatalf("FindAll returned error: %v", err)
+	}
+	assertPagination(t, pagination, 2, 10, 1)
+	if len(got) != 2 || got[0].Name != "Monas" || got[1].Name != "Bogor" {
+		t.Fatalf("got %d products, want Monas and Bogor within 50 km", len(got))
+	}
+}
+
+func testFindAllKeywordTotal(t *testing.T, repo product.Repository) {
+	for _, name := range []string{"Kopi Arabika", "Teh Melati", "kopi robusta", "Gula Aren", "Kopi Luwak"} {
+		mustStore(t, repo, &product.Product{Name: name})
+	}
+
+	// Total dihitung dengan filter yang sama dengan data, bukan seluruh koleksi
+	got, pagination, err := repo.FindAll(context.Background(), product.Filter{Page: 2, Limit: 2, Keyword: "kopi"})
+	if err != nil {
+		t.Fatalf("FindAll returned error: %v", err)
+	}
+	assertPagination(t, pagination, 3, 2, 2)
+	if len(got) != 1 || got[0].Name != "Kopi Luwak" {
+		t.Fatalf("got %d products on page 2, want only Kopi Luwak", len(got))
+	}
+}
+
+func testFindAllCursor(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	names := storeProducts(t, repo, 5)
+
+	// Halaman pertama dimulai dengan cursor kosong
+	first, pagination, err := repo.FindAll(ctx, product.Filter{Limit: 2, UseCursor: true})
+	if err != nil {
+		t.Fatalf("FindAll returned error: %v", err)
+	}
+	if !pagination.Cursor || !pagination.HasNext || pagination.NextCursor == "" {
+		t.Fatalf("got pagination %+v, want cursor pagination with next cursor", *pagination)
+	}
+	if len(first) != 2 || first[0].Name != names[0] || first[1].Name != names[1] {
+		t.Fatalf("got %d products on first page, want %s and %s", len(first), names[0], names[1])
+	}
+
+	// Produk yang disisipkan di antara dua permintaan tidak membuat halaman berikutnya berisi duplikat
+	mustStore(t, repo, &product.Product{Name: "Produk Baru"})
+
+	var got []string
+	cursor := pagination.NextCursor
+	for cursor != "" {
+		page, pagination, err := repo.FindAll(ctx, product.Filter{Limit: 2, UseCursor: true, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("FindAll returned error: %v", err)
+		}
+		for _, p := range page {
+			got = append(got, p.Name)
+		}
+		cursor = pagination.NextCursor
+	}
+	want := append(append([]string{}, names[2:]...), "Produk Baru")
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got remaining products %v, want %v", got, want)
+	}
+
+	_, _, err = repo.FindAll(ctx, product.Filter{Limit: 2, UseCursor: true, Cursor: "!"})
+	if !errors.Is(err, product.ErrInvalidCursor) {
+		t.Fatalf("got error %v, want %v", err, product.ErrInvalidCursor)
+	}
+}
+
+func testFindAllSortAndFields(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	mustStore(t, repo, &product.Product{Code: "SKU-C", Name: "Cokelat", Stock: 5})
+	mustStore(t, repo, &product.Product{Code: "SKU-A", Name: "Apel", Stock: 10})
+	mustStore(t, repo, &product.Product{Code: "SKU-B", Name: "Beras", Stock: 5})
+
+	sorts, err := product.ParseSort("-stock,product_name")
+	if err != nil {
+		t.Fatalf("ParseSort returned error: %v", err)
+	}
+	fields, err := product.ParseFields("product_id,product_name")
+	if err != nil {
+		t.Fatalf("ParseFields returned error: %v", err)
+	}
+
+	got, pagination, err := repo.FindAll(ctx, product.Filter{Page: 1, Limit: 10, Sort: sorts, Fields: fields})
+	if err != nil {
+		t.Fatalf("FindAll returned error: %v", err)
+	}
+	if pagination.Total != 3 {
+		t.Fatalf("got total %d, want 3", pagination.Total)
+	}
+
+	var names []string
+	for _, p := range got {
+		names = append(names, p.Name)
+		// Field yang tidak dipilih tidak dibaca, sedangkan ID selalu dibaca
+		if p.ID.IsZero() || p.Code != "" || p.Stock != 0 {
+			t.Fatalf("got product %+v, want only product_id and product_name", *p)
+		}
+	}
+	if want := []string{"Apel", "Beras", "Cokelat"}; fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Fatalf("got order %v, want %v", names, want)
+	}
+
+	if _, err := product.ParseSort("available"); !errors.Is(err, product.ErrInvalidSort) {
+		t.Fatalf("got error %v, want %v", err, product.ErrInvalidSort)
+	}
+	if _, err := product.ParseFields("product_id,password"); !errors.Is(err, product.ErrInvalidFields) {
+		t.Fatalf("got error %v, want %v", err, product.ErrInvalidFields)
+	}
+}
+
+func testFindAllConditions(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	first := mustStore(t, repo, &product.Product{Code: "SKU-1", Name: "Satu", Stock: 1, CreatedAt: 1704067200})
+	second := mustStore(t, repo, &product.Product{Code: "SKU-5", Name: "Lima", Stock: 5, CreatedAt: 1717200000})
+	third := mustStore(t, repo, &product.Product{Code: "SKU-9", Name: "Sembilan", Stock: 9, CreatedAt: 1735689600})
+
+	parse := func(key, value string) product.Condition {
+		t.Helper()
+		condition, err := product.ParseCondition(key, value)
+		if err != nil || condition == nil {
+			t.Fatalf("ParseCondition(%q, %q) returned %v, %v", key, value, condition, err)
+		}
+		return *condition
+	}
+
+	tests := []struct {
+		name       string
+		conditions []product.Condition
+		want       []string
+	}{
+		{"range", []product.Condition{parse("stock[gte]", "2"), parse("stock[lte]", "9")}, []string{"Lima", "Sembilan"}},
+		{"in", []product.Condition{parse("product_id[in]", first.String()+",bukan-id,"+third.String())}, []string{"Satu", "Sembilan"}},
+		{"ne", []product.Condition{parse("code[ne]", "SKU-5")}, []string{"Satu", "Sembilan"}},
+		{"combined", []product.Condition{parse("product_id[in]", first.String()+","+second.String()), parse("stock[gt]", "1")}, []string{"Lima"}},
+		{"created_at", []product.Condition{parse("created_at[gte]", "2024-06-01T00:00:00Z"), parse("created_at[lt]", "1735689600")}, []string{"Lima"}},
+	}
+	for _, tt := range tests {
+		got, pagination, err := repo.FindAll(ctx, product.Filter{Page: 1, Limit: 10, Conditions: tt.conditions})
+		if err != nil {
+			t.Fatalf("%s: FindAll returned error: %v", tt.name, err)
+		}
+		var names []string
+		for _, p := range got {
+			names = append(names, p.Name)
+		}
+		if fmt.Sprint(names) != fmt.Sprint(tt.want) || pagination.Total != len(tt.want) {
+			t.Fatalf("%s: got %v (total %d), want %v", tt.name, names, pagination.Total, tt.want)
+		}
+	}
+
+	for key, value := range map[string]string{"password[eq]": "x", "stock[like]": "1", "code[gt]": "A", "stock[lte]": "lima"} {
+		if _, err := product.ParseCondition(key, value); !errors.Is(err, product.ErrInvalidFilter) {
+			t.Fatalf("ParseCondition(%q, %q) returned error %v, want %v", key, value, err, product.ErrInvalidFilter)
+		}
+	}
+}
+
+func testFindAllKeywordLiteral(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	mustStore(t, repo, &product.Product{Name: "Kopi (250g)"})
+	mustStore(t, repo, &product.Product{Name: "Kopi 250g"})
+
+	// Metakarakter regex pada keyword dicocokkan sebagai teks biasa
+	for keyword, want := range map[string]int{"(250g)": 1, "kopi (": 1, "Kopi.250g": 0, "[": 0} {
+		got, pagination, err := repo.FindAll(ctx, product.Filter{Keyword: keyword})
+		if err != nil {
+			t.Fatalf("FindAll(%q) returned error: %v", keyword, err)
+		}
+		if len(got) != want || pagination.Total != want {
+			t.Fatalf("FindAll(%q) returned %d products (total %d), want %d", keyword, len(got), pagination.Total, want)
+		}
+	}
+}
+
+func testSearch(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	mustStore(t, repo, &product.Product{Name: "Teh Hijau", Description: "Cocok diminum bersama kopi"})
+	mustStore(t, repo, &product.Product{Name: "Kopi Arabika", Description: "Biji kopi pilihan dari Gayo"})
+	mustStore(t, repo, &product.Product{Name: "Gula Aren", Description: "Pemanis alami"})
+	deleted := mustStore(t, repo, &product.Product{Name: "Kopi Lama"})
+	if err := repo.DeleteById(ctx, deleted, 0); err != nil {
+		t.Fatalf("DeleteById returned error: %v", err)
+	}
+
+	// Kata pada nama lebih relevan daripada kata pada deskripsi, produk yang di-soft delete tidak disertakan
+	hits, pagination, err := repo.Search(ctx, product.SearchQuery{Query: "KOPI"})
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if len(hits) != 2 || pagination.Total != 2 {
+		t.Fatalf("got %d hits (total %d), want 2", len(hits), pagination.Total)
+	}
+	if hits[0].Product.Name != "Kopi Arabika" || hits[1].Product.Name != "Teh Hijau" {
+		t.Fatalf("got order %s, %s, want Kopi Arabika, Teh Hijau", hits[0].Product.Name, hits[1].Product.Name)
+	}
+	if hits[0].Score <= hits[1].Score || hits[1].Score <= 0 {
+		t.Fatalf("got scores %v and %v, want positive and descending", hits[0].Score, hits[1].Score)
+	}
+
+	// Produk cocok jika memuat salah satu kata, dan sintaks pencarian dari pengguna tidak diteruskan ke database
+	hits, _, err = repo.Search(ctx, product.SearchQuery{Query: `"gayo" -aren .*`})
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	var names []string
+	for _, hit := range hits {
+		names = append(names, hit.Product.Name)
+	}
+	sort.Strings(names)
+	if want := []string{"Gula Aren", "Kopi Arabika"}; fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", names, want)
+	}
+}
+
+func testUpdatePartial(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Name: "Teh Manis", Stock: 4, CreatedAt: 1700000000})
+
+	// Field yang kosong tidak ikut diperbarui
+	if err := repo.Update(ctx, &product.Product{ID: id, Stock: 9, UpdatedAt: 1700000100}); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	got, err := repo.Find(ctx, id)
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	if got.Name != "Teh Manis" || got.Stock != 9 || got.CreatedAt != 1700000000 || got.UpdatedAt != 1700000100 {
+		t.Fatalf("Find after Update returned %+v", got)
+	}
+
+	// Produk yang tidak ada tidak dapat diperbarui
+	assertError(t, repo.Update(ctx, &product.Product{ID: unknownID(t, repo), Name: "Teh"}), product.ErrProductNotFound)
+}
+
+func testUpdateVersionConflict(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	p := &product.Product{Name: "Kopi Susu", Stock: 2}
+	id := mustStore(t, repo, p)
+	if p.Version != 1 {
+		t.Fatalf("Store set version %d, want 1", p.Version)
+	}
+
+	// Update dengan versi yang sesuai berhasil dan menaikkan versi
+	update := &product.Product{ID: id, Name: "Kopi Susu Gula Aren", Version: 1}
+	if err := repo.Update(ctx, update); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if update.Version != 2 {
+		t.Fatalf("Update set version %d, want 2", update.Version)
+	}
+
+	// Update kedua dengan versi lama ditolak dan tidak mengubah data
+	stale := &product.Product{ID: id, Name: "Kopi Hitam", Version: 1}
+	if err := repo.Update(ctx, stale); !errors.Is(err, product.ErrVersionConflict) {
+		t.Fatalf("Update with a stale version returned %v, want ErrVersionConflict", err)
+	}
+	got, err := repo.Find(ctx, id)
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	if got.Name != "Kopi Susu Gula Aren" || got.Version != 2 {
+		t.Fatalf("Find after a rejected Update returned %+v", got)
+	}
+
+	// Perubahan stok juga menaikkan versi sehingga ETag lama tidak berlaku lagi
+	mustAdjustStock(t, repo, id, 3)
+	if got, err = repo.Find(ctx, id); err != nil || got.Version != 3 {
+		t.Fatalf("Find after AdjustStock returned %+v, %v, want version 3", got, err)
+	}
+
+	// Versi 0 berarti tanpa pemeriksaan versi
+	if err := repo.Update(ctx, &product.Product{ID: id, Description: "Tanpa gula"}); err != nil {
+		t.Fatalf("Update without a version returned error: %v", err)
+	}
+
+	if err := repo.DeleteById(ctx, id, 3); !errors.Is(err, product.ErrVersionConflict) {
+		t.Fatalf("DeleteById with a stale version returned %v, want ErrVersionConflict", err)
+	}
+	if err := repo.DeleteById(ctx, id, 4); err != nil {
+		t.Fatalf("DeleteById returned error: %v", err)
+	}
+}
+
+func testPatch(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{
+		Code: "SKU-1", Name: "Kopi Tubruk", Stock: 5, Description: "Kopi hitam", Geo: product.NewGeoPoint(-6.2, 106.8),
+	})
+	mustStore(t, repo, &product.Product{Code: "SKU-2", Name: "Teh Tarik", Stock: 1})
+
+	// Nilai nol dan nil tetap diterapkan, berbeda dengan Update
+	changes := product.Changes{"description": "", "geo": nil, "stock": int64(0), "updated_at": int64(1700000100)}
+	if err := repo.Patch(ctx, id, changes, 1); err != nil {
+		t.Fatalf("Patch returned error: %v", err)
+	}
+	got, err := repo.Find(ctx, id)
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	if got.Name != "Kopi Tubruk" || got.Description != "" || got.Geo != nil || got.Stock != 0 || got.UpdatedAt != 1700000100 || got.Version != 2 {
+		t.Fatalf("Find after Patch returned %+v", got)
+	}
+
+	// Versi lama, kode milik produk lain, dan field yang dikelola repository ditolak
+	if err := repo.Patch(ctx, id, product.Changes{"product_name": "Kopi"}, 1); !errors.Is(err, product.ErrVersionConflict) {
+		t.Fatalf("Patch with a stale version returned %v, want ErrVersionConflict", err)
+	}
+	if err := repo.Patch(ctx, id, product.Changes{"code": "SKU-2"}, 0); !errors.Is(err, product.ErrCodeConflict) {
+		t.Fatalf("Patch with a used code returned %v, want ErrCodeConflict", err)
+	}
+	for _, changes := range []product.Changes{{"version": int64(9)}, {"deleted_at": int64(1)}, {"product_name": 1}, {"unknown": "x"}} {
+		if err := repo.Patch(ctx, id, changes, 0); !errors.Is(err, product.ErrInvalidPatch) {
+			t.Fatalf("Patch(%v) returned %v, want ErrInvalidPatch", changes, err)
+		}
+	}
+
+	// Kode dapat dikosongkan tanpa bentrok dengan produk lain yang juga tidak memiliki kode
+	if err := repo.Patch(ctx, id, product.Changes{"code": ""}, 0); err != nil {
+		t.Fatalf("Patch clearing the code returned error: %v", err)
+	}
+	if got, err = repo.Find(ctx, id); err != nil || got.Code != "" || got.Version != 3 {
+		t.Fatalf("Find after clearing the code returned %+v, %v", got, err)
+	}
+
+	assertError(t, repo.Patch(ctx, unknownID(t, repo), product.Changes{"product_name": "Kopi"}, 0), product.ErrProductNotFound)
+}
+
+func testDeleteById(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Name: "Roti Bakar", Stock: 1})
+	mustStore(t, repo, &product.Product{Name: "Roti Tawar", Stock: 1})
+
+	if err := repo.DeleteById(ctx, id, 0); err != nil {
+		t.Fatalf("DeleteById returned error: %v", err)
+	}
+
+	_, err := repo.Find(ctx, id)
+	assertError(t, err, product.ErrProductNotFound)
+
+	// Produk yang sudah dihapus tidak muncul kecuali diminta
+	got, pagination, err := repo.FindAll(ctx, product.Filter{})
+	if err != nil {
+		t.Fatalf("FindAll returned error: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "Roti Tawar" {
+		t.Fatalf("FindAll returned %+v, want only the active product", got)
+	}
+	assertPagination(t, pagination, 1, 10, 1)
+
+	got, _, err = repo.FindAll(ctx, product.Filter{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("FindAll returned error: %v", err)
+	}
+	if len(got) != 2 || got[0].DeletedAt == 0 {
+		t.Fatalf("FindAll with IncludeDeleted returned %+v, want the deleted product first", got)
+	}
+
+	// Menghapus ulang produk yang sudah dihapus dianggap tidak ditemukan
+	assertError(t, repo.DeleteById(ctx, id, 0), product.ErrProductNotFound)
+}
+
+func testDeleteByIdNotFound(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+
+	assertError(t, repo.DeleteById(ctx, "", 0), product.ErrInvalidID)
+	assertError(t, repo.DeleteById(ctx, unknownID(t, repo), 0), product.ErrProductNotFound)
+
+	assertError(t, repo.DeleteById(ctx, product.ID("not-an-id"), 0), product.ErrInvalidID)
+}
+
+func testRestoreAndPurge(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	restored := mustStore(t, repo, &product.Product{Name: "Susu", Stock: 1})
+	purged := mustStore(t, repo, &product.Product{Name: "Keju", Stock: 1})
+	active := mustStore(t, repo, &product.Product{Name: "Mentega", Stock: 1})
+
+	// Restore hanya berlaku untuk produk yang sudah dihapus
+	assertError(t, repo.Restore(ctx, active), product.ErrProductNotFound)
+
+	for _, id := range []product.ID{restored, purged} {
+		if err := repo.DeleteById(ctx, id, 0); err != nil {
+			t.Fatalf("DeleteById returned error: %v", err)
+		}
+	}
+
+	if err := repo.Restore(ctx, restored); err != nil {
+		t.Fatalf("Restore returned error: %v", err)
+	}
+	got, err := repo.Find(ctx, restored)
+	if err != nil {
+		t.Fatalf("Find after Restore returned error: %v", err)
+	}
+	if got.DeletedAt != 0 {
+		t.Fatalf("Find after Restore returned DeletedAt %d, want 0", got.DeletedAt)
+	}
+
+	// Produk yang dihapus setelah batas waktu tidak ikut di-purge
+	count, err := repo.Purge(ctx, time.Now().Add(-time.Hour).Unix())
+	if err != nil {
+		t.Fatalf("Purge returned error: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("Purge returned %d, want 0", count)
+	}
+
+	count, err = repo.Purge(ctx, time.Now().Add(time.Minute).Unix())
+	if err != nil {
+		t.Fatalf("Purge returned error: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("Purge returned %d, want 1", count)
+	}
+
+	remaining, _, err := repo.FindAll(ctx, product.Filter{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("FindAll returned error: %v", err)
+	}
+	if len(remaining) != 2 {
+		t.Fatalf("FindAll after Purge returned %d products, want 2", len(remaining))
+	}
+}
+
+func testRemove(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Code: "SKU-1", Name: "Kopi", Stock: 1})
+	deleted := mustStore(t, repo, &product.Product{Name: "Teh", Stock: 1})
+	if err := repo.DeleteById(ctx, deleted, 0); err != nil {
+		t.Fatalf("DeleteById returned error: %v", err)
+	}
+
+	// Produk aktif maupun yang sudah di-soft delete dihapus permanen
+	for _, target := range []product.ID{id, deleted} {
+		if err := repo.Remove(ctx, target); err != nil {
+			t.Fatalf("Remove(%s) returned error: %v", target, err)
+		}
+	}
+	got, _, err := repo.FindAll(ctx, product.Filter{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("FindAll returned error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("FindAll returned %d products after Remove, want 0", len(got))
+	}
+	assertError(t, repo.Remove(ctx, id), product.ErrProductNotFound)
+
+	// Kode produk yang dihapus permanen dapat digunakan kembali
+	mustStore(t, repo, &product.Product{Code: "SKU-1", Name: "Kopi Baru"})
+}
+
+func testFindByCode(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Code: "SKU-001", Name: "Gula Aren", Stock: 3})
+	mustStore(t, repo, &product.Product{Name: "Tanpa Kode", Stock: 1})
+
+	got, err := repo.FindByCode(ctx, "SKU-001")
+	if err != nil {
+		t.Fatalf("FindByCode returned error: %v", err)
+	}
+	if got.ID != id || got.Code != "SKU-001" || got.Name != "Gula Aren" {
+		t.Fatalf("FindByCode returned %+v, want the stored product", got)
+	}
+
+	_, err = repo.FindByCode(ctx, "SKU-404")
+	assertError(t, err, product.ErrProductNotFound)
+
+	// Produk tanpa kode tidak dapat dicari dengan kode kosong
+	_, err = repo.FindByCode(ctx, "")
+	assertError(t, err, product.ErrProductNotFound)
+}
+
+func testCodeConflict(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	mustStore(t, repo, &product.Product{Code: "SKU-001", Name: "Gula Aren", Stock: 3})
+	other := mustStore(t, repo, &product.Product{Code: "SKU-002", Name: "Gula Pasir", Stock: 3})
+
+	// Produk tanpa kode boleh lebih dari satu
+	mustStore(t, repo, &product.Product{Name: "Tanpa Kode 1", Stock: 1})
+	mustStore(t, repo, &product.Product{Name: "Tanpa Kode 2", Stock: 1})
+
+	if _, err := repo.Store(ctx, &product.Product{Code: "SKU-001", Name: "Duplikat", Stock: 1}); !errors.Is(err, product.ErrCodeConflict) {
+		t.Fatalf("Store with a duplicate code returned %v, want ErrCodeConflict", err)
+	}
+
+	if err := repo.Update(ctx, &product.Product{ID: other, Code: "SKU-001"}); !errors.Is(err, product.ErrCodeConflict) {
+		t.Fatalf("Update with a duplicate code returned %v, want ErrCodeConflict", err)
+	}
+
+	// Memperbarui produk dengan kodenya sendiri bukan konflik
+	if err := repo.Update(ctx, &product.Product{ID: other, Code: "SKU-002", Stock: 7}); err != nil {
+		t.Fatalf("Update with its own code returned error: %v", err)
+	}
+}
+
+func testDeleteByCode(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Code: "SKU-001", Name: "Gula Aren", Stock: 3})
+
+	assertError(t, repo.Delete(ctx, "", 0), product.ErrProductNotFound)
+	assertError(t, repo.Delete(ctx, "SKU-404", 0), product.ErrProductNotFound)
+
+	if err := repo.Delete(ctx, "SKU-001", 0); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+
+	_, err := repo.Find(ctx, id)
+	assertError(t, err, product.ErrProductNotFound)
+	_, err = repo.FindByCode(ctx, "SKU-001")
+	assertError(t, err, product.ErrProductNotFound)
+
+	// Menghapus ulang produk yang sudah dihapus dianggap tidak ditemukan
+	assertError(t, repo.Delete(ctx, "SKU-001", 0), product.ErrProductNotFound)
+}
+
+func testAdjustStock(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Name: "Gula Aren", Stock: 5})
+
+	movement, err := repo.AdjustStock(ctx, &product.StockMovement{ProductID: id, Quantity: -3, Reason: product.ReasonSale, CreatedAt: 100})
+	if err != nil {
+		t.Fatalf("AdjustStock returned error: %v", err)
+	}
+	if movement.ID.IsZero() || movement.ProductID != id || movement.Quantity != -3 || movement.StockAfter != 2 {
+		t.Fatalf("AdjustStock returned %+v, want product %s, quantity -3 and stock after 2", *movement, id)
+	}
+
+	// Pengurangan yang membuat stok negatif ditolak dan tidak dicatat
+	_, err = repo.AdjustStock(ctx, &product.StockMovement{ProductID: id, Quantity: -3, Reason: product.ReasonSale})
+	if !errors.Is(err, product.ErrInsufficientStock) {
+		t.Fatalf("got error %v, want %v", err, product.ErrInsufficientStock)
+	}
+
+	// Stok boleh berkurang tepat sampai 0
+	if _, err := repo.AdjustStock(ctx, &product.StockMovement{ProductID: id, Quantity: -2, Reason: product.ReasonDamage}); err != nil {
+		t.Fatalf("AdjustStock returned error: %v", err)
+	}
+
+	got, err := repo.Find(ctx, id)
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	if got.Stock != 0 {
+		t.Fatalf("stock is %d, want 0", got.Stock)
+	}
+
+	movements, pagination, err := repo.FindMovements(ctx, id, product.MovementFilter{})
+	if err != nil {
+		t.Fatalf("FindMovements returned error: %v", err)
+	}
+	assertPagination(t, pagination, 2, 10, 1)
+	if len(movements) != 2 || movements[0].Reason != product.ReasonDamage || movements[1].Reason != product.ReasonSale {
+		t.Fatalf("got %d movements, want damage then sale", len(movements))
+	}
+	if movements[1].CreatedAt != 100 || movements[1].StockAfter != 2 {
+		t.Fatalf("got movement %+v, want created at 100 and stock after 2", *movements[1])
+	}
+}
+
+func testAdjustStockNotFound(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+
+	_, err := repo.AdjustStock(ctx, &product.StockMovement{ProductID: unknownID(t, repo), Quantity: 1, Reason: product.ReasonPurchase})
+	assertError(t, err, product.ErrProductNotFound)
+
+	// Stok produk yang sudah di-soft delete tidak dapat diubah
+	id := mustStore(t, repo, &product.Product{Name: "Gula Aren", Stock: 1})
+	if err := repo.DeleteById(ctx, id, 0); err != nil {
+		t.Fatalf("DeleteById returned error: %v", err)
+	}
+	_, err = repo.AdjustStock(ctx, &product.StockMovement{ProductID: id, Quantity: 1, Reason: product.ReasonPurchase})
+	assertError(t, err, product.ErrProductNotFound)
+}
+
+func testPatchStockCorrection(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Name: "Gula Aren", Stock: 5})
+
+	// PATCH stock menjadi 0 ditulis sebagai Patch field lain diikuti pergerakan correction dengan versi yang sama
+	stored, err := repo.Find(ctx, id)
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	if err := repo.Patch(ctx, id, product.Changes{"description": "Organik"}, stored.Version); err != nil {
+		t.Fatalf("Patch returned error: %v", err)
+	}
+	movement, err := repo.AdjustStock(ctx, &product.StockMovement{ProductID: id, Quantity: -5, Reason: product.ReasonCorrection,
+		ExpectedVersion: stored.Version + 1})
+	if err != nil {
+		t.Fatalf("AdjustStock returned error: %v", err)
+	}
+	if movement.StockAfter != 0 {
+		t.Fatalf("AdjustStock returned stock after %d, want 0", movement.StockAfter)
+	}
+
+	// Versi yang sudah usang ditolak tanpa mengubah stok
+	_, err = repo.AdjustStock(ctx, &product.StockMovement{ProductID: id, Quantity: 3, Reason: product.ReasonCorrection,
+		ExpectedVersion: stored.Version})
+	assertError(t, err, product.ErrVersionConflict)
+
+	got, err := repo.Find(ctx, id)
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	if got.Stock != 0 || got.Description != "Organik" || got.Version != stored.Version+2 {
+		t.Fatalf("got %+v, want stock 0, the new description and version %d", *got, stored.Version+2)
+	}
+}
+
+func testFindMovementsPagination(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Name: "Gula Aren"})
+	other := mustStore(t, repo, &product.Product{Name: "Kopi Susu"})
+
+	for i := 1; i <= 5; i++ {
+		if _, err := repo.AdjustStock(ctx, &product.StockMovement{ProductID: id, Quantity: int64(i), Reason: product.ReasonPurchase}); err != nil {
+			t.Fatalf("AdjustStock returned error: %v", err)
+		}
+	}
+	if _, err := repo.AdjustStock(ctx, &product.StockMovement{ProductID: other, Quantity: 1, Reason: product.ReasonPurchase}); err != nil {
+		t.Fatalf("AdjustStock returned error: %v", err)
+	}
+
+	// Halaman kedua berisi pergerakan ketiga dan kedua dari yang terbaru
+	movements, pagination, err := repo.FindMovements(ctx, id, product.MovementFilter{Page: 2, Limit: 2})
+	if err != nil {
+		t.Fatalf("FindMovements returned error: %v", err)
+	}
+	assertPagination(t, pagination, 5, 2, 2)
+	if len(movements) != 2 || movements[0].Quantity != 3 || movements[1].Quantity != 2 {
+		t.Fatalf("got %d movements, want quantities 3 and 2", len(movements))
+	}
+}
+
+func testReserveAndConfirm(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Name: "Gula Aren"})
+	mustAdjustStock(t, repo, id, 5)
+
+	reservation := mustReserve(t, repo, id, 3, 1000)
+	if reservation.ID.IsZero() || reservation.ProductID != id || reservation.Status != product.ReservationActive {
+		t.Fatalf("Reserve returned %+v, want active reservation for product %s", *reservation, id)
+	}
+	assertStock(t, repo, id, 5, 3)
+
+	// Stok yang sudah ditahan tidak dapat direservasi ulang atau dikurangi
+	_, err := repo.Reserve(ctx, &product.Reservation{ProductID: id, Quantity: 3, Status: product.ReservationActive, ExpiresAt: 1000})
+	if !errors.Is(err, product.ErrInsufficientStock) {
+		t.Fatalf("got error %v, want %v", err, product.ErrInsufficientStock)
+	}
+	_, err = repo.AdjustStock(ctx, &product.StockMovement{ProductID: id, Quantity: -3, Reason: product.ReasonSale})
+	if !errors.Is(err, product.ErrInsufficientStock) {
+		t.Fatalf("got error %v, want %v", err, product.ErrInsufficientStock)
+	}
+
+	// Reservasi yang sudah melewati batas waktu tidak dapat dikonfirmasi
+	_, err = repo.ConfirmReservation(ctx, reservation.ID, 1000)
+	if !errors.Is(err, product.ErrReservationNotActive) {
+		t.Fatalf("got error %v, want %v", err, product.ErrReservationNotActive)
+	}
+
+	confirmed, err := repo.ConfirmReservation(ctx, reservation.ID, 999)
+	if err != nil {
+		t.Fatalf("ConfirmReservation returned error: %v", err)
+	}
+	if confirmed.Status != product.ReservationConfirmed || confirmed.UpdatedAt != 999 {
+		t.Fatalf("ConfirmReservation returned %+v, want confirmed at 999", *confirmed)
+	}
+	assertStock(t, repo, id, 2, 0)
+
+	// Konfirmasi tercatat di buku besar stok
+	movements, _, err := repo.FindMovements(ctx, id, product.MovementFilter{})
+	if err != nil {
+		t.Fatalf("FindMovements returned error: %v", err)
+	}
+	if len(movements) != 2 || movements[0].Quantity != -3 || movements[0].Reason != product.ReasonSale || movements[0].StockAfter != 2 {
+		t.Fatalf("got %d movements, want latest sale of -3 with stock after 2", len(movements))
+	}
+
+	// Reservasi yang sudah dikonfirmasi tidak dapat dikonfirmasi ulang atau dilepas
+	_, err = repo.ConfirmReservation(ctx, reservation.ID, 999)
+	if !errors.Is(err, product.ErrReservationNotActive) {
+		t.Fatalf("got error %v, want %v", err, product.ErrReservationNotActive)
+	}
+	_, err = repo.ReleaseReservation(ctx, reservation.ID, product.ReservationReleased, 999)
+	if !errors.Is(err, product.ErrReservationNotActive) {
+		t.Fatalf("got error %v, want %v", err, product.ErrReservationNotActive)
+	}
+}
+
+func testReserveAndRelease(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Name: "Gula Aren"})
+	mustAdjustStock(t, repo, id, 5)
+
+	reservation := mustReserve(t, repo, id, 4, 1000)
+	released, err := repo.ReleaseReservation(ctx, reservation.ID, product.ReservationReleased, 500)
+	if err != nil {
+		t.Fatalf("ReleaseReservation returned error: %v", err)
+	}
+	if released.Status != product.ReservationReleased || released.UpdatedAt != 500 {
+		t.Fatalf("ReleaseReservation returned %+v, want released at 500", *released)
+	}
+	assertStock(t, repo, id, 5, 0)
+
+	got, err := repo.FindReservation(ctx, reservation.ID)
+	if err != nil {
+		t.Fatalf("FindReservation returned error: %v", err)
+	}
+	if got.Status != product.ReservationReleased || got.Quantity != 4 || got.ExpiresAt != 1000 {
+		t.Fatalf("FindReservation returned %+v, want released reservation of 4 units", *got)
+	}
+}
+
+func testReservationNotFound(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+
+	_, err := repo.Reserve(ctx, &product.Reservation{ProductID: unknownID(t, repo), Quantity: 1, Status: product.ReservationActive})
+	assertError(t, err, product.ErrProductNotFound)
+
+	// ID reservasi yang tidak dikenal, termasuk ID milik produk, tidak ditemukan
+	missing := mustStore(t, repo, &product.Product{Name: "Gula Aren"})
+	if _, err := repo.FindReservation(ctx, missing); !errors.Is(err, product.ErrReservationNotFound) {
+		t.Fatalf("got error %v, want %v", err, product.ErrReservationNotFound)
+	}
+	if _, err := repo.ConfirmReservation(ctx, missing, 0); !errors.Is(err, product.ErrReservationNotFound) {
+		t.Fatalf("got error %v, want %v", err, product.ErrReservationNotFound)
+	}
+	if _, err := repo.ReleaseReservation(ctx, missing, product.ReservationReleased, 0); !errors.Is(err, product.ErrReservationNotFound) {
+		t.Fatalf("got error %v, want %v", err, product.ErrReservationNotFound)
+	}
+}
+
+func testFindExpiredReservations(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Name: "Gula Aren"})
+	mustAdjustStock(t, repo, id, 10)
+
+	late := mustReserve(t, repo, id, 1, 300)
+	early := mustReserve(t, repo, id, 1, 100)
+	mustReserve(t, repo, id, 1, 900)
+	released := mustReserve(t, repo, id, 1, 50)
+	if _, err := repo.ReleaseReservation(ctx, released.ID, product.ReservationReleased, 60); err != nil {
+		t.Fatalf("ReleaseReservation returned error: %v", err)
+	}
+
+	// Hanya reservasi aktif yang kedaluwarsa yang dikembalikan, dari yang paling lama
+	expired, err := repo.FindExpiredReservations(ctx, 300, 10)
+	if err != nil {
+		t.Fatalf("FindExpiredReservations returned error: %v", err)
+	}
+	if len(expired) != 2 || expired[0].ID != early.ID || expired[1].ID != late.ID {
+		t.Fatalf("got %d expired reservations, want the reservations expiring at 100 and 300", len(expired))
+	}
+
+	expired, err = repo.FindExpiredReservations(ctx, 300, 1)
+	if err != nil {
+		t.Fatalf("FindExpiredReservations returned error: %v", err)
+	}
+	if len(expired) != 1 || expired[0].ID != early.ID {
+		t.Fatalf("got %d expired reservations, want only the reservation expiring at 100", len(expired))
+	}
+
+	if _, err := repo.ReleaseReservation(ctx, early.ID, product.ReservationExpired, 300); err != nil {
+		t.Fatalf("ReleaseReservation returned error: %v", err)
+	}
+	assertStock(t, repo, id, 10, 2)
+}
+
+// mustStore menyimpan produk dan menghentikan pengujian jika gagal.
+func testStoreAndFindLocation(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	warehouse := mustStoreLocation(t, repo, "WH-01", product.LocationWarehouse)
+	mustStoreLocation(t, repo, "ST-01", product.LocationStore)
+
+	got, err := repo.FindLocation(ctx, warehouse)
+	if err != nil {
+		t.Fatalf("FindLocation returned error: %v", err)
+	}
+	if got.ID != warehouse || got.Code != "WH-01" || got.Type != product.LocationWarehouse {
+		t.Fatalf("FindLocation returned %+v, want warehouse WH-01", *got)
+	}
+
+	// Kode lokasi harus unik
+	_, err = repo.StoreLocation(ctx, &product.Location{Code: "WH-01", Name: "Gudang Lain", Type: product.LocationWarehouse})
+	if !errors.Is(err, product.ErrLocationCodeConflict) {
+		t.Fatalf("got error %v, want %v", err, product.ErrLocationCodeConflict)
+	}
+
+	locations, err := repo.FindLocations(ctx)
+	if err != nil {
+		t.Fatalf("FindLocations returned error: %v", err)
+	}
+	if len(locations) != 2 || locations[0].Code != "WH-01" || locations[1].Code != "ST-01" {
+		t.Fatalf("got %d locations, want WH-01 and ST-01 in insertion order", len(locations))
+	}
+
+	_, err = repo.FindLocation(ctx, unknownID(t, repo))
+	if !errors.Is(err, product.ErrLocationNotFound) {
+		t.Fatalf("got error %v, want %v", err, product.ErrLocationNotFound)
+	}
+}
+
+func testAdjustLocationStock(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Name: "Kopi Gayo"})
+	warehouse := mustStoreLocation(t, repo, "WH-01", product.LocationWarehouse)
+	mustAdjustStock(t, repo, id, 2)
+
+	// Penambahan stok pada lokasi menambah stok total dan stok yang sudah dialokasikan
+	movement, err := repo.AdjustStock(ctx, &product.StockMovement{ProductID: id, LocationID: warehouse, Quantity: 5, Reason: product.ReasonPurchase})
+	if err != nil {
+		t.Fatalf("AdjustStock returned error: %v", err)
+	}
+	if movement.LocationID != warehouse || movement.StockAfter != 7 {
+		t.Fatalf("AdjustStock returned %+v, want movement at %s with stock after 7", *movement, warehouse)
+	}
+	assertAllocated(t, repo, id, 7, 5)
+	assertLocationStock(t, repo, id, warehouse, 5)
+
+	// Stok lokasi tidak boleh negatif dan stok tanpa lokasi tidak boleh memakai stok yang sudah dialokasikan
+	_, err = repo.AdjustStock(ctx, &product.StockMovement{ProductID: id, LocationID: warehouse, Quantity: -6, Reason: product.ReasonSale})
+	if !errors.Is(err, product.ErrInsufficientStock) {
+		t.Fatalf("got error %v, want %v", err, product.ErrInsufficientStock)
+	}
+	_, err = repo.AdjustStock(ctx, &product.StockMovement{ProductID: id, Quantity: -3, Reason: product.ReasonSale})
+	if !errors.Is(err, product.ErrInsufficientStock) {
+		t.Fatalf("got error %v, want %v", err, product.ErrInsufficientStock)
+	}
+	assertAllocated(t, repo, id, 7, 5)
+	assertLocationStock(t, repo, id, warehouse, 5)
+
+	if _, err := repo.AdjustStock(ctx, &product.StockMovement{ProductID: id, LocationID: warehouse, Quantity: -5, Reason: product.ReasonSale}); err != nil {
+		t.Fatalf("AdjustStock returned error: %v", err)
+	}
+	assertAllocated(t, repo, id, 2, 0)
+	assertLocationStock(t, repo, id, warehouse, 0)
+}
+
+func testTransferStock(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Name: "Teh Tubruk"})
+	warehouse := mustStoreLocation(t, repo, "WH-01", product.LocationWarehouse)
+	store := mustStoreLocation(t, repo, "ST-01", product.LocationStore)
+	mustAdjustStock(t, repo, id, 10)
+
+	// Mengalokasikan stok ke gudang lalu memindahkan sebagian ke toko
+	if _, err := repo.TransferStock(ctx, &product.StockTransfer{ProductID: id, ToLocationID: warehouse, Quantity: 8}); err != nil {
+		t.Fatalf("TransferStock returned error: %v", err)
+	}
+	if _, err := repo.TransferStock(ctx, &product.StockTransfer{ProductID: id, FromLocationID: warehouse, ToLocationID: store, Quantity: 3}); err != nil {
+		t.Fatalf("TransferStock returned error: %v", err)
+	}
+	assertAllocated(t, repo, id, 10, 8)
+	assertLocationStock(t, repo, id, warehouse, 5)
+	assertLocationStock(t, repo, id, store, 3)
+
+	// Lokasi asal dan stok yang belum dialokasikan harus mencukupi
+	_, err := repo.TransferStock(ctx, &product.StockTransfer{ProductID: id, FromLocationID: store, ToLocationID: warehouse, Quantity: 4})
+	if !errors.Is(err, product.ErrInsufficientStock) {
+		t.Fatalf("got error %v, want %v", err, product.ErrInsufficientStock)
+	}
+	_, err = repo.TransferStock(ctx, &product.StockTransfer{ProductID: id, ToLocationID: store, Quantity: 3})
+	if !errors.Is(err, product.ErrInsufficientStock) {
+		t.Fatalf("got error %v, want %v", err, product.ErrInsufficientStock)
+	}
+	assertLocationStock(t, repo, id, warehouse, 5)
+	assertLocationStock(t, repo, id, store, 3)
+
+	// Mengembalikan stok toko menjadi stok yang belum dialokasikan
+	if _, err := repo.TransferStock(ctx, &product.StockTransfer{ProductID: id, FromLocationID: store, Quantity: 3}); err != nil {
+		t.Fatalf("TransferStock returned error: %v", err)
+	}
+	assertAllocated(t, repo, id, 10, 5)
+	assertLocationStock(t, repo, id, store, 0)
+
+	// Setiap transfer dicatat sebagai dua pergerakan tanpa mengubah stok total
+	movements, _, err := repo.FindMovements(ctx, id, product.MovementFilter{Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("FindMovements returned error: %v", err)
+	}
+	if len(movements) != 7 {
+		t.Fatalf("got %d movements, want 7", len(movements))
+	}
+	var total int64
+	for _, movement := range movements[:6] {
+		if movement.Reason != product.ReasonTransfer || movement.StockAfter != 10 {
+			t.Fatalf("got movement %+v, want transfer with stock after 10", *movement)
+		}
+		total += movement.Quantity
+	}
+	if total != 0 {
+		t.Fatalf("transfer movements sum to %d, want 0", total)
+	}
+
+	stocks, err := repo.FindLocationStocks(ctx, product.LocationStockFilter{LocationID: warehouse})
+	if err != nil {
+		t.Fatalf("FindLocationStocks returned error: %v", err)
+	}
+	if len(stocks) != 1 || stocks[0].ProductID != id || stocks[0].Quantity != 5 {
+		t.Fatalf("got %d stocks at warehouse, want product %s with quantity 5", len(stocks), id)
+	}
+}
+
+func testConfirmReservationAllocatedStock(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Name: "Cokelat Bubuk"})
+	warehouse := mustStoreLocation(t, repo, "WH-01", product.LocationWarehouse)
+	mustAdjustStock(t, repo, id, 5)
+
+	// Reservasi dibuat sebelum stok dialokasikan ke gudang, sehingga hanya 2 unit yang belum dialokasikan
+	reservation := mustReserve(t, repo, id, 3, 1000)
+	if _, err := repo.TransferStock(ctx, &product.StockTransfer{ProductID: id, ToLocationID: warehouse, Quantity: 3}); err != nil {
+		t.Fatalf("TransferStock returned error: %v", err)
+	}
+	assertAllocated(t, repo, id, 5, 3)
+
+	// Konfirmasi ditolak karena memakai stok yang sudah dialokasikan, dan reservasinya tetap aktif
+	_, err := repo.ConfirmReservation(ctx, reservation.ID, 999)
+	if !errors.Is(err, product.ErrInsufficientStock) {
+		t.Fatalf("got error %v, want %v", err, product.ErrInsufficientStock)
+	}
+	got, err := repo.FindReservation(ctx, reservation.ID)
+	if err != nil {
+		t.Fatalf("FindReservation returned error: %v", err)
+	}
+	if got.Status != product.ReservationActive {
+		t.Fatalf("got reservation status %q, want %q", got.Status, product.ReservationActive)
+	}
+	assertStock(t, repo, id, 5, 3)
+
+	// Setelah satu unit dikembalikan dari gudang, reservasi dapat dikonfirmasi
+	if _, err := repo.TransferStock(ctx, &product.StockTransfer{ProductID: id, FromLocationID: warehouse, Quantity: 1}); err != nil {
+		t.Fatalf("TransferStock returned error: %v", err)
+	}
+	if _, err := repo.ConfirmReservation(ctx, reservation.ID, 999); err != nil {
+		t.Fatalf("ConfirmReservation returned error: %v", err)
+	}
+	assertAllocated(t, repo, id, 2, 2)
+}
+
+func testBulkWrite(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+
+	existingID := mustStore(t, repo, &product.Product{Code: "SKU-A", Name: "Kopi", CreatedAt: 1700000000})
+	deletedID := mustStore(t, repo, &product.Product{Code: "SKU-D", Name: "Teh"})
+
+	results, err := repo.BulkWrite(ctx, []product.BulkOperation{
+		{Action: product.BulkCreate, Product: &product.Product{Code: "SKU-B", Name: "Gula"}},
+		{Action: product.BulkCreate, Product: &product.Product{Code: "SKU-A", Name: "Duplikat"}},
+		{Action: product.BulkUpsert, Product: &product.Product{Code: "SKU-A", Name: "Kopi Baru", CreatedAt: 1800000000}},
+		{Action: product.BulkUpsert, Product: &product.Product{Code: "SKU-C", Name: "Susu", CreatedAt: 1800000000}},
+		{Action: product.BulkDelete, Product: &product.Product{ID: deletedID}},
+		{Action: product.BulkDelete, Product: &product.Product{Code: "SKU-MISSING"}},
+		{Action: product.BulkUpsert, Product: &product.Product{Name: "Tanpa Kode"}},
+	})
+	if err != nil {
+		t.Fatalf("BulkWrite returned error: %v", err)
+	}
+	if len(results) != 7 {
+		t.Fatalf("got %d results, want 7", len(results))
+	}
+	for i, result := range results {
+		if result.Index != i {
+			t.Fatalf("result %d has index %d", i, result.Index)
+		}
+	}
+
+	wantStatus := []string{product.BulkCreated, product.BulkFailed, product.BulkUpdated, product.BulkCreated, product.BulkDeleted, product.BulkFailed, product.BulkFailed}
+	for i, want := range wantStatus {
+		if results[i].Status != want {
+			t.Fatalf("result %d has status %q (err %v), want %q", i, results[i].Status, results[i].Err, want)
+		}
+	}
+	assertError(t, results[1].Err, product.ErrCodeConflict)
+	assertError(t, results[5].Err, product.ErrProductNotFound)
+	assertError(t, results[6].Err, product.ErrInvalidBulk)
+
+	// Upsert memperbarui produk yang sudah ada tanpa mengubah waktu pembuatannya
+	if results[2].ID != existingID {
+		t.Fatalf("upsert updated %s, want %s", results[2].ID, existingID)
+	}
+	updated, err := repo.Find(ctx, existingID)
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	if updated.Name != "Kopi Baru" || updated.CreatedAt != 1700000000 || updated.Version != 2 {
+		t.Fatalf("upserted product is %+v, want name Kopi Baru, created_at 1700000000, version 2", updated)
+	}
+
+	for _, i := range []int{0, 3} {
+		created, err := repo.Find(ctx, results[i].ID)
+		if err != nil {
+			t.Fatalf("Find created product %d returned error: %v", i, err)
+		}
+		if created.Code != results[i].Code || created.Version != 1 {
+			t.Fatalf("created product %d is %+v, want code %s and version 1", i, created, results[i].Code)
+		}
+	}
+
+	if _, err := repo.Find(ctx, deletedID); !errors.Is(err, product.ErrProductNotFound) {
+		t.Fatalf("Find deleted product returned %v, want ErrProductNotFound", err)
+	}
+
+	// Kode milik produk yang sudah di-soft delete tetap digunakan, sehingga upsert dengan kode tersebut tidak memperbarui
+	// produk yang dihapus dan tidak menyisipkan produk kedua dengan kode yang sama
+	results, err = repo.BulkWrite(ctx, []product.BulkOperation{
+		{Action: product.BulkUpsert, Product: &product.Product{Code: "SKU-D", Name: "Teh Baru"}},
+	})
+	if err != nil {
+		t.Fatalf("BulkWrite upserting a deleted code returned error: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != product.BulkFailed {
+		t.Fatalf("upsert of a deleted code returned %+v, want a failed result", results)
+	}
+	assertError(t, results[0].Err, product.ErrCodeConflict)
+
+	all, _, err := repo.FindAll(ctx, product.Filter{IncludeDeleted: true, Limit: 100})
+	if err != nil {
+		t.Fatalf("FindAll returned error: %v", err)
+	}
+	var withCode []*product.Product
+	for _, p := range all {
+		if p.Code == "SKU-D" {
+			withCode = append(withCode, p)
+		}
+	}
+	if len(withCode) != 1 || withCode[0].ID != deletedID || withCode[0].DeletedAt == 0 || withCode[0].Name != "Teh" {
+		t.Fatalf("products with code SKU-D are %+v, want only the deleted product unchanged", withCode)
+	}
+}
+
+func testImportJob(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+
+	job, err := repo.StoreImportJob(ctx, &product.ImportJob{
+		Status:    product.JobPending,
+		Mode:      product.BulkUpsert,
+		Filename:  "produk.csv",
+		TotalRows: 3,
+		Header:    []string{"code", "product_name"},
+		CreatedAt: 1700000000,
+		UpdatedAt: 1700000000,
+	})
+	if err != nil {
+		t.Fatalf("StoreImportJob returned error: %v", err)
+	}
+	if job.ID.IsZero() {
+		t.Fatal("StoreImportJob did not assign an ID")
+	}
+
+	// Progres dan baris yang ditolak disimpan, sedangkan waktu pembuatan job tidak berubah
+	job.Status, job.ProcessedRows, job.Created, job.Rejected = product.JobCompleted, 3, 2, 1
+	job.CreatedAt, job.UpdatedAt, job.FinishedAt = 1800000000, 1700000060, 1700000060
+	job.Errors = []product.ImportRowError{{Row: 3, Values: []string{"SKU-1", ""}, Message: "product_name is required"}}
+	if err := repo.UpdateImportJob(ctx, job); err != nil {
+		t.Fatalf("UpdateImportJob returned error: %v", err)
+	}
+
+	found, err := repo.FindImportJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("FindImportJob returned error: %v", err)
+	}
+	if found.Status != product.JobCompleted || found.ProcessedRows != 3 || found.Created != 2 || found.Rejected != 1 {
+		t.Fatalf("FindImportJob returned %+v, want completed job with 3 processed, 2 created, 1 rejected", found)
+	}
+	if found.CreatedAt != 1700000000 || found.FinishedAt != 1700000060 || found.Mode != product.BulkUpsert {
+		t.Fatalf("FindImportJob returned %+v, want created_at 1700000000, finished_at 1700000060, mode upsert", found)
+	}
+	if len(found.Header) != 2 || found.Header[1] != "product_name" {
+		t.Fatalf("FindImportJob returned header %v, want [code product_name]", found.Header)
+	}
+	if len(found.Errors) != 1 || found.Errors[0].Row != 3 || found.Errors[0].Values[0] != "SKU-1" || found.Errors[0].Message != "product_name is required" {
+		t.Fatalf("FindImportJob returned errors %+v", found.Errors)
+	}
+
+	// Data yang tersimpan tidak berubah ketika job milik pemanggil diubah
+	found.Errors[0].Values[0] = "SKU-X"
+	again, err := repo.FindImportJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("FindImportJob returned error: %v", err)
+	}
+	if again.Errors[0].Values[0] != "SKU-1" {
+		t.Fatalf("stored job shares memory with caller: %+v", again.Errors)
+	}
+
+	if _, err := repo.FindImportJob(ctx, "missing"); !errors.Is(err, product.ErrJobNotFound) {
+		t.Fatalf("FindImportJob with unknown ID returned %v, want ErrJobNotFound", err)
+	}
+	if err := repo.UpdateImportJob(ctx, &product.ImportJob{ID: "missing"}); !errors.Is(err, product.ErrJobNotFound) {
+		t.Fatalf("UpdateImportJob with unknown ID returned %v, want ErrJobNotFound", err)
+	}
+}
+
+func testIdempotencyKey(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+
+	claim := func(createdAt int64, fingerprint string) *product.IdempotencyRecord {
+		t.Helper()
+		existing, err := repo.ClaimIdempotencyKey(ctx, &product.IdempotencyRecord{
+			Key: "retry-1", Fingerprint: fingerprint, CreatedAt: createdAt, ExpiresAt: createdAt + 60,
+		})
+		if err != nil {
+			t.Fatalf("ClaimIdempotencyKey returned error: %v", err)
+		}
+		return existing
+	}
+
+	if existing := claim(1000, "a"); existing != nil {
+		t.Fatalf("first claim returned %+v, want nil", existing)
+	}
+
+	// Klaim kedua selagi request pertama diproses mengembalikan record yang belum selesai
+	existing := claim(1010, "a")
+	if existing == nil || existing.Completed() || existing.Fingerprint != "a" {
+		t.Fatalf("second claim returned %+v, want the in-progress record", existing)
+	}
+
+	err := repo.CompleteIdempotencyKey(ctx, &product.IdempotencyRecord{
+		Key:       "retry-1",
+		Status:    201,
+		Header:    map[string]string{"Content-Type": "application/json"},
+		Body:      []byte(`{"status":201}`),
+		ExpiresAt: 5000,
+	})
+	if err != nil {
+		t.Fatalf("CompleteIdempotencyKey returned error: %v", err)
+	}
+
+	// Record yang sudah selesai tidak dilepas oleh ReleaseIdempotencyKey
+	if err := repo.ReleaseIdempotencyKey(ctx, "retry-1"); err != nil {
+		t.Fatalf("ReleaseIdempotencyKey returned error: %v", err)
+	}
+
+	existing = claim(2000, "b")
+	if existing == nil || existing.Status != 201 || existing.Fingerprint != "a" || existing.ExpiresAt != 5000 ||
+		string(existing.Body) != `{"status":201}` || existing.Header["Content-Type"] != "application/json" {
+		t.Fatalf("claim after completion returned %+v, want the stored response", existing)
+	}
+
+	// Setelah kedaluwarsa key dapat diklaim kembali, dan klaim yang dilepas dapat diklaim ulang
+	if existing := claim(5000, "b"); existing != nil {
+		t.Fatalf("claim after expiry returned %+v, want nil", existing)
+	}
+	if err := repo.ReleaseIdempotencyKey(ctx, "retry-1"); err != nil {
+		t.Fatalf("ReleaseIdempotencyKey returned error: %v", err)
+	}
+	if existing := claim(5001, "c"); existing != nil {
+		t.Fatalf("claim after release returned %+v, want nil", existing)
+	}
+}
+
+func testExport(t *testing.T, repo product.Repository) {
+	ctx := context.Background()
+	// Jumlah produk melebihi satu halaman baca pada repository yang membaca FindAll per halaman
+	names := storeProducts(t, repo, 520)
+	deleted := mustStore(t, repo, &product.Product{Name: "Dihapus", Stock: 1000})
+	if err := repo.DeleteById(ctx, deleted, 0); err != nil {
+		t.Fatalf("DeleteById returned error: %v", err)
+	}
+
+	export := func(filter product.Filter) []*product.Product {
+		t.Helper()
+		var got []*product.Product
+		if err := repo.Export(ctx, filter, func(p *product.Product) error {
+			got = append(got, p)
+			return nil
+		}); err != nil {
+			t.Fatalf("Export(%+v) returned error: %v", filter, err)
+		}
+		return got
+	}
+
+	// Pagination pada filter diabaikan, seluruh produk yang belum dihapus dibaca sesuai urutan penyimpanan
+	got := export(product.Filter{Page: 2, Limit: 10})
+	if len(got) != len(names) {
+		t.Fatalf("Export returned %d products, want %d", len(got), len(names))
+	}
+	for i, p := range got {
+		if p.Name != names[i] {
+			t.Fatalf("Export item %d is %q, want %q", i, p.Name, names[i])
+		}
+	}
+	if got := export(product.Filter{IncludeDeleted: true}); len(got) != len(names)+1 {
+		t.Fatalf("Export with deleted returned %d products, want %d", len(got), len(names)+1)
+	}
+
+	// Ekspresi filter, urutan, dan projection sama dengan FindAll
+	condition, err := product.ParseCondition("stock[gte]", "510")
+	if err != nil || condition == nil {
+		t.Fatalf("ParseCondition returned %v, %v", condition, err)
+	}
+	sorts, _ := product.ParseSort("-stock")
+	got = export(product.Filter{Conditions: []product.Condition{*condition}, Sort: sorts, Fields: []string{"product_name"}})
+	if len(got) != 10 {
+		t.Fatalf("Export with condition returned %d products, want 10", len(got))
+	}
+	if got[0].Name != "Produk 520" || got[9].Name != "Produk 511" {
+		t.Fatalf("Export with sort returned %q..%q, want Produk 520..Produk 511", got[0].Name, got[9].Name)
+	}
+	if got[0].Stock != 0 || got[0].Code != "" {
+		t.Fatalf("Export with fields returned unselected fields: %+v", got[0])
+	}
+
+	// Error dari fn menghentikan export dan dikembalikan apa adanya
+	errStop := errors.New("stop")
+	calls := 0
+	err = repo.Export(ctx, product.Filter{}, func(*product.Product) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || calls != 1 {
+		t.Fatalf("Export returned %v after %d calls, want %v after 1 call", err, calls, errStop)
+	}
+}
+
+func mustStore(t *testing.T, repo product.Repository, p *product.Product) product.ID {
+	t.Helper()
+
+	id, err := repo.Store(context.Background(), p)
+	if err != nil {
+		t.Fatalf("Store returned error: %v", err)
+	}
+	return id
+}
+
+// unknownID mengembalikan ID dengan format yang valid untuk repository namun tidak dimiliki produk mana pun.
+// ID didapat dengan menyimpan produk lalu menghapusnya secara permanen, sehingga suite tidak bergantung
+// pada format ID milik backend tertentu.
+func unknownID(t *testing.T, repo product.Repository) product.ID {
+	t.Helper()
+
+	ctx := context.Background()
+	id := mustStore(t, repo, &product.Product{Name: "Sementara", Stock: 1})
+	if err := repo.DeleteById(ctx, id, 0); err != nil {
+		t.Fatalf("DeleteById returned error: %v", err)
+	}
+	if _, err := repo.Purge(ctx, time.Now().Add(time.Minute).Unix()); err != nil {
+		t.Fatalf("Purge returned error: %v", err)
+	}
+	return id
+}
+
+// storeProducts menyimpan n produk secara berurutan dan mengembalikan nama-namanya.
+func storeProducts(t *testing.T, repo product.Repository, n int) []string {
+	t.Helper()
+
+	names := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("Produk %02d", i+1)
+		mustStore(t, repo, &product.Product{Name: name, Stock: int64(i)})
+		names = append(names, name)
+	}
+	return names
+}
+
+// mustAdjustStock menambahkan stok produk dan menghentikan pengujian jika gagal.
+func mustAdjustStock(t *testing.T, repo product.Repository, id product.ID, quantity int64) {
+	t.Helper()
+
+	_, err := repo.AdjustStock(context.Background(), &product.StockMovement{ProductID: id, Quantity: quantity, Reason: product.ReasonPurchase})
+	if err != nil {
+		t.Fatalf("AdjustStock returned error: %v", err)
+	}
+}
+
+// mustReserve membuat reservasi aktif dan menghentikan pengujian jika gagal.
+func mustReserve(t *testing.T, repo product.Repository, id product.ID, quantity, expiresAt int64) *product.Reservation {
+	t.Helper()
+
+	reservation, err := repo.Reserve(context.Background(), &product.Reservation{
+		ProductID: id,
+		Quantity:  quantity,
+		Status:    product.ReservationActive,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("Reserve returned error: %v", err)
+	}
+	return reservation
+}
+
+// assertStock memeriksa stok fisik dan stok yang ditahan sebuah produk.
+func assertStock(t *testing.T, repo product.Repository, id product.ID, stock, reserved int64) {
+	t.Helper()
+
+	got, err := repo.Find(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	if got.Stock != stock || got.Reserved != reserved {
+		t.Fatalf("got stock %d and reserved %d, want %d and %d", got.Stock, got.Reserved, stock, reserved)
+	}
+}
+
+// mustStoreLocation menyimpan lokasi baru dan menghentikan pengujian jika gagal.
+func mustStoreLocation(t *testing.T, repo product.Repository, code, locationType string) product.ID {
+	t.Helper()
+
+	id, err := repo.StoreLocation(context.Background(), &product.Location{Code: code, Name: "Lokasi " + code, Type: locationType})
+	if err != nil {
+		t.Fatalf("StoreLocation returned error: %v", err)
+	}
+	return id
+}
+
+// assertAllocated memeriksa stok total dan stok yang sudah dialokasikan ke lokasi sebuah produk.
+func assertAllocated(t *testing.T, repo product.Repository, id product.ID, stock, allocated int64) {
+	t.Helper()
+
+	got, err := repo.Find(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	if got.Stock != stock || got.Allocated != allocated {
+		t.Fatalf("got stock %d and allocated %d, want %d and %d", got.Stock, got.Allocated, stock, allocated)
+	}
+}
+
+// assertLocationStock memeriksa stok sebuah produk pada satu lokasi, lokasi tanpa stok dianggap 0.
+func assertLocationStock(t *testing.T, repo product.Repository, id, locationID product.ID, quantity int64) {
+	t.Helper()
+
+	stocks, err := repo.FindLocationStocks(context.Background(), product.LocationStockFilter{ProductIDs: []product.ID{id}, LocationID: locationID})
+	if err != nil {
+		t.Fatalf("FindLocationStocks returned error: %v", err)
+	}
+	var got int64
+	for _, stock := range stocks {
+		got += stock.Quantity
+	}
+	if got != quantity {
+		t.Fatalf("got quantity %d at location %s, want %d", got, locationID, quantity)
+	}
+}
+
+func assertPagination(t *testing.T, got *utils.Pagination, total, limit, currentPage int) {
+	t.Helper()
+
+	if got == nil {
+		t.Fatal("pagination is nil")
+	}
+	if got.Total != total || got.Limit != limit || got.CurrentPage != currentPage {
+		t.Fatalf("pagination is %+v, want total %d, limit %d, current page %d", *got, total, limit, currentPage)
+	}
+}
+
+// assertError memeriksa bahwa err adalah (atau membungkus) error domain want.
+func assertError(t *testing.T, err error, want error) {
+	t.Helper()
+
+	if err == nil {
+		t.Fatalf("got no error, want %q", want)
+	}
+	if !errors.Is(err, want) {
+		t.Fatalf("got error %q, want %q", err, want)
+	}
+}
@@ -8,33 +8,181 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
+	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/exp/slog"
 )
 
+const (
+	// movementCollection adalah nama koleksi MongoDB yang menyimpan buku besar pergerakan stok.
+	movementCollection = "stock_movements"
+
+	// reservationCollection adalah nama koleksi MongoDB yang menyimpan reservasi stok.
+	reservationCollection = "stock_reservations"
+
+	// locationCollection adalah nama koleksi MongoDB yang menyimpan lokasi (gudang atau toko).
+	locationCollection = "locations"
+
+	// locationStockCollection adalah nama koleksi MongoDB yang menyimpan stok per produk per lokasi.
+	locationStockCollection = "location_stocks"
+
+	// idempotencyCollection adalah nama koleksi MongoDB yang menyimpan record Idempotency-Key.
+	idempotencyCollection = "idempotency_keys"
+
+	// importJobCollection adalah nama koleksi MongoDB yang menyimpan job import produk.
+	importJobCollection = "import_jobs"
+)
+
 // storeRepository adalah struct yang mengimplementasikan interface Repository
 // untuk berinteraksi dengan koleksi MongoDB yang menyimpan data produk (store).
 type storeRepository struct {
 	client     *mongo.Client
 	db         string
 	collection string
+	registry   *bsoncodec.Registry // Registry BSON yang menerjemahkan product.ID ke/dari ObjectID
 }
 
 // NewstoreRepository adalah constructor yang digunakan untuk membuat instance baru dari storeRepository.
+// Error koneksi dan timeout MongoDB dikembalikan sebagai product.ErrUnavailable.
 func NewstoreRepository(client *mongo.Client, db string, collection string) product.Repository {
-	return &storeRepository{
+	return withUnavailable(&storeRepository{
 		client:     client,
 		db:         db,
 		collection: collection,
+		registry:   newRegistry(),
+	}, mongoUnavailable)
+}
+
+// EnsureIndexes membuat index yang dibutuhkan koleksi produk, yaitu index unik untuk kode produk (SKU).
+// Index unik hanya berlaku untuk dokumen yang memiliki kode sehingga produk tanpa kode tetap dapat disimpan.
+func EnsureIndexes(ctx context.Context, client *mongo.Client, db string, collection string) error {
+	_, err := client.Database(db).Collection(collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().
+			SetName("code_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.D{{Key: "code", Value: bson.D{{Key: "$gt", Value: ""}}}}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Index teks untuk pencarian kata pada nama dan deskripsi produk. Bahasa "none" menonaktifkan stemming dan
+	// stop word bahasa Inggris sehingga kata dalam bahasa Indonesia dicocokkan apa adanya.
+	_, err = client.Database(db).Collection(collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "product_name", Value: "text"}, {Key: "description", Value: "text"}},
+		Options: options.Index().
+			SetName("product_text").
+			SetWeights(bson.D{{Key: "product_name", Value: 2}, {Key: "description", Value: 1}}).
+			SetDefaultLanguage("none"),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Index 2dsphere untuk pencarian produk berdasarkan jarak, produk tanpa geo tidak diindeks
+	_, err = client.Database(db).Collection(collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "geo", Value: "2dsphere"}},
+		Options: options.Index().SetName("geo_2dsphere"),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Index untuk membaca riwayat pergerakan stok per produk dari yang terbaru
+	_, err = client.Database(db).Collection(movementCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "_id", Value: -1}},
+		Options: options.Index().SetName("product_id_movement"),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Index untuk sweeper yang mencari reservasi aktif yang sudah kedaluwarsa
+	_, err = client.Database(db).Collection(reservationCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+		Options: options.Index().SetName("status_expires_at"),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Index unik untuk kode lokasi
+	_, err = client.Database(db).Collection(locationCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetName("code_unique").SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Satu dokumen stok untuk setiap pasangan produk dan lokasi, serta index untuk membaca stok per lokasi
+	_, err = client.Database(db).Collection(locationStockCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "location_id", Value: 1}},
+			Options: options.Index().SetName("product_id_location_id").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "location_id", Value: 1}},
+			Options: options.Index().SetName("location_id"),
+		},
+	})
+	if err != nil {
+		return err
 	}
+
+	// Index untuk menghapus record Idempotency-Key yang sudah kedaluwarsa, key sendiri disimpan sebagai _id yang unik
+	_, err = client.Database(db).Collection(idempotencyCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetName("expires_at"),
+	})
+	return err
+}
+
+// productCollection mengembalikan koleksi produk yang menggunakan registry BSON untuk product.ID.
+func (r *storeRepository) productCollection() *mongo.Collection {
+	return r.client.Database(r.db).Collection(r.collection, options.Collection().SetRegistry(r.registry))
+}
+
+// stockMovementCollection mengembalikan koleksi buku besar pergerakan stok yang menggunakan registry BSON untuk product.ID.
+func (r *storeRepository) stockMovementCollection() *mongo.Collection {
+	return r.client.Database(r.db).Collection(movementCollection, options.Collection().SetRegistry(r.registry))
+}
+
+// stockReservationCollection mengembalikan koleksi reservasi stok yang menggunakan registry BSON untuk product.ID.
+func (r *storeRepository) stockReservationCollection() *mongo.Collection {
+	return r.client.Database(r.db).Collection(reservationCollection, options.Collection().SetRegistry(r.registry))
+}
+
+// locationCollection mengembalikan koleksi lokasi yang menggunakan registry BSON untuk product.ID.
+func (r *storeRepository) locationCollection() *mongo.Collection {
+	return r.client.Database(r.db).Collection(locationCollection, options.Collection().SetRegistry(r.registry))
+}
+
+// locationStockCollection mengembalikan koleksi stok per lokasi yang menggunakan registry BSON untuk product.ID.
+func (r *storeRepository) locationStockCollection() *mongo.Collection {
+	return r.client.Database(r.db).Collection(locationStockCollection, options.Collection().SetRegistry(r.registry))
+}
+
+// idempotencyKeyCollection mengembalikan koleksi record Idempotency-Key.
+func (r *storeRepository) idempotencyKeyCollection() *mongo.Collection {
+	return r.client.Database(r.db).Collection(idempotencyCollection)
+}
+
+// importJobCollection mengembalikan koleksi job import yang menggunakan registry BSON untuk product.ID.
+func (r *storeRepository) importJobCollection() *mongo.Collection {
+	return r.client.Database(r.db).Collection(importJobCollection, options.Collection().SetRegistry(r.registry))
 }
 
 // Find berfungsi untuk mencari satu produk (store) berdasarkan ID yang diberikan.
-func (r *storeRepository) Find(ctx context.Context, id string) (*product.Product, error) {
+func (r *storeRepository) Find(ctx context.Context, id product.ID) (*product.Product, error) {
 	// Mulai tracing untuk fungsi Find
 	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:Find")
 	defer span.End()
@@ -42,23 +190,52 @@ func (r *storeRepository) Find(ctx context.Context, id string) (*product.Product
 	var storeData product.Product
 
 	// Mengambil koleksi yang dituju dalam database
-	collection := r.client.Database(r.db).Collection(r.collection)
+	collection := r.productCollection()
 
-	// Mengonversi ID dari string ke ObjectID MongoDB
-	objectId, err := primitive.ObjectIDFromHex(id)
+	// Menerjemahkan ID domain ke ObjectID MongoDB
+	objectId, err := objectID(id)
 	if err != nil {
 		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
 		return nil, err
 	}
 
-	// Membuat filter untuk pencarian berdasarkan ID
-	filter := bson.D{{"_id", objectId}}
+	// Membuat filter untuk pencarian berdasarkan ID, produk yang sudah di-soft delete tidak ikut dicari
+	filter := bson.D{{Key: "_id", Value: objectId}, notDeleted()}
 	err = collection.FindOne(ctx, filter).Decode(&storeData)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("error Finding a store")
+			return nil, product.ErrProductNotFound
+		}
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, err
+	}
+	return &storeData, nil
+}
+
+// FindByCode berfungsi untuk mencari satu produk (store) berdasarkan kode produk (SKU).
+func (r *storeRepository) FindByCode(ctx context.Context, code string) (*product.Product, error) {
+	// Mulai tracing untuk fungsi FindByCode
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:FindByCode")
+	defer span.End()
+
+	// Produk tanpa kode tidak dapat dicari berdasarkan kode
+	if code == "" {
+		return nil, product.ErrProductNotFound
+	}
+
+	var storeData product.Product
+
+	collection := r.productCollection()
+
+	// Membuat filter untuk pencarian berdasarkan kode, produk yang sudah di-soft delete tidak ikut dicari
+	filter := bson.D{{Key: "code", Value: code}, notDeleted()}
+	err := collection.FindOne(ctx, filter).Decode(&storeData)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, product.ErrProductNotFound
 		}
 		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, err
 	}
 	return &storeData, nil
 }
@@ -69,7 +246,7 @@ func (r *storeRepository) FindAll(ctx context.Context, filter product.Filter) ([
 	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:FindAll")
 	defer span.End()
 
-	collection := r.client.Database(r.db).Collection(r.collection)
+	collection := r.productCollection()
 
 	// Pengaturan pagination
 	var currentPage, limit int
@@ -78,61 +255,256 @@ func (r *storeRepository) FindAll(ctx context.Context, filter product.Filter) ([
 	} else {
 		currentPage, limit = filter.Page, filter.Limit
 	}
+	if filter.UseCursor && filter.Limit > 0 {
+		// Mode cursor tidak memakai nomor halaman
+		limit = filter.Limit
+	}
 	skip := (currentPage - 1) * limit
 
-	// Menghitung total dokumen untuk pagination
-	totalDocuments, err := collection.CountDocuments(ctx, bson.D{})
+	bsonFilter := productFilter(filter)
+
+	// Mode cursor membaca dokumen setelah _id pada cursor tanpa skip dan tanpa menghitung total
+	if filter.UseCursor {
+		return r.findAfter(ctx, bsonFilter, filter, limit)
+	}
+
+	// Tahap pertama memilih dokumen yang sesuai filter. Pencarian berdasarkan jarak menggunakan $geoNear
+	// sehingga hasil diurutkan dari yang terdekat, selain itu hasil diurutkan sesuai urutan penyisipan.
+	var pipeline mongo.Pipeline
+	if filter.Near != nil {
+		pipeline = mongo.Pipeline{{{Key: "$geoNear", Value: geoNearStage(bsonFilter, filter.Near)}}}
+	} else {
+		pipeline = mongo.Pipeline{
+			{{Key: "$match", Value: bsonFilter}},
+			{{Key: "$sort", Value: sortDocument(filter.Sort)}},
+		}
+	}
+
+	// Projection diterapkan setelah skip dan limit sehingga hanya field yang diminta yang dibaca
+	items := bson.A{
+		bson.D{{Key: "$skip", Value: skip}},
+		bson.D{{Key: "$limit", Value: limit}},
+	}
+	if projection := productProjection(filter); projection != nil {
+		items = append(items, bson.D{{Key: "$project", Value: projection}})
+	}
+
+	// Data halaman dan total dihitung dalam satu aggregation dengan $facet,
+	// sehingga total selalu menggunakan filter yang sama dengan data
+	pipeline = append(pipeline, bson.D{{Key: "$facet", Value: bson.D{
+		{Key: "items", Value: items},
+		{Key: "total", Value: bson.A{
+			bson.D{{Key: "$count", Value: "count"}},
+		}},
+	}}})
+
+	cur, err := collection.Aggregate(ctx, pipeline)
 	if err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, nil, err
+	}
+	defer cur.Close(ctx)
+
+	// $facet selalu menghasilkan tepat satu dokumen
+	var result struct {
+		Items []struct {
+			product.Product `bson:",inline"`
+			Distance        *float64 `bson:"distance,omitempty"`
+		} `bson:"items"`
+		Total []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+	}
+	if cur.Next(ctx) {
+		if err := cur.Decode(&result); err != nil {
+			slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+			return nil, nil, err
+		}
+	}
+	if err := cur.Err(); err != nil {
 		return nil, nil, err
 	}
 
 	// Membuat struktur pagination
 	pagination := utils.Pagination{
-		Total:       int(totalDocuments),
 		Limit:       limit,
 		CurrentPage: currentPage,
 	}
+	if len(result.Total) > 0 {
+		pagination.Total = result.Total[0].Count
+	}
+
+	// Memasukkan hasil query ke dalam slice stores, jarak dari $geoNear dalam meter diubah menjadi kilometer
+	var stores []*product.Product
+	for i := range result.Items {
+		elem := result.Items[i].Product
+		if distance := result.Items[i].Distance; distance != nil {
+			km := *distance / 1000
+			elem.Distance = &km
+		}
+		stores = append(stores, &elem)
+	}
+	return stores, &pagination, nil
+}
 
-	// Menerapkan pagination pada query
-	findOptions := options.Find()
-	findOptions.SetSkip(int64(skip))
-	findOptions.SetLimit(int64(limit))
+// Search berfungsi untuk mencari produk berdasarkan kata pada nama dan deskripsi menggunakan index teks.
+func (r *storeRepository) Search(ctx context.Context, query product.SearchQuery) ([]*product.SearchHit, *utils.Pagination, error) {
+	// Mulai tracing untuk fungsi Search
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:Search")
+	defer span.End()
 
-	// Membuat filter untuk latitude dan longitude
-	bsonFilter := bson.D{}
+	// Pengaturan pagination
+	var currentPage, limit int
+	if query.Limit <= 0 || query.Page <= 0 {
+		currentPage, limit = 1, 10
+	} else {
+		currentPage, limit = query.Page, query.Limit
+	}
+	skip := (currentPage - 1) * limit
 
-	if filter.Latitude != "" && filter.Longitude != "" {
-		bsonFilter = append(bsonFilter, bson.E{
-			Key:   "address.geo.latitude",
-			Value: bson.D{{Key: "$eq", Value: filter.Latitude}},
-		}, bson.E{
-			Key:   "address.geo.longitude",
-			Value: bson.D{{Key: "$eq", Value: filter.Longitude}},
-		})
+	// Kata yang sudah dibersihkan dipisahkan dengan spasi sehingga $text mencocokkan salah satu kata,
+	// tanpa frasa atau negasi dari input pengguna
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{
+			{Key: "$text", Value: bson.D{{Key: "$search", Value: strings.Join(query.Terms(), " ")}}},
+			notDeleted(),
+		}}},
+		{{Key: "$addFields", Value: bson.D{{Key: "score", Value: bson.D{{Key: "$meta", Value: "textScore"}}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$facet", Value: bson.D{
+			{Key: "items", Value: bson.A{
+				bson.D{{Key: "$skip", Value: skip}},
+				bson.D{{Key: "$limit", Value: limit}},
+			}},
+			{Key: "total", Value: bson.A{
+				bson.D{{Key: "$count", Value: "count"}},
+			}},
+		}}},
 	}
 
-	// Membuat filter untuk keyword pencarian
-	if filter.Keyword != "" {
-		bsonFilter = append(bsonFilter, bson.E{
-			Key:   "name",
-			Value: bson.D{{Key: "$regex", Value: primitive.Regex{Pattern: filter.Keyword, Options: "i"}}},
-		})
+	cur, err := r.productCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, nil, err
 	}
+	defer cur.Close(ctx)
 
-	var stores []*product.Product
+	// $facet selalu menghasilkan tepat satu dokumen
+	var result struct {
+		Items []struct {
+			product.Product `bson:",inline"`
+			Score           float64 `bson:"score"`
+		} `bson:"items"`
+		Total []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+	}
+	if cur.Next(ctx) {
+		if err := cur.Decode(&result); err != nil {
+			slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+			return nil, nil, err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return nil, nil, err
+	}
 
-	// Menjalankan query untuk menemukan data yang sesuai dengan filter dan opsi yang diterapkan
-	cur, err := collection.Find(ctx, bsonFilter, findOptions)
+	pagination := utils.Pagination{
+		Limit:       limit,
+		CurrentPage: currentPage,
+	}
+	if len(result.Total) > 0 {
+		pagination.Total = result.Total[0].Count
+	}
+
+	var hits []*product.SearchHit
+	for i := range result.Items {
+		elem := result.Items[i].Product
+		hits = append(hits, &product.SearchHit{Product: &elem, Score: result.Items[i].Score})
+	}
+	return hits, &pagination, nil
+}
+
+// Export berfungsi untuk membaca semua produk yang sesuai filter melalui cursor MongoDB. Dokumen di-decode
+// satu per satu sehingga seluruh hasil tidak pernah ditampung di memori.
+func (r *storeRepository) Export(ctx context.Context, filter product.Filter, fn func(*product.Product) error) error {
+	// Mulai tracing untuk fungsi Export
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:Export")
+	defer span.End()
+
+	// Tahap yang sama dengan FindAll tanpa skip, limit, dan $facet
+	bsonFilter := productFilter(filter)
+	var pipeline mongo.Pipeline
+	if filter.Near != nil {
+		pipeline = mongo.Pipeline{{{Key: "$geoNear", Value: geoNearStage(bsonFilter, filter.Near)}}}
+	} else {
+		pipeline = mongo.Pipeline{
+			{{Key: "$match", Value: bsonFilter}},
+			{{Key: "$sort", Value: sortDocument(filter.Sort)}},
+		}
+	}
+	if projection := productProjection(filter); projection != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$project", Value: projection}})
+	}
+
+	// Pengurutan seluruh koleksi dapat melebihi batas memori $sort, sehingga MongoDB diizinkan memakai disk
+	cur, err := r.productCollection().Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, nil, errors.New("error finding store")
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var item struct {
+			product.Product `bson:",inline"`
+			Distance        *float64 `bson:"distance,omitempty"`
+		}
+		if err := cur.Decode(&item); err != nil {
+			slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+			return err
+		}
+		// Jarak dari $geoNear dalam meter diubah menjadi kilometer
+		if item.Distance != nil {
+			km := *item.Distance / 1000
+			item.Product.Distance = &km
+		}
+		if err := fn(&item.Product); err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
+
+// findAfter menjalankan cursor (keyset) pagination berdasarkan _id. Cursor berisi ObjectID dokumen terakhir
+// pada halaman sebelumnya, dan satu dokumen tambahan dibaca untuk mengetahui apakah masih ada halaman berikutnya.
+func (r *storeRepository) findAfter(ctx context.Context, bsonFilter bson.D, filter product.Filter, limit int) ([]*product.Product, *utils.Pagination, error) {
+	if filter.Cursor != "" {
+		position, err := product.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		after, err := primitive.ObjectIDFromHex(position)
+		if err != nil {
+			return nil, nil, product.ErrInvalidCursor
 		}
+		bsonFilter = append(bsonFilter, bson.E{Key: "_id", Value: bson.D{{Key: "$gt", Value: after}}})
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit + 1))
+	if projection := productProjection(filter); projection != nil {
+		findOptions.SetProjection(projection)
+	}
+	cur, err := r.productCollection().Find(ctx, bsonFilter, findOptions)
+	if err != nil {
 		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
 		return nil, nil, err
 	}
 	defer cur.Close(ctx)
 
-	// Memasukkan hasil query ke dalam slice stores
+	var stores []*product.Product
 	for cur.Next(ctx) {
 		var elem product.Product
 		if err := cur.Decode(&elem); err != nil {
@@ -141,26 +513,152 @@ func (r *storeRepository) FindAll(ctx context.Context, filter product.Filter) ([
 		}
 		stores = append(stores, &elem)
 	}
+	if err := cur.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// Cursor halaman berikutnya hanya dibuat jika dokumen tambahan ditemukan
+	pagination := utils.Pagination{Limit: limit, Cursor: true}
+	if len(stores) > limit {
+		stores = stores[:limit]
+		pagination.HasNext = true
+		pagination.NextCursor = product.EncodeCursor(stores[limit-1].ID.String())
+	}
 	return stores, &pagination, nil
 }
 
+// productFilter membuat filter MongoDB dari Filter. Produk yang sudah di-soft delete hanya disertakan jika diminta,
+// keyword di-escape sehingga metakarakter regex dari pengguna dicocokkan sebagai teks biasa (pencarian berdasarkan
+// kata menggunakan Search), dan ekspresi filter digabungkan dengan $and karena field yang sama dapat muncul lebih dari sekali.
+func productFilter(filter product.Filter) bson.D {
+	bsonFilter := bson.D{}
+	if !filter.IncludeDeleted {
+		bsonFilter = append(bsonFilter, notDeleted())
+	}
+	if filter.Keyword != "" {
+		bsonFilter = append(bsonFilter, bson.E{
+			Key:   "product_name",
+			Value: bson.D{{Key: "$regex", Value: primitive.Regex{Pattern: regexp.QuoteMeta(filter.Keyword), Options: "i"}}},
+		})
+	}
+	if len(filter.Conditions) > 0 {
+		bsonFilter = append(bsonFilter, bson.E{Key: "$and", Value: conditionFilters(filter.Conditions)})
+	}
+	return bsonFilter
+}
+
+// sortDocument membuat dokumen $sort dari parameter sort. Nama JSON field sama dengan nama field BSON,
+// dan _id selalu ditambahkan sebagai kriteria terakhir agar urutan stabil untuk nilai yang sama.
+func sortDocument(sorts []product.SortField) bson.D {
+	doc := bson.D{}
+	for _, s := range sorts {
+		direction := 1
+		if s.Desc {
+			direction = -1
+		}
+		doc = append(doc, bson.E{Key: s.Field, Value: direction})
+	}
+	return append(doc, bson.E{Key: "_id", Value: 1})
+}
+
+// productProjection membuat projection dari Filter.Fields, atau nil jika seluruh field diminta.
+// _id selalu disertakan, sedangkan field hasil perhitungan (available, locations) tidak disimpan di koleksi.
+func productProjection(filter product.Filter) bson.D {
+	if len(filter.Fields) == 0 {
+		return nil
+	}
+
+	projection := bson.D{{Key: "_id", Value: 1}}
+	for _, field := range product.SelectableFields {
+		switch {
+		case !filter.Selects(field), field == "product_id", field == "available", field == "locations":
+			continue
+		case field == "distance_km":
+			// Jarak dari $geoNear ditulis ke field distance
+			projection = append(projection, bson.E{Key: "distance", Value: 1})
+		default:
+			projection = append(projection, bson.E{Key: field, Value: 1})
+		}
+	}
+	return projection
+}
+
+// conditionFilters menerjemahkan AST filter menjadi daftar dokumen filter MongoDB. Nama JSON field sama dengan
+// nama field BSON kecuali product_id yang disimpan di _id. Nilai product_id yang bukan ObjectID tetap dikirim
+// sebagai string sehingga tidak cocok dengan dokumen mana pun, sama seperti pada repository lain.
+func conditionFilters(conditions []product.Condition) bson.A {
+	filters := bson.A{}
+	for _, c := range conditions {
+		key := c.Field
+		values := bson.A{}
+		for _, value := range c.Values {
+			if key == "product_id" {
+				if id, err := objectID(product.ID(value.(string))); err == nil {
+					value = id
+				}
+			}
+			values = append(values, value)
+		}
+		if key == "product_id" {
+			key = "_id"
+		}
+
+		var expression bson.D
+		if c.Operator == product.OpIn {
+			expression = bson.D{{Key: "$in", Value: values}}
+		} else {
+			expression = bson.D{{Key: "$" + string(c.Operator), Value: values[0]}}
+		}
+		filters = append(filters, bson.D{{Key: key, Value: expression}})
+	}
+	return filters
+}
+
+// geoNearStage membuat tahap $geoNear untuk pencarian berdasarkan jarak pada index 2dsphere.
+// Jarak setiap produk ditulis ke field distance dalam meter karena titik pusatnya GeoJSON.
+func geoNearStage(bsonFilter bson.D, near *product.GeoQuery) bson.D {
+	stage := bson.D{
+		{Key: "near", Value: product.NewGeoPoint(near.Latitude, near.Longitude)},
+		{Key: "key", Value: "geo"},
+		{Key: "distanceField", Value: "distance"},
+		{Key: "spherical", Value: true},
+		{Key: "query", Value: bsonFilter},
+	}
+	if near.RadiusKM > 0 {
+		stage = append(stage, bson.E{Key: "maxDistance", Value: near.RadiusKM * 1000})
+	}
+	return stage
+}
+
 // Store berfungsi untuk menyimpan data produk (store) baru ke dalam database.
-func (r *storeRepository) Store(ctx context.Context, dataStore *product.Product) (primitive.ObjectID, error) {
+func (r *storeRepository) Store(ctx context.Context, dataStore *product.Product) (product.ID, error) {
 	// Mulai tracing untuk fungsi Store
 	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:Store")
 	defer span.End()
 
-	collection := r.client.Database(r.db).Collection(r.collection)
+	collection := r.productCollection()
 
-	// Menyisipkan data baru ke dalam koleksi
+	// Menyisipkan data baru ke dalam koleksi, setiap produk baru dimulai dari versi 1
+	dataStore.Version = 1
 	doInsert, err := collection.InsertOne(ctx, dataStore)
 	if err != nil {
+		// Index unik pada kode produk menolak kode yang sudah digunakan
+		if mongo.IsDuplicateKeyError(err) {
+			return "", product.ErrCodeConflict
+		}
 		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
-		return primitive.ObjectID{}, errors.New("error writing to repository")
+		return "", errors.New("error writing to repository")
 	}
 
-	// Mengembalikan ID dari dokumen yang baru saja disimpan
-	return doInsert.InsertedID.(primitive.ObjectID), nil
+	// Mengembalikan ID dari dokumen yang baru saja disimpan dalam bentuk ID domain
+	switch insertedID := doInsert.InsertedID.(type) {
+	case primitive.ObjectID:
+		return product.ID(insertedID.Hex()), nil
+	case string:
+		return product.ID(insertedID), nil
+	default:
+		return "", fmt.Errorf("unexpected inserted ID type %T", insertedID)
+	}
 }
 
 // Update berfungsi untuk memperbarui data produk (store) yang sudah ada di dalam database.
@@ -169,79 +667,1445 @@ func (r *storeRepository) Update(ctx context.Context, dataStore *product.Product
 	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:Update")
 	defer span.End()
 
+	updatedStore := updatedFields(dataStore)
+
+	collection := r.productCollection()
+
+	// Menerjemahkan ID domain ke ObjectID MongoDB
+	objectId, err := objectID(dataStore.ID)
+	if err != nil {
+		return err
+	}
+
+	// Melakukan update pada dokumen berdasarkan ID, produk yang sudah di-soft delete tidak dapat diperbarui.
+	// Jika versi yang diharapkan diisi, hanya dokumen dengan versi tersebut yang diperbarui.
+	filter := bson.D{{Key: "_id", Value: objectId}, notDeleted()}
+	versioned := filter
+	if dataStore.Version != 0 {
+		versioned = append(append(bson.D{}, filter...), bson.E{Key: "version", Value: dataStore.Version})
+	}
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}}}
+	if len(updatedStore) > 0 {
+		update = append(update, bson.E{Key: "$set", Value: updatedStore})
+	}
+
+	var updated product.Product
+	err = collection.FindOneAndUpdate(
+		ctx,
+		versioned,
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.D{{Key: "version", Value: 1}}),
+	).Decode(&updated)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// Produk yang tidak ada tidak dapat diperbarui, sedangkan produk dengan versi lain ditolak
+		return r.versionConflict(ctx, filter, dataStore.Version, product.ErrProductNotFound)
+	}
+	if err != nil {
+		// Index unik pada kode produk menolak kode yang sudah digunakan produk lain
+		if mongo.IsDuplicateKeyError(err) {
+			return product.ErrCodeConflict
+		}
+		fmt.Println(err, "err")
+		return err
+	}
+	dataStore.Version = updated.Version
+
+	return nil
+}
+
+// updatedFields mengembalikan field dataStore yang boleh diperbarui dan tidak kosong sebagai isi $set.
+func updatedFields(dataStore *product.Product) bson.D {
 	updatedStore := bson.D{}
 
 	// Menggunakan refleksi untuk memeriksa setiap field dalam struct dataStore
 	values := reflect.ValueOf(*dataStore)
 	types := values.Type()
 	for i := 0; i < values.NumField(); i++ {
-		// Jika field bukan ID dan bukan field kosong, maka tambahkan ke updatedStore
-		if types.Field(i).Name != "ID" && !utils.IsEmptyStruct(values.Field(i)) {
-			updatedStore = append(updatedStore, primitive.E{Key: types.Field(i).Tag.Get("json"), Value: values.Field(i).Interface()})
+		// Jika field boleh diperbarui dan bukan field kosong, maka tambahkan ke updatedStore
+		if updatableField(types.Field(i)) && !utils.IsEmptyStruct(values.Field(i)) {
+			updatedStore = append(updatedStore, primitive.E{Key: columnName(types.Field(i)), Value: values.Field(i).Interface()})
 		}
 	}
+	return updatedStore
+}
 
-	collection := r.client.Database(r.db).Collection(r.collection)
+// Patch berfungsi untuk menerapkan perubahan eksplisit pada produk, termasuk nilai nol dan nil.
+// Field yang bernilai nil dihapus dari dokumen dengan $unset, field lain ditulis dengan $set.
+func (r *storeRepository) Patch(ctx context.Context, id product.ID, changes product.Changes, version int64) error {
+	// Mulai tracing untuk fungsi Patch
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:Patch")
+	defer span.End()
 
-	// Melakukan update pada dokumen berdasarkan ID
-	_, err := collection.UpdateOne(
-		ctx,
-		bson.M{"_id": dataStore.ID},
-		bson.D{
-			{Key: "$set", Value: updatedStore},
-		},
-	)
+	fields, err := patchFields(changes)
 	if err != nil {
-		fmt.Println(err, "err")
 		return err
 	}
 
+	// Menerjemahkan ID domain ke ObjectID MongoDB
+	objectId, err := objectID(id)
+	if err != nil {
+		return err
+	}
+
+	// Nama field dokumen diambil dari tag bson, bukan tag json
+	set, unset := bson.D{}, bson.D{}
+	for _, f := range fields {
+		if f.value.Kind() == reflect.Pointer && f.value.IsNil() {
+			unset = append(unset, bson.E{Key: columnName(f.field), Value: ""})
+			continue
+		}
+		set = append(set, bson.E{Key: columnName(f.field), Value: f.value.Interface()})
+	}
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}}}
+	if len(set) > 0 {
+		update = append(update, bson.E{Key: "$set", Value: set})
+	}
+	if len(unset) > 0 {
+		update = append(update, bson.E{Key: "$unset", Value: unset})
+	}
+
+	// Pemeriksaan versi dan perubahan dilakukan dalam satu operasi atomik
+	filter := bson.D{{Key: "_id", Value: objectId}, notDeleted()}
+	versioned := filter
+	if version != 0 {
+		versioned = append(append(bson.D{}, filter...), bson.E{Key: "version", Value: version})
+	}
+	result, err := r.productCollection().UpdateOne(ctx, versioned, update)
+	if err != nil {
+		// Index unik pada kode produk menolak kode yang sudah digunakan produk lain
+		if mongo.IsDuplicateKeyError(err) {
+			return product.ErrCodeConflict
+		}
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return r.versionConflict(ctx, filter, version, product.ErrProductNotFound)
+	}
+
 	return nil
 }
 
-// DeleteById berfungsi untuk menghapus produk (store) dari database berdasarkan ID yang diberikan.
-func (r *storeRepository) DeleteById(ctx context.Context, id string) error {
+// DeleteById berfungsi untuk menandai produk (store) sebagai dihapus (soft delete) berdasarkan ID yang diberikan.
+// Dokumen tidak dihapus dari koleksi, hanya field deleted_at yang diisi dengan waktu penghapusan.
+func (r *storeRepository) DeleteById(ctx context.Context, id product.ID, version int64) error {
 	// Mulai tracing untuk fungsi DeleteById
 	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:DeleteById")
 	defer span.End()
 
 	// Periksa apakah ID kosong
-	if id == "" {
-		return errors.New("ID is empty")
+	if id.IsZero() {
+		return product.ErrInvalidID
 	}
 
-	collection := r.client.Database(r.db).Collection(r.collection)
-
-	// Mengonversi ID dari string ke ObjectID MongoDB
-	objectID, err2 := primitive.ObjectIDFromHex(id)
+	// Menerjemahkan ID domain ke ObjectID MongoDB
+	objectId, err2 := objectID(id)
 	if err2 != nil {
 		return err2
 	}
 
-	// Memeriksa apakah dokumen dengan ID tersebut ada
-	result := collection.FindOne(ctx, bson.M{"_id": objectID})
+	// Mengisi deleted_at hanya pada dokumen yang belum dihapus dan versinya sesuai
+	return r.softDelete(ctx, bson.D{{Key: "_id", Value: objectId}, notDeleted()}, version)
+}
+
+// Restore berfungsi untuk memulihkan produk (store) yang sudah di-soft delete berdasarkan ID yang diberikan.
+func (r *storeRepository) Restore(ctx context.Context, id product.ID) error {
+	// Mulai tracing untuk fungsi Restore
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:Restore")
+	defer span.End()
+
+	collection := r.productCollection()
 
-	if result.Err() != nil {
-		if errors.Is(result.Err(), mongo.ErrNoDocuments) {
-			return errors.New("store not found")
-		}
-		return result.Err()
+	// Menerjemahkan ID domain ke ObjectID MongoDB
+	objectId, err := objectID(id)
+	if err != nil {
+		return err
 	}
 
-	// Menghapus dokumen dari koleksi
-	_, err := collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	// Mengosongkan deleted_at hanya pada dokumen yang memang sudah dihapus
+	result, err := collection.UpdateOne(
+		ctx,
+		bson.D{{Key: "_id", Value: objectId}, {Key: "deleted_at", Value: bson.D{{Key: "$gt", Value: 0}}}},
+		bson.D{
+			{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: int64(0)}}},
+			{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}},
+		},
+	)
 	if err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
 		return err
 	}
 
-	return nil
+	if result.MatchedCount == 0 {
+		return product.ErrProductNotFound
+	}
+
+	return nil
+}
+
+// Purge berfungsi untuk menghapus permanen produk (store) yang sudah di-soft delete
+// pada atau sebelum waktu deletedBefore.
+func (r *storeRepository) Purge(ctx context.Context, deletedBefore int64) (int64, error) {
+	// Mulai tracing untuk fungsi Purge
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:Purge")
+	defer span.End()
+
+	collection := r.productCollection()
+
+	// Hanya dokumen yang sudah dihapus dan melewati masa retensi yang dihapus permanen
+	result, err := collection.DeleteMany(ctx, bson.D{
+		{Key: "deleted_at", Value: bson.D{{Key: "$gt", Value: 0}, {Key: "$lte", Value: deletedBefore}}},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// Remove berfungsi untuk menghapus permanen satu produk (store) berdasarkan ID yang diberikan.
+func (r *storeRepository) Remove(ctx context.Context, id product.ID) error {
+	// Mulai tracing untuk fungsi Remove
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:Remove")
+	defer span.End()
+
+	objectId, err := objectID(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.productCollection().DeleteOne(ctx, bson.D{{Key: "_id", Value: objectId}})
+	if err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return product.ErrProductNotFound
+	}
+	return nil
+}
+
+// Delete berfungsi untuk menandai produk (store) sebagai dihapus (soft delete) berdasarkan kode produk (SKU).
+func (r *storeRepository) Delete(ctx context.Context, code string, version int64) error {
+	// Mulai tracing untuk fungsi Delete
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:Delete")
+	defer span.End()
+
+	// Periksa apakah kode kosong
+	if code == "" {
+		return product.ErrProductNotFound
+	}
+
+	// Mengisi deleted_at hanya pada dokumen yang belum dihapus dan versinya sesuai
+	return r.softDelete(ctx, bson.D{{Key: "code", Value: code}, notDeleted()}, version)
+}
+
+// softDelete mengisi deleted_at dan menambah versi pada produk yang cocok dengan filter. Jika version bukan 0,
+// hanya produk dengan versi tersebut yang diubah, dan ErrVersionConflict dikembalikan jika produk ada dengan versi lain.
+func (r *storeRepository) softDelete(ctx context.Context, filter bson.D, version int64) error {
+	collection := r.productCollection()
+
+	versioned := filter
+	if version != 0 {
+		versioned = append(append(bson.D{}, filter...), bson.E{Key: "version", Value: version})
+	}
+	result, err := collection.UpdateOne(
+		ctx,
+		versioned,
+		bson.D{
+			{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: time.Now().UTC().Unix()}}},
+			{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Jika tidak ada dokumen yang cocok, produk tidak ada, sudah dihapus, atau versinya sudah berubah
+	if result.MatchedCount == 0 {
+		return r.versionConflict(ctx, filter, version, product.ErrProductNotFound)
+	}
+
+	return nil
+}
+
+// versionConflict mengembalikan ErrVersionConflict jika version bukan 0 dan produk yang cocok dengan filter masih ada,
+// selain itu notFound dikembalikan.
+func (r *storeRepository) versionConflict(ctx context.Context, filter bson.D, version int64, notFound error) error {
+	if version == 0 {
+		return notFound
+	}
+	count, err := r.productCollection().CountDocuments(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return product.ErrVersionConflict
+	}
+	return notFound
+}
+
+// AdjustStock berfungsi untuk mengubah stok produk secara atomik dengan $inc dan mencatat pergerakannya.
+// Kondisi stok tidak negatif dan versi yang diharapkan (jika diisi) diperiksa di dalam filter sehingga aman dari race condition.
+func (r *storeRepository) AdjustStock(ctx context.Context, movement *product.StockMovement) (*product.StockMovement, error) {
+	// Mulai tracing untuk fungsi AdjustStock
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:AdjustStock")
+	defer span.End()
+
+	collection := r.productCollection()
+
+	// Menerjemahkan ID domain ke ObjectID MongoDB
+	objectId, err := objectID(movement.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pengurangan stok hanya diterapkan jika stok hasilnya tidak lebih kecil dari stok yang ditahan reservasi.
+	// Pengurangan tanpa lokasi juga tidak boleh memakai stok yang sudah dialokasikan ke lokasi.
+	filter := bson.D{{Key: "_id", Value: objectId}, notDeleted()}
+	if movement.Quantity < 0 {
+		stockAfter := bson.D{{Key: "$add", Value: bson.A{"$stock", movement.Quantity}}}
+		conditions := bson.A{
+			bson.D{{Key: "$gte", Value: bson.A{stockAfter, bson.D{{Key: "$ifNull", Value: bson.A{"$reserved", 0}}}}}},
+		}
+		if movement.LocationID.IsZero() {
+			conditions = append(conditions, bson.D{{Key: "$gte", Value: bson.A{stockAfter, bson.D{{Key: "$ifNull", Value: bson.A{"$allocated", 0}}}}}})
+		}
+		filter = append(filter, bson.E{Key: "$expr", Value: bson.D{{Key: "$and", Value: conditions}}})
+	}
+
+	// Versi yang diharapkan hanya diperiksa jika diisi
+	if movement.ExpectedVersion != 0 {
+		filter = append(filter, bson.E{Key: "version", Value: movement.ExpectedVersion})
+	}
+
+	// Perubahan pada sebuah lokasi juga mengubah jumlah stok yang sudah dialokasikan
+	inc := bson.D{{Key: "stock", Value: movement.Quantity}, {Key: "version", Value: int64(1)}}
+	if !movement.LocationID.IsZero() {
+		inc = append(inc, bson.E{Key: "allocated", Value: movement.Quantity})
+	}
+
+	var storeData product.Product
+	err = collection.FindOneAndUpdate(
+		ctx,
+		filter,
+		bson.D{{Key: "$inc", Value: inc}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&storeData)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+			return nil, err
+		}
+		return nil, r.stockConflict(ctx, objectId, movement.ExpectedVersion)
+	}
+
+	// Mengembalikan perubahan stok produk jika langkah berikutnya gagal
+	revert := func() {
+		_, revertErr := collection.UpdateOne(ctx, bson.D{{Key: "_id", Value: objectId}}, bson.D{{Key: "$inc", Value: negate(inc)}})
+		if revertErr != nil {
+			slog.ErrorContext(ctx, "Error reverting stock", slog.Any("err ", revertErr))
+		}
+	}
+
+	// Mengubah stok lokasi, stok lokasi tidak boleh menjadi negatif
+	if !movement.LocationID.IsZero() {
+		if err := r.changeLocationStock(ctx, movement.ProductID, movement.LocationID, movement.Quantity, movement.CreatedAt); err != nil {
+			revert()
+			return nil, err
+		}
+	}
+
+	// Mencatat pergerakan stok ke buku besar
+	stored := *movement
+	stored.StockAfter = storeData.Stock
+	recorded, err := r.insertMovement(ctx, &stored)
+	if err != nil {
+		// Mengembalikan perubahan stok agar stok tetap sesuai dengan buku besar
+		revert()
+		if !movement.LocationID.IsZero() {
+			if revertErr := r.changeLocationStock(ctx, movement.ProductID, movement.LocationID, -movement.Quantity, movement.CreatedAt); revertErr != nil {
+				slog.ErrorContext(ctx, "Error reverting location stock", slog.Any("err ", revertErr))
+			}
+		}
+		return nil, err
+	}
+
+	return recorded, nil
+}
+
+// insertMovement menyisipkan satu pergerakan stok ke buku besar dan mengembalikannya dengan ID yang baru dibuat.
+func (r *storeRepository) insertMovement(ctx context.Context, movement *product.StockMovement) (*product.StockMovement, error) {
+	stored := *movement
+	stored.ID = ""
+	doInsert, err := r.stockMovementCollection().InsertOne(ctx, stored)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+		return nil, errors.New("error writing to repository")
+	}
+
+	insertedID, ok := doInsert.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return nil, fmt.Errorf("unexpected inserted ID type %T", doInsert.InsertedID)
+	}
+	stored.ID = product.ID(insertedID.Hex())
+
+	return &stored, nil
+}
+
+// FindMovements berfungsi untuk membaca riwayat pergerakan stok sebuah produk, dari yang terbaru, dengan pagination.
+func (r *storeRepository) FindMovements(ctx context.Context, productID product.ID, filter product.MovementFilter) ([]*product.StockMovement, *utils.Pagination, error) {
+	// Mulai tracing untuk fungsi FindMovements
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:FindMovements")
+	defer span.End()
+
+	// Menerjemahkan ID domain ke ObjectID MongoDB
+	objectId, err := objectID(productID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Pengaturan pagination
+	var currentPage, limit int
+	if filter.Limit <= 0 || filter.Page <= 0 {
+		currentPage, limit = 1, 10
+	} else {
+		currentPage, limit = filter.Page, filter.Limit
+	}
+	skip := (currentPage - 1) * limit
+
+	collection := r.stockMovementCollection()
+	bsonFilter := bson.D{{Key: "product_id", Value: objectId}}
+
+	// Menghitung total pergerakan stok untuk pagination
+	totalDocuments, err := collection.CountDocuments(ctx, bsonFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := utils.Pagination{
+		Total:       int(totalDocuments),
+		Limit:       limit,
+		CurrentPage: currentPage,
+	}
+
+	// ObjectID bertambah seiring waktu sehingga urutan _id menurun berarti dari yang terbaru
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	cur, err := collection.Find(ctx, bsonFilter, findOptions)
+	if err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, nil, err
+	}
+	defer cur.Close(ctx)
+
+	var movements []*product.StockMovement
+	for cur.Next(ctx) {
+		var elem product.StockMovement
+		if err := cur.Decode(&elem); err != nil {
+			slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+			continue
+		}
+		movements = append(movements, &elem)
+	}
+	return movements, &pagination, nil
+}
+
+// Reserve berfungsi untuk menahan stok produk dengan $inc pada field reserved lalu menyimpan reservasi.
+// Kondisi stok tersedia (stock - reserved) diperiksa di dalam filter sehingga aman dari race condition.
+func (r *storeRepository) Reserve(ctx context.Context, reservation *product.Reservation) (*product.Reservation, error) {
+	// Mulai tracing untuk fungsi Reserve
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:Reserve")
+	defer span.End()
+
+	collection := r.productCollection()
+
+	// Menerjemahkan ID domain ke ObjectID MongoDB
+	objectId, err := objectID(reservation.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{{Key: "_id", Value: objectId}, notDeleted(), {Key: "$expr", Value: bson.D{{Key: "$gte", Value: bson.A{
+		bson.D{{Key: "$subtract", Value: bson.A{"$stock", bson.D{{Key: "$ifNull", Value: bson.A{"$reserved", 0}}}}}},
+		reservation.Quantity,
+	}}}}}
+	result, err := collection.UpdateOne(ctx, filter, bson.D{{Key: "$inc", Value: bson.D{{Key: "reserved", Value: reservation.Quantity}, {Key: "version", Value: 1}}}})
+	if err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		// Membedakan produk yang tidak ditemukan dengan stok yang tidak mencukupi
+		count, err := collection.CountDocuments(ctx, bson.D{{Key: "_id", Value: objectId}, notDeleted()})
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, product.ErrProductNotFound
+		}
+		return nil, product.ErrInsufficientStock
+	}
+
+	// Menyimpan reservasi
+	stored := *reservation
+	stored.ID = ""
+	doInsert, err := r.stockReservationCollection().InsertOne(ctx, stored)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+
+		// Melepas kembali stok yang sudah ditahan
+		_, revertErr := collection.UpdateOne(
+			ctx,
+			bson.D{{Key: "_id", Value: objectId}},
+			bson.D{{Key: "$inc", Value: bson.D{{Key: "reserved", Value: -reservation.Quantity}, {Key: "version", Value: 1}}}},
+		)
+		if revertErr != nil {
+			slog.ErrorContext(ctx, "Error reverting reserved stock", slog.Any("err ", revertErr))
+		}
+		return nil, errors.New("error writing to repository")
+	}
+
+	insertedID, ok := doInsert.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return nil, fmt.Errorf("unexpected inserted ID type %T", doInsert.InsertedID)
+	}
+	stored.ID = product.ID(insertedID.Hex())
+
+	return &stored, nil
+}
+
+// FindReservation berfungsi untuk mencari reservasi berdasarkan ID.
+func (r *storeRepository) FindReservation(ctx context.Context, reservationID product.ID) (*product.Reservation, error) {
+	// Mulai tracing untuk fungsi FindReservation
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:FindReservation")
+	defer span.End()
+
+	// ID yang bukan ObjectID tidak mungkin dimiliki reservasi mana pun
+	objectId, err := objectID(reservationID)
+	if err != nil {
+		return nil, product.ErrReservationNotFound
+	}
+
+	var reservation product.Reservation
+	err = r.stockReservationCollection().FindOne(ctx, bson.D{{Key: "_id", Value: objectId}}).Decode(&reservation)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, product.ErrReservationNotFound
+		}
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// ConfirmReservation berfungsi untuk mengonfirmasi reservasi aktif, mengurangi stok dan stok yang ditahan produk,
+// lalu mencatat pergerakan stok. Perubahan status dilakukan lebih dahulu dengan filter status active
+// sehingga reservasi yang sama tidak dapat dikonfirmasi dua kali atau dilepas bersamaan.
+func (r *storeRepository) ConfirmReservation(ctx context.Context, reservationID product.ID, confirmedAt int64) (*product.Reservation, error) {
+	// Mulai tracing untuk fungsi ConfirmReservation
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:ConfirmReservation")
+	defer span.End()
+
+	objectId, err := objectID(reservationID)
+	if err != nil {
+		return nil, product.ErrReservationNotFound
+	}
+
+	// Reservasi yang sudah melewati batas waktu tidak dapat dikonfirmasi walaupun belum disapu
+	reservation, err := r.transitionReservation(ctx, bson.D{
+		{Key: "_id", Value: objectId},
+		{Key: "status", Value: product.ReservationActive},
+		{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: confirmedAt}}},
+	}, product.ReservationConfirmed, confirmedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mengurangi stok fisik dan stok yang ditahan dengan jumlah yang sama.
+	// Reservasi memakai stok yang belum dialokasikan ke lokasi.
+	var storeData product.Product
+	err = r.productCollection().FindOneAndUpdate(
+		ctx,
+		bson.D{
+			{Key: "_id", Value: reservation.ProductID},
+			{Key: "$expr", Value: bson.D{{Key: "$gte", Value: bson.A{
+				bson.D{{Key: "$subtract", Value: bson.A{"$stock", reservation.Quantity}}},
+				bson.D{{Key: "$ifNull", Value: bson.A{"$allocated", 0}}},
+			}}}},
+		},
+		bson.D{{Key: "$inc", Value: bson.D{{Key: "stock", Value: -reservation.Quantity}, {Key: "reserved", Value: -reservation.Quantity}, {Key: "version", Value: 1}}}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&storeData)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+			return nil, err
+		}
+
+		// Reservasi dikembalikan menjadi active agar dapat dikonfirmasi ulang atau dilepas
+		_, revertErr := r.stockReservationCollection().UpdateOne(
+			ctx,
+			bson.D{{Key: "_id", Value: objectId}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: product.ReservationActive}}}},
+		)
+		if revertErr != nil {
+			slog.ErrorContext(ctx, "Error reverting reservation", slog.Any("err ", revertErr))
+		}
+
+		count, err := r.productCollection().CountDocuments(ctx, bson.D{{Key: "_id", Value: reservation.ProductID}})
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, product.ErrProductNotFound
+		}
+		return nil, product.ErrInsufficientStock
+	}
+
+	// Mencatat pengurangan stok ke buku besar
+	_, err = r.insertMovement(ctx, &product.StockMovement{
+		ProductID:  reservation.ProductID,
+		Quantity:   -reservation.Quantity,
+		Reason:     product.ReasonSale,
+		Note:       "reservation " + reservation.ID.String(),
+		StockAfter: storeData.Stock,
+		CreatedAt:  confirmedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// ReleaseReservation berfungsi untuk mengubah reservasi aktif menjadi released atau expired
+// lalu melepas stok yang ditahan pada produk.
+func (r *storeRepository) ReleaseReservation(ctx context.Context, reservationID product.ID, status string, releasedAt int64) (*product.Reservation, error) {
+	// Mulai tracing untuk fungsi ReleaseReservation
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:ReleaseReservation")
+	defer span.End()
+
+	objectId, err := objectID(reservationID)
+	if err != nil {
+		return nil, product.ErrReservationNotFound
+	}
+
+	reservation, err := r.transitionReservation(ctx, bson.D{
+		{Key: "_id", Value: objectId},
+		{Key: "status", Value: product.ReservationActive},
+	}, status, releasedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Melepas stok yang ditahan, produk yang sudah di-purge tidak lagi cocok dan diabaikan
+	_, err = r.productCollection().UpdateOne(
+		ctx,
+		bson.D{{Key: "_id", Value: reservation.ProductID}},
+		bson.D{{Key: "$inc", Value: bson.D{{Key: "reserved", Value: -reservation.Quantity}, {Key: "version", Value: 1}}}},
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// FindExpiredReservations berfungsi untuk mencari reservasi aktif yang kedaluwarsa, dari yang paling lama.
+func (r *storeRepository) FindExpiredReservations(ctx context.Context, before int64, limit int) ([]*product.Reservation, error) {
+	// Mulai tracing untuk fungsi FindExpiredReservations
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:FindExpiredReservations")
+	defer span.End()
+
+	filter := bson.D{
+		{Key: "status", Value: product.ReservationActive},
+		{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: before}}},
+	}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "expires_at", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cur, err := r.stockReservationCollection().Find(ctx, filter, findOptions)
+	if err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var reservations []*product.Reservation
+	for cur.Next(ctx) {
+		var elem product.Reservation
+		if err := cur.Decode(&elem); err != nil {
+			slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+			continue
+		}
+		reservations = append(reservations, &elem)
+	}
+	return reservations, nil
+}
+
+// StoreLocation berfungsi untuk menyimpan lokasi baru ke dalam database.
+func (r *storeRepository) StoreLocation(ctx context.Context, location *product.Location) (product.ID, error) {
+	// Mulai tracing untuk fungsi StoreLocation
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:StoreLocation")
+	defer span.End()
+
+	stored := *location
+	stored.ID = ""
+	doInsert, err := r.locationCollection().InsertOne(ctx, stored)
+	if err != nil {
+		// Index unik pada kode lokasi menolak kode yang sudah digunakan
+		if mongo.IsDuplicateKeyError(err) {
+			return "", product.ErrLocationCodeConflict
+		}
+		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+		return "", errors.New("error writing to repository")
+	}
+
+	insertedID, ok := doInsert.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", fmt.Errorf("unexpected inserted ID type %T", doInsert.InsertedID)
+	}
+	return product.ID(insertedID.Hex()), nil
+}
+
+// FindLocation berfungsi untuk mencari lokasi berdasarkan ID.
+func (r *storeRepository) FindLocation(ctx context.Context, locationID product.ID) (*product.Location, error) {
+	// Mulai tracing untuk fungsi FindLocation
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:FindLocation")
+	defer span.End()
+
+	// ID yang bukan ObjectID tidak mungkin dimiliki lokasi mana pun
+	objectId, err := objectID(locationID)
+	if err != nil {
+		return nil, product.ErrLocationNotFound
+	}
+
+	var location product.Location
+	err = r.locationCollection().FindOne(ctx, bson.D{{Key: "_id", Value: objectId}}).Decode(&location)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, product.ErrLocationNotFound
+		}
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, err
+	}
+	return &location, nil
+}
+
+// FindLocations berfungsi untuk mengembalikan semua lokasi sesuai urutan pembuatan.
+func (r *storeRepository) FindLocations(ctx context.Context) ([]*product.Location, error) {
+	// Mulai tracing untuk fungsi FindLocations
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:FindLocations")
+	defer span.End()
+
+	cur, err := r.locationCollection().Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var locations []*product.Location
+	for cur.Next(ctx) {
+		var elem product.Location
+		if err := cur.Decode(&elem); err != nil {
+			slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+			continue
+		}
+		locations = append(locations, &elem)
+	}
+	return locations, nil
+}
+
+// FindLocationStocks berfungsi untuk mengembalikan stok per lokasi yang sesuai dengan filter.
+func (r *storeRepository) FindLocationStocks(ctx context.Context, filter product.LocationStockFilter) ([]*product.LocationStock, error) {
+	// Mulai tracing untuk fungsi FindLocationStocks
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:FindLocationStocks")
+	defer span.End()
+
+	bsonFilter := bson.D{}
+	if len(filter.ProductIDs) > 0 {
+		bsonFilter = append(bsonFilter, bson.E{Key: "product_id", Value: bson.D{{Key: "$in", Value: filter.ProductIDs}}})
+	}
+	if !filter.LocationID.IsZero() {
+		bsonFilter = append(bsonFilter, bson.E{Key: "location_id", Value: filter.LocationID})
+	}
+
+	cur, err := r.locationStockCollection().Find(ctx, bsonFilter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var stocks []*product.LocationStock
+	for cur.Next(ctx) {
+		var elem product.LocationStock
+		if err := cur.Decode(&elem); err != nil {
+			slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+			continue
+		}
+		stocks = append(stocks, &elem)
+	}
+	return stocks, nil
+}
+
+// TransferStock berfungsi untuk memindahkan stok antar lokasi lalu mencatat pergerakannya.
+// Setiap langkah menggunakan update atomik dengan syarat stok asal mencukupi, dan langkah yang sudah
+// berhasil dikembalikan jika langkah berikutnya gagal.
+func (r *storeRepository) TransferStock(ctx context.Context, transfer *product.StockTransfer) (*product.StockTransfer, error) {
+	// Mulai tracing untuk fungsi TransferStock
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:TransferStock")
+	defer span.End()
+
+	collection := r.productCollection()
+
+	objectId, err := objectID(transfer.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	var storeData product.Product
+	err = collection.FindOne(ctx, bson.D{{Key: "_id", Value: objectId}, notDeleted()}).Decode(&storeData)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, product.ErrProductNotFound
+		}
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, err
+	}
+
+	// Mengambil stok dari lokasi asal atau dari stok yang belum dialokasikan
+	if transfer.FromLocationID.IsZero() {
+		result, err := collection.UpdateOne(
+			ctx,
+			bson.D{{Key: "_id", Value: objectId}, {Key: "$expr", Value: bson.D{{Key: "$gte", Value: bson.A{
+				bson.D{{Key: "$subtract", Value: bson.A{"$stock", bson.D{{Key: "$ifNull", Value: bson.A{"$allocated", 0}}}}}},
+				transfer.Quantity,
+			}}}}},
+			bson.D{{Key: "$inc", Value: bson.D{{Key: "allocated", Value: transfer.Quantity}}}},
+		)
+		if err != nil {
+			slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+			return nil, err
+		}
+		if result.MatchedCount == 0 {
+			return nil, product.ErrInsufficientStock
+		}
+	} else if err := r.changeLocationStock(ctx, transfer.ProductID, transfer.FromLocationID, -transfer.Quantity, transfer.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	// Menambahkan stok ke lokasi tujuan atau mengembalikannya menjadi stok yang belum dialokasikan
+	if transfer.ToLocationID.IsZero() {
+		_, err = collection.UpdateOne(ctx, bson.D{{Key: "_id", Value: objectId}}, bson.D{{Key: "$inc", Value: bson.D{{Key: "allocated", Value: -transfer.Quantity}}}})
+	} else {
+		err = r.changeLocationStock(ctx, transfer.ProductID, transfer.ToLocationID, transfer.Quantity, transfer.CreatedAt)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+
+		// Mengembalikan stok yang sudah diambil dari lokasi asal
+		var revertErr error
+		if transfer.FromLocationID.IsZero() {
+			_, revertErr = collection.UpdateOne(ctx, bson.D{{Key: "_id", Value: objectId}}, bson.D{{Key: "$inc", Value: bson.D{{Key: "allocated", Value: -transfer.Quantity}}}})
+		} else {
+			revertErr = r.changeLocationStock(ctx, transfer.ProductID, transfer.FromLocationID, transfer.Quantity, transfer.CreatedAt)
+		}
+		if revertErr != nil {
+			slog.ErrorContext(ctx, "Error reverting location stock", slog.Any("err ", revertErr))
+		}
+		return nil, err
+	}
+
+	// Rincian stok per lokasi merupakan bagian dari produk sehingga versi produk ikut bertambah
+	if _, err := collection.UpdateOne(ctx, bson.D{{Key: "_id", Value: objectId}}, bson.D{{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}}}); err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+	}
+
+	// Mencatat perpindahan ke buku besar, total stok produk tidak berubah
+	for _, movement := range transferMovements(transfer, storeData.Stock) {
+		if _, err := r.insertMovement(ctx, movement); err != nil {
+			return nil, err
+		}
+	}
+
+	recorded := *transfer
+	return &recorded, nil
+}
+
+// changeLocationStock menambahkan quantity ke stok produk pada sebuah lokasi secara atomik.
+// Dokumen stok lokasi dibuat jika belum ada, dan ErrInsufficientStock dikembalikan jika stok lokasi akan menjadi negatif.
+func (r *storeRepository) changeLocationStock(ctx context.Context, productID, locationID product.ID, quantity, updatedAt int64) error {
+	filter := bson.D{{Key: "product_id", Value: productID}, {Key: "location_id", Value: locationID}}
+	update := bson.D{
+		{Key: "$inc", Value: bson.D{{Key: "quantity", Value: quantity}}},
+		{Key: "$set", Value: bson.D{{Key: "updated_at", Value: updatedAt}}},
+	}
+
+	// Penambahan stok membuat dokumen baru jika belum ada, pengurangan hanya berlaku jika stok mencukupi
+	opts := options.Update().SetUpsert(quantity > 0)
+	if quantity < 0 {
+		filter = append(filter, bson.E{Key: "quantity", Value: bson.D{{Key: "$gte", Value: -quantity}}})
+	}
+
+	result, err := r.locationStockCollection().UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return err
+	}
+	if quantity < 0 && result.MatchedCount == 0 {
+		return product.ErrInsufficientStock
+	}
+	return nil
+}
+
+// stockConflict menentukan alasan update stok produk tidak cocok dengan dokumen mana pun:
+// produk tidak ditemukan, versinya berbeda dengan expectedVersion (jika diisi), atau stok tidak mencukupi.
+func (r *storeRepository) stockConflict(ctx context.Context, objectId primitive.ObjectID, expectedVersion int64) error {
+	var stored product.Product
+	err := r.productCollection().FindOne(ctx, bson.D{{Key: "_id", Value: objectId}, notDeleted()},
+		options.FindOne().SetProjection(bson.D{{Key: "version", Value: 1}})).Decode(&stored)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return product.ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if expectedVersion != 0 && expectedVersion != stored.Version {
+		return product.ErrVersionConflict
+	}
+	return product.ErrInsufficientStock
+}
+
+// negate mengembalikan salinan dokumen $inc dengan setiap nilai dibalik tandanya.
+func negate(inc bson.D) bson.D {
+	negated := make(bson.D, 0, len(inc))
+	for _, e := range inc {
+		// Versi produk tidak dibalik sehingga tetap bertambah, pengembalian juga merupakan perubahan produk
+		if e.Key == "version" {
+			negated = append(negated, e)
+			continue
+		}
+		negated = append(negated, bson.E{Key: e.Key, Value: -e.Value.(int64)})
+	}
+	return negated
+}
+
+// transitionReservation mengubah status reservasi yang cocok dengan filter dan mengembalikan reservasi setelah diubah.
+// Jika tidak ada yang cocok, ErrReservationNotFound atau ErrReservationNotActive dikembalikan.
+func (r *storeRepository) transitionReservation(ctx context.Context, filter bson.D, status string, at int64) (*product.Reservation, error) {
+	collection := r.stockReservationCollection()
+
+	var reservation product.Reservation
+	err := collection.FindOneAndUpdate(
+		ctx,
+		filter,
+		bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: status}, {Key: "updated_at", Value: at}}}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&reservation)
+	if err == nil {
+		return &reservation, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, err
+	}
+
+	// Membedakan reservasi yang tidak ada dengan reservasi yang sudah tidak aktif
+	count, err := collection.CountDocuments(ctx, filter[:1])
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, product.ErrReservationNotFound
+	}
+	return nil, product.ErrReservationNotActive
+}
+
+// objectID menerjemahkan ID domain menjadi ObjectID MongoDB.
+func objectID(id product.ID) (primitive.ObjectID, error) {
+	objectId, err := primitive.ObjectIDFromHex(id.String())
+	if err != nil {
+		// Pesan error parser tidak diteruskan ke klien, cukup nilai ID yang ditolak
+		return primitive.NilObjectID, fmt.Errorf("%w: %q", product.ErrInvalidID, id.String())
+	}
+	return objectId, nil
+}
+
+// notDeleted mengembalikan kondisi filter untuk dokumen yang belum di-soft delete.
+// Dokumen lama yang belum memiliki field deleted_at juga dianggap belum dihapus.
+func notDeleted() bson.E {
+	return bson.E{Key: "deleted_at", Value: bson.D{{Key: "$in", Value: bson.A{0, nil}}}}
+}
+
+// BulkWrite berfungsi untuk menjalankan banyak operasi create, upsert, dan delete dalam satu BulkWrite tanpa urutan.
+// Produk yang akan di-upsert atau dihapus dibaca terlebih dahulu dalam satu query untuk menentukan ID dan hasil setiap operasi.
+func (r *storeRepository) BulkWrite(ctx context.Context, operations []product.BulkOperation) ([]*product.BulkResult, error) {
+	// Mulai tracing untuk fungsi BulkWrite
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:BulkWrite")
+	defer span.End()
+
+	results := make([]*product.BulkResult, len(operations))
+	for i, operation := range operations {
+		results[i] = &product.BulkResult{Index: i}
+		if operation.Product != nil {
+			results[i].ID, results[i].Code = operation.Product.ID, operation.Product.Code
+		}
+	}
+
+	existing, err := r.findBulkTargets(ctx, operations)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []mongo.WriteModel
+	var modelResults []*product.BulkResult // Hasil milik setiap model, sesuai index pada models
+	deletedAt := time.Now().UTC().Unix()
+	write := func(model mongo.WriteModel, result *product.BulkResult, status string) {
+		result.Status = status
+		models = append(models, model)
+		modelResults = append(modelResults, result)
+	}
+	insert := func(p *product.Product, result *product.BulkResult) {
+		stored := *p
+		stored.ID = product.ID(primitive.NewObjectID().Hex())
+		stored.Version = 1
+		result.ID = stored.ID
+		write(mongo.NewInsertOneModel().SetDocument(stored), result, product.BulkCreated)
+	}
+
+	for i, operation := range operations {
+		result, p := results[i], operation.Product
+		if p == nil {
+			result.Fail(product.ErrInvalidBulk)
+			continue
+		}
+
+		switch operation.Action {
+		case product.BulkCreate:
+			insert(p, result)
+		case product.BulkUpsert:
+			if p.Code == "" {
+				result.Fail(product.ErrInvalidBulk)
+				continue
+			}
+			update, err := upsertUpdate(p)
+			if err != nil {
+				return nil, err
+			}
+
+			// Produk yang belum dihapus dengan kode yang sama diperbarui, selain itu dokumen baru disisipkan. Status
+			// updated sementara, hasil sebenarnya ditentukan dari hasil BulkWrite oleh resolveBulkWrite.
+			result.ID = existing.byCode[p.Code]
+			write(mongo.NewUpdateOneModel().
+				SetFilter(bson.D{{Key: "code", Value: p.Code}, notDeleted()}).
+				SetUpdate(update).
+				SetUpsert(true), result, product.BulkUpdated)
+		case product.BulkDelete:
+			target, err := existing.deleteTarget(p)
+			if err != nil {
+				result.Fail(err)
+				continue
+			}
+			result.ID = target
+			objectId, _ := objectID(target)
+			write(mongo.NewUpdateOneModel().SetFilter(bson.D{{Key: "_id", Value: objectId}, notDeleted()}).SetUpdate(bson.D{
+				{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: deletedAt}}},
+				{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}},
+			}), result, product.BulkDeleted)
+		default:
+			result.Fail(product.ErrInvalidBulk)
+		}
+	}
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	// Operasi tanpa urutan tetap dijalankan meskipun operasi lain gagal, kegagalannya dilaporkan per index model
+	res, err := r.productCollection().BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, writeErr := range bulkErr.WriteErrors {
+			result := modelResults[writeErr.Index]
+			if mongo.IsDuplicateKeyError(writeErr) {
+				// Upsert yang tidak menemukan produk aktif menyisipkan dokumen baru, sehingga kode milik produk
+				// yang sudah di-soft delete juga ditolak oleh index unik
+				result.Fail(product.ErrCodeConflict)
+				continue
+			}
+			slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", writeErr))
+			result.Fail(errors.New("error writing to repository"))
+		}
+	} else if err != nil {
+		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+		return nil, err
+	}
+	if res == nil {
+		return results, nil
+	}
+
+	if err := r.resolveBulkWrite(ctx, res, modelResults, deletedAt); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// upsertUpdate membuat dokumen update untuk upsert produk p berdasarkan kode. Field yang diisi ditulis dengan $set,
+// sedangkan field lain (termasuk created_at) hanya diisi dengan $setOnInsert ketika dokumen baru disisipkan, sehingga
+// produk yang sudah ada tetap mempertahankan waktu pembuatannya.
+func upsertUpdate(p *product.Product) (bson.D, error) {
+	changes := *p
+	changes.CreatedAt = 0
+	set := updatedFields(&changes)
+
+	stored := *p
+	stored.ID, stored.Version, stored.DeletedAt = "", 0, 0
+	raw, err := bson.Marshal(stored)
+	if err != nil {
+		return nil, err
+	}
+	var document bson.D
+	if err := bson.Unmarshal(raw, &document); err != nil {
+		return nil, err
+	}
+
+	// Field yang sama tidak boleh muncul pada $set dan $setOnInsert, version diisi oleh $inc
+	written := map[string]bool{"_id": true, "version": true}
+	for _, field := range set {
+		written[field.Key] = true
+	}
+	setOnInsert := bson.D{}
+	for _, field := range document {
+		if !written[field.Key] {
+			setOnInsert = append(setOnInsert, field)
+		}
+	}
+
+	update := bson.D{
+		{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}},
+		{Key: "$setOnInsert", Value: setOnInsert},
+	}
+	if len(set) > 0 {
+		update = append(update, bson.E{Key: "$set", Value: set})
+	}
+	return update, nil
+}
+
+// resolveBulkWrite menentukan hasil upsert dan delete dari hasil BulkWrite, karena produk dapat dibuat atau dihapus
+// oleh request lain setelah findBulkTargets membacanya. Upsert yang tercantum pada UpsertedIDs menyisipkan produk
+// baru (created), sedangkan upsert lain yang berhasil cocok dengan produk aktif (updated). Jika MatchedCount lebih
+// kecil dari jumlah upsert yang cocok ditambah delete, sebagian produk sudah dihapus lebih dahulu dan delete-nya
+// dilaporkan sebagai ErrProductNotFound.
+func (r *storeRepository) resolveBulkWrite(ctx context.Context, res *mongo.BulkWriteResult, modelResults []*product.BulkResult, deletedAt int64) error {
+	var matched int64
+	var unknownCodes bson.A                     // Kode upsert yang cocok dengan produk yang belum terbaca sebelumnya
+	var deletedIDs bson.A                       // ID produk yang ditandai dihapus oleh BulkWrite ini
+	var deletes []*product.BulkResult           // Hasil delete yang berhasil menurut BulkWrite
+	upserts := map[string]*product.BulkResult{} // Hasil upsert yang cocok berdasarkan kode
+
+	for i, result := range modelResults {
+		switch result.Status {
+		case product.BulkUpdated:
+			if upsertedID, ok := res.UpsertedIDs[int64(i)]; ok {
+				result.Status = product.BulkCreated
+				if objectId, ok := upsertedID.(primitive.ObjectID); ok {
+					result.ID = product.ID(objectId.Hex())
+				}
+				continue
+			}
+			matched++
+			if result.ID.IsZero() {
+				unknownCodes = append(unknownCodes, result.Code)
+				upserts[result.Code] = result
+			}
+		case product.BulkDeleted:
+			matched++
+			objectId, _ := objectID(result.ID)
+			deletedIDs = append(deletedIDs, objectId)
+			deletes = append(deletes, result)
+		}
+	}
+
+	if len(unknownCodes) > 0 {
+		cursor, err := r.productCollection().Find(ctx,
+			bson.D{notDeleted(), {Key: "code", Value: bson.D{{Key: "$in", Value: unknownCodes}}}},
+			options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}, {Key: "code", Value: 1}}),
+		)
+		if err != nil {
+			slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+			return err
+		}
+		var found []product.Product
+		if err := cursor.All(ctx, &found); err != nil {
+			return err
+		}
+		for _, p := range found {
+			if result, ok := upserts[p.Code]; ok {
+				result.ID = p.ID
+			}
+		}
+	}
+
+	if res.MatchedCount >= matched || len(deletes) == 0 {
+		return nil
+	}
+	cursor, err := r.productCollection().Find(ctx,
+		bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: deletedIDs}}}, {Key: "deleted_at", Value: deletedAt}},
+		options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return err
+	}
+	var found []product.Product
+	if err := cursor.All(ctx, &found); err != nil {
+		return err
+	}
+	deleted := map[product.ID]bool{}
+	for _, p := range found {
+		deleted[p.ID] = true
+	}
+	for _, result := range deletes {
+		if !deleted[result.ID] {
+			result.Fail(product.ErrProductNotFound)
+		}
+	}
+	return nil
+}
+
+// bulkTargets berisi ID produk yang belum dihapus berdasarkan kode dan ID, dibaca sebelum BulkWrite.
+type bulkTargets struct {
+	byCode map[string]product.ID
+	byID   map[product.ID]bool
+}
+
+// deleteTarget mengembalikan ID produk yang akan dihapus berdasarkan ID atau kode p.
+func (t bulkTargets) deleteTarget(p *product.Product) (product.ID, error) {
+	if !p.ID.IsZero() {
+		if _, err := objectID(p.ID); err != nil {
+			return "", err
+		}
+		if !t.byID[p.ID] {
+			return "", product.ErrProductNotFound
+		}
+		return p.ID, nil
+	}
+	if p.Code == "" {
+		return "", product.ErrInvalidBulk
+	}
+	id, ok := t.byCode[p.Code]
+	if !ok {
+		return "", product.ErrProductNotFound
+	}
+	return id, nil
+}
+
+// findBulkTargets membaca produk yang belum dihapus dengan kode upsert atau ID dan kode delete pada operations.
+func (r *storeRepository) findBulkTargets(ctx context.Context, operations []product.BulkOperation) (bulkTargets, error) {
+	targets := bulkTargets{byCode: map[string]product.ID{}, byID: map[product.ID]bool{}}
+
+	codes, ids := bson.A{}, bson.A{}
+	for _, operation := range operations {
+		p := operation.Product
+		switch {
+		case p == nil:
+		case operation.Action == product.BulkUpsert && p.Code != "":
+			codes = append(codes, p.Code)
+		case operation.Action == product.BulkDelete && !p.ID.IsZero():
+			if objectId, err := objectID(p.ID); err == nil {
+				ids = append(ids, objectId)
+			}
+		case operation.Action == product.BulkDelete && p.Code != "":
+			codes = append(codes, p.Code)
+		}
+	}
+
+	var or bson.A
+	if len(codes) > 0 {
+		or = append(or, bson.D{{Key: "code", Value: bson.D{{Key: "$in", Value: codes}}}})
+	}
+	if len(ids) > 0 {
+		or = append(or, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+	}
+	if len(or) == 0 {
+		return targets, nil
+	}
+
+	cursor, err := r.productCollection().Find(ctx,
+		bson.D{notDeleted(), {Key: "$or", Value: or}},
+		options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}, {Key: "code", Value: 1}}),
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return targets, err
+	}
+	var found []product.Product
+	if err := cursor.All(ctx, &found); err != nil {
+		return targets, err
+	}
+	for _, p := range found {
+		targets.byID[p.ID] = true
+		if p.Code != "" {
+			targets.byCode[p.Code] = p.ID
+		}
+	}
+	return targets, nil
+}
+
+// ClaimIdempotencyKey berfungsi untuk menyimpan klaim Idempotency-Key baru, atau mengembalikan record yang sudah ada.
+// Key disimpan sebagai _id sehingga dua klaim bersamaan dengan key yang sama ditolak oleh MongoDB.
+func (r *storeRepository) ClaimIdempotencyKey(ctx context.Context, record *product.IdempotencyRecord) (*product.IdempotencyRecord, error) {
+	// Mulai tracing untuk fungsi ClaimIdempotencyKey
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:ClaimIdempotencyKey")
+	defer span.End()
+
+	collection := r.idempotencyKeyCollection()
+
+	// Record yang sudah kedaluwarsa dihapus sehingga key-nya dapat diklaim kembali
+	_, err := collection.DeleteMany(ctx, bson.D{{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: record.CreatedAt}}}})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error deleting expired idempotency keys", slog.Any("err ", err))
+		return nil, err
+	}
+
+	claim := *record
+	claim.Status = 0
+	claim.Header = nil
+	claim.Body = nil
+
+	// Record yang sedang diperiksa dapat dilepas di antara InsertOne dan FindOne, sehingga klaim dicoba sekali lagi
+	for attempt := 0; attempt < 2; attempt++ {
+		_, err = collection.InsertOne(ctx, claim)
+		if err == nil {
+			return nil, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+			return nil, err
+		}
+
+		var existing product.IdempotencyRecord
+		err = collection.FindOne(ctx, bson.D{{Key: "_id", Value: record.Key}}).Decode(&existing)
+		if err == nil {
+			return &existing, nil
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+			return nil, err
+		}
+	}
+	return nil, product.ErrIdempotencyKeyInProgress
+}
+
+// CompleteIdempotencyKey berfungsi untuk menyimpan response pada record Idempotency-Key yang masih diproses.
+func (r *storeRepository) CompleteIdempotencyKey(ctx context.Context, record *product.IdempotencyRecord) error {
+	// Mulai tracing untuk fungsi CompleteIdempotencyKey
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:CompleteIdempotencyKey")
+	defer span.End()
+
+	_, err := r.idempotencyKeyCollection().UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: record.Key}, {Key: "status", Value: 0}},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "status", Value: record.Status},
+			{Key: "header", Value: record.Header},
+			{Key: "body", Value: record.Body},
+			{Key: "expires_at", Value: record.ExpiresAt},
+		}}},
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+	}
+	return err
+}
+
+// ReleaseIdempotencyKey berfungsi untuk menghapus record Idempotency-Key yang masih diproses.
+func (r *storeRepository) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	// Mulai tracing untuk fungsi ReleaseIdempotencyKey
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:ReleaseIdempotencyKey")
+	defer span.End()
+
+	_, err := r.idempotencyKeyCollection().DeleteOne(ctx, bson.D{{Key: "_id", Value: key}, {Key: "status", Value: 0}})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error deleting from repository", slog.Any("err ", err))
+	}
+	return err
+}
+
+// StoreImportJob berfungsi untuk menyimpan job import baru dengan ObjectID sebagai ID.
+func (r *storeRepository) StoreImportJob(ctx context.Context, job *product.ImportJob) (*product.ImportJob, error) {
+	// Mulai tracing untuk fungsi StoreImportJob
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:StoreImportJob")
+	defer span.End()
+
+	stored := *job
+	stored.ID = product.ID(primitive.NewObjectID().Hex())
+	if _, err := r.importJobCollection().InsertOne(ctx, &stored); err != nil {
+		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// UpdateImportJob berfungsi untuk menyimpan status, progres, dan hasil job import.
+// Dokumen diganti seluruhnya kecuali created_at, sehingga baris yang ditolak selalu sesuai dengan progres terakhir.
+func (r *storeRepository) UpdateImportJob(ctx context.Context, job *product.ImportJob) error {
+	// Mulai tracing untuk fungsi UpdateImportJob
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:UpdateImportJob")
+	defer span.End()
+
+	// ID yang bukan ObjectID tidak mungkin dimiliki job mana pun
+	objectId, err := objectID(job.ID)
+	if err != nil {
+		return product.ErrJobNotFound
+	}
+
+	existing, err := r.FindImportJob(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	updated := *job
+	updated.ID, updated.CreatedAt = existing.ID, existing.CreatedAt
+
+	result, err := r.importJobCollection().ReplaceOne(ctx, bson.D{{Key: "_id", Value: objectId}}, &updated)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error writing to repository", slog.Any("err ", err))
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return product.ErrJobNotFound
+	}
+	return nil
 }
 
-// Delete berfungsi untuk menghapus produk (store) dari database berdasarkan kode tertentu.
-// Fungsi ini belum diimplementasikan.
-func (r *storeRepository) Delete(ctx context.Context, code string) error {
-	//TODO implement me
-	panic("implement me")
+// FindImportJob berfungsi untuk mencari job import berdasarkan ID.
+func (r *storeRepository) FindImportJob(ctx context.Context, id product.ID) (*product.ImportJob, error) {
+	// Mulai tracing untuk fungsi FindImportJob
+	ctx, span := infrastructure.Tracer().Start(ctx, "repository:store:FindImportJob")
+	defer span.End()
+
+	// ID yang bukan ObjectID tidak mungkin dimiliki job mana pun
+	objectId, err := objectID(id)
+	if err != nil {
+		return nil, product.ErrJobNotFound
+	}
+
+	var job product.ImportJob
+	err = r.importJobCollection().FindOne(ctx, bson.D{{Key: "_id", Value: objectId}}).Decode(&job)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, product.ErrJobNotFound
+		}
+		slog.ErrorContext(ctx, "Collection Error", slog.Any("err ", err))
+		return nil, err
+	}
+	return &job, nil
 }
 
 /*
@@ -256,4 +2120,25 @@ Fungsi NewstoreRepository:
 
 Fungsi ini adalah constructor untuk membuat instance baru dari storeRepository. Parameter yang diterima adalah client (
 
+Pencarian Teks:
+
+Search menggunakan index teks product_text pada product_name (bobot 2) dan description (bobot 1) dengan operator $text, lalu mengurutkan hasil berdasarkan textScore. Kata kunci yang dikirim ke $text hanya berisi kata hasil SearchQuery.Terms, sedangkan keyword pada FindAll di-escape dengan regexp.QuoteMeta sebelum digunakan sebagai $regex.
+
+Idempotency-Key:
+
+Record Idempotency-Key disimpan di koleksi idempotency_keys dengan key sebagai _id, sehingga InsertOne yang kedua untuk key yang sama gagal dengan duplicate key error dan record yang sudah ada dibaca kembali. Record yang kedaluwarsa dihapus dengan DeleteMany sebelum setiap klaim menggunakan index expires_at, dan CompleteIdempotencyKey serta ReleaseIdempotencyKey hanya mengubah record yang masih berstatus 0 (sedang diproses).
+
+Export:
+
+Export menjalankan aggregation dengan filter, urutan, dan projection yang sama seperti FindAll (lihat productFilter), tetapi tanpa skip, limit, dan $facet. Dokumen dibaca dari cursor satu per satu dan langsung diteruskan ke pemanggil, sehingga katalog yang besar dapat diekspor tanpa menampung seluruh hasil di memori.
+
+Job Import:
+
+Job import disimpan di koleksi import_jobs dengan ObjectID sebagai _id. Header file, perubahan pada dry-run, dan baris yang ditolak disimpan di dalam dokumen job, sehingga jumlah baris pada satu file dibatasi oleh product.MaxImportRows agar dokumen tidak melebihi batas ukuran dokumen MongoDB.
+
+BulkWrite:
+
+BulkWrite mengirim seluruh operasi dalam satu perintah BulkWrite MongoDB dengan SetOrdered(false). Sebelumnya produk yang akan di-upsert (berdasarkan kode) atau dihapus dibaca dalam satu query, sehingga delete terhadap produk yang tidak ada langsung gagal dengan ErrProductNotFound. Create menjadi InsertOne dengan ObjectID yang dibuat lebih dahulu, sedangkan upsert menjadi UpdateOne dengan upsert pada filter kode dan produk yang belum dihapus (lihat upsertUpdate). Kegagalan dari BulkWriteException dipetakan kembali ke operasinya melalui index model; duplicate key menjadi ErrCodeConflict, termasuk upsert terhadap kode milik produk yang sudah di-soft delete karena index unik kode juga berlaku untuk produk tersebut.
+
+Hasil pembacaan awal dapat berubah sebelum BulkWrite dijalankan, sehingga hasil akhir ditentukan dari hasil BulkWrite oleh resolveBulkWrite: upsert yang tercantum pada UpsertedIDs menghasilkan created, upsert lain yang berhasil menghasilkan updated, dan jika MatchedCount lebih kecil dari jumlah upsert yang cocok ditambah delete, delete yang produknya sudah dihapus lebih dahulu dilaporkan sebagai ErrProductNotFound.
 */
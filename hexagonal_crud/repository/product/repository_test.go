@@ -0,0 +1,40 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"CRUD_Hexagonal/repository/product/producttest"
+	"context"
+	"os"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TestStoreRepository menjalankan suite conformance terhadap MongoDB sungguhan.
+// Pengujian ini dilewati jika MONGO_TEST_DSN tidak diatur.
+func TestStoreRepository(t *testing.T) {
+	dsn := os.Getenv("MONGO_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MONGO_TEST_DSN is not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(dsn))
+	if err != nil {
+		t.Fatalf("connect to mongo: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Disconnect(ctx) })
+
+	producttest.RunRepositorySuite(t, func(t *testing.T) product.Repository {
+		// Setiap subtest menggunakan database terpisah yang dihapus setelah selesai
+		db := "producttest_" + primitive.NewObjectID().Hex()
+		t.Cleanup(func() { _ = client.Database(db).Drop(ctx) })
+
+		if err := EnsureIndexes(ctx, client, db, "products"); err != nil {
+			t.Fatalf("ensure indexes: %v", err)
+		}
+		return NewstoreRepository(client, db, "products")
+	})
+}
@@ -0,0 +1,226 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// updatableField menentukan apakah sebuah field Product boleh diubah melalui Update.
+// ID, DeletedAt, dan Version dikelola oleh repository, sedangkan field dengan tag bson "-" tidak disimpan.
+func updatableField(field reflect.StructField) bool {
+	return field.Name != "ID" && field.Name != "DeletedAt" && field.Name != "Version" && field.Tag.Get("bson") != "-"
+}
+
+// patchField adalah satu perubahan pada Product beserta field struct tujuannya.
+type patchField struct {
+	field reflect.StructField // Field Product yang diubah
+	index int                 // Index field pada struct Product
+	value reflect.Value       // Nilai baru yang sudah sesuai dengan tipe field
+}
+
+// patchFields mencocokkan setiap kunci changes (nama JSON) dengan field Product yang boleh diperbarui,
+// urut berdasarkan nama. Nilai nil menjadi nilai nol dari tipe field, sedangkan field yang tidak dikenal
+// atau nilai dengan tipe yang tidak sesuai menghasilkan product.ErrInvalidPatch.
+func patchFields(changes product.Changes) ([]patchField, error) {
+	types := reflect.TypeOf(product.Product{})
+	fields := make([]patchField, 0, len(changes))
+	for _, name := range changes.Fields() {
+		index := -1
+		for i := 0; i < types.NumField(); i++ {
+			if jsonName(types.Field(i)) == name && updatableField(types.Field(i)) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("%w: field %q cannot be patched", product.ErrInvalidPatch, name)
+		}
+
+		field := types.Field(index)
+		value := reflect.Zero(field.Type)
+		if changes[name] != nil {
+			value = reflect.ValueOf(changes[name])
+			if !value.Type().AssignableTo(field.Type) {
+				return nil, fmt.Errorf("%w: field %q must be %s", product.ErrInvalidPatch, name, field.Type)
+			}
+		}
+		fields = append(fields, patchField{field: field, index: index, value: value})
+	}
+	return fields, nil
+}
+
+// jsonName mengembalikan nama field dari tag json sebuah field.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
+
+// columnName mengembalikan nama kolom (PostgreSQL) atau field dokumen (MongoDB) dari tag bson sebuah field.
+func columnName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("bson"), ",")
+	return name
+}
+
+// transferMovements membuat dua catatan buku besar untuk sebuah perpindahan stok: pengurangan pada
+// lokasi asal dan penambahan pada lokasi tujuan. Total stok produk (stockAfter) tidak berubah.
+func transferMovements(transfer *product.StockTransfer, stockAfter int64) []*product.StockMovement {
+	return []*product.StockMovement{
+		{
+			ProductID:  transfer.ProductID,
+			LocationID: transfer.FromLocationID,
+			Quantity:   -transfer.Quantity,
+			Reason:     product.ReasonTransfer,
+			Note:       transfer.Note,
+			StockAfter: stockAfter,
+			CreatedAt:  transfer.CreatedAt,
+		},
+		{
+			ProductID:  transfer.ProductID,
+			LocationID: transfer.ToLocationID,
+			Quantity:   transfer.Quantity,
+			Reason:     product.ReasonTransfer,
+			Note:       transfer.Note,
+			StockAfter: stockAfter,
+			CreatedAt:  transfer.CreatedAt,
+		},
+	}
+}
+
+// exportPageSize adalah jumlah produk yang dibaca per halaman oleh exportPages.
+const exportPageSize = 500
+
+// exportPages membaca semua produk yang sesuai filter halaman demi halaman dengan FindAll milik repo, untuk repository
+// yang tidak memiliki cursor database bawaan. Hanya satu halaman yang ditampung di memori pada satu waktu.
+func exportPages(ctx context.Context, repo product.Repository, filter product.Filter, fn func(*product.Product) error) error {
+	filter.UseCursor, filter.Cursor, filter.Limit = false, "", exportPageSize
+	for filter.Page = 1; ; filter.Page++ {
+		products, _, err := repo.FindAll(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, p := range products {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		if len(products) < exportPageSize {
+			return nil
+		}
+	}
+}
+
+// bulkWriteEach menjalankan operasi bulk satu per satu dengan method repo, untuk repository yang tidak memiliki
+// operasi batch bawaan. Error domain hanya menggagalkan operasi tersebut, sedangkan error lain (misalnya koneksi
+// database terputus) menghentikan batch dan dikembalikan langsung; operasi sebelumnya tetap tersimpan.
+func bulkWriteEach(ctx context.Context, repo product.Repository, operations []product.BulkOperation) ([]*product.BulkResult, error) {
+	results := make([]*product.BulkResult, len(operations))
+	for i, operation := range operations {
+		result := &product.BulkResult{Index: i}
+		results[i] = result
+		if operation.Product == nil {
+			result.Fail(product.ErrInvalidBulk)
+			continue
+		}
+		result.ID, result.Code = operation.Product.ID, operation.Product.Code
+
+		var err error
+		switch operation.Action {
+		case product.BulkCreate:
+			err = bulkStore(ctx, repo, operation.Product, result)
+		case product.BulkUpsert:
+			err = bulkUpsert(ctx, repo, operation.Product, result)
+		case product.BulkDelete:
+			err = bulkDelete(ctx, repo, operation.Product, result)
+		default:
+			err = product.ErrInvalidBulk
+		}
+
+		var domainErr *product.Error
+		if err != nil && !errors.As(err, &domainErr) {
+			return nil, err
+		}
+		if err != nil {
+			result.Fail(err)
+		}
+	}
+	return results, nil
+}
+
+// bulkStore menyimpan salinan p sebagai produk baru.
+func bulkStore(ctx context.Context, repo product.Repository, p *product.Product, result *product.BulkResult) error {
+	stored := *p
+	stored.ID = ""
+	id, err := repo.Store(ctx, &stored)
+	if err != nil {
+		return err
+	}
+	result.ID, result.Status = id, product.BulkCreated
+	return nil
+}
+
+// bulkUpsert memperbarui produk dengan kode p.Code jika ada, selain itu menyimpan p sebagai produk baru.
+func bulkUpsert(ctx context.Context, repo product.Repository, p *product.Product, result *product.BulkResult) error {
+	if p.Code == "" {
+		return product.ErrInvalidBulk
+	}
+	existing, err := repo.FindByCode(ctx, p.Code)
+	if errors.Is(err, product.ErrProductNotFound) {
+		return bulkStore(ctx, repo, p, result)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Produk yang sudah ada tetap mempertahankan waktu pembuatannya
+	updated := *p
+	updated.ID, updated.Version, updated.CreatedAt = existing.ID, 0, 0
+	if err := repo.Update(ctx, &updated); err != nil {
+		return err
+	}
+	result.ID, result.Status = existing.ID, product.BulkUpdated
+	return nil
+}
+
+// bulkDelete menandai produk dengan ID p.ID, atau kode p.Code jika ID kosong, sebagai dihapus.
+func bulkDelete(ctx context.Context, repo product.Repository, p *product.Product, result *product.BulkResult) error {
+	id := p.ID
+	if id.IsZero() {
+		if p.Code == "" {
+			return product.ErrInvalidBulk
+		}
+		existing, err := repo.FindByCode(ctx, p.Code)
+		if err != nil {
+			return err
+		}
+		id = existing.ID
+	}
+	if err := repo.DeleteById(ctx, id, 0); err != nil {
+		return err
+	}
+	result.ID, result.Status = id, product.BulkDeleted
+	return nil
+}
+
+/*
+Penjelasan Fungsi dan Komentar dalam Kode:
+File ini berisi fungsi bantu yang dipakai bersama oleh repository MongoDB, PostgreSQL, dan in-memory.
+Fungsi updatableField:
+
+Update pada setiap repository hanya mengubah field yang tidak kosong. Fungsi ini memastikan field yang dikelola repository (ID, DeletedAt, dan Version) serta field hasil perhitungan yang tidak disimpan (tag bson "-", seperti Available dan Locations) tidak pernah ikut diperbarui.
+Fungsi patchFields:
+
+Patch pada setiap repository menerapkan product.Changes secara eksplisit. Fungsi ini mencocokkan nama JSON pada Changes dengan field Product, menolak field yang dikelola repository, dan mengubah nil menjadi nilai nol sehingga field dapat dikosongkan. Nama penyimpanan (kolom PostgreSQL atau field dokumen MongoDB) selalu diambil dari tag bson melalui columnName, bukan dari tag json.
+Fungsi bulkWriteEach:
+
+Repository PostgreSQL dan in-memory menjalankan BulkWrite dengan memanggil Store, FindByCode, Update, dan DeleteById untuk setiap operasi, sehingga aturan keunikan kode, soft delete, dan versi sama persis dengan operasi tunggal. Repository MongoDB memiliki implementasi sendiri yang mengirim seluruh operasi dalam satu BulkWrite.
+Fungsi exportPages:
+
+Repository PostgreSQL dan in-memory menjalankan Export dengan membaca FindAll per halaman berisi exportPageSize produk, sehingga filter, urutan, dan projection sama dengan GET /product. Repository MongoDB membaca langsung dari cursor aggregation.
+Fungsi transferMovements:
+
+Perpindahan stok antar lokasi dicatat sebagai dua pergerakan stok dengan kode alasan transfer sehingga riwayat stok setiap lokasi tetap dapat direkonsiliasi dari buku besar.
+*/
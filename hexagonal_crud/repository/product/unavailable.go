@@ -0,0 +1,219 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"CRUD_Hexagonal/utils"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// unavailableRepository membungkus repository database dan menerjemahkan error koneksi atau timeout
+// menjadi product.ErrUnavailable, sehingga setiap method tidak perlu memeriksa error driver satu per satu.
+type unavailableRepository struct {
+	repo        product.Repository
+	unavailable func(error) bool // Menentukan apakah error berasal dari database yang tidak dapat dihubungi
+}
+
+// withUnavailable membungkus repo dengan pemeriksaan error unavailable milik backend-nya.
+func withUnavailable(repo product.Repository, unavailable func(error) bool) product.Repository {
+	return &unavailableRepository{repo: repo, unavailable: unavailable}
+}
+
+// wrap membungkus err dengan product.Unavailable jika err berasal dari database yang tidak dapat dihubungi.
+func (r *unavailableRepository) wrap(err error) error {
+	if err == nil || errors.Is(err, product.ErrUnavailable) || !r.unavailable(err) {
+		return err
+	}
+	return product.Unavailable(err)
+}
+
+// mongoUnavailable mengenali error jaringan, timeout, dan pemilihan server pada driver MongoDB.
+func mongoUnavailable(err error) bool {
+	return mongo.IsNetworkError(err) || mongo.IsTimeout(err) || errors.Is(err, mongo.ErrClientDisconnected)
+}
+
+// postgresUnavailable mengenali error koneksi, timeout, dan kelas error PostgreSQL yang berarti server tidak dapat melayani.
+func postgresUnavailable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 08: connection exception, 53: insufficient resources, 57P: operator intervention (misalnya shutdown)
+		return strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "53") || strings.HasPrefix(pgErr.Code, "57P")
+	}
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connectErr) || errors.As(err, &netErr) ||
+		errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func (r *unavailableRepository) Find(ctx context.Context, id product.ID) (*product.Product, error) {
+	res, err := r.repo.Find(ctx, id)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) FindByCode(ctx context.Context, code string) (*product.Product, error) {
+	res, err := r.repo.FindByCode(ctx, code)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) Store(ctx context.Context, dataStore *product.Product) (product.ID, error) {
+	id, err := r.repo.Store(ctx, dataStore)
+	return id, r.wrap(err)
+}
+
+func (r *unavailableRepository) Update(ctx context.Context, dataStore *product.Product) error {
+	return r.wrap(r.repo.Update(ctx, dataStore))
+}
+
+func (r *unavailableRepository) Patch(ctx context.Context, id product.ID, changes product.Changes, version int64) error {
+	return r.wrap(r.repo.Patch(ctx, id, changes, version))
+}
+
+func (r *unavailableRepository) FindAll(ctx context.Context, filter product.Filter) ([]*product.Product, *utils.Pagination, error) {
+	res, pagination, err := r.repo.FindAll(ctx, filter)
+	return res, pagination, r.wrap(err)
+}
+
+func (r *unavailableRepository) Search(ctx context.Context, query product.SearchQuery) ([]*product.SearchHit, *utils.Pagination, error) {
+	res, pagination, err := r.repo.Search(ctx, query)
+	return res, pagination, r.wrap(err)
+}
+
+func (r *unavailableRepository) Delete(ctx context.Context, code string, version int64) error {
+	return r.wrap(r.repo.Delete(ctx, code, version))
+}
+
+func (r *unavailableRepository) DeleteById(ctx context.Context, id product.ID, version int64) error {
+	return r.wrap(r.repo.DeleteById(ctx, id, version))
+}
+
+func (r *unavailableRepository) Restore(ctx context.Context, id product.ID) error {
+	return r.wrap(r.repo.Restore(ctx, id))
+}
+
+func (r *unavailableRepository) Remove(ctx context.Context, id product.ID) error {
+	return r.wrap(r.repo.Remove(ctx, id))
+}
+
+func (r *unavailableRepository) Purge(ctx context.Context, deletedBefore int64) (int64, error) {
+	purged, err := r.repo.Purge(ctx, deletedBefore)
+	return purged, r.wrap(err)
+}
+
+func (r *unavailableRepository) AdjustStock(ctx context.Context, movement *product.StockMovement) (*product.StockMovement, error) {
+	res, err := r.repo.AdjustStock(ctx, movement)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) FindMovements(ctx context.Context, productID product.ID, filter product.MovementFilter) ([]*product.StockMovement, *utils.Pagination, error) {
+	res, pagination, err := r.repo.FindMovements(ctx, productID, filter)
+	return res, pagination, r.wrap(err)
+}
+
+func (r *unavailableRepository) Reserve(ctx context.Context, reservation *product.Reservation) (*product.Reservation, error) {
+	res, err := r.repo.Reserve(ctx, reservation)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) FindReservation(ctx context.Context, reservationID product.ID) (*product.Reservation, error) {
+	res, err := r.repo.FindReservation(ctx, reservationID)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) ConfirmReservation(ctx context.Context, reservationID product.ID, confirmedAt int64) (*product.Reservation, error) {
+	res, err := r.repo.ConfirmReservation(ctx, reservationID, confirmedAt)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) ReleaseReservation(ctx context.Context, reservationID product.ID, status string, releasedAt int64) (*product.Reservation, error) {
+	res, err := r.repo.ReleaseReservation(ctx, reservationID, status, releasedAt)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) FindExpiredReservations(ctx context.Context, before int64, limit int) ([]*product.Reservation, error) {
+	res, err := r.repo.FindExpiredReservations(ctx, before, limit)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) StoreLocation(ctx context.Context, location *product.Location) (product.ID, error) {
+	id, err := r.repo.StoreLocation(ctx, location)
+	return id, r.wrap(err)
+}
+
+func (r *unavailableRepository) FindLocation(ctx context.Context, locationID product.ID) (*product.Location, error) {
+	res, err := r.repo.FindLocation(ctx, locationID)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) FindLocations(ctx context.Context) ([]*product.Location, error) {
+	res, err := r.repo.FindLocations(ctx)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) FindLocationStocks(ctx context.Context, filter product.LocationStockFilter) ([]*product.LocationStock, error) {
+	res, err := r.repo.FindLocationStocks(ctx, filter)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) TransferStock(ctx context.Context, transfer *product.StockTransfer) (*product.StockTransfer, error) {
+	res, err := r.repo.TransferStock(ctx, transfer)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) Export(ctx context.Context, filter product.Filter, fn func(*product.Product) error) error {
+	return r.wrap(r.repo.Export(ctx, filter, fn))
+}
+
+func (r *unavailableRepository) BulkWrite(ctx context.Context, operations []product.BulkOperation) ([]*product.BulkResult, error) {
+	res, err := r.repo.BulkWrite(ctx, operations)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) ClaimIdempotencyKey(ctx context.Context, record *product.IdempotencyRecord) (*product.IdempotencyRecord, error) {
+	res, err := r.repo.ClaimIdempotencyKey(ctx, record)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) CompleteIdempotencyKey(ctx context.Context, record *product.IdempotencyRecord) error {
+	return r.wrap(r.repo.CompleteIdempotencyKey(ctx, record))
+}
+
+func (r *unavailableRepository) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	return r.wrap(r.repo.ReleaseIdempotencyKey(ctx, key))
+}
+
+func (r *unavailableRepository) StoreImportJob(ctx context.Context, job *product.ImportJob) (*product.ImportJob, error) {
+	res, err := r.repo.StoreImportJob(ctx, job)
+	return res, r.wrap(err)
+}
+
+func (r *unavailableRepository) UpdateImportJob(ctx context.Context, job *product.ImportJob) error {
+	return r.wrap(r.repo.UpdateImportJob(ctx, job))
+}
+
+func (r *unavailableRepository) FindImportJob(ctx context.Context, id product.ID) (*product.ImportJob, error) {
+	res, err := r.repo.FindImportJob(ctx, id)
+	return res, r.wrap(err)
+}
+
+/*
+Penjelasan Kode:
+
+unavailableRepository:
+Repository MongoDB dan PostgreSQL mengembalikan error driver apa adanya ketika database tidak dapat dihubungi. Alih-alih
+memeriksa error tersebut di setiap method, NewstoreRepository dan NewPostgresRepository membungkus repository dengan
+unavailableRepository. Setiap method diteruskan ke repository asli, lalu error yang dikenali sebagai error koneksi atau
+timeout dibungkus dengan product.Unavailable sehingga lapisan API mengembalikan status 503 Service Unavailable.
+Error domain lain (ErrProductNotFound, ErrCodeConflict, dan seterusnya) diteruskan tanpa perubahan.
+
+mongoUnavailable dan postgresUnavailable:
+Fungsi ini berisi pengetahuan khusus driver: label NetworkError dan error timeout pada MongoDB, serta error koneksi pgconn,
+driver.ErrBadConn, dan kode SQLSTATE kelas 08, 53, dan 57P pada PostgreSQL. Repository in-memory tidak dibungkus karena
+tidak memiliki koneksi yang dapat terputus.
+*/
@@ -5,46 +5,140 @@ import (
 	"CRUD_Hexagonal/infrastructure"
 	"CRUD_Hexagonal/utils"
 	"context"
+	"errors"
+	"strings"
 	"time"
+
+	"golang.org/x/exp/slog"
 )
 
 // adapter adalah struct yang mengimplementasikan interface ProductInterface
 // untuk berinteraksi dengan repository produk (store).
 type adapter struct {
-	storeRepo product.Repository
+	storeRepo      product.Repository
+	purgeRetention time.Duration // Lama produk yang sudah di-soft delete disimpan sebelum dapat di-purge
+	reservationTTL time.Duration // Lama default reservasi stok jika permintaan tidak menentukan TTL
+	idempotencyTTL time.Duration // Lama response request dengan Idempotency-Key disimpan untuk diputar ulang
 }
 
+// idempotencyLockTimeout adalah lama klaim Idempotency-Key yang belum selesai berlaku. Jika proses berhenti sebelum
+// response disimpan, key dapat digunakan kembali setelah waktu ini tanpa menunggu idempotencyTTL.
+const idempotencyLockTimeout = time.Minute
+
 // NewStoreService adalah constructor yang digunakan untuk membuat instance baru dari adapter
 // dan mengembalikannya sebagai implementasi ProductInterface.
-func NewStoreService(storeRepo product.Repository) product.ProductInterface {
-	return &adapter{storeRepo: storeRepo}
+func NewStoreService(storeRepo product.Repository, purgeRetention time.Duration, reservationTTL time.Duration, idempotencyTTL time.Duration) product.ProductInterface {
+	return &adapter{storeRepo: storeRepo, purgeRetention: purgeRetention, reservationTTL: reservationTTL, idempotencyTTL: idempotencyTTL}
 }
 
 // Find mencari produk (store) berdasarkan ID yang diberikan.
-func (a adapter) Find(ctx context.Context, id string) (*product.Product, error) {
+func (a adapter) Find(ctx context.Context, id product.ID) (*product.Product, error) {
 	// Memulai tracing untuk fungsi Find
 	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:Find")
 	defer span.End()
 
-	return a.storeRepo.Find(ctx, id)
+	res, err := a.storeRepo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	res.ComputeAvailable()
+
+	// Melengkapi produk dengan rincian stok per lokasi
+	if err := a.attachLocations(ctx, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// FindByCode mencari produk (store) berdasarkan kode produk (SKU) yang diberikan.
+func (a adapter) FindByCode(ctx context.Context, code string) (*product.Product, error) {
+	// Memulai tracing untuk fungsi FindByCode
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:FindByCode")
+	defer span.End()
+
+	res, err := a.storeRepo.FindByCode(ctx, strings.TrimSpace(code))
+	if err != nil {
+		return nil, err
+	}
+	res.ComputeAvailable()
+
+	// Melengkapi produk dengan rincian stok per lokasi
+	if err := a.attachLocations(ctx, res); err != nil {
+		return nil, err
+	}
+	return res, nil
 }
 
 // Store menyimpan produk (store) baru ke dalam repository dan mengatur waktu pembuatan.
-func (a adapter) Store(ctx context.Context, product *product.Product) (*product.Product, error) {
+// Stok awal dicatat sebagai pergerakan stok dengan kode alasan initial.
+func (a adapter) Store(ctx context.Context, store *product.Product) (*product.Product, error) {
 	// Memulai tracing untuk fungsi Store
 	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:Store")
 	defer span.End()
 
-	// Mengatur waktu pembuatan produk
-	product.CreatedAt = time.Now().UTC().Unix()
+	// Stok awal tidak boleh negatif
+	if store.Stock < 0 {
+		return nil, product.ErrInvalidStockAdjustment
+	}
+
+	// Koordinat produk bersifat opsional, tetapi harus valid jika diisi
+	if store.Geo != nil {
+		if err := store.Geo.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	store.Distance = nil
+
+	// Field yang dikelola server (ID, versi, soft delete, serta stok yang ditahan dan dialokasikan) tidak boleh
+	// diisi klien, sehingga nilainya dari request diabaikan seperti pada BulkStore
+	store.ID, store.Version, store.DeletedAt = "", 0, 0
+	store.Reserved, store.Available, store.Allocated, store.Locations = 0, 0, 0, nil
+
+	// Mengatur waktu pembuatan produk dan merapikan kode produk
+	store.CreatedAt = time.Now().UTC().Unix()
+	store.Code = strings.TrimSpace(store.Code)
+
+	// Produk disimpan dengan stok 0, stok awal ditambahkan melalui buku besar stok
+	initialStock := store.Stock
+	store.Stock = 0
 
 	// Menyimpan produk ke dalam repository
-	insertID, err := a.storeRepo.Store(ctx, product)
+	insertID, err := a.storeRepo.Store(ctx, store)
 
 	// Mengatur ID produk dengan ID yang baru disisipkan
-	product.ID = insertID
+	store.ID = insertID
+
+	if err != nil {
+		return store, err
+	}
+	if initialStock == 0 {
+		store.ComputeAvailable()
+		return store, nil
+	}
+
+	movement, err := a.storeRepo.AdjustStock(ctx, &product.StockMovement{
+		ProductID: insertID,
+		Quantity:  initialStock,
+		Reason:    product.ReasonInitial,
+		CreatedAt: store.CreatedAt,
+	})
+	if err != nil {
+		// Produk dan stok awalnya disimpan dalam dua langkah, sehingga produk dihapus kembali agar klien yang
+		// menerima error tidak meninggalkan produk tanpa catatan stok awal. Penghapusan tetap dijalankan
+		// walaupun request sudah dibatalkan.
+		if errRemove := a.storeRepo.Remove(context.WithoutCancel(ctx), insertID); errRemove != nil {
+			slog.ErrorContext(ctx, "Failed to remove product after opening stock failed service:product:Store",
+				slog.String("product_id", insertID.String()), slog.Any("err ", errRemove))
+		}
+		return nil, err
+	}
+	store.Stock = movement.StockAfter
+	store.ComputeAvailable()
 
-	return product, err
+	// Stok awal dicatat sebagai perubahan produk sehingga versinya ikut bertambah
+	store.Version++
+
+	return store, nil
 }
 
 // Update memperbarui data produk (store) yang ada di dalam repository
@@ -54,44 +148,722 @@ func (a adapter) Update(ctx context.Context, store *product.Product) error {
 	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:Update")
 	defer span.End()
 
-	// Mengatur waktu pembaruan produk
+	// Koordinat produk hanya diperbarui jika diisi dan harus valid
+	if store.Geo != nil {
+		if err := store.Geo.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Mengatur waktu pembaruan produk dan merapikan kode produk
 	store.UpdatedAt = time.Now().UTC().Unix()
+	store.Code = strings.TrimSpace(store.Code)
+
+	// Stok hanya dapat diubah melalui buku besar stok, sehingga stok yang berbeda dari stok saat ini ditolak.
+	// Stok 0 tidak dapat dibedakan dari field yang tidak dikirim dan dianggap tidak diubah.
+	if store.Stock != 0 {
+		current, err := a.storeRepo.Find(ctx, store.ID)
+		if err != nil {
+			return err
+		}
+		if current.Stock != store.Stock {
+			return product.ErrStockNotUpdatable
+		}
+	}
+
+	// Nilai 0 membuat repository melewati field stok
+	store.Stock = 0
+	store.Reserved = 0
+	store.Available = 0
+	store.Allocated = 0
 
 	return a.storeRepo.Update(ctx, store)
 }
 
+// Patch menerapkan dokumen JSON Merge Patch atau JSON Patch pada produk (store) dan mengembalikan produk hasilnya.
+func (a adapter) Patch(ctx context.Context, id product.ID, document product.PatchDocument, version int64) (*product.Product, error) {
+	// Memulai tracing untuk fungsi Patch
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:Patch")
+	defer span.End()
+
+	current, err := a.storeRepo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	current.ComputeAvailable()
+
+	// Versi yang diharapkan klien harus sama dengan versi produk yang dibaca
+	if version != 0 && version != current.Version {
+		return nil, product.ErrVersionConflict
+	}
+
+	// Dokumen patch diterapkan pada produk saat ini, hasilnya hanya field yang berubah
+	changes, err := document.Apply(current)
+	if err != nil {
+		return nil, err
+	}
+	if code, ok := changes["code"].(string); ok {
+		changes["code"] = strings.TrimSpace(code)
+	}
+
+	// Produk hasil patch harus memenuhi aturan validasi yang sama seperti Create, misalnya product_name wajib diisi
+	patched := *current
+	changes.ApplyTo(&patched)
+	if fieldErrors := utils.Validate(&patched); len(fieldErrors) > 0 {
+		return nil, fieldErrors
+	}
+
+	// Stok tidak ditulis langsung, selisihnya dicatat sebagai pergerakan stok correction setelah field lain.
+	// Stok baru tidak boleh lebih kecil dari stok yang ditahan reservasi atau yang sudah dialokasikan ke lokasi.
+	_, stockChanged := changes["stock"]
+	delete(changes, "stock")
+	if stockChanged && (patched.Stock < current.Reserved || patched.Stock < current.Allocated) {
+		return nil, product.ErrInsufficientStock
+	}
+
+	// Perubahan ditulis dengan versi yang dibaca sehingga perubahan lain di antara Find dan Patch ditolak sebagai konflik
+	expected := current.Version
+	if len(changes) > 0 {
+		changes["updated_at"] = time.Now().UTC().Unix()
+		if err := a.storeRepo.Patch(ctx, id, changes, expected); err != nil {
+			return nil, err
+		}
+		expected++
+	}
+
+	if stockChanged {
+		_, err := a.storeRepo.AdjustStock(ctx, &product.StockMovement{
+			ProductID:       id,
+			Quantity:        patched.Stock - current.Stock,
+			Reason:          product.ReasonCorrection,
+			CreatedAt:       time.Now().UTC().Unix(),
+			ExpectedVersion: expected,
+		})
+		if err != nil {
+			// Field lain sudah ditulis, sehingga dikembalikan ke nilai sebelumnya agar patch gagal seluruhnya.
+			// Pengembalian tetap dijalankan walaupun request sudah dibatalkan.
+			if len(changes) > 0 {
+				reverted := changes.Revert(current)
+				reverted["updated_at"] = current.UpdatedAt
+				if errRevert := a.storeRepo.Patch(context.WithoutCancel(ctx), id, reverted, expected); errRevert != nil {
+					slog.ErrorContext(ctx, "Failed to revert patch after stock correction failed service:product:Patch",
+						slog.String("product_id", id.String()), slog.Any("err ", errRevert))
+				}
+			}
+			return nil, err
+		}
+	}
+
+	return a.Find(ctx, id)
+}
+
 // FindAll mencari semua produk (store) dengan filter tertentu dan mendukung pagination.
 func (a adapter) FindAll(ctx context.Context, filter product.Filter) ([]*product.Product, *utils.Pagination, error) {
 	// Memulai tracing untuk fungsi FindAll
 	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:FindAll")
 	defer span.End()
 
+	// Cursor pagination dan pencarian berdasarkan jarak memiliki urutan sendiri sehingga tidak dapat digabungkan
+	if err := filter.ValidateOrder(); err != nil {
+		return nil, nil, err
+	}
+
 	// Mencari semua produk yang sesuai dengan filter dan mengembalikan hasil serta pagination
 	res, pagination, err := a.storeRepo.FindAll(ctx, filter)
+	if err != nil {
+		return res, pagination, err
+	}
+	for _, p := range res {
+		p.ComputeAvailable()
+	}
+
+	// Rincian stok per lokasi untuk seluruh halaman dibaca dengan satu query, kecuali tidak dipilih pada projection
+	if filter.Selects("locations") {
+		if err := a.attachLocations(ctx, res...); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return res, pagination, nil
+}
+
+// Export membaca semua produk yang sesuai dengan filter tanpa pagination dan memanggil fn untuk setiap produk.
+func (a adapter) Export(ctx context.Context, filter product.Filter, fn func(*product.Product) error) error {
+	// Memulai tracing untuk fungsi Export
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:Export")
+	defer span.End()
+
+	// Export selalu membaca seluruh hasil, sehingga pagination dari filter diabaikan
+	filter.Page, filter.Limit, filter.UseCursor, filter.Cursor = 0, 0, false, ""
+	if err := filter.ValidateOrder(); err != nil {
+		return err
+	}
 
-	return res, pagination, err
+	return a.storeRepo.Export(ctx, filter, func(p *product.Product) error {
+		p.ComputeAvailable()
+		return fn(p)
+	})
 }
 
-// Delete adalah fungsi yang belum diimplementasikan untuk menghapus produk (store)
-// berdasarkan kode tertentu.
-func (a adapter) Delete(ctx context.Context, code string) error {
-	//TODO implement me
-	panic("implement me")
+// Search mencari produk berdasarkan kata pada nama dan deskripsi, lalu menambahkan highlight pada setiap hasil.
+func (a adapter) Search(ctx context.Context, query product.SearchQuery) ([]*product.SearchHit, *utils.Pagination, error) {
+	// Memulai tracing untuk fungsi Search
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:Search")
+	defer span.End()
+
+	// Kata kunci harus berisi setidaknya satu kata dan tidak terlalu panjang
+	if err := query.Validate(); err != nil {
+		return nil, nil, err
+	}
+	terms := query.Terms()
+
+	hits, pagination, err := a.storeRepo.Search(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, hit := range hits {
+		hit.Product.ComputeAvailable()
+
+		// Hanya field yang memuat kata yang cocok yang disertakan pada highlight
+		hit.Highlights = map[string]string{}
+		if text, ok := product.Highlight(hit.Product.Name, terms); ok {
+			hit.Highlights["product_name"] = text
+		}
+		if text, ok := product.Highlight(hit.Product.Description, terms); ok {
+			hit.Highlights["description"] = text
+		}
+	}
+	return hits, pagination, nil
+}
+
+// Delete menandai produk (store) sebagai dihapus (soft delete) berdasarkan kode produk (SKU).
+func (a adapter) Delete(ctx context.Context, code string, version int64) error {
+	// Memulai tracing untuk fungsi Delete
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:Delete")
+	defer span.End()
+
+	return a.storeRepo.Delete(ctx, strings.TrimSpace(code), version)
 }
 
-// DeleteById menghapus produk (store) dari repository berdasarkan ID yang diberikan.
-func (a adapter) DeleteById(ctx context.Context, id string) error {
+// DeleteById menandai produk (store) sebagai dihapus (soft delete) berdasarkan ID yang diberikan.
+func (a adapter) DeleteById(ctx context.Context, id product.ID, version int64) error {
 	// Memulai tracing untuk fungsi DeleteById
 	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:DeleteByID")
 	defer span.End()
 
-	err := a.storeRepo.DeleteById(ctx, id)
+	err := a.storeRepo.DeleteById(ctx, id, version)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// Restore memulihkan produk (store) yang sudah di-soft delete lalu mengembalikan data produk tersebut.
+func (a adapter) Restore(ctx context.Context, id product.ID) (*product.Product, error) {
+	// Memulai tracing untuk fungsi Restore
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:Restore")
+	defer span.End()
+
+	if err := a.storeRepo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return a.Find(ctx, id)
+}
+
+// Purge menghapus permanen produk (store) yang sudah di-soft delete lebih lama dari masa retensi.
+func (a adapter) Purge(ctx context.Context) (int64, error) {
+	// Memulai tracing untuk fungsi Purge
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:Purge")
+	defer span.End()
+
+	// Batas waktu penghapusan, produk yang dihapus pada atau sebelum waktu ini akan di-purge
+	deletedBefore := time.Now().UTC().Add(-a.purgeRetention).Unix()
+
+	return a.storeRepo.Purge(ctx, deletedBefore)
+}
+
+// AdjustStock memvalidasi permintaan perubahan stok lalu mengubah stok produk dan mencatat pergerakannya.
+func (a adapter) AdjustStock(ctx context.Context, id product.ID, adjustment product.StockAdjustment) (*product.StockMovement, error) {
+	// Memulai tracing untuk fungsi AdjustStock
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:AdjustStock")
+	defer span.End()
+
+	// Perubahan stok harus memiliki jumlah dan kode alasan yang valid.
+	// Kode initial hanya digunakan saat produk dibuat.
+	// Kode transfer hanya digunakan oleh TransferStock.
+	reason := strings.TrimSpace(adjustment.Reason)
+	if adjustment.Quantity == 0 || reason == product.ReasonInitial || reason == product.ReasonTransfer || !product.ValidReason(reason) {
+		return nil, product.ErrInvalidStockAdjustment
+	}
+
+	// Perubahan pada sebuah lokasi hanya berlaku untuk lokasi yang terdaftar
+	movement := &product.StockMovement{
+		ProductID: id,
+		Quantity:  adjustment.Quantity,
+		Reason:    reason,
+		Note:      strings.TrimSpace(adjustment.Note),
+		CreatedAt: time.Now().UTC().Unix(),
+	}
+	if !adjustment.LocationID.IsZero() {
+		location, err := a.storeRepo.FindLocation(ctx, adjustment.LocationID)
+		if err != nil {
+			return nil, err
+		}
+		movement.LocationID = location.ID
+	}
+
+	return a.storeRepo.AdjustStock(ctx, movement)
+}
+
+// FindMovements mengembalikan riwayat pergerakan stok produk beserta pagination.
+func (a adapter) FindMovements(ctx context.Context, id product.ID, filter product.MovementFilter) ([]*product.StockMovement, *utils.Pagination, error) {
+	// Memulai tracing untuk fungsi FindMovements
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:FindMovements")
+	defer span.End()
+
+	// Memastikan produk ada sehingga produk yang tidak dikenal tidak dianggap memiliki riwayat kosong
+	if _, err := a.storeRepo.Find(ctx, id); err != nil {
+		return nil, nil, err
+	}
+
+	return a.storeRepo.FindMovements(ctx, id, filter)
+}
+
+// Reserve menahan stok produk selama TTL yang diminta atau TTL default.
+func (a adapter) Reserve(ctx context.Context, id product.ID, request product.ReservationRequest) (*product.Reservation, error) {
+	// Memulai tracing untuk fungsi Reserve
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:Reserve")
+	defer span.End()
+
+	// Jumlah reservasi harus lebih dari 0 dan TTL tidak boleh negatif
+	if request.Quantity <= 0 || request.TTLSeconds < 0 {
+		return nil, product.ErrInvalidReservation
+	}
+
+	ttl := a.reservationTTL
+	if request.TTLSeconds > 0 {
+		ttl = time.Duration(request.TTLSeconds) * time.Second
+	}
+
+	now := time.Now().UTC()
+	return a.storeRepo.Reserve(ctx, &product.Reservation{
+		ProductID: id,
+		Quantity:  request.Quantity,
+		Status:    product.ReservationActive,
+		ExpiresAt: now.Add(ttl).Unix(),
+		CreatedAt: now.Unix(),
+		UpdatedAt: now.Unix(),
+	})
+}
+
+// FindReservation mencari reservasi berdasarkan ID.
+func (a adapter) FindReservation(ctx context.Context, reservationID product.ID) (*product.Reservation, error) {
+	// Memulai tracing untuk fungsi FindReservation
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:FindReservation")
+	defer span.End()
+
+	return a.storeRepo.FindReservation(ctx, reservationID)
+}
+
+// ConfirmReservation mengonfirmasi reservasi aktif yang belum kedaluwarsa dan mengurangi stok produk.
+func (a adapter) ConfirmReservation(ctx context.Context, reservationID product.ID) (*product.Reservation, error) {
+	// Memulai tracing untuk fungsi ConfirmReservation
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:ConfirmReservation")
+	defer span.End()
+
+	return a.storeRepo.ConfirmReservation(ctx, reservationID, time.Now().UTC().Unix())
+}
+
+// ReleaseReservation membatalkan reservasi aktif dan melepas stok yang ditahan.
+func (a adapter) ReleaseReservation(ctx context.Context, reservationID product.ID) (*product.Reservation, error) {
+	// Memulai tracing untuk fungsi ReleaseReservation
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:ReleaseReservation")
+	defer span.End()
+
+	return a.storeRepo.ReleaseReservation(ctx, reservationID, product.ReservationReleased, time.Now().UTC().Unix())
+}
+
+// ReleaseExpiredReservations melepas semua reservasi aktif yang sudah kedaluwarsa secara bertahap.
+func (a adapter) ReleaseExpiredReservations(ctx context.Context) (int64, error) {
+	// Memulai tracing untuk fungsi ReleaseExpiredReservations
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:ReleaseExpiredReservations")
+	defer span.End()
+
+	const batchSize = 100
+	now := time.Now().UTC().Unix()
+
+	var released int64
+	for {
+		expired, err := a.storeRepo.FindExpiredReservations(ctx, now, batchSize)
+		if err != nil {
+			return released, err
+		}
+
+		for _, reservation := range expired {
+			_, err := a.storeRepo.ReleaseReservation(ctx, reservation.ID, product.ReservationExpired, now)
+			// Reservasi yang lebih dahulu dikonfirmasi atau dilepas pengguna tidak perlu disapu
+			if errors.Is(err, product.ErrReservationNotActive) {
+				continue
+			}
+			if err != nil {
+				return released, err
+			}
+			released++
+		}
+
+		if len(expired) < batchSize {
+			return released, nil
+		}
+	}
+}
+
+// CreateLocation memvalidasi lalu menyimpan lokasi baru (gudang atau toko).
+func (a adapter) CreateLocation(ctx context.Context, location *product.Location) (*product.Location, error) {
+	// Memulai tracing untuk fungsi CreateLocation
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:CreateLocation")
+	defer span.End()
+
+	// Kode dan nama lokasi wajib diisi, dan tipe lokasi harus dikenal
+	location.Code = strings.TrimSpace(location.Code)
+	location.Name = strings.TrimSpace(location.Name)
+	location.Type = strings.TrimSpace(location.Type)
+	if location.Code == "" || location.Name == "" || !product.ValidLocationType(location.Type) {
+		return nil, product.ErrInvalidLocation
+	}
+	location.CreatedAt = time.Now().UTC().Unix()
+
+	id, err := a.storeRepo.StoreLocation(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	location.ID = id
+
+	return location, nil
+}
+
+// FindLocation mencari lokasi berdasarkan ID yang diberikan.
+func (a adapter) FindLocation(ctx context.Context, locationID product.ID) (*product.Location, error) {
+	// Memulai tracing untuk fungsi FindLocation
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:FindLocation")
+	defer span.End()
+
+	return a.storeRepo.FindLocation(ctx, locationID)
+}
+
+// FindLocations mengembalikan semua lokasi yang terdaftar.
+func (a adapter) FindLocations(ctx context.Context) ([]*product.Location, error) {
+	// Memulai tracing untuk fungsi FindLocations
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:FindLocations")
+	defer span.End()
+
+	return a.storeRepo.FindLocations(ctx)
+}
+
+// FindStockByProduct mengembalikan stok sebuah produk pada setiap lokasi.
+func (a adapter) FindStockByProduct(ctx context.Context, id product.ID) ([]*product.LocationStock, error) {
+	// Memulai tracing untuk fungsi FindStockByProduct
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:FindStockByProduct")
+	defer span.End()
+
+	// Memastikan produk ada sehingga produk yang tidak dikenal tidak dianggap tidak memiliki stok
+	res, err := a.storeRepo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.storeRepo.FindLocationStocks(ctx, product.LocationStockFilter{ProductIDs: []product.ID{res.ID}})
+}
+
+// FindStockByLocation mengembalikan stok setiap produk pada sebuah lokasi.
+func (a adapter) FindStockByLocation(ctx context.Context, locationID product.ID) ([]*product.LocationStock, error) {
+	// Memulai tracing untuk fungsi FindStockByLocation
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:FindStockByLocation")
+	defer span.End()
+
+	// Memastikan lokasi ada sehingga lokasi yang tidak dikenal tidak dianggap kosong
+	location, err := a.storeRepo.FindLocation(ctx, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.storeRepo.FindLocationStocks(ctx, product.LocationStockFilter{LocationID: location.ID})
+}
+
+// TransferStock memvalidasi lalu memindahkan stok produk dari satu lokasi ke lokasi lain.
+// Lokasi asal atau tujuan yang kosong berarti stok yang belum dialokasikan ke lokasi mana pun.
+func (a adapter) TransferStock(ctx context.Context, id product.ID, transfer product.StockTransfer) (*product.StockTransfer, error) {
+	// Memulai tracing untuk fungsi TransferStock
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:TransferStock")
+	defer span.End()
+
+	// Jumlah harus lebih dari 0 dan lokasi asal harus berbeda dengan lokasi tujuan
+	if transfer.Quantity <= 0 || transfer.FromLocationID == transfer.ToLocationID {
+		return nil, product.ErrInvalidTransfer
+	}
+
+	// Lokasi asal dan tujuan harus terdaftar, ID yang disimpan mengikuti bentuk ID dari repository
+	for _, locationID := range []*product.ID{&transfer.FromLocationID, &transfer.ToLocationID} {
+		if locationID.IsZero() {
+			continue
+		}
+		location, err := a.storeRepo.FindLocation(ctx, *locationID)
+		if err != nil {
+			return nil, err
+		}
+		*locationID = location.ID
+	}
+
+	transfer.ProductID = id
+	transfer.Note = strings.TrimSpace(transfer.Note)
+	transfer.CreatedAt = time.Now().UTC().Unix()
+
+	return a.storeRepo.TransferStock(ctx, &transfer)
+}
+
+// attachLocations mengisi rincian stok per lokasi pada produk yang diberikan.
+func (a adapter) attachLocations(ctx context.Context, products ...*product.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]product.ID, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+
+	stocks, err := a.storeRepo.FindLocationStocks(ctx, product.LocationStockFilter{ProductIDs: ids})
+	if err != nil {
+		return err
+	}
+
+	byProduct := make(map[product.ID][]product.LocationStock, len(products))
+	for _, stock := range stocks {
+		byProduct[stock.ProductID] = append(byProduct[stock.ProductID], *stock)
+	}
+	for _, p := range products {
+		p.Locations = byProduct[p.ID]
+	}
+	return nil
+}
+
+// BulkStore menyimpan atau memperbarui (upsert) banyak produk sekaligus dan mengembalikan hasil setiap item.
+func (a adapter) BulkStore(ctx context.Context, products []*product.Product, upsert bool) ([]*product.BulkResult, error) {
+	// Memulai tracing untuk fungsi BulkStore
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:BulkStore")
+	defer span.End()
+
+	if len(products) == 0 || len(products) > product.MaxBulkOperations {
+		return nil, product.ErrInvalidBulk
+	}
+
+	action := product.BulkCreate
+	if upsert {
+		action = product.BulkUpsert
+	}
+
+	now := time.Now().UTC().Unix()
+	results := make([]*product.BulkResult, len(products))
+	operations := make([]product.BulkOperation, 0, len(products))
+	indexes := make([]int, 0, len(products))  // Index item asal untuk setiap operasi
+	stocks := make([]int64, 0, len(products)) // Stok yang diminta setiap operasi, dicatat ke buku besar setelah ditulis
+
+	for i, p := range products {
+		results[i] = &product.BulkResult{Index: i}
+		if p == nil {
+			results[i].Fail(product.ErrInvalidBulk)
+			continue
+		}
+		results[i].ID, results[i].Code = p.ID, p.Code
+
+		// Item diperiksa dengan aturan yang sama seperti Store, item yang tidak valid tidak dikirim ke repository
+		if p.Stock < 0 {
+			results[i].Fail(product.ErrInvalidStockAdjustment)
+			continue
+		}
+		if p.Geo != nil {
+			if err := p.Geo.Validate(); err != nil {
+				results[i].Fail(err)
+				continue
+			}
+		}
+
+		item := *p
+		item.ID, item.Version, item.DeletedAt, item.Distance = "", 0, 0, nil
+		item.Code = strings.TrimSpace(item.Code)
+		item.CreatedAt = now
+		if upsert {
+			item.UpdatedAt = now
+		}
+
+		// Produk disimpan dan diperbarui tanpa stok, stok yang diminta dicatat melalui buku besar stok seperti pada Store
+		stocks = append(stocks, item.Stock)
+		item.Stock, item.Reserved, item.Available, item.Allocated = 0, 0, 0, 0
+
+		operations = append(operations, product.BulkOperation{Action: action, Product: &item})
+		indexes = append(indexes, i)
+	}
+
+	written, err := a.bulkWrite(ctx, operations, indexes, results)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, result := range written {
+		// Stok 0 tidak dapat dibedakan dari field yang tidak dikirim, sehingga stok produk tidak diubah
+		if stocks[j] == 0 {
+			continue
+		}
+		var err error
+		switch result.Status {
+		case product.BulkCreated:
+			_, err = a.storeRepo.AdjustStock(ctx, &product.StockMovement{
+				ProductID: result.ID,
+				Quantity:  stocks[j],
+				Reason:    product.ReasonInitial,
+				CreatedAt: now,
+			})
+		case product.BulkUpdated:
+			err = a.correctStock(ctx, result.ID, stocks[j], now)
+		}
+		if err != nil && result.Status == product.BulkCreated {
+			// Seperti pada Store, produk baru dihapus kembali agar item dapat dikirim ulang tanpa ErrCodeConflict.
+			// Penghapusan tetap dijalankan walaupun request sudah dibatalkan, dan ID hanya dilaporkan jika gagal.
+			if errRemove := a.storeRepo.Remove(context.WithoutCancel(ctx), result.ID); errRemove != nil {
+				slog.ErrorContext(ctx, "Failed to remove product after opening stock failed service:product:BulkStore",
+					slog.String("product_id", result.ID.String()), slog.Any("err ", errRemove))
+			} else {
+				result.ID = ""
+			}
+		}
+		if err != nil {
+			// Produk yang diperbarui tetap tersimpan, ID dilaporkan agar stok dapat dicatat ulang melalui AdjustStock
+			result.Fail(err)
+		}
+	}
+	return results, nil
+}
+
+// correctStock mencatat pergerakan stok correction sebesar selisih stock dan stok produk saat ini.
+// Pergerakan hanya diterapkan jika produk belum diubah pihak lain sejak dibaca.
+func (a adapter) correctStock(ctx context.Context, id product.ID, stock int64, now int64) error {
+	current, err := a.storeRepo.Find(ctx, id)
+	if err != nil {
+		return err
+	}
+	if current.Stock == stock {
+		return nil
+	}
+	_, err = a.storeRepo.AdjustStock(ctx, &product.StockMovement{
+		ProductID:       id,
+		Quantity:        stock - current.Stock,
+		Reason:          product.ReasonCorrection,
+		CreatedAt:       now,
+		ExpectedVersion: current.Version,
+	})
+	return err
+}
+
+// BulkDelete menandai banyak produk sebagai dihapus (soft delete) dan mengembalikan hasil setiap item.
+func (a adapter) BulkDelete(ctx context.Context, products []*product.Product) ([]*product.BulkResult, error) {
+	// Memulai tracing untuk fungsi BulkDelete
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:BulkDelete")
+	defer span.End()
+
+	if len(products) == 0 || len(products) > product.MaxBulkOperations {
+		return nil, product.ErrInvalidBulk
+	}
+
+	results := make([]*product.BulkResult, len(products))
+	operations := make([]product.BulkOperation, 0, len(products))
+	indexes := make([]int, 0, len(products))
+	for i, p := range products {
+		results[i] = &product.BulkResult{Index: i}
+		if p == nil || (p.ID.IsZero() && strings.TrimSpace(p.Code) == "") {
+			results[i].Fail(product.ErrInvalidBulk)
+			continue
+		}
+		target := &product.Product{ID: p.ID, Code: strings.TrimSpace(p.Code)}
+		results[i].ID, results[i].Code = target.ID, target.Code
+
+		operations = append(operations, product.BulkOperation{Action: product.BulkDelete, Product: target})
+		indexes = append(indexes, i)
+	}
+
+	if _, err := a.bulkWrite(ctx, operations, indexes, results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// bulkWrite menjalankan operations pada repository lalu menempatkan hasilnya ke results sesuai index item asal.
+// Hasil yang dikembalikan berurutan sesuai operations.
+func (a adapter) bulkWrite(ctx context.Context, operations []product.BulkOperation, indexes []int, results []*product.BulkResult) ([]*product.BulkResult, error) {
+	if len(operations) == 0 {
+		return nil, nil
+	}
+
+	written, err := a.storeRepo.BulkWrite(ctx, operations)
+	if err != nil {
+		return nil, err
+	}
+	for j, result := range written {
+		result.Index = indexes[j]
+		results[indexes[j]] = result
+	}
+	return written, nil
+}
+
+// ClaimIdempotencyKey mengklaim Idempotency-Key, atau mengembalikan response tersimpan jika request ini adalah pengulangan.
+func (a adapter) ClaimIdempotencyKey(ctx context.Context, key string, fingerprint string) (*product.IdempotencyRecord, error) {
+	// Memulai tracing untuk fungsi ClaimIdempotencyKey
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:ClaimIdempotencyKey")
+	defer span.End()
+
+	if key == "" || len(key) > product.MaxIdempotencyKeyLength {
+		return nil, product.ErrInvalidIdempotencyKey
+	}
+
+	now := time.Now().UTC()
+	existing, err := a.storeRepo.ClaimIdempotencyKey(ctx, &product.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now.Unix(),
+		ExpiresAt:   now.Add(idempotencyLockTimeout).Unix(),
+	})
+	if err != nil || existing == nil {
+		return nil, err
+	}
+
+	// Key yang sama hanya boleh digunakan untuk request yang sama persis
+	if existing.Fingerprint != fingerprint {
+		return nil, product.ErrIdempotencyKeyReused
+	}
+	if !existing.Completed() {
+		return nil, product.ErrIdempotencyKeyInProgress
+	}
+	return existing, nil
+}
+
+// CompleteIdempotencyKey menyimpan response request dan memperpanjang masa berlaku key menjadi idempotencyTTL.
+func (a adapter) CompleteIdempotencyKey(ctx context.Context, record *product.IdempotencyRecord) error {
+	// Memulai tracing untuk fungsi CompleteIdempotencyKey
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:CompleteIdempotencyKey")
+	defer span.End()
+
+	record.ExpiresAt = time.Now().UTC().Add(a.idempotencyTTL).Unix()
+	return a.storeRepo.CompleteIdempotencyKey(ctx, record)
+}
+
+// ReleaseIdempotencyKey melepas klaim Idempotency-Key sehingga request dapat diulang, misalnya setelah kesalahan server.
+func (a adapter) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	// Memulai tracing untuk fungsi ReleaseIdempotencyKey
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:ReleaseIdempotencyKey")
+	defer span.End()
+
+	return a.storeRepo.ReleaseIdempotencyKey(ctx, key)
+}
+
 /*
 Penjelasan Fungsi dan Komentar dalam Kode:
 Package product:
@@ -102,24 +874,71 @@ Struct adapter:
 adapter adalah implementasi dari interface ProductInterface. Struct ini menggunakan storeRepo, yang merupakan instance dari product.Repository, untuk berinteraksi dengan repository produk.
 Fungsi NewStoreService:
 
-Fungsi ini adalah constructor untuk membuat instance baru dari adapter dan mengembalikannya sebagai implementasi dari ProductInterface. Ini memungkinkan layanan produk untuk digunakan di seluruh aplikasi.
+Fungsi ini adalah constructor untuk membuat instance baru dari adapter dan mengembalikannya sebagai implementasi dari ProductInterface. Ini memungkinkan layanan produk untuk digunakan di seluruh aplikasi. Parameter purgeRetention menentukan berapa lama produk yang sudah di-soft delete disimpan sebelum dapat dihapus permanen, dan idempotencyTTL menentukan berapa lama response request dengan Idempotency-Key disimpan.
 Fungsi Find:
 
-Fungsi ini mencari produk berdasarkan ID dan mengembalikannya. Fungsi ini juga memulai tracing untuk memantau kinerja dan masalah.
+Fungsi ini mencari produk berdasarkan ID dan mengembalikannya beserta stok yang tersedia (Available). Fungsi ini juga memulai tracing untuk memantau kinerja dan masalah.
 Fungsi Store:
 
-Fungsi ini menyimpan produk baru ke dalam repository dan mengatur waktu pembuatan produk. Setelah produk disimpan, fungsi ini mengatur ID produk dengan ID yang baru disisipkan.
+Fungsi ini menyimpan produk baru ke dalam repository dan mengatur waktu pembuatan produk. Koordinat produk (Geo) bersifat opsional dan ditolak dengan ErrInvalidGeoPoint jika tidak valid. Setelah produk disimpan, fungsi ini mengatur ID produk dengan ID yang baru disisipkan. Stok awal tidak disimpan langsung, melainkan dicatat sebagai pergerakan stok dengan kode alasan initial agar buku besar stok selalu cocok dengan nilai stok produk.
 Fungsi Update:
 
-Fungsi ini memperbarui produk yang ada di dalam repository dan mengatur waktu pembaruan produk. Ini memanfaatkan tracing untuk memantau proses.
+Fungsi ini memperbarui produk yang ada di dalam repository dan mengatur waktu pembaruan produk. Stok tidak ikut diperbarui karena perubahan stok wajib melalui AdjustStock; stok yang berbeda dari stok saat ini ditolak dengan ErrStockNotUpdatable agar perubahan tidak hilang diam-diam, sedangkan stok 0 dianggap tidak dikirim. Ini memanfaatkan tracing untuk memantau proses.
+Fungsi Patch:
+
+Fungsi ini membaca produk saat ini, menerapkan dokumen JSON Merge Patch atau JSON Patch melalui PatchDocument.Apply, lalu menulis hanya field yang berubah ke repository bersama waktu pembaruan. Perubahan ditulis dengan versi yang dibaca, sehingga perubahan lain yang terjadi di antara pembacaan dan penulisan ditolak dengan ErrVersionConflict dan tidak tertimpa.
+
+Perubahan stock tidak ditulis sebagai field biasa, melainkan dicatat melalui AdjustStock sebagai pergerakan correction sebesar selisih stok baru dan stok saat ini dengan ExpectedVersion, sehingga pemeriksaan versi tetap berlaku. Jika pencatatan stok gagal setelah field lain ditulis, field tersebut dikembalikan ke nilai sebelumnya dengan Changes.Revert agar patch tidak diterapkan sebagian.
 Fungsi FindAll:
 
-Fungsi ini mencari semua produk dengan filter tertentu dan mendukung pagination. Ini mengembalikan hasil pencarian serta informasi pagination.
+Fungsi ini mencari semua produk dengan filter tertentu dan mendukung pagination. Ini mengembalikan hasil pencarian serta informasi pagination. Selain page/limit, FindAll mendukung cursor pagination (Filter.UseCursor) yang tidak dapat digabungkan dengan pencarian berdasarkan jarak. Parameter sort (Filter.Sort) juga tidak dapat digabungkan dengan cursor maupun pencarian berdasarkan jarak (ErrInvalidSort), dan rincian stok per lokasi hanya dibaca jika field locations dipilih pada projection (Filter.Fields).
+Fungsi Search:
+
+Fungsi ini memvalidasi kata kunci pencarian teks (ErrInvalidSearch jika tidak berisi kata apa pun atau terlalu panjang), menjalankan pencarian pada repository, lalu mengisi Highlights untuk nama dan deskripsi produk yang memuat kata yang dicari.
+Fungsi FindByCode:
+
+Fungsi ini mencari produk berdasarkan kode produk (SKU). Spasi di awal dan akhir kode diabaikan.
 Fungsi Delete:
 
-Fungsi ini adalah placeholder yang belum diimplementasikan. Fungsi ini dimaksudkan untuk menghapus produk berdasarkan kode tertentu.
+Fungsi ini menandai produk sebagai dihapus (soft delete) berdasarkan kode produk (SKU), sama seperti DeleteById.
 Fungsi DeleteById:
 
-Fungsi ini menghapus produk dari repository berdasarkan ID yang diberikan. Fungsi ini juga memulai tracing untuk memantau kinerja dan masalah.
+Fungsi ini menandai produk sebagai dihapus (soft delete) berdasarkan ID yang diberikan. Fungsi ini juga memulai tracing untuk memantau kinerja dan masalah.
+Fungsi Restore:
+
+Fungsi ini memulihkan produk yang sudah di-soft delete, lalu mengambil kembali data produk tersebut dari repository.
+Fungsi Purge:
+
+Fungsi ini menghitung batas waktu berdasarkan masa retensi dan menghapus permanen produk yang sudah dihapus sebelum batas waktu tersebut.
+Fungsi AdjustStock:
+
+Fungsi ini menolak perubahan stok dengan jumlah 0 atau kode alasan yang tidak dikenal (ErrInvalidStockAdjustment), lalu meneruskan perubahan ke repository yang mengubah stok secara atomik dan mencatat pergerakannya. Jika LocationID diisi, lokasi harus terdaftar dan perubahan juga diterapkan pada stok lokasi tersebut.
+Fungsi FindMovements:
+
+Fungsi ini memastikan produk ada, lalu mengembalikan riwayat pergerakan stok produk dengan pagination.
+Fungsi Reserve, FindReservation, ConfirmReservation, dan ReleaseReservation:
+
+Fungsi-fungsi ini mengelola reservasi stok untuk proses checkout. Reserve menolak jumlah yang tidak lebih dari 0 (ErrInvalidReservation) dan menggunakan reservationTTL jika permintaan tidak menentukan TTL. Stok yang ditahan mengurangi Available, sedangkan Stock baru berkurang saat reservasi dikonfirmasi.
+Fungsi ReleaseExpiredReservations:
+
+Fungsi ini dipanggil oleh sweeper (lihat sweeper.go) untuk melepas reservasi aktif yang sudah kedaluwarsa dalam batch berisi 100 reservasi.
+Fungsi CreateLocation, FindLocation, dan FindLocations:
+
+Fungsi-fungsi ini mengelola lokasi penyimpanan stok (gudang atau toko). CreateLocation menolak lokasi tanpa kode, tanpa nama, atau dengan tipe yang tidak dikenal (ErrInvalidLocation).
+Fungsi FindStockByProduct dan FindStockByLocation:
+
+Fungsi-fungsi ini mengembalikan stok per lokasi untuk satu produk atau untuk satu lokasi, setelah memastikan produk atau lokasinya ada.
+Fungsi TransferStock:
+
+Fungsi ini memindahkan stok antar lokasi tanpa mengubah total stok produk. Lokasi asal atau tujuan yang kosong berarti stok yang belum dialokasikan, sehingga transfer juga digunakan untuk mengalokasikan stok ke lokasi dan mengembalikannya. Permintaan dengan jumlah tidak lebih dari 0 atau lokasi asal sama dengan tujuan ditolak dengan ErrInvalidTransfer.
+Fungsi attachLocations:
+
+Fungsi ini mengisi Product.Locations untuk Find, FindByCode, dan FindAll. Untuk FindAll, stok seluruh produk pada halaman dibaca dengan satu panggilan FindLocationStocks.
+Fungsi BulkStore dan BulkDelete:
+
+Fungsi ini memeriksa setiap item dengan aturan yang sama seperti Store dan Delete, lalu mengirim item yang valid ke Repository.BulkWrite dalam satu panggilan. Item yang tidak valid atau gagal ditulis hanya menandai hasil item tersebut (partial failure). Seperti Store, produk baru disimpan dengan stok 0 lalu stok awalnya dicatat sebagai pergerakan stok dengan kode alasan initial, sedangkan stok yang diminta untuk produk yang diperbarui melalui upsert dicatat dengan correctStock sebagai pergerakan correction sebesar selisihnya dengan stok saat ini. Pencatatan stok yang gagal (misalnya ErrVersionConflict karena produk diubah pihak lain) menandai item sebagai gagal, bukan dilaporkan berhasil. Jika stok awal produk baru gagal dicatat, produk tersebut dihapus kembali dengan Remove seperti pada Store sehingga item dapat dikirim ulang tanpa ErrCodeConflict. Stok 0 dianggap tidak dikirim, sama seperti pada Update.
+Fungsi ClaimIdempotencyKey, CompleteIdempotencyKey, dan ReleaseIdempotencyKey:
+
+Fungsi-fungsi ini digunakan oleh middleware Idempotency-Key. Klaim baru hanya berlaku selama idempotencyLockTimeout agar key tidak terkunci jika proses berhenti sebelum response disimpan, lalu CompleteIdempotencyKey memperpanjangnya menjadi idempotencyTTL. Key yang digunakan ulang dengan fingerprint berbeda ditolak dengan ErrIdempotencyKeyReused, dan key yang request pertamanya belum selesai ditolak dengan ErrIdempotencyKeyInProgress.
 Dengan penjelasan dan komentar ini, diharapkan kode lebih mudah dipahami dan dimengerti fungsinya dalam konteks aplikasi.
 */
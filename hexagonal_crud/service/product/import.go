@@ -0,0 +1,349 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"CRUD_Hexagonal/infrastructure"
+	"CRUD_Hexagonal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// importBatchSize adalah jumlah baris yang dikirim ke BulkStore dalam satu panggilan.
+// Progres job disimpan setelah setiap batch.
+const importBatchSize = 500
+
+// importRow adalah satu baris data pada file import beserta nomor barisnya.
+type importRow struct {
+	number int      // Nomor baris pada file, baris header adalah baris 1
+	values []string // Isi setiap kolom sesuai urutan header
+}
+
+// ImportProducts memeriksa header file import, menyimpan job baru, lalu memproses baris-barisnya di latar belakang.
+func (a adapter) ImportProducts(ctx context.Context, request product.ImportRequest) (*product.ImportJob, error) {
+	// Memulai tracing untuk fungsi ImportProducts
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:ImportProducts")
+	defer span.End()
+
+	if request.Mode == "" {
+		request.Mode = product.BulkCreate
+	}
+	if request.Mode != product.BulkCreate && request.Mode != product.BulkUpsert {
+		return nil, fmt.Errorf("%w: unknown mode %q", product.ErrInvalidImport, request.Mode)
+	}
+
+	columns, err := importColumns(request.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	// Baris yang seluruh kolomnya kosong (misalnya baris kosong di akhir spreadsheet) diabaikan
+	rows := make([]importRow, 0, len(request.Rows))
+	for i, values := range request.Rows {
+		if !isEmptyRow(values) {
+			rows = append(rows, importRow{number: i + 2, values: values})
+		}
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: file has no data rows", product.ErrInvalidImport)
+	}
+	if len(rows) > product.MaxImportRows {
+		return nil, fmt.Errorf("%w: file has more than %d data rows", product.ErrInvalidImport, product.MaxImportRows)
+	}
+
+	now := time.Now().UTC().Unix()
+	job, err := a.storeRepo.StoreImportJob(ctx, &product.ImportJob{
+		Status:    product.JobPending,
+		Mode:      request.Mode,
+		DryRun:    request.DryRun,
+		Filename:  request.Filename,
+		TotalRows: len(rows),
+		Header:    request.Header,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Job diproses setelah response dikirim, sehingga context-nya tidak ikut dibatalkan bersama request
+	running := *job
+	go a.runImport(context.WithoutCancel(ctx), &running, columns, rows)
+
+	job.ComputeProgress()
+	return job, nil
+}
+
+// FindImportJob mencari job import berdasarkan ID beserta progresnya.
+func (a adapter) FindImportJob(ctx context.Context, id product.ID) (*product.ImportJob, error) {
+	// Memulai tracing untuk fungsi FindImportJob
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:FindImportJob")
+	defer span.End()
+
+	job, err := a.storeRepo.FindImportJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	job.ComputeProgress()
+	return job, nil
+}
+
+// runImport memproses baris job per batch dan menyimpan progresnya setelah setiap batch.
+func (a adapter) runImport(ctx context.Context, job *product.ImportJob, columns []string, rows []importRow) {
+	// Memulai tracing untuk fungsi runImport
+	ctx, span := infrastructure.Tracer().Start(ctx, "service:store:runImport")
+	defer span.End()
+
+	job.Status = product.JobRunning
+	a.saveImportJob(ctx, job)
+
+	seen := make(map[string]bool) // Kode produk yang sudah muncul pada baris sebelumnya, digunakan pada dry-run
+	for start := 0; start < len(rows); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		if err := a.importBatch(ctx, job, columns, rows[start:end], seen); err != nil {
+			// Kesalahan yang tidak terkait satu baris menghentikan job, baris yang sudah disimpan tetap tersimpan
+			slog.ErrorContext(ctx, "Import job failed service:product:runImport", slog.String("job_id", job.ID.String()), slog.Any("err ", err))
+			job.Status, job.Error = product.JobFailed, importErrorMessage(err)
+			job.FinishedAt = time.Now().UTC().Unix()
+			a.saveImportJob(ctx, job)
+			return
+		}
+		job.ProcessedRows = end
+		a.saveImportJob(ctx, job)
+	}
+
+	job.Status = product.JobCompleted
+	job.FinishedAt = time.Now().UTC().Unix()
+	a.saveImportJob(ctx, job)
+}
+
+// importBatch memetakan dan memvalidasi setiap baris, lalu menyimpan baris yang valid melalui BulkStore
+// atau, pada dry-run, mencatat perubahan yang akan dilakukan.
+func (a adapter) importBatch(ctx context.Context, job *product.ImportJob, columns []string, batch []importRow, seen map[string]bool) error {
+	products := make([]*product.Product, 0, len(batch))
+	valid := make([]importRow, 0, len(batch))
+	for _, row := range batch {
+		p, err := mapImportRow(columns, row.values)
+		if err == nil {
+			if fieldErrors := utils.Validate(p); len(fieldErrors) > 0 {
+				err = fieldErrors
+			}
+		}
+		if err != nil {
+			rejectImportRow(job, row, err)
+			continue
+		}
+		products = append(products, p)
+		valid = append(valid, row)
+	}
+	if len(products) == 0 {
+		return nil
+	}
+
+	if job.DryRun {
+		return a.previewImport(ctx, job, products, valid, seen)
+	}
+
+	results, err := a.BulkStore(ctx, products, job.Mode == product.BulkUpsert)
+	if err != nil {
+		return err
+	}
+	for i, result := range results {
+		switch result.Status {
+		case product.BulkCreated:
+			job.Created++
+		case product.BulkUpdated:
+			job.Updated++
+		default:
+			rejectImportRow(job, valid[i], result.Err)
+		}
+	}
+	return nil
+}
+
+// previewImport menentukan apakah setiap produk akan dibuat atau diperbarui tanpa menyimpannya.
+// Kode yang sudah digunakan, baik oleh produk yang tersimpan maupun baris sebelumnya, ditolak pada mode create.
+func (a adapter) previewImport(ctx context.Context, job *product.ImportJob, products []*product.Product, rows []importRow, seen map[string]bool) error {
+	for i, p := range products {
+		change := product.ImportChange{Row: rows[i].number, Action: product.BulkCreated, Code: strings.TrimSpace(p.Code), Name: p.Name}
+
+		if change.Code != "" {
+			exists := seen[change.Code]
+			if !exists {
+				existing, err := a.storeRepo.FindByCode(ctx, change.Code)
+				switch {
+				case err == nil:
+					exists, change.ProductID = true, existing.ID
+				case !errors.Is(err, product.ErrProductNotFound):
+					return err
+				}
+			}
+			if exists && job.Mode == product.BulkCreate {
+				rejectImportRow(job, rows[i], product.ErrCodeConflict)
+				continue
+			}
+			if exists {
+				change.Action = product.BulkUpdated
+			}
+			seen[change.Code] = true
+		}
+
+		if change.Action == product.BulkUpdated {
+			job.Updated++
+		} else {
+			job.Created++
+		}
+		job.Changes = append(job.Changes, change)
+	}
+	return nil
+}
+
+// saveImportJob menyimpan progres job. Kegagalan hanya dicatat karena job tetap diproses sampai selesai.
+func (a adapter) saveImportJob(ctx context.Context, job *product.ImportJob) {
+	job.UpdatedAt = time.Now().UTC().Unix()
+	if err := a.storeRepo.UpdateImportJob(ctx, job); err != nil {
+		slog.ErrorContext(ctx, "Failed to save import job service:product:saveImportJob", slog.String("job_id", job.ID.String()), slog.Any("err ", err))
+	}
+}
+
+// importColumns memetakan setiap kolom header ke field Product melalui product.ImportColumns.
+// Kolom yang tidak dikenal dipetakan ke string kosong dan diabaikan.
+func importColumns(header []string) ([]string, error) {
+	columns := make([]string, len(header))
+	found := make(map[string]bool)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		field, ok := product.ImportColumns[name]
+		if !ok {
+			continue
+		}
+		if found[field] {
+			return nil, fmt.Errorf("%w: column %s appears more than once", product.ErrInvalidImport, field)
+		}
+		found[field] = true
+		columns[i] = field
+	}
+
+	if !found["product_name"] {
+		return nil, fmt.Errorf("%w: missing product_name column", product.ErrInvalidImport)
+	}
+	if found["latitude"] != found["longitude"] {
+		return nil, fmt.Errorf("%w: latitude and longitude columns must be used together", product.ErrInvalidImport)
+	}
+	return columns, nil
+}
+
+// mapImportRow membuat Product dari isi satu baris. Nilai yang tidak dapat diparsing dikembalikan sebagai
+// utils.ValidationErrors sehingga dilaporkan dengan format yang sama seperti hasil utils.Validate.
+func mapImportRow(columns []string, values []string) (*product.Product, error) {
+	p := &product.Product{}
+	var fieldErrors utils.ValidationErrors
+	var latitude, longitude string
+
+	for i, field := range columns {
+		if field == "" || i >= len(values) {
+			continue
+		}
+		value := strings.TrimSpace(values[i])
+		switch field {
+		case "code":
+			p.Code = value
+		case "product_name":
+			p.Name = value
+		case "description":
+			p.Description = value
+		case "stock":
+			if value == "" {
+				continue
+			}
+			stock, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				fieldErrors = append(fieldErrors, utils.FieldError{Field: "stock", Rule: "integer", Message: "stock must be an integer"})
+				continue
+			}
+			p.Stock = stock
+		case "latitude":
+			latitude = value
+		case "longitude":
+			longitude = value
+		}
+	}
+
+	if latitude != "" || longitude != "" {
+		lat, errLat := strconv.ParseFloat(latitude, 64)
+		lon, errLon := strconv.ParseFloat(longitude, 64)
+		if errLat != nil || errLon != nil {
+			fieldErrors = append(fieldErrors, utils.FieldError{Field: "geo", Rule: "geopoint", Message: "geo must have a numeric latitude and longitude"})
+		} else {
+			p.Geo = product.NewGeoPoint(lat, lon)
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, fieldErrors
+	}
+	return p, nil
+}
+
+// rejectImportRow mencatat baris yang ditolak beserta alasannya pada job.
+func rejectImportRow(job *product.ImportJob, row importRow, err error) {
+	job.Rejected++
+	job.Errors = append(job.Errors, product.ImportRowError{
+		Row:     row.number,
+		Values:  row.values,
+		Message: importErrorMessage(err),
+	})
+}
+
+// importErrorMessage mengembalikan pesan error yang aman ditampilkan ke pengguna. Error yang bukan error domain
+// atau hasil validasi bisa berisi detail internal (misalnya pesan driver database), sehingga diganti pesan umum.
+func importErrorMessage(err error) string {
+	var domainErr *product.Error
+	var fieldErrors utils.ValidationErrors
+	if errors.As(err, &domainErr) || errors.As(err, &fieldErrors) {
+		return err.Error()
+	}
+	return "internal error"
+}
+
+// isEmptyRow mengembalikan true jika semua kolom pada baris kosong.
+func isEmptyRow(values []string) bool {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+Penjelasan Fungsi Kode:
+Fungsi ImportProducts:
+
+Fungsi ini dipanggil oleh endpoint POST /product/import setelah file CSV atau XLSX dibaca menjadi header dan baris.
+Header diperiksa lebih dahulu (kolom product_name wajib ada, kolom yang sama tidak boleh muncul dua kali, dan latitude
+harus berpasangan dengan longitude) sehingga file yang salah format langsung ditolak dengan ErrInvalidImport (422).
+Setelah itu job disimpan dengan status pending dan baris diproses oleh runImport di goroutine terpisah.
+Fungsi runImport:
+
+Baris diproses per batch berisi importBatchSize baris. Setiap baris dipetakan ke Product oleh mapImportRow lalu
+divalidasi dengan utils.Validate seperti POST /product. Baris yang valid disimpan melalui BulkStore, sehingga stok awal
+dicatat ke buku besar stok dan mode upsert memperbarui produk dengan kode yang sama (stok yang berbeda dicatat sebagai
+pergerakan correction). Baris yang tidak valid atau gagal
+disimpan dicatat pada job.Errors, dan progres job disimpan setelah setiap batch. Jika seluruh batch gagal (misalnya
+database tidak dapat dihubungi), job berhenti dengan status failed dan baris yang sudah disimpan tidak dibatalkan.
+Dry-run:
+
+Pada dry-run tidak ada produk yang disimpan. previewImport mencari setiap kode produk dengan FindByCode untuk menentukan
+apakah baris akan membuat atau memperbarui produk, lalu mencatatnya pada job.Changes. Pada mode create, kode yang sudah
+digunakan ditolak dengan ErrCodeConflict seperti saat import sebenarnya.
+*/
@@ -0,0 +1,38 @@
+package product
+
+import (
+	"CRUD_Hexagonal/domain/product"
+	"context"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// RunReservationSweeper melepas reservasi stok yang sudah kedaluwarsa setiap interval
+// sampai ctx dibatalkan. Fungsi ini dijalankan sebagai goroutine dari cmd/main.go.
+func RunReservationSweeper(ctx context.Context, service product.ProductInterface, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := service.ReleaseExpiredReservations(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to release expired reservations", slog.Any("err ", err))
+			}
+			if released > 0 {
+				slog.InfoContext(ctx, "Expired reservations released.", slog.Int64("released", released))
+			}
+		}
+	}
+}
+
+/*
+Penjelasan Fungsi Kode:
+Fungsi RunReservationSweeper:
+
+Sweeper berjalan di latar belakang dan memanggil ReleaseExpiredReservations setiap interval (RESERVATION_SWEEP_INTERVAL). Reservasi yang melewati batas waktu diubah menjadi expired dan stok yang ditahan dilepas sehingga dapat dijual kembali. Error dicatat ke log tanpa menghentikan sweeper, sehingga gangguan sementara pada database tidak menghentikan proses pelepasan reservasi berikutnya.
+*/
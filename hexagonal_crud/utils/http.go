@@ -12,6 +12,7 @@ type Response struct {
 	Message    string      `json:"message"`
 	Data       interface{} `json:"data"`
 	Pagination *Pagination `json:"pagination,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"`
 }
 
 // Pagination is pagination response model.
@@ -20,15 +21,31 @@ type Pagination struct {
 	Limit       int `json:"limit"`
 	CurrentPage int `json:"current_page"`
 	LastPage    int `json:"last_page"`
+
+	HasNext bool `json:"has_next"` // true jika masih ada halaman setelah halaman ini
+	HasPrev bool `json:"has_prev"` // true jika ada halaman sebelum halaman ini
+
+	// Cursor menandai hasil cursor pagination. Envelope hanya berisi next_cursor,
+	// yang kosong jika halaman ini adalah halaman terakhir.
+	Cursor     bool   `json:"-"`
+	NextCursor string `json:"-"`
 }
 
-// ResponseWithJSON to write response with JSON format.
+// ResponseWithJSON to write response with JSON format regardless of the Accept header, see Respond.
 func ResponseWithJSON(c *fiber.Ctx, code int, data interface{}, err error, pagination ...*Pagination) {
+	c.Status(code)
+	_ = c.JSON(newResponse(code, data, err, pagination...))
+}
+
+// newResponse builds the envelope, filling in the derived pagination fields.
+func newResponse(code int, data interface{}, err error, pagination ...*Pagination) Response {
 	r := Response{
 		Status:  code,
 		Message: strings.ToLower(http.StatusText(code)),
 	}
-	if len(pagination) > 0 && pagination[0] != nil {
+	if len(pagination) > 0 && pagination[0] != nil && pagination[0].Cursor {
+		r.NextCursor = pagination[0].NextCursor
+	} else if len(pagination) > 0 && pagination[0] != nil {
 		r.Pagination = pagination[0]
 		if r.Pagination.CurrentPage <= 0 {
 			r.Pagination.CurrentPage = 1
@@ -37,15 +54,45 @@ func ResponseWithJSON(c *fiber.Ctx, code int, data interface{}, err error, pagin
 		if r.Pagination.LastPage <= 0 {
 			r.Pagination.LastPage = 1
 		}
+		r.Pagination.HasNext = r.Pagination.CurrentPage < r.Pagination.LastPage
+		r.Pagination.HasPrev = r.Pagination.CurrentPage > 1
 	}
 
 	r.Data = data
 	if err != nil {
 		r.Message = err.Error()
 	}
+	return r
+}
+
+// MIMEApplicationProblemJSON is the media type of RFC 7807 problem details.
+const MIMEApplicationProblemJSON = "application/problem+json"
 
-	// Set response header.
-	c.Accepts("application/json")
+// Problem is an RFC 7807 problem details response model.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// AcceptsProblem reports whether the client asked for problem details.
+// The normal envelope stays the default, so the format is opt-in: the client must
+// prefer application/problem+json over application/json in the Accept header.
+func AcceptsProblem(c *fiber.Ctx) bool {
+	return c.Accepts(fiber.MIMEApplicationJSON, MIMEApplicationProblemJSON) == MIMEApplicationProblemJSON
+}
+
+// ResponseWithProblem to write response with problem+json format.
+func ResponseWithProblem(c *fiber.Ctx, p Problem) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
 
-	_ = c.JSON(r)
+	_ = c.Status(p.Status).JSON(p, MIMEApplicationProblemJSON)
 }
@@ -0,0 +1,474 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Media types produced by Respond in addition to JSON.
+const (
+	MIMEApplicationMsgPack = "application/msgpack"
+	MIMETextCSV            = "text/csv"
+)
+
+// Pagination headers sent with CSV responses, which have no room for the envelope.
+const (
+	HeaderTotalCount  = "X-Total-Count"
+	HeaderCurrentPage = "X-Current-Page"
+	HeaderLastPage    = "X-Last-Page"
+	HeaderNextCursor  = "X-Next-Cursor"
+)
+
+// ErrNotAcceptable is returned in the 406 response when no media type in the Accept header can be produced.
+var ErrNotAcceptable = errors.New("not acceptable")
+
+// renderer encodes the response envelope, decoded into an ordered JSON tree, in one media type.
+type renderer func(w io.Writer, c *fiber.Ctx, r Response, tree *jsonObject) error
+
+// offer is a media type accepted in the Accept header and the renderer producing it.
+// JSON offers have no renderer because the envelope is written with c.JSON directly.
+type offer struct {
+	mediaType   string
+	contentType string
+	render      renderer
+	listOnly    bool
+}
+
+// offers are tried in order, so wildcards such as */* and application/* select JSON.
+var offers = []offer{
+	{mediaType: fiber.MIMEApplicationJSON},
+	{mediaType: MIMEApplicationProblemJSON},
+	{mediaType: MIMEApplicationMsgPack, contentType: MIMEApplicationMsgPack, render: renderMsgPack},
+	{mediaType: "application/x-msgpack", contentType: "application/x-msgpack", render: renderMsgPack},
+	{mediaType: "application/vnd.msgpack", contentType: "application/vnd.msgpack", render: renderMsgPack},
+	{mediaType: fiber.MIMEApplicationXML, contentType: fiber.MIMEApplicationXMLCharsetUTF8, render: renderXML},
+	{mediaType: fiber.MIMETextXML, contentType: fiber.MIMETextXMLCharsetUTF8, render: renderXML},
+	{mediaType: MIMETextCSV, contentType: MIMETextCSV + "; charset=utf-8", render: renderCSV, listOnly: true},
+}
+
+// Respond writes the standard envelope in the media type preferred by the Accept header:
+// JSON (the default), MessagePack, XML, or CSV when data is a list and the request succeeded.
+// A successful response that cannot be produced in any accepted type becomes 406 Not Acceptable;
+// error responses fall back to JSON instead so the error itself is not lost.
+func Respond(c *fiber.Ctx, code int, data interface{}, err error, pagination ...*Pagination) {
+	// The body depends on the Accept header, so caches must keep the variants apart.
+	c.Vary(fiber.HeaderAccept)
+
+	chosen, ok := negotiate(c, code, data)
+	if !ok {
+		if code < http.StatusBadRequest {
+			ResponseWithJSON(c, http.StatusNotAcceptable, nil, notAcceptable(code, data))
+			return
+		}
+		chosen = offers[0]
+	}
+
+	r := newResponse(code, data, err, pagination...)
+	if chosen.render == nil {
+		c.Status(code)
+		_ = c.JSON(r)
+		return
+	}
+
+	tree, errTree := decodeTree(r)
+	var body bytes.Buffer
+	if errTree == nil {
+		errTree = chosen.render(&body, c, r, tree)
+	}
+	if errTree != nil {
+		ResponseWithJSON(c, http.StatusInternalServerError, nil, errTree)
+		return
+	}
+	c.Set(fiber.HeaderContentType, chosen.contentType)
+	_ = c.Status(code).Send(body.Bytes())
+}
+
+// CheckAcceptable returns an error wrapping ErrNotAcceptable if a response without list data cannot be produced
+// in any type of the Accept header. Handlers that change data can check it before doing any work,
+// so a 406 never hides a completed change.
+func CheckAcceptable(c *fiber.Ctx) error {
+	if _, ok := negotiate(c, http.StatusOK, nil); !ok {
+		return notAcceptable(http.StatusOK, nil)
+	}
+	return nil
+}
+
+// NegotiatedContentType returns the Content-Type Respond writes for a response without list data,
+// or an empty string if the Accept header matches none of the media types.
+// Media types rendered the same way, such as application/json and application/problem+json, share one value.
+func NegotiatedContentType(c *fiber.Ctx) string {
+	chosen, ok := negotiate(c, http.StatusOK, nil)
+	switch {
+	case !ok:
+		return ""
+	case chosen.render == nil:
+		return fiber.MIMEApplicationJSON
+	}
+	return chosen.contentType
+}
+
+// notAcceptable builds the 406 error listing the media types available for the response.
+func notAcceptable(code int, data interface{}) error {
+	return fmt.Errorf("%w, supported media types: %s", ErrNotAcceptable, strings.Join(mediaTypes(availableOffers(code, data)), ", "))
+}
+
+// negotiate picks the offer matching the Accept header. A missing header selects JSON.
+func negotiate(c *fiber.Ctx, code int, data interface{}) (offer, bool) {
+	available := availableOffers(code, data)
+	chosen := c.Accepts(mediaTypes(available)...)
+	for _, o := range available {
+		if o.mediaType == chosen {
+			return o, true
+		}
+	}
+	return offer{}, false
+}
+
+// availableOffers filters out CSV unless data is a list and the request succeeded.
+func availableOffers(code int, data interface{}) []offer {
+	list := code < http.StatusBadRequest && isList(data)
+	available := make([]offer, 0, len(offers))
+	for _, o := range offers {
+		if !o.listOnly || list {
+			available = append(available, o)
+		}
+	}
+	return available
+}
+
+func mediaTypes(available []offer) []string {
+	types := make([]string, len(available))
+	for i, o := range available {
+		types[i] = o.mediaType
+	}
+	return types
+}
+
+// isList reports whether data is a slice or array, other than raw bytes.
+func isList(data interface{}) bool {
+	if data == nil {
+		return false
+	}
+	v := reflect.ValueOf(data)
+	return (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8
+}
+
+// jsonObject is a decoded JSON object that keeps the order of its keys, so every media type
+// uses the field names, omitempty rules, and order of the JSON response.
+type jsonObject struct {
+	keys   []string
+	values []interface{}
+}
+
+// get returns the value of key, or nil if the object has no such key.
+func (o *jsonObject) get(key string) interface{} {
+	for i, k := range o.keys {
+		if k == key {
+			return o.values[i]
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the object back to JSON in its original key order.
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeTree encodes the envelope as JSON and decodes it into nil, bool, string, json.Number,
+// []interface{}, and *jsonObject values.
+func decodeTree(r Response) (*jsonObject, error) {
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	value, err := decodeValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	return value.(*jsonObject), nil
+}
+
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := &jsonObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			object.keys = append(object.keys, key.(string))
+			object.values = append(object.values, value)
+		}
+		_, err = dec.Token()
+		return object, err
+	case json.Delim('['):
+		array := []interface{}{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = dec.Token()
+		return array, err
+	default:
+		return token, nil
+	}
+}
+
+// renderMsgPack encodes the envelope as a MessagePack map with the same keys as the JSON envelope.
+// Whole numbers are encoded as integers and other numbers as float64.
+func renderMsgPack(w io.Writer, _ *fiber.Ctx, _ Response, tree *jsonObject) error {
+	return encodeMsgPack(msgpack.NewEncoder(w), tree)
+}
+
+func encodeMsgPack(enc *msgpack.Encoder, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return enc.EncodeNil()
+	case bool:
+		return enc.EncodeBool(v)
+	case string:
+		return enc.EncodeString(v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return enc.EncodeInt(n)
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		return enc.EncodeFloat64(f)
+	case []interface{}:
+		if err := enc.EncodeArrayLen(len(v)); err != nil {
+			return err
+		}
+		for _, item := range v {
+			if err := encodeMsgPack(enc, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *jsonObject:
+		if err := enc.EncodeMapLen(len(v.keys)); err != nil {
+			return err
+		}
+		for i, key := range v.keys {
+			if err := enc.EncodeString(key); err != nil {
+				return err
+			}
+			if err := encodeMsgPack(enc, v.values[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("msgpack: unsupported value %T", value)
+	}
+}
+
+// renderXML encodes the envelope under a <response> root. Object keys become elements, array items
+// become <item> elements, and null values are left out. Keys that are not valid element names are
+// written as <field name="key">.
+func renderXML(w io.Writer, _ *fiber.Ctx, _ Response, tree *jsonObject) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := encodeXML(enc, xml.StartElement{Name: xml.Name{Local: "response"}}, tree); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func encodeXML(enc *xml.Encoder, start xml.StartElement, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	var err error
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if err = encodeXML(enc, xml.StartElement{Name: xml.Name{Local: "item"}}, item); err != nil {
+				return err
+			}
+		}
+	case *jsonObject:
+		for i, key := range v.keys {
+			if err = encodeXML(enc, xmlElement(key), v.values[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		err = enc.EncodeToken(xml.CharData(scalarText(v)))
+	}
+	if err != nil {
+		return err
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlElement returns the element for an object key.
+func xmlElement(key string) xml.StartElement {
+	if validXMLName(key) {
+		return xml.StartElement{Name: xml.Name{Local: key}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: "field"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: key}},
+	}
+}
+
+// validXMLName accepts the ASCII subset of XML names used by JSON field names.
+func validXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		letter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !letter && (i == 0 || !(r == '-' || r == '.' || (r >= '0' && r <= '9'))) {
+			return false
+		}
+	}
+	return true
+}
+
+// renderCSV writes the data list as CSV with one row per item and a header row of all columns.
+// Nested objects are flattened into dotted columns (geo.type), arrays are written as JSON text,
+// and the pagination of the envelope is moved to response headers.
+func renderCSV(w io.Writer, c *fiber.Ctx, r Response, tree *jsonObject) error {
+	if r.Pagination != nil {
+		c.Set(HeaderTotalCount, strconv.Itoa(r.Pagination.Total))
+		c.Set(HeaderCurrentPage, strconv.Itoa(r.Pagination.CurrentPage))
+		c.Set(HeaderLastPage, strconv.Itoa(r.Pagination.LastPage))
+	}
+	if r.NextCursor != "" {
+		c.Set(HeaderNextCursor, r.NextCursor)
+	}
+
+	items, _ := tree.get("data").([]interface{})
+	var columns []string
+	seen := map[string]bool{}
+	rows := make([]map[string]string, len(items))
+	for i, item := range items {
+		rows[i] = map[string]string{}
+		if err := flattenCSV("", item, rows[i], func(column string) {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	if len(columns) > 0 {
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			record[i] = row[column]
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// flattenCSV stores the cells of value in row. Items that are not objects use a single "value" column.
+func flattenCSV(prefix string, value interface{}, row map[string]string, addColumn func(string)) error {
+	object, ok := value.(*jsonObject)
+	if !ok {
+		column := prefix
+		if column == "" {
+			column = "value"
+		}
+		addColumn(column)
+		if array, isArray := value.([]interface{}); isArray {
+			text, err := json.Marshal(array)
+			if err != nil {
+				return err
+			}
+			row[column] = string(text)
+			return nil
+		}
+		row[column] = scalarText(value)
+		return nil
+	}
+	for i, key := range object.keys {
+		column := key
+		if prefix != "" {
+			column = prefix + "." + key
+		}
+		if err := flattenCSV(column, object.values[i], row, addColumn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scalarText returns the text of a JSON scalar, null is empty.
+func scalarText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,273 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// renderItem is a list item with a nested object, an omitted empty field, and a float.
+type renderItem struct {
+	ID    string      `json:"id"`
+	Price float64     `json:"price"`
+	Geo   *renderGeo  `json:"geo,omitempty"`
+	Note  string      `json:"note,omitempty"`
+	Tags  []string    `json:"tags"`
+	Extra interface{} `json:"extra"`
+}
+
+type renderGeo struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+var renderItems = []renderItem{
+	{ID: "a", Price: 1.5, Geo: &renderGeo{Lat: -6.2, Lon: 106.8}, Tags: []string{"x", "y"}},
+	{ID: "b", Price: 2, Note: "kedua", Tags: []string{}},
+}
+
+// newRenderApp registers routes answering with a list, a single object, and an error.
+func newRenderApp() *fiber.App {
+	app := fiber.New()
+	app.Get("/list", func(c *fiber.Ctx) error {
+		Respond(c, http.StatusOK, renderItems, nil, &Pagination{Total: 12, Limit: 5, CurrentPage: 2})
+		return nil
+	})
+	app.Get("/cursor", func(c *fiber.Ctx) error {
+		Respond(c, http.StatusOK, renderItems, nil, &Pagination{Cursor: true, NextCursor: "next"})
+		return nil
+	})
+	app.Get("/item", func(c *fiber.Ctx) error {
+		Respond(c, http.StatusOK, renderItems[0], nil)
+		return nil
+	})
+	app.Get("/error", func(c *fiber.Ctx) error {
+		Respond(c, http.StatusNotFound, nil, errors.New("product not found"))
+		return nil
+	})
+	app.Post("/check", func(c *fiber.Ctx) error {
+		if err := CheckAcceptable(c); err != nil {
+			Respond(c, http.StatusNotAcceptable, nil, err)
+			return nil
+		}
+		Respond(c, http.StatusOK, nil, nil)
+		return nil
+	})
+	return app
+}
+
+func get(t *testing.T, app *fiber.App, method, target, accept string) (*http.Response, []byte) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, nil)
+	if accept != "" {
+		req.Header.Set(fiber.HeaderAccept, accept)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s returned error: %v", method, target, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading %s body: %v", target, err)
+	}
+	return resp, body
+}
+
+func TestRespondNegotiatesMediaType(t *testing.T) {
+	app := newRenderApp()
+
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", fiber.MIMEApplicationJSON},
+		{"*/*", fiber.MIMEApplicationJSON},
+		{"application/*", fiber.MIMEApplicationJSON},
+		{MIMEApplicationProblemJSON, fiber.MIMEApplicationJSON},
+		{MIMEApplicationMsgPack, MIMEApplicationMsgPack},
+		{"application/x-msgpack", "application/x-msgpack"},
+		{"application/vnd.msgpack", "application/vnd.msgpack"},
+		{fiber.MIMEApplicationXML, fiber.MIMEApplicationXMLCharsetUTF8},
+		{fiber.MIMETextXML, fiber.MIMETextXMLCharsetUTF8},
+		{"text/csv", "text/csv; charset=utf-8"},
+		{"text/html;q=0.9, application/xml;q=0.5, application/json;q=0.1", fiber.MIMEApplicationXMLCharsetUTF8},
+	}
+	for _, tt := range tests {
+		resp, body := get(t, app, fiber.MethodGet, "/list", tt.accept)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Accept %q returned %d: %s", tt.accept, resp.StatusCode, body)
+		}
+		if got := resp.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(got, tt.contentType) {
+			t.Errorf("Accept %q returned Content-Type %q, want %q", tt.accept, got, tt.contentType)
+		}
+		if got := resp.Header.Get(fiber.HeaderVary); got != fiber.HeaderAccept {
+			t.Errorf("Accept %q returned Vary %q, want Accept", tt.accept, got)
+		}
+	}
+}
+
+func TestRespondNotAcceptable(t *testing.T) {
+	app := newRenderApp()
+
+	// CSV is only produced for lists, so a single object cannot be sent as CSV
+	for _, tt := range []struct{ target, accept string }{{"/item", "text/csv"}, {"/list", "image/png"}} {
+		resp, body := get(t, app, fiber.MethodGet, tt.target, tt.accept)
+		if resp.StatusCode != http.StatusNotAcceptable {
+			t.Fatalf("GET %s with Accept %s returned %d, want 406: %s", tt.target, tt.accept, resp.StatusCode, body)
+		}
+		var r Response
+		if err := json.Unmarshal(body, &r); err != nil {
+			t.Fatalf("decoding %s: %v", body, err)
+		}
+		if !strings.HasPrefix(r.Message, ErrNotAcceptable.Error()) || !strings.Contains(r.Message, fiber.MIMEApplicationJSON) {
+			t.Errorf("got message %q, want the supported media types", r.Message)
+		}
+	}
+
+	// Errors fall back to JSON rather than turning into 406
+	resp, body := get(t, app, fiber.MethodGet, "/error", "text/csv")
+	if resp.StatusCode != http.StatusNotFound || !strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+		t.Fatalf("error with Accept text/csv returned %d %s: %s", resp.StatusCode, resp.Header.Get(fiber.HeaderContentType), body)
+	}
+
+	// CheckAcceptable lets handlers refuse a change they could not report
+	if resp, body = get(t, app, fiber.MethodPost, "/check", "image/png"); resp.StatusCode != http.StatusNotAcceptable {
+		t.Fatalf("CheckAcceptable with Accept image/png returned %d: %s", resp.StatusCode, body)
+	}
+	if resp, body = get(t, app, fiber.MethodPost, "/check", fiber.MIMEApplicationXML); resp.StatusCode != http.StatusOK {
+		t.Fatalf("CheckAcceptable with Accept application/xml returned %d: %s", resp.StatusCode, body)
+	}
+}
+
+func TestNegotiatedContentType(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(NegotiatedContentType(c))
+	})
+
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", fiber.MIMEApplicationJSON},
+		{MIMEApplicationProblemJSON, fiber.MIMEApplicationJSON},
+		{fiber.MIMEApplicationXML, fiber.MIMEApplicationXMLCharsetUTF8},
+		{"application/x-msgpack", "application/x-msgpack"},
+		// CSV is only produced for lists
+		{"text/csv", ""},
+		{"image/png", ""},
+	}
+	for _, tt := range tests {
+		if _, body := get(t, app, fiber.MethodGet, "/", tt.accept); string(body) != tt.contentType {
+			t.Errorf("Accept %q returned %q, want %q", tt.accept, body, tt.contentType)
+		}
+	}
+}
+
+func TestRespondMsgPack(t *testing.T) {
+	resp, body := get(t, newRenderApp(), fiber.MethodGet, "/list", MIMEApplicationMsgPack)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /list returned %d: %s", resp.StatusCode, body)
+	}
+
+	var r map[string]interface{}
+	if err := msgpack.Unmarshal(body, &r); err != nil {
+		t.Fatalf("decoding msgpack: %v", err)
+	}
+	data, _ := r["data"].([]interface{})
+	if len(data) != 2 {
+		t.Fatalf("got data %v, want 2 items", r["data"])
+	}
+	first, _ := data[0].(map[string]interface{})
+	if first["id"] != "a" || first["price"] != 1.5 || first["note"] != nil {
+		t.Fatalf("got first item %v", first)
+	}
+	if _, ok := first["note"]; ok {
+		t.Errorf("first item has note, want it omitted like in JSON")
+	}
+	second, _ := data[1].(map[string]interface{})
+	if price, ok := second["price"].(int8); !ok || price != 2 {
+		t.Errorf("got price %#v, want the whole number 2 encoded as an integer", second["price"])
+	}
+	pagination, _ := r["pagination"].(map[string]interface{})
+	if pagination["last_page"] != int8(3) {
+		t.Errorf("got pagination %v, want last_page 3", pagination)
+	}
+}
+
+func TestRespondXML(t *testing.T) {
+	resp, body := get(t, newRenderApp(), fiber.MethodGet, "/list", fiber.MIMEApplicationXML)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /list returned %d: %s", resp.StatusCode, body)
+	}
+
+	var r struct {
+		XMLName xml.Name `xml:"response"`
+		Status  int      `xml:"status"`
+		Items   []struct {
+			ID   string   `xml:"id"`
+			Lat  float64  `xml:"geo>lat"`
+			Tags []string `xml:"tags>item"`
+		} `xml:"data>item"`
+		Total int `xml:"pagination>total"`
+	}
+	if err := xml.Unmarshal(body, &r); err != nil {
+		t.Fatalf("decoding %s: %v", body, err)
+	}
+	if r.Status != http.StatusOK || len(r.Items) != 2 || r.Items[0].ID != "a" || r.Items[0].Lat != -6.2 ||
+		strings.Join(r.Items[0].Tags, ",") != "x,y" || r.Total != 12 {
+		t.Fatalf("got %+v from %s", r, body)
+	}
+	// Null values such as extra are left out
+	if strings.Contains(string(body), "<extra>") {
+		t.Errorf("got %s, want null values left out", body)
+	}
+}
+
+func TestRespondCSV(t *testing.T) {
+	app := newRenderApp()
+
+	resp, body := get(t, app, fiber.MethodGet, "/list", "text/csv")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /list returned %d: %s", resp.StatusCode, body)
+	}
+	records, err := csv.NewReader(strings.NewReader(string(body))).ReadAll()
+	if err != nil {
+		t.Fatalf("decoding %s: %v", body, err)
+	}
+	want := [][]string{
+		{"id", "price", "geo.lat", "geo.lon", "tags", "extra", "note"},
+		{"a", "1.5", "-6.2", "106.8", `["x","y"]`, "", ""},
+		{"b", "2", "", "", "[]", "", "kedua"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d: %s", len(records), len(want), body)
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("record %d is %q, want %q", i, records[i], want[i])
+		}
+	}
+
+	// Pagination moves to headers
+	for header, want := range map[string]string{HeaderTotalCount: "12", HeaderCurrentPage: "2", HeaderLastPage: "3"} {
+		if got := resp.Header.Get(header); got != want {
+			t.Errorf("got %s %q, want %q", header, got, want)
+		}
+	}
+	resp, _ = get(t, app, fiber.MethodGet, "/cursor", "text/csv")
+	if got := resp.Header.Get(HeaderNextCursor); got != "next" {
+		t.Errorf("got %s %q, want next", HeaderNextCursor, got)
+	}
+}
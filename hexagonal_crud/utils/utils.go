@@ -19,6 +19,8 @@ func IsEmptyStruct(field reflect.Value) bool {
 		return field.Int() == 0
 	case reflect.Struct:
 		return field.IsZero()
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return field.IsNil()
 	default:
 		return false
 	}
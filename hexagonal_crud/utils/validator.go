@@ -1,10 +1,73 @@
 package utils
 
 import (
-	validators "github.com/go-playground/validator/v10"
+	"reflect"
 	"strings"
+
+	validators "github.com/go-playground/validator/v10"
 )
 
+// FieldError describes a single field that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`           // JSON name of the invalid field
+	Rule    string `json:"rule"`            // validation tag that failed, e.g. required
+	Param   string `json:"param,omitempty"` // parameter of the rule, e.g. 255 for max=255
+	Message string `json:"message"`         // human readable message
+}
+
+// ValidationErrors is the result of Validate, one FieldError per failed rule.
+// It implements error so handlers can return it as-is.
+type ValidationErrors []FieldError
+
+// Error joins the messages of all field errors.
+func (v ValidationErrors) Error() string {
+	errMsg := make([]string, 0, len(v))
+	for _, e := range v {
+		errMsg = append(errMsg, e.Message)
+	}
+	return strings.Join(errMsg, ", ")
+}
+
+// validate is shared by every call because the validator caches struct metadata.
+// It is safe for concurrent use once all rules are registered.
+var validate = newValidator()
+
+// ruleMessages holds the messages of rules registered with RegisterRule.
+var ruleMessages = map[string]string{}
+
+func newValidator() *validators.Validate {
+	v := validators.New(validators.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(jsonFieldName)
+	return v
+}
+
+// jsonFieldName reports fields by their JSON name so clients can match errors to request fields.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// RegisterRule registers a custom validation tag. fn receives the field value (pointers are
+// dereferenced) and reports whether it is valid. message is appended to the field name, e.g.
+// "must be a valid SKU". RegisterRule is not safe for concurrent use and must be called before
+// validation starts, typically from an init function.
+func RegisterRule(tag, message string, fn func(value interface{}) bool) error {
+	err := validate.RegisterValidation(tag, func(fl validators.FieldLevel) bool {
+		return fn(fl.Field().Interface())
+	})
+	if err != nil {
+		return err
+	}
+	ruleMessages[tag] = message
+	return nil
+}
+
 func customErrorMessage(fe validators.FieldError) string {
 	switch fe.Tag() {
 	case "required_if":
@@ -15,24 +78,73 @@ func customErrorMessage(fe validators.FieldError) string {
 		return "Invalid email."
 	case "oneof":
 		return fe.Field() + " must be one of the following: " + fe.Param()
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fe.Field() + " must be at least " + fe.Param() + " characters"
+		}
+		return fe.Field() + " must be at least " + fe.Param()
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fe.Field() + " must be at most " + fe.Param() + " characters"
+		}
+		return fe.Field() + " must be at most " + fe.Param()
+	case "gte":
+		return fe.Field() + " must be greater than or equal to " + fe.Param()
+	case "lte":
+		return fe.Field() + " must be less than or equal to " + fe.Param()
+	}
+	if message, ok := ruleMessages[fe.Tag()]; ok {
+		return fe.Field() + " " + message
 	}
 	return fe.Error() // default error
 }
 
-func Validate(payload interface{}) string {
-	var errMsg []string
+// Validate validates payload against its validate tags and returns nil if it is valid.
+func Validate(payload interface{}) ValidationErrors {
+	return toValidationErrors(validate.Struct(payload))
+}
 
-	validate := validators.New()
+// ValidatePartial validates only the fields of payload that are not empty. It is meant for
+// partial updates, where an empty field means "leave unchanged" and rules such as required
+// do not apply.
+func ValidatePartial(payload interface{}) ValidationErrors {
+	value := reflect.Indirect(reflect.ValueOf(payload))
+	if value.Kind() != reflect.Struct {
+		return Validate(payload)
+	}
 
-	var err error
+	var fields []string
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).IsExported() && !IsEmptyStruct(value.Field(i)) {
+			fields = append(fields, value.Type().Field(i).Name)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return toValidationErrors(validate.StructPartial(payload, fields...))
+}
 
-	if err = validate.Struct(payload); err == nil {
-		return ""
+func toValidationErrors(err error) ValidationErrors {
+	if err == nil {
+		return nil
 	}
 
-	for _, e := range err.(validators.ValidationErrors) {
-		errMsg = append(errMsg, customErrorMessage(e))
+	fieldErrors, ok := err.(validators.ValidationErrors)
+	if !ok {
+		return ValidationErrors{{Message: err.Error()}}
 	}
 
-	return strings.Join(errMsg, ", ")
+	result := make(ValidationErrors, 0, len(fieldErrors))
+	for _, e := range fieldErrors {
+		// Namespace uses JSON names and starts with the struct name, e.g. Product.geo.coordinates
+		_, field, _ := strings.Cut(e.Namespace(), ".")
+		result = append(result, FieldError{
+			Field:   field,
+			Rule:    e.Tag(),
+			Param:   e.Param(),
+			Message: customErrorMessage(e),
+		})
+	}
+	return result
 }
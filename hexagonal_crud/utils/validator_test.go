@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func init() {
+	// Rules must be registered before validation starts
+	if err := RegisterRule("testeven", "must be even", func(value interface{}) bool {
+		n, ok := value.(int)
+		return ok && n%2 == 0
+	}); err != nil {
+		panic(err)
+	}
+}
+
+type validatedItem struct {
+	Name   string           `json:"product_name" validate:"required,max=5"`
+	Stock  int              `json:"stock" validate:"gte=0"`
+	Count  int              `json:"count" validate:"omitempty,testeven"`
+	Nested *validatedNested `json:"nested" validate:"omitempty"`
+	Plain  string           `validate:"omitempty,min=2"`
+}
+
+type validatedNested struct {
+	Code string `json:"code" validate:"required"`
+}
+
+func TestValidateReportsFieldErrors(t *testing.T) {
+	if got := Validate(&validatedItem{Name: "Kopi"}); got != nil {
+		t.Fatalf("Validate of a valid item returned %v", got)
+	}
+
+	got := Validate(&validatedItem{Name: "Kopi Susu", Stock: -1, Count: 3, Nested: &validatedNested{}, Plain: "x"})
+	want := ValidationErrors{
+		{Field: "product_name", Rule: "max", Param: "5", Message: "product_name must be at most 5 characters"},
+		{Field: "stock", Rule: "gte", Param: "0", Message: "stock must be greater than or equal to 0"},
+		{Field: "count", Rule: "testeven", Message: "count must be even"},
+		{Field: "nested.code", Rule: "required", Message: "code is required"},
+		{Field: "Plain", Rule: "min", Param: "2", Message: "Plain must be at least 2 characters"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d field errors %+v, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("field error %d is %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// Error joins the messages so ValidationErrors can be returned as an error
+	if msg := got.Error(); !strings.HasPrefix(msg, "product_name must be at most 5 characters, stock must be") {
+		t.Errorf("got message %q", msg)
+	}
+}
+
+func TestValidatePartialSkipsEmptyFields(t *testing.T) {
+	// product_name is required, but empty fields are left unchanged by partial updates
+	if got := ValidatePartial(&validatedItem{Stock: 3}); got != nil {
+		t.Fatalf("ValidatePartial without a name returned %v", got)
+	}
+	if got := ValidatePartial(&validatedItem{}); got != nil {
+		t.Fatalf("ValidatePartial of an empty item returned %v", got)
+	}
+
+	got := ValidatePartial(&validatedItem{Name: "Kopi Susu", Count: 3})
+	if len(got) != 2 || got[0].Field != "product_name" || got[1].Field != "count" {
+		t.Fatalf("got %+v, want errors for product_name and count", got)
+	}
+}